@@ -17,6 +17,7 @@ import (
 	intOtel "github.com/htchan/BookSpider/internal/otel"
 	repo "github.com/htchan/BookSpider/internal/repo/sqlc"
 	"github.com/htchan/BookSpider/internal/router"
+	"github.com/htchan/BookSpider/internal/service"
 )
 
 func main() {
@@ -53,6 +54,11 @@ func main() {
 		log.Error().Err(err).Msg("init tracer failed")
 	}
 
+	mp, err := intOtel.NewMeterProvider(conf.TraceConfig)
+	if err != nil {
+		log.Error().Err(err).Msg("init meter failed")
+	}
+
 	repo.Migrate(conf.DatabaseConfig, "/migrations")
 
 	db, dbErr := repo.OpenDatabaseByConfig(conf.DatabaseConfig)
@@ -78,15 +84,22 @@ func main() {
 	// 	services[siteName] = serv
 	// }
 	services := common.LoadServices(conf.AvailableSiteNames, db, conf.SiteConfigs, 1)
-	readDataService := common.LoadReadDataService(db, conf.SiteConfigs)
+	readDataService := common.LoadReadDataService(db, conf.ReadSiteConfigs())
+
+	var importService service.ImportService
+	if conf.ImportStorage != "" {
+		importService = common.LoadImportService(db, conf.ImportStorage)
+	}
 
 	shutdown.LogEnabled = true
 	shutdownHandler := shutdown.New(syscall.SIGINT, syscall.SIGTERM)
 
 	// load routes
+	// sessions created by api are shared with lite routes
+	auth, limiter := router.NewAuthenticator(conf.AuthConfig), router.NewRateLimiter()
 	r := chi.NewRouter()
-	router.AddAPIRoutes(r, conf, services, readDataService)
-	router.AddLiteRoutes(r, conf, services, readDataService)
+	router.AddAPIRoutes(r, conf, auth, limiter, services, readDataService, importService)
+	router.AddLiteRoutes(r, conf, auth, limiter, services, readDataService)
 
 	server := http.Server{
 		Addr:         ":9427",
@@ -113,6 +126,9 @@ func main() {
 	shutdownHandler.Register("tracer", func() error {
 		return tp.Shutdown(context.Background())
 	})
+	shutdownHandler.Register("meter", func() error {
+		return mp.Shutdown(context.Background())
+	})
 
 	shutdownHandler.Listen(60 * time.Second)
 }
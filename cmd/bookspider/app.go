@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/htchan/BookSpider/internal/common"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	repo "github.com/htchan/BookSpider/internal/repo/sqlc"
+	"github.com/htchan/BookSpider/internal/service"
+)
+
+var errUnknownSite = errors.New("unknown site")
+
+// app loads config, database and services on first use, so a command only
+// needs the environment of what it uses
+type app struct {
+	out io.Writer
+
+	conf     *config.WorkerConfig
+	db       *sql.DB
+	services map[string]service.Service
+	readData service.ReadDataService
+}
+
+func newApp(out io.Writer) *app {
+	return &app{out: out}
+}
+
+func (a *app) config() (*config.WorkerConfig, error) {
+	if a.conf != nil {
+		return a.conf, nil
+	}
+
+	conf, err := config.LoadWorkerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load worker config fail: %w", err)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, fmt.Errorf("validate config fail: %w", err)
+	}
+
+	a.conf = conf
+
+	return a.conf, nil
+}
+
+func (a *app) database() (*sql.DB, error) {
+	if a.db != nil {
+		return a.db, nil
+	}
+
+	conf, err := a.config()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := repo.OpenDatabaseByConfig(conf.DatabaseConfig)
+	if err != nil {
+		return nil, fmt.Errorf("open database fail: %w", err)
+	}
+
+	a.db = db
+
+	return a.db, nil
+}
+
+func (a *app) loadServices() (map[string]service.Service, error) {
+	if a.services != nil {
+		return a.services, nil
+	}
+
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+
+	a.services = common.LoadServices(a.conf.AvailableSiteNames, db, a.conf.SiteConfigs, int64(a.conf.MaxWorkingThreads))
+
+	return a.services, nil
+}
+
+func (a *app) service(site string) (service.Service, error) {
+	services, err := a.loadServices()
+	if err != nil {
+		return nil, err
+	}
+
+	serv, ok := services[site]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownSite, site)
+	}
+
+	return serv, nil
+}
+
+// sitesOf returns the service of site in args, or services of all sites
+// ordered by name if args is empty
+func (a *app) sitesOf(args []string) ([]service.Service, error) {
+	if len(args) > 1 {
+		return nil, errUsage
+	} else if len(args) == 1 {
+		serv, err := a.service(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return []service.Service{serv}, nil
+	}
+
+	services, err := a.loadServices()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]service.Service, 0, len(services))
+	for _, serv := range services {
+		result = append(result, serv)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+
+	return result, nil
+}
+
+func (a *app) readDataService() (service.ReadDataService, error) {
+	if a.readData != nil {
+		return a.readData, nil
+	}
+
+	db, err := a.database()
+	if err != nil {
+		return nil, err
+	}
+
+	a.readData = common.LoadReadDataService(db, a.conf.SiteConfigs)
+
+	return a.readData, nil
+}
+
+func (a *app) book(ctx context.Context, site string, id int) (*model.Book, error) {
+	readData, err := a.readDataService()
+	if err != nil {
+		return nil, err
+	}
+
+	bk, err := readData.Book(ctx, site, strconv.Itoa(id), "")
+	if err != nil {
+		return nil, fmt.Errorf("find book fail: %w", err)
+	}
+
+	return bk, nil
+}
+
+func (a *app) summary(ctx context.Context, site string) error {
+	readData, err := a.readDataService()
+	if err != nil {
+		return err
+	}
+
+	return printJSON(a.out, site+" summary", readData.Stats(ctx, site))
+}
+
+func (a *app) close() {
+	if a.db != nil {
+		a.db.Close()
+		a.db = nil
+	}
+}
@@ -0,0 +1,146 @@
+// bookspider runs the operations of worker on demand with the worker config
+//
+//	bookspider process <site>
+//	bookspider update|download|explore|validate <site> [--id N]
+//	bookspider book show <site> <id>
+//	bookspider patch-missing|patch-storage|backup [site]
+//	bookspider checksum rebuild
+//	bookspider config validate
+//
+// every command accepts --dry-run to print what it would do without doing it
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"process":       {usage: "process <site>", run: runProcess},
+	"update":        {usage: "update <site> [--id N]", run: runUpdate},
+	"download":      {usage: "download <site> [--id N]", run: runDownload},
+	"explore":       {usage: "explore <site> [--id N]", run: runExplore},
+	"validate":      {usage: "validate <site> [--id N]", run: runValidate},
+	"book":          {usage: "book show <site> <id>", run: runBook},
+	"patch-missing": {usage: "patch-missing [site]", run: runPatchMissing},
+	"patch-storage": {usage: "patch-storage [site]", run: runPatchStorage},
+	"backup":        {usage: "backup [site]", run: runBackup},
+	"checksum":      {usage: "checksum rebuild", run: runChecksum},
+	"config":        {usage: "config validate", run: runConfig},
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: bookspider <command> [arguments] [--dry-run]")
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+func main() {
+	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.99999Z07:00"
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx = log.Logger.WithContext(ctx)
+
+	app := newApp(os.Stdout)
+	code := run(ctx, app, os.Stderr, os.Args[1:])
+	app.close()
+	stop()
+
+	os.Exit(code)
+}
+
+// run dispatches args to the command and returns the exit code, 2 for
+// invalid usage and 1 for failed command
+func run(ctx context.Context, a *app, stderr io.Writer, args []string) int {
+	if len(args) < 1 {
+		usage(stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stderr)
+		return 2
+	}
+
+	err := cmd.run(ctx, a, args[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "Usage: bookspider %s [--dry-run]\n", cmd.usage)
+		return 2
+	} else if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("command", strings.Join(args, " ")).Msg("command failed")
+		return 1
+	}
+
+	return 0
+}
+
+// options are the flags shared by all commands
+type options struct {
+	dryRun   bool
+	id       int
+	logLevel string
+}
+
+// parseArgs parses the flags placed before or after the positional
+// arguments, and returns the positional arguments
+func parseArgs(name string, args []string, withID bool) (*options, []string, error) {
+	opts := new(options)
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print what the command would do without doing it")
+	fs.StringVar(&opts.logLevel, "log-level", "info", "level of logs printed to stderr")
+	if withID {
+		fs.IntVar(&opts.id, "id", 0, "id of the book, all books of site if not set")
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, errors.Join(errUsage, err)
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	level, err := zerolog.ParseLevel(opts.logLevel)
+	if err != nil {
+		return nil, nil, errors.Join(errUsage, err)
+	}
+	zerolog.SetGlobalLevel(level)
+
+	return opts, positional, nil
+}
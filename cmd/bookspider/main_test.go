@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	mockservice "github.com/htchan/BookSpider/internal/mock/service/v1"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	"github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_parseArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		args           []string
+		withID         bool
+		wantOpts       *options
+		wantPositional []string
+		wantError      error
+	}{
+		{
+			name:           "positional arguments only",
+			args:           []string{"test"},
+			wantOpts:       &options{logLevel: "info"},
+			wantPositional: []string{"test"},
+		},
+		{
+			name:           "flags before and after positional arguments",
+			args:           []string{"--dry-run", "show", "--log-level", "warn", "test", "1"},
+			wantOpts:       &options{dryRun: true, logLevel: "warn"},
+			wantPositional: []string{"show", "test", "1"},
+		},
+		{
+			name:           "id flag",
+			args:           []string{"test", "--id", "3"},
+			withID:         true,
+			wantOpts:       &options{id: 3, logLevel: "info"},
+			wantPositional: []string{"test"},
+		},
+		{
+			name:      "id flag not accepted",
+			args:      []string{"test", "--id", "3"},
+			withID:    false,
+			wantError: errUsage,
+		},
+		{
+			name:      "invalid id",
+			args:      []string{"test", "--id", "abc"},
+			withID:    true,
+			wantError: errUsage,
+		},
+		{
+			name:      "invalid log level",
+			args:      []string{"test", "--log-level", "loud"},
+			wantError: errUsage,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts, positional, err := parseArgs("test", test.args, test.withID)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantOpts, opts)
+			assert.Equal(t, test.wantPositional, positional)
+		})
+	}
+}
+
+func Test_run(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		args       []string
+		setup      func(*mockservice.MockService, *mockservice.MockReadDataService)
+		wantCode   int
+		wantOut    string
+		wantStderr string
+	}{
+		{
+			name:       "no command",
+			args:       nil,
+			wantCode:   2,
+			wantStderr: "Usage: bookspider <command> [arguments] [--dry-run]",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"unknown"},
+			wantCode:   2,
+			wantStderr: "  patch-missing [site]\n",
+		},
+		{
+			name:       "command with invalid arguments",
+			args:       []string{"process"},
+			wantCode:   2,
+			wantStderr: "Usage: bookspider process <site> [--dry-run]\n",
+		},
+		{
+			name:     "unknown site",
+			args:     []string{"process", "unknown"},
+			wantCode: 1,
+		},
+		{
+			name: "process dry run prints summary",
+			args: []string{"process", "test", "--dry-run"},
+			setup: func(serv *mockservice.MockService, readData *mockservice.MockReadDataService) {
+				readData.EXPECT().Stats(gomock.Any(), "test").Return(repo.Summary{BookCount: 5})
+			},
+			wantCode: 0,
+			wantOut:  "dry run: process test\ntest summary: {\n  \"BookCount\": 5,",
+		},
+		{
+			name: "update dry run of book",
+			args: []string{"update", "test", "--id", "3", "--dry-run"},
+			setup: func(serv *mockservice.MockService, readData *mockservice.MockReadDataService) {
+				bk := &model.Book{Site: "test", ID: 3}
+				readData.EXPECT().Book(gomock.Any(), "test", "3", "").Return(bk, nil)
+				serv.EXPECT().BookURL(bk).Return("https://test.com/3")
+			},
+			wantCode: 0,
+			wantOut:  "dry run: update book test-3 from https://test.com/3\n",
+		},
+		{
+			name: "download runs service",
+			args: []string{"download", "test"},
+			setup: func(serv *mockservice.MockService, readData *mockservice.MockReadDataService) {
+				serv.EXPECT().Download(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, stats *service.DownloadStats) error {
+					stats.Total.Add(2)
+					return nil
+				})
+			},
+			wantCode: 0,
+			wantOut:  "download: total=2",
+		},
+		{
+			name: "patch-missing dry run lists missing ids",
+			args: []string{"patch-missing", "--dry-run"},
+			setup: func(serv *mockservice.MockService, readData *mockservice.MockReadDataService) {
+				serv.EXPECT().MissingBookIDs(gomock.Any()).Return([]int{3, 4}, nil)
+			},
+			wantCode: 0,
+			wantOut:  "dry run: patch 2 missing records of test: [3 4]\n",
+		},
+		{
+			name: "patch-missing dry run fails",
+			args: []string{"patch-missing", "test", "--dry-run"},
+			setup: func(serv *mockservice.MockService, readData *mockservice.MockReadDataService) {
+				serv.EXPECT().MissingBookIDs(gomock.Any()).Return(nil, service.ErrUnavailable)
+			},
+			wantCode: 1,
+		},
+		{
+			name:     "config validate",
+			args:     []string{"config", "validate"},
+			wantCode: 0,
+			wantOut:  "config valid: sites [test], available sites [test]\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			serv := mockservice.NewMockService(ctrl)
+			serv.EXPECT().Name().Return("test").AnyTimes()
+			readData := mockservice.NewMockReadDataService(ctrl)
+			if test.setup != nil {
+				test.setup(serv, readData)
+			}
+
+			var out, stderr bytes.Buffer
+			a := &app{
+				out: &out,
+				conf: &config.WorkerConfig{
+					AvailableSiteNames: []string{"test"},
+					SiteConfigs:        map[string]config.SiteConfig{"test": {}},
+				},
+				services: map[string]service.Service{"test": serv},
+				readData: readData,
+			}
+
+			code := run(t.Context(), a, &stderr, test.args)
+			assert.Equal(t, test.wantCode, code)
+			assert.Contains(t, out.String(), test.wantOut)
+			assert.Contains(t, stderr.String(), test.wantStderr)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/htchan/BookSpider/internal/common"
+	"github.com/htchan/BookSpider/internal/service"
+)
+
+func runPatchMissing(ctx context.Context, a *app, args []string) error {
+	opts, positional, err := parseArgs("patch-missing", args, false)
+	if err != nil {
+		return err
+	}
+
+	services, err := a.sitesOf(positional)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, serv := range services {
+		if opts.dryRun {
+			ids, err := serv.MissingBookIDs(ctx)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			printDryRun(a.out, "patch %d missing records of %s: %v", len(ids), serv.Name(), ids)
+			continue
+		}
+
+		stats := new(service.UpdateStats)
+		finish := watchProgress(a.out, "patch-missing "+serv.Name(), stats)
+		errs = append(errs, serv.PatchMissingRecords(ctx, stats))
+		finish()
+	}
+
+	return errors.Join(errs...)
+}
+
+func runPatchStorage(ctx context.Context, a *app, args []string) error {
+	opts, positional, err := parseArgs("patch-storage", args, false)
+	if err != nil {
+		return err
+	}
+
+	services, err := a.sitesOf(positional)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, serv := range services {
+		if opts.dryRun {
+			printDryRun(a.out, "patch download status of %s by storage", serv.Name())
+			continue
+		}
+
+		stats := new(service.PatchStorageStats)
+		finish := watchProgress(a.out, "patch-storage "+serv.Name(), stats)
+		errs = append(errs, serv.PatchDownloadStatus(ctx, stats))
+		finish()
+	}
+
+	return errors.Join(errs...)
+}
+
+func runBackup(ctx context.Context, a *app, args []string) error {
+	opts, positional, err := parseArgs("backup", args, false)
+	if err != nil {
+		return err
+	}
+
+	services, err := a.sitesOf(positional)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, serv := range services {
+		if opts.dryRun {
+			printDryRun(a.out, "backup %s to %s", serv.Name(), a.conf.SiteConfigs[serv.Name()].BackupDirectory)
+			continue
+		}
+
+		backupErr := serv.Backup(ctx)
+		if backupErr != nil {
+			fmt.Fprintf(a.out, "backup %s: failed\n", serv.Name())
+		} else {
+			fmt.Fprintf(a.out, "backup %s: completed\n", serv.Name())
+		}
+		errs = append(errs, backupErr)
+	}
+
+	return errors.Join(errs...)
+}
+
+func runChecksum(ctx context.Context, a *app, args []string) error {
+	opts, positional, err := parseArgs("checksum", args, false)
+	if err != nil {
+		return err
+	} else if len(positional) != 1 || positional[0] != "rebuild" {
+		return errUsage
+	}
+
+	db, err := a.database()
+	if err != nil {
+		return err
+	}
+
+	name := "checksum rebuild"
+	if opts.dryRun {
+		name = "checksum rebuild (dry run)"
+	}
+
+	stats := new(service.ChecksumStats)
+	finish := watchProgress(a.out, name, stats)
+	defer finish()
+
+	return common.LoadChecksumService(db).RebuildChecksums(ctx, opts.dryRun, stats)
+}
+
+func runConfig(ctx context.Context, a *app, args []string) error {
+	_, positional, err := parseArgs("config", args, false)
+	if err != nil {
+		return err
+	} else if len(positional) != 1 || positional[0] != "validate" {
+		return errUsage
+	}
+
+	// validating config changes nothing, so dry run validates as well
+	conf, err := a.config()
+	if err != nil {
+		return err
+	}
+
+	sites := make([]string, 0, len(conf.SiteConfigs))
+	for site := range conf.SiteConfigs {
+		sites = append(sites, site)
+	}
+	sort.Strings(sites)
+
+	fmt.Fprintf(a.out, "config valid: sites %v, available sites %v\n", sites, conf.AvailableSiteNames)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+const progressInterval = 10 * time.Second
+
+// printStats prints the counters of stats struct in one line, e.g.
+// "update: total=3 fail=1 unchanged=2"
+func printStats(w io.Writer, name string, stats any) {
+	v := reflect.ValueOf(stats).Elem()
+
+	fields := make([]string, 0, v.NumField())
+	for i := range v.NumField() {
+		counter, ok := v.Field(i).Addr().Interface().(*atomic.Int64)
+		if !ok {
+			continue
+		}
+
+		fields = append(fields, fmt.Sprintf("%s=%d", snakeCase(v.Type().Field(i).Name), counter.Load()))
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", name, strings.Join(fields, " "))
+}
+
+// watchProgress prints stats every progress interval until the returned
+// function is called, which prints the final stats
+func watchProgress(w io.Writer, name string, stats any) func() {
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				printStats(w, name+" (in progress)", stats)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		printStats(w, name, stats)
+	}
+}
+
+func printJSON(w io.Writer, name string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s fail: %w", name, err)
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", name, data)
+
+	return nil
+}
+
+func printDryRun(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, "dry run: "+format+"\n", args...)
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
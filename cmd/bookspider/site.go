@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/service"
+)
+
+// siteCommand parses the arguments of command running on one site
+func (a *app) siteCommand(name string, args []string, withID bool) (*options, service.Service, error) {
+	opts, positional, err := parseArgs(name, args, withID)
+	if err != nil {
+		return nil, nil, err
+	} else if len(positional) != 1 {
+		return nil, nil, errUsage
+	}
+
+	serv, err := a.service(positional[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return opts, serv, nil
+}
+
+func runProcess(ctx context.Context, a *app, args []string) error {
+	opts, serv, err := a.siteCommand("process", args, false)
+	if err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		printDryRun(a.out, "process %s", serv.Name())
+		return a.summary(ctx, serv.Name())
+	}
+
+	fmt.Fprintf(a.out, "process %s: started, steps are logged at trace level\n", serv.Name())
+	processErr := serv.Process(ctx)
+	fmt.Fprintf(a.out, "process %s: completed\n", serv.Name())
+
+	return errors.Join(processErr, a.summary(ctx, serv.Name()))
+}
+
+func runUpdate(ctx context.Context, a *app, args []string) error {
+	opts, serv, err := a.siteCommand("update", args, true)
+	if err != nil {
+		return err
+	}
+
+	stats := new(service.UpdateStats)
+	if opts.id > 0 {
+		bk, err := a.book(ctx, serv.Name(), opts.id)
+		if err != nil {
+			return err
+		}
+
+		if opts.dryRun {
+			printDryRun(a.out, "update book %s from %s", bk, serv.BookURL(bk))
+			return nil
+		}
+
+		updateErr := serv.UpdateBook(ctx, bk, stats)
+		printStats(a.out, "update", stats)
+		fmt.Fprintln(a.out, serv.BookInfo(ctx, bk))
+
+		return updateErr
+	}
+
+	if opts.dryRun {
+		printDryRun(a.out, "update books of %s", serv.Name())
+		return nil
+	}
+
+	finish := watchProgress(a.out, "update", stats)
+	defer finish()
+
+	return serv.Update(ctx, stats)
+}
+
+func runDownload(ctx context.Context, a *app, args []string) error {
+	opts, serv, err := a.siteCommand("download", args, true)
+	if err != nil {
+		return err
+	}
+
+	stats := new(service.DownloadStats)
+	if opts.id > 0 {
+		bk, err := a.book(ctx, serv.Name(), opts.id)
+		if err != nil {
+			return err
+		}
+
+		if opts.dryRun {
+			printDryRun(a.out, "download book %s from %s", bk, serv.BookURL(bk))
+			return nil
+		}
+
+		downloadErr := serv.DownloadBook(ctx, bk, stats)
+		printStats(a.out, "download", stats)
+		fmt.Fprintln(a.out, serv.BookInfo(ctx, bk))
+
+		return downloadErr
+	}
+
+	if opts.dryRun {
+		printDryRun(a.out, "download end books of %s", serv.Name())
+		return nil
+	}
+
+	finish := watchProgress(a.out, "download", stats)
+	defer finish()
+
+	return serv.Download(ctx, stats)
+}
+
+func runExplore(ctx context.Context, a *app, args []string) error {
+	opts, serv, err := a.siteCommand("explore", args, true)
+	if err != nil {
+		return err
+	}
+
+	stats := new(service.UpdateStats)
+	if opts.id > 0 {
+		// book not found in database is explored as a new book
+		bk, err := a.book(ctx, serv.Name(), opts.id)
+		if errors.Is(err, sql.ErrNoRows) {
+			newBk := model.NewBook(serv.Name(), opts.id)
+			bk = &newBk
+		} else if err != nil {
+			return err
+		}
+
+		if opts.dryRun {
+			printDryRun(a.out, "explore book %s from %s", bk, serv.BookURL(bk))
+			return nil
+		}
+
+		exploreErr := serv.ExploreBook(ctx, bk, stats)
+		printStats(a.out, "explore", stats)
+		fmt.Fprintln(a.out, serv.BookInfo(ctx, bk))
+
+		return exploreErr
+	}
+
+	if opts.dryRun {
+		printDryRun(a.out, "explore new books of %s", serv.Name())
+		return a.summary(ctx, serv.Name())
+	}
+
+	finish := watchProgress(a.out, "explore", stats)
+	defer finish()
+
+	return serv.Explore(ctx, stats)
+}
+
+func runValidate(ctx context.Context, a *app, args []string) error {
+	opts, serv, err := a.siteCommand("validate", args, true)
+	if err != nil {
+		return err
+	}
+
+	if opts.id > 0 {
+		bk, err := a.book(ctx, serv.Name(), opts.id)
+		if err != nil {
+			return err
+		}
+
+		if opts.dryRun {
+			printDryRun(a.out, "validate end of book %s", bk)
+			return nil
+		}
+
+		validateErr := serv.ValidateBookEnd(ctx, bk)
+		fmt.Fprintln(a.out, serv.BookInfo(ctx, bk))
+
+		return validateErr
+	}
+
+	if opts.dryRun {
+		printDryRun(a.out, "validate end of books of %s", serv.Name())
+		return nil
+	}
+
+	validateErr := serv.ValidateEnd(ctx)
+
+	return errors.Join(validateErr, a.summary(ctx, serv.Name()))
+}
+
+func runBook(ctx context.Context, a *app, args []string) error {
+	_, positional, err := parseArgs("book", args, false)
+	if err != nil {
+		return err
+	} else if len(positional) != 3 || positional[0] != "show" {
+		return errUsage
+	}
+
+	serv, err := a.service(positional[1])
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(positional[2])
+	if err != nil {
+		return errors.Join(errUsage, service.ErrInvalidBookID)
+	}
+
+	// showing book changes nothing, so dry run shows the book as well
+	bk, err := a.book(ctx, serv.Name(), id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.out, serv.BookInfo(ctx, bk))
+	fmt.Fprintf(a.out, "url: %s\n", serv.BookURL(bk))
+
+	return nil
+}
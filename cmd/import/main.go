@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/htchan/BookSpider/internal/common"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	repo "github.com/htchan/BookSpider/internal/repo/sqlc"
+	"github.com/htchan/BookSpider/internal/service"
+)
+
+func main() {
+	filePath := flag.String("file", "", "txt or epub file to import")
+	formatStr := flag.String("format", "", "format of file, default by file extension")
+	title := flag.String("title", "", "title of book, default by file content")
+	writer := flag.String("writer", "", "writer of book, default by file content")
+	bookType := flag.String("type", "", "type of book")
+	flag.Parse()
+
+	zerolog.TimeFieldFormat = "2006-01-02T15:04:05.99999Z07:00"
+
+	if *filePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conf, confErr := config.LoadImportConfig()
+	if confErr != nil {
+		log.Fatal().Err(confErr).Msg("load import config")
+	}
+
+	if validErr := conf.Validate(); validErr != nil {
+		log.Fatal().Err(validErr).Msg("validate config fail")
+	}
+
+	db, dbErr := repo.OpenDatabaseByConfig(conf.DatabaseConfig)
+	if dbErr != nil {
+		log.Fatal().Err(dbErr).Msg("load db fail")
+	}
+	defer db.Close()
+
+	file, err := os.Open(*filePath)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", *filePath).Msg("open file fail")
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		log.Fatal().Err(err).Str("file", *filePath).Msg("stat file fail")
+	}
+
+	if *formatStr == "" {
+		*formatStr = strings.ToLower(strings.TrimPrefix(filepath.Ext(*filePath), "."))
+	}
+
+	ctx := log.Logger.WithContext(context.Background())
+	bk, err := common.LoadImportService(db, conf.Storage).ImportBook(ctx, service.ImportParams{
+		Format: *formatStr,
+		File:   file,
+		Size:   info.Size(),
+		Title:  *title,
+		Writer: *writer,
+		Type:   *bookType,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Str("file", *filePath).Msg("import book fail")
+	}
+
+	log.Info().Str("book", bk.String()).Str("title", bk.Title).Str("writer", bk.Writer.Name).Msg("book imported")
+}
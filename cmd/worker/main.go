@@ -75,6 +75,12 @@ func main() {
 	}
 	defer tp.Shutdown(context.Background())
 
+	mp, err := intOtel.NewMeterProvider(conf.TraceConfig)
+	if err != nil {
+		log.Error().Err(err).Msg("init meter failed")
+	}
+	defer mp.Shutdown(context.Background())
+
 	repo.Migrate(conf.DatabaseConfig, "/migrations")
 
 	db, dbErr := repo.OpenDatabaseByConfig(conf.DatabaseConfig)
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS books__work_id;
+ALTER TABLE books DROP COLUMN IF EXISTS work_id;
+
+DROP INDEX IF EXISTS works__title_key;
+DROP INDEX IF EXISTS works__writer_key;
+
+DROP TABLE IF EXISTS works;
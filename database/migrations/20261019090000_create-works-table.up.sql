@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS works (
+  id SERIAL PRIMARY KEY,
+  title TEXT NOT NULL,
+  writer TEXT NOT NULL,
+  title_key TEXT NOT NULL,
+  writer_key TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS works__title_key ON works(title_key);
+CREATE INDEX IF NOT EXISTS works__writer_key ON works(writer_key);
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS work_id INTEGER;
+CREATE INDEX IF NOT EXISTS books__work_id ON books(work_id);
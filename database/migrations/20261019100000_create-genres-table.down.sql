@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS books__type;
+DROP INDEX IF EXISTS genres__genre;
+
+DROP TABLE IF EXISTS genres;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS genres (
+  site VARCHAR(15) NOT NULL,
+  type VARCHAR(20) NOT NULL,
+  genre VARCHAR(20) NOT NULL,
+  PRIMARY KEY (site, type)
+);
+
+CREATE INDEX IF NOT EXISTS genres__genre ON genres(genre);
+CREATE INDEX IF NOT EXISTS books__type ON books(site, type);
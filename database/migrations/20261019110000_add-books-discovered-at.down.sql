@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS books__id_keyset;
+DROP INDEX IF EXISTS books__title_keyset;
+DROP INDEX IF EXISTS books__update_date_keyset;
+DROP INDEX IF EXISTS books__discovered_at;
+ALTER TABLE books DROP COLUMN IF EXISTS discovered_at;
@@ -0,0 +1,7 @@
+-- books exist before this migration are discovered at the migration time
+ALTER TABLE books ADD COLUMN IF NOT EXISTS discovered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+-- keyset indexes of book listings, in the order of sort key, site, id and hash code
+CREATE INDEX IF NOT EXISTS books__discovered_at ON books(discovered_at, site, id, hash_code);
+CREATE INDEX IF NOT EXISTS books__update_date_keyset ON books((coalesce(update_date, '')), site, id, hash_code);
+CREATE INDEX IF NOT EXISTS books__title_keyset ON books((coalesce(title, '')), site, id, hash_code);
+CREATE INDEX IF NOT EXISTS books__id_keyset ON books(id, site, hash_code);
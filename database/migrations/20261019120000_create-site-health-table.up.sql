@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS site_health (
+  site VARCHAR(15) PRIMARY KEY,
+  status VARCHAR(10) NOT NULL,
+  phase VARCHAR(30) NOT NULL,
+  checked INTEGER NOT NULL DEFAULT 0,
+  failed INTEGER NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  checked_at TIMESTAMP NOT NULL
+);
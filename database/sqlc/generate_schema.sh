@@ -7,7 +7,7 @@ while ! docker exec -i bookspider-sqlc-generator pg_isready -U book_spider; do s
 
 # run migration and dump schema
 docker exec bookspider-sqlc-generator bash -c 'for filename in /migrations/*.up.sql; do psql -U book_spider -d db -f $filename; done' && \
-docker exec bookspider-sqlc-generator bash -c "pg_dump -U book_spider -d db -t books -t writers -t errors --schema-only > /sqlc/schema.sql"
+docker exec bookspider-sqlc-generator bash -c "pg_dump -U book_spider -d db -t books -t writers -t errors -t works -t genres --schema-only > /sqlc/schema.sql"
 
 # kill container
 docker kill bookspider-sqlc-generator
\ No newline at end of file
@@ -14,6 +14,17 @@ VALUES
 ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
+-- name: CreateBookWithNextID :one
+-- takes the id next to the largest id of site, no row is returned when a
+-- concurrent insert takes the same id first
+INSERT INTO books
+(site, id, hash_code, title, writer_id, writer_checksum, type,
+update_date, update_chapter, status, is_downloaded, checksum)
+VALUES
+($1, (select coalesce(max(id), 0) + 1 from books where site=$1), 0, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (site, id, hash_code) DO NOTHING
+RETURNING *;
+
 -- name: UpdateBook :one
 Update books SET 
 title=$4, writer_id=$5, writer_checksum=$12, type=$6, update_date=$7, update_chapter=$8,
@@ -81,38 +92,450 @@ from books left join writers on books.writer_id=writers.id
 where books.site=$1 and books.status='END' and books.is_downloaded=false
 order by books.site, books.id desc, books.hash_code desc;
 
--- name: ListBooksByTitleWriter :many
+-- listing queries are one per sort, so the keyset compares the typed columns
+-- of the sort. direction is chosen by ascending and has_cursor parameters, which
+-- a generic plan of prepared statement can't fold, so the keyset may not use
+-- the sort index.
+-- books of a work are listed once by the latest updated book matching search
+-- name: ListBooksByTitleWriterUpdated :many
 select books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.update_date, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any(sqlc.arg(titles)::text[]) or
+  writers.name like any(sqlc.arg(writers)::text[])) and
+  (sqlc.arg(genre)::text = '' or coalesce(genres.genre, 'other') = sqlc.arg(genre)::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any(sqlc.arg(titles)::text[]) or
+      other_writers.name like any(sqlc.arg(writers)::text[])) and
+      (sqlc.arg(genre)::text = '' or coalesce(other_genres.genre, 'other') = sqlc.arg(genre)::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) > (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) < (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then coalesce(books.update_date, '') end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  coalesce(books.update_date, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: ListBooksByTitleWriterTitle :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.title, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any(sqlc.arg(titles)::text[]) or
+  writers.name like any(sqlc.arg(writers)::text[])) and
+  (sqlc.arg(genre)::text = '' or coalesce(genres.genre, 'other') = sqlc.arg(genre)::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any(sqlc.arg(titles)::text[]) or
+      other_writers.name like any(sqlc.arg(writers)::text[])) and
+      (sqlc.arg(genre)::text = '' or coalesce(other_genres.genre, 'other') = sqlc.arg(genre)::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) > (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) < (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then coalesce(books.title, '') end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  coalesce(books.title, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: ListBooksByTitleWriterID :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  ''::text as sort_key
 from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.status != 'ERROR' and 
-  (($1 != '%%' and books.title like $1) or
-  ($2 != '%%' and writers.name like $2))
-order by books.update_date desc, books.id desc, books.site desc limit $3 offset $4;
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any(sqlc.arg(titles)::text[]) or
+  writers.name like any(sqlc.arg(writers)::text[])) and
+  (sqlc.arg(genre)::text = '' or coalesce(genres.genre, 'other') = sqlc.arg(genre)::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any(sqlc.arg(titles)::text[]) or
+      other_writers.name like any(sqlc.arg(writers)::text[])) and
+      (sqlc.arg(genre)::text = '' or coalesce(other_genres.genre, 'other') = sqlc.arg(genre)::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (books.id, books.site, books.hash_code) > (
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (books.id, books.site, books.hash_code) < (
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  books.id desc, books.site desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: ListBooksByTitleWriterDiscovered :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  to_char(books.discovered_at, 'YYYY-MM-DD"T"HH24:MI:SS.US')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any(sqlc.arg(titles)::text[]) or
+  writers.name like any(sqlc.arg(writers)::text[])) and
+  (sqlc.arg(genre)::text = '' or coalesce(genres.genre, 'other') = sqlc.arg(genre)::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any(sqlc.arg(titles)::text[]) or
+      other_writers.name like any(sqlc.arg(writers)::text[])) and
+      (sqlc.arg(genre)::text = '' or coalesce(other_genres.genre, 'other') = sqlc.arg(genre)::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (books.discovered_at, books.site, books.id, books.hash_code) > (
+    sqlc.arg(cursor_key)::text::timestamp,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (books.discovered_at, books.site, books.id, books.hash_code) < (
+    sqlc.arg(cursor_key)::text::timestamp,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then books.discovered_at end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  books.discovered_at desc, books.site desc, books.id desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: CountBooksByTitleWriter :one
+select count(*)
+from books left join writers on books.writer_id=writers.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any(sqlc.arg(titles)::text[]) or
+  writers.name like any(sqlc.arg(writers)::text[])) and
+  (sqlc.arg(genre)::text = '' or coalesce(genres.genre, 'other') = sqlc.arg(genre)::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any(sqlc.arg(titles)::text[]) or
+      other_writers.name like any(sqlc.arg(writers)::text[])) and
+      (sqlc.arg(genre)::text = '' or coalesce(other_genres.genre, 'other') = sqlc.arg(genre)::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  ));
 
 -- name: ListRandomBooks :many
+with sampled as (
+  select books.site, books.id, books.hash_code, books.title,
+    books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+    books.update_date, books.update_chapter, 
+    books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+    case sqlc.arg(sort_by)::text
+      when 'title' then coalesce(books.title, '')
+      when 'id' then lpad(books.id::text, 10, '0')
+      when 'discovered' then to_char(books.discovered_at, 'YYYYMMDDHH24MISSUS')
+      else coalesce(books.update_date, '')
+    end::text as sort_key
+  from books left join writers on books.writer_id=writers.id 
+    left join errors on books.site=errors.site and books.id=errors.id
+    left join genres on books.site=genres.site and books.type=genres.type
+  where books.is_downloaded=true and
+    (sqlc.arg(genre)::text = '' or coalesce(genres.genre, 'other') = sqlc.arg(genre)::text)
+  order by books.site, books.id desc, books.hash_code desc 
+  limit sqlc.arg(limit_count) offset RANDOM() * 
+  greatest(
+    (select count(*) - sqlc.arg(limit_count)
+    from books as bks left join genres as gns on bks.site=gns.site and bks.type=gns.type
+    where bks.is_downloaded=true and
+    (sqlc.arg(genre)::text = '' or coalesce(gns.genre, 'other') = sqlc.arg(genre)::text)), 0
+  )
+)
+select sampled.site, sampled.id, sampled.hash_code, sampled.title,
+  sampled.writer_id, sampled.writer_name, sampled.type,
+  sampled.update_date, sampled.update_chapter,
+  sampled.status, sampled.is_downloaded, sampled.error_data
+from sampled
+order by
+  case when sqlc.arg(sort_by)::text = 'title' then sampled.sort_key end asc,
+  sampled.sort_key desc, sampled.site desc, sampled.id desc, sampled.hash_code desc;
+
+-- name: ListBooksByGenreUpdated :many
 select books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id 
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.update_date, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.is_downloaded=true
-order by books.site, books.id desc, books.hash_code desc 
-limit $1 offset RANDOM() * 
-greatest(
-  (select count(*) - $1
-  from books as bks where bks.is_downloaded=true), 0
-);
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = sqlc.arg(genre)::text and
+  (sqlc.arg(site)::text = '' or books.site = sqlc.arg(site)::text) and
+  (
+    (sqlc.arg(status)::text = '' and books.status != 'ERROR') or
+    books.status = sqlc.arg(status)::text
+  ) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) > (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) < (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then coalesce(books.update_date, '') end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  coalesce(books.update_date, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: ListBooksByGenreTitle :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.title, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = sqlc.arg(genre)::text and
+  (sqlc.arg(site)::text = '' or books.site = sqlc.arg(site)::text) and
+  (
+    (sqlc.arg(status)::text = '' and books.status != 'ERROR') or
+    books.status = sqlc.arg(status)::text
+  ) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) > (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) < (
+    sqlc.arg(cursor_key)::text,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then coalesce(books.title, '') end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  coalesce(books.title, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: ListBooksByGenreID :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  ''::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = sqlc.arg(genre)::text and
+  (sqlc.arg(site)::text = '' or books.site = sqlc.arg(site)::text) and
+  (
+    (sqlc.arg(status)::text = '' and books.status != 'ERROR') or
+    books.status = sqlc.arg(status)::text
+  ) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (books.id, books.site, books.hash_code) > (
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (books.id, books.site, books.hash_code) < (
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  books.id desc, books.site desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: ListBooksByGenreDiscovered :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  to_char(books.discovered_at, 'YYYY-MM-DD"T"HH24:MI:SS.US')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = sqlc.arg(genre)::text and
+  (sqlc.arg(site)::text = '' or books.site = sqlc.arg(site)::text) and
+  (
+    (sqlc.arg(status)::text = '' and books.status != 'ERROR') or
+    books.status = sqlc.arg(status)::text
+  ) and (
+  not sqlc.arg(has_cursor)::bool or (
+    sqlc.arg(ascending)::bool and (books.discovered_at, books.site, books.id, books.hash_code) > (
+    sqlc.arg(cursor_key)::text::timestamp,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  ) or (
+    not sqlc.arg(ascending)::bool and (books.discovered_at, books.site, books.id, books.hash_code) < (
+    sqlc.arg(cursor_key)::text::timestamp,
+    sqlc.arg(cursor_site)::text,
+    sqlc.arg(cursor_id)::integer,
+    sqlc.arg(cursor_hash_code)::integer
+    )
+  )
+)
+order by
+  case when sqlc.arg(ascending)::bool then books.discovered_at end asc,
+  case when sqlc.arg(ascending)::bool then books.site end asc,
+  case when sqlc.arg(ascending)::bool then books.id end asc,
+  case when sqlc.arg(ascending)::bool then books.hash_code end asc,
+  books.discovered_at desc, books.site desc, books.id desc, books.hash_code desc
+limit sqlc.arg(limit_count);
+
+-- name: CountBooksByGenre :one
+select count(*)
+from books left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = sqlc.arg(genre)::text and
+  (sqlc.arg(site)::text = '' or books.site = sqlc.arg(site)::text) and
+  (
+    (sqlc.arg(status)::text = '' and books.status != 'ERROR') or
+    books.status = sqlc.arg(status)::text
+  );
+
+-- name: ListUnmappedBookTypes :many
+select distinct books.type::text as type
+from books left join genres on books.site=genres.site and books.type=genres.type
+where books.site=$1 and books.type is not null and books.type != '' and
+  genres.genre is null;
+
+-- name: CreateGenre :exec
+insert into genres (site, type, genre) values ($1, $2, $3)
+on conflict (site, type) do nothing;
+
+-- name: SaveSiteHealth :exec
+insert into site_health (site, status, phase, checked, failed, error, checked_at)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (site) do update set status=$2, phase=$3, checked=$4, failed=$5, error=$6, checked_at=$7;
+
+-- name: GetSiteHealth :one
+select site, status, phase, checked, failed, error, checked_at from site_health where site=$1;
 
 -- name: GetBookGroupByID :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
 from books
   left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
@@ -121,13 +544,18 @@ where (books.checksum, books.writer_checksum) = (
   where bks.site=$1 and bks.id=$2 
   and bks.checksum != '' and bks.writer_checksum != ''
   order by bks.hash_code desc limit 1
+) or books.work_id = (
+  select bks.work_id from books as bks
+  where bks.site=$1 and bks.id=$2 and bks.work_id is not null
+  order by bks.hash_code desc limit 1
 ) or books.site=$1 and books.id=$2;
 
 -- name: GetBookGroupByIDHash :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
 from books
   left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
@@ -136,8 +564,65 @@ where (books.checksum, books.writer_checksum) = (
   where bks.site=$1 and bks.id=$2 and bks.hash_code=$3 
   and bks.checksum != '' and bks.writer_checksum != ''
   order by bks.hash_code desc limit 1
+) or books.work_id = (
+  select bks.work_id from books as bks
+  where bks.site=$1 and bks.id=$2 and bks.hash_code=$3 and bks.work_id is not null
 ) or books.site=$1 and books.id=$2;
 
+-- name: CreateWork :one
+insert into works (title, writer, title_key, writer_key)
+values ($1, $2, $3, $4)
+returning *;
+
+-- name: GetWork :one
+select * from works where id=$1;
+
+-- name: ListWorkCandidates :many
+select * from works where title_key=$1 or writer_key=$2
+order by id;
+
+-- name: ListBooksByWorkID :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.work_id=$1
+order by books.site, books.id, books.hash_code;
+
+-- name: ListBooksByWorkIDs :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.work_id = any(sqlc.arg(work_ids)::integer[]) and books.status != 'ERROR'
+order by books.work_id, books.site, books.id, books.hash_code;
+
+-- name: ListBooksWithoutWork :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1 and books.work_id is null and books.status != 'ERROR'
+order by books.site, books.id, books.hash_code;
+
+-- name: UpdateBookWork :exec
+update books set work_id=$4 where site=$1 and id=$2 and hash_code=$3;
+
+-- name: MoveWorkBooks :exec
+update books set work_id=$1 where work_id=$2;
+
+-- name: DeleteWork :exec
+delete from works where id=$1;
+
 -- name: UpdateBooksStatus :exec
 update books set is_downloaded=false, status='END' 
 where (update_date < $1 or 
@@ -160,11 +645,121 @@ where (update_date < $1 or
     update_chapter like '%外传%' or update_chapter like '%结尾%') and 
   status='INPROGRESS';
 
+-- name: ListBooksWithoutChecksum :many
+select books.site, books.id, books.hash_code, coalesce(books.title, '') as title
+from books
+where books.status != 'ERROR' and (books.checksum='' or books.checksum is null)
+order by books.site, books.id, books.hash_code;
+
+-- name: UpdateBookChecksum :exec
+update books set checksum=$4 where site=$1 and id=$2 and hash_code=$3;
+
 -- name: CreateWriter :one
 insert into writers (name, checksum) values ($1, $2) 
 on conflict (name) do update set name=$1 
 returning *;
 
+-- name: GetWriter :one
+select id, coalesce(name, '') as name from writers where id=$1;
+
+-- name: ListBooksByWriter :many
+with latest as (
+  select distinct on (books.site, books.id)
+    books.site, books.id, books.hash_code, books.title,
+    books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+    books.update_date, books.update_chapter, 
+    books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+    coalesce(books.work_id, 0) as work_id,
+    books.discovered_at
+  from books left join writers on books.writer_id=writers.id 
+    left join errors on books.site=errors.site and books.id=errors.id
+  where books.writer_id=sqlc.arg(writer_id) or books.writer_checksum = (
+    select wts.checksum from writers as wts 
+    where wts.id=sqlc.arg(writer_id) and wts.checksum != ''
+  )
+  order by books.site, books.id, books.hash_code desc
+)
+select latest.site, latest.id, latest.hash_code, latest.title,
+  latest.writer_id, latest.writer_name, latest.type,
+  latest.update_date, latest.update_chapter,
+  latest.status, latest.is_downloaded, latest.error_data,
+  latest.work_id
+from latest
+order by
+  case when sqlc.arg(sort_by)::text = 'title' then coalesce(latest.title, '') end asc,
+  case when sqlc.arg(sort_by)::text = 'id' then latest.id end desc,
+  case when sqlc.arg(sort_by)::text = 'discovered' then latest.discovered_at end desc,
+  case when sqlc.arg(sort_by)::text not in ('title', 'id', 'discovered') then coalesce(latest.update_date, '') end desc,
+  latest.site desc, latest.id desc;
+
+-- writer listings are one per order. writers are listed from the cursor in
+-- descending order of the keys, or ascending if the cursor is backward. id is
+-- negated so writers of the same keys are listed by id
+-- name: ListWritersByBookCount :many
+select writers.id, coalesce(writers.name, '') as name,
+  count(distinct (books.site, books.id)) as book_count,
+  coalesce(max(books.update_date), '')::text as latest_update
+from writers join books on books.writer_id=writers.id
+where books.status != 'ERROR' and (
+  cardinality(sqlc.arg(names)::text[]) = 0 or
+  writers.name like any(sqlc.arg(names)::text[])
+)
+group by writers.id
+having not sqlc.arg(has_cursor)::bool or (
+  not sqlc.arg(backward)::bool and (count(distinct (books.site, books.id)), -writers.id) < (
+    sqlc.arg(cursor_book_count)::bigint,
+    -sqlc.arg(cursor_id)::integer
+  )
+) or (
+  sqlc.arg(backward)::bool and (count(distinct (books.site, books.id)), -writers.id) > (
+    sqlc.arg(cursor_book_count)::bigint,
+    -sqlc.arg(cursor_id)::integer
+  )
+)
+order by
+  case when sqlc.arg(backward)::bool then count(distinct (books.site, books.id)) end asc,
+  case when sqlc.arg(backward)::bool then writers.id end desc,
+  count(distinct (books.site, books.id)) desc, writers.id asc
+limit sqlc.arg(limit_count);
+
+-- name: ListWritersByLatestUpdate :many
+select writers.id, coalesce(writers.name, '') as name,
+  count(distinct (books.site, books.id)) as book_count,
+  coalesce(max(books.update_date), '')::text as latest_update
+from writers join books on books.writer_id=writers.id
+where books.status != 'ERROR' and (
+  cardinality(sqlc.arg(names)::text[]) = 0 or
+  writers.name like any(sqlc.arg(names)::text[])
+)
+group by writers.id
+having not sqlc.arg(has_cursor)::bool or (
+  not sqlc.arg(backward)::bool and (coalesce(max(books.update_date), ''), count(distinct (books.site, books.id)), -writers.id) < (
+    sqlc.arg(cursor_latest_update)::text,
+    sqlc.arg(cursor_book_count)::bigint,
+    -sqlc.arg(cursor_id)::integer
+  )
+) or (
+  sqlc.arg(backward)::bool and (coalesce(max(books.update_date), ''), count(distinct (books.site, books.id)), -writers.id) > (
+    sqlc.arg(cursor_latest_update)::text,
+    sqlc.arg(cursor_book_count)::bigint,
+    -sqlc.arg(cursor_id)::integer
+  )
+)
+order by
+  case when sqlc.arg(backward)::bool then coalesce(max(books.update_date), '') end asc,
+  case when sqlc.arg(backward)::bool then count(distinct (books.site, books.id)) end asc,
+  case when sqlc.arg(backward)::bool then writers.id end desc,
+  coalesce(max(books.update_date), '') desc, count(distinct (books.site, books.id)) desc, writers.id asc
+limit sqlc.arg(limit_count);
+
+-- name: ListWritersWithoutChecksum :many
+select id, coalesce(name, '') as name from writers
+where checksum='' or checksum is null
+order by id;
+
+-- name: UpdateWriterChecksum :exec
+update writers set checksum=$2 where id=$1;
+
 -- name: CreateError :one
 insert into errors (site, id, data) values ($1, $2, $3)
 on conflict (site, id)
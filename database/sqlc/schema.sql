@@ -33,7 +33,9 @@ CREATE TABLE public.books (
     status character varying(10) NOT NULL,
     is_downloaded boolean DEFAULT false NOT NULL,
     checksum text,
-    writer_checksum text
+    writer_checksum text,
+    work_id integer,
+    discovered_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
 );
 
 
@@ -52,6 +54,36 @@ CREATE TABLE public.errors (
 
 ALTER TABLE public.errors OWNER TO book_spider;
 
+--
+-- Name: genres; Type: TABLE; Schema: public; Owner: book_spider
+--
+
+CREATE TABLE public.genres (
+    site character varying(15) NOT NULL,
+    type character varying(20) NOT NULL,
+    genre character varying(20) NOT NULL
+);
+
+
+ALTER TABLE public.genres OWNER TO book_spider;
+
+--
+-- Name: site_health; Type: TABLE; Schema: public; Owner: book_spider
+--
+
+CREATE TABLE public.site_health (
+    site character varying(15) NOT NULL,
+    status character varying(10) NOT NULL,
+    phase character varying(30) NOT NULL,
+    checked integer DEFAULT 0 NOT NULL,
+    failed integer DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    checked_at timestamp without time zone NOT NULL
+);
+
+
+ALTER TABLE public.site_health OWNER TO book_spider;
+
 --
 -- Name: writers; Type: TABLE; Schema: public; Owner: book_spider
 --
@@ -87,6 +119,43 @@ ALTER TABLE public.writers_id_seq OWNER TO book_spider;
 ALTER SEQUENCE public.writers_id_seq OWNED BY public.writers.id;
 
 
+--
+-- Name: works; Type: TABLE; Schema: public; Owner: book_spider
+--
+
+CREATE TABLE public.works (
+    id integer NOT NULL,
+    title text NOT NULL,
+    writer text NOT NULL,
+    title_key text NOT NULL,
+    writer_key text NOT NULL
+);
+
+
+ALTER TABLE public.works OWNER TO book_spider;
+
+--
+-- Name: works_id_seq; Type: SEQUENCE; Schema: public; Owner: book_spider
+--
+
+CREATE SEQUENCE public.works_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE public.works_id_seq OWNER TO book_spider;
+
+--
+-- Name: works_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: book_spider
+--
+
+ALTER SEQUENCE public.works_id_seq OWNED BY public.works.id;
+
+
 --
 -- Name: writers id; Type: DEFAULT; Schema: public; Owner: book_spider
 --
@@ -94,6 +163,29 @@ ALTER SEQUENCE public.writers_id_seq OWNED BY public.writers.id;
 ALTER TABLE ONLY public.writers ALTER COLUMN id SET DEFAULT nextval('public.writers_id_seq'::regclass);
 
 
+--
+-- Name: works id; Type: DEFAULT; Schema: public; Owner: book_spider
+--
+
+ALTER TABLE ONLY public.works ALTER COLUMN id SET DEFAULT nextval('public.works_id_seq'::regclass);
+
+
+--
+-- Name: genres genres_pkey; Type: CONSTRAINT; Schema: public; Owner: book_spider
+--
+
+ALTER TABLE ONLY public.genres
+    ADD CONSTRAINT genres_pkey PRIMARY KEY (site, type);
+
+
+--
+-- Name: site_health site_health_pkey; Type: CONSTRAINT; Schema: public; Owner: book_spider
+--
+
+ALTER TABLE ONLY public.site_health
+    ADD CONSTRAINT site_health_pkey PRIMARY KEY (site);
+
+
 --
 -- Name: writers writers_pkey; Type: CONSTRAINT; Schema: public; Owner: book_spider
 --
@@ -102,6 +194,14 @@ ALTER TABLE ONLY public.writers
     ADD CONSTRAINT writers_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: works works_pkey; Type: CONSTRAINT; Schema: public; Owner: book_spider
+--
+
+ALTER TABLE ONLY public.works
+    ADD CONSTRAINT works_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: books__checksum; Type: INDEX; Schema: public; Owner: book_spider
 --
@@ -109,6 +209,20 @@ ALTER TABLE ONLY public.writers
 CREATE INDEX books__checksum ON public.books USING btree (checksum, writer_checksum);
 
 
+--
+-- Name: books__discovered_at; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX books__discovered_at ON public.books USING btree (discovered_at, site, id, hash_code);
+
+
+--
+-- Name: books__id_keyset; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX books__id_keyset ON public.books USING btree (id, site, hash_code);
+
+
 --
 -- Name: books__is_downloaded; Type: INDEX; Schema: public; Owner: book_spider
 --
@@ -123,6 +237,34 @@ CREATE INDEX books__is_downloaded ON public.books USING btree (site, is_download
 CREATE INDEX books__status ON public.books USING btree (status, is_downloaded);
 
 
+--
+-- Name: books__work_id; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX books__work_id ON public.books USING btree (work_id);
+
+
+--
+-- Name: books__title_keyset; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX books__title_keyset ON public.books USING btree (COALESCE(title, ''::text), site, id, hash_code);
+
+
+--
+-- Name: books__type; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX books__type ON public.books USING btree (site, type);
+
+
+--
+-- Name: books__update_date_keyset; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX books__update_date_keyset ON public.books USING btree (COALESCE(update_date, ''::character varying), site, id, hash_code);
+
+
 --
 -- Name: books__vendor_reference; Type: INDEX; Schema: public; Owner: book_spider
 --
@@ -179,6 +321,27 @@ CREATE INDEX checksum_index ON public.books USING btree (checksum);
 CREATE UNIQUE INDEX errors_index ON public.errors USING btree (site, id);
 
 
+--
+-- Name: genres__genre; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX genres__genre ON public.genres USING btree (genre);
+
+
+--
+-- Name: works__title_key; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX works__title_key ON public.works USING btree (title_key);
+
+
+--
+-- Name: works__writer_key; Type: INDEX; Schema: public; Owner: book_spider
+--
+
+CREATE INDEX works__writer_key ON public.works USING btree (writer_key);
+
+
 --
 -- Name: writers__name; Type: INDEX; Schema: public; Owner: book_spider
 --
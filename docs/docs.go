@@ -15,6 +15,116 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/book-spider/auth/session": {
+            "post": {
+                "description": "create session cookie by basic auth of user, so the password is not sent in every request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Create session",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.sessionResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete the session of cookie",
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Delete session",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/book-spider/books/import": {
+            "post": {
+                "description": "import txt or epub file as book of local site",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Import book",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "book file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "epub"
+                        ],
+                        "type": "string",
+                        "description": "format of file, default by file extension",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "title of book, default by file content",
+                        "name": "title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "writer of book, default by file content",
+                        "name": "writer",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "type of book",
+                        "name": "type",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
         "/api/book-spider/db-stats": {
             "get": {
                 "description": "db stats",
@@ -61,6 +171,107 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/book-spider/genres": {
+            "get": {
+                "description": "list genres books can be browsed by",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "List genres",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.genresResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/genres/{genre}/books": {
+            "get": {
+                "description": "list books of genre filtered by site and status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Browse books by genre",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "genre of books",
+                        "name": "genre",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "site of books",
+                        "name": "site",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "INPROGRESS",
+                            "END"
+                        ],
+                        "type": "string",
+                        "description": "status of books",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "title",
+                            "id",
+                            "discovered"
+                        ],
+                        "type": "string",
+                        "description": "order of books",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of page, from next or prev of last response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page, at most 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.booksResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
         "/api/book-spider/info": {
             "get": {
                 "description": "get all sites info",
@@ -125,19 +336,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/book-spider/sites/{siteName}/books/random": {
+        "/api/book-spider/sites/{siteName}/books/bundle": {
             "get": {
-                "description": "list random books",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "download all downloaded books of search result as a zip with manifest.json",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
                     "book-spider-api"
                 ],
-                "summary": "List random books",
+                "summary": "Download search result bundle",
                 "parameters": [
                     {
                         "type": "string",
@@ -145,13 +353,46 @@ const docTemplate = `{
                         "name": "siteName",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "title of books, either title or writer is required",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "writer of books, either title or writer is required",
+                        "name": "writer",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "epub"
+                        ],
+                        "type": "string",
+                        "description": "format of books",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of books",
+                        "name": "script",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/router.booksResp"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -163,9 +404,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/book-spider/sites/{siteName}/books/search": {
+        "/api/book-spider/sites/{siteName}/books/random": {
             "get": {
-                "description": "search books",
+                "description": "list random books",
                 "consumes": [
                     "application/json"
                 ],
@@ -175,7 +416,7 @@ const docTemplate = `{
                 "tags": [
                     "book-spider-api"
                 ],
-                "summary": "Search books",
+                "summary": "List random books",
                 "parameters": [
                     {
                         "type": "string",
@@ -183,6 +424,30 @@ const docTemplate = `{
                         "name": "siteName",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "genre of books",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "title",
+                            "id",
+                            "discovered"
+                        ],
+                        "type": "string",
+                        "description": "order of books",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page, at most 100",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -201,9 +466,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/book-spider/sites/{siteName}/books/{idHash}": {
+        "/api/book-spider/sites/{siteName}/books/search": {
             "get": {
-                "description": "get book info",
+                "description": "search books",
                 "consumes": [
                     "application/json"
                 ],
@@ -213,7 +478,7 @@ const docTemplate = `{
                 "tags": [
                     "book-spider-api"
                 ],
-                "summary": "Get book info",
+                "summary": "Search books",
                 "parameters": [
                     {
                         "type": "string",
@@ -224,17 +489,40 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
-                        "name": "idHash",
-                        "in": "path",
-                        "required": true
+                        "description": "genre of books",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "title",
+                            "id",
+                            "discovered"
+                        ],
+                        "type": "string",
+                        "description": "order of books",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of page, from next or prev of last response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page, at most 100",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Book"
+                            "$ref": "#/definitions/router.booksResp"
                         }
                     },
                     "400": {
@@ -246,9 +534,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/book-spider/sites/{siteName}/books/{idHash}/download": {
+        "/api/book-spider/sites/{siteName}/books/{idHash}": {
             "get": {
-                "description": "download book in txt format",
+                "description": "get book info",
                 "consumes": [
                     "application/json"
                 ],
@@ -258,7 +546,7 @@ const docTemplate = `{
                 "tags": [
                     "book-spider-api"
                 ],
-                "summary": "Download book",
+                "summary": "Get book info",
                 "parameters": [
                     {
                         "type": "string",
@@ -277,9 +565,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "the book content",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.Book"
                         }
                     },
                     "400": {
@@ -291,36 +579,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/lite/book-spider/": {
+        "/api/book-spider/sites/{siteName}/books/{idHash}/chapters": {
             "get": {
-                "description": "home page",
-                "produces": [
-                    "text/html"
-                ],
-                "tags": [
-                    "book-spider-lite"
+                "description": "list chapter titles of downloaded book",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "Home page",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/lite/book-spider/sites/{siteName}": {
-            "get": {
-                "description": "site info page",
                 "produces": [
-                    "text/html"
+                    "application/json"
                 ],
                 "tags": [
-                    "book-spider-lite"
+                    "book-spider-api"
                 ],
-                "summary": "site info page",
+                "summary": "List book chapters",
                 "parameters": [
                     {
                         "type": "string",
@@ -328,28 +599,55 @@ const docTemplate = `{
                         "name": "siteName",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of titles",
+                        "name": "script",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/router.chaptersResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
                         }
                     }
                 }
             }
         },
-        "/lite/book-spider/sites/{siteName}/books/{idHash}": {
+        "/api/book-spider/sites/{siteName}/books/{idHash}/chapters/{index}": {
             "get": {
-                "description": "book info page",
+                "description": "read a chapter of downloaded book",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "text/html"
+                    "application/json"
                 ],
                 "tags": [
-                    "book-spider-lite"
+                    "book-spider-api"
                 ],
-                "summary": "Book info page",
+                "summary": "Read book chapter",
                 "parameters": [
                     {
                         "type": "string",
@@ -364,13 +662,783 @@ const docTemplate = `{
                         "name": "idHash",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "chapter index",
+                        "name": "index",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of content",
+                        "name": "script",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.chapterResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/sites/{siteName}/books/{idHash}/cover": {
+            "get": {
+                "description": "generated cover image of book showing title, writer and site",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Book cover",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of title and writer",
+                        "name": "script",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the cover image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "cover not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/sites/{siteName}/books/{idHash}/download": {
+            "get": {
+                "description": "download book in txt, epub, fb2, html or markdown format",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Download book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "epub",
+                            "fb2",
+                            "html",
+                            "markdown"
+                        ],
+                        "type": "string",
+                        "description": "format of content",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of content",
+                        "name": "script",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the book content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/sites/{siteName}/books/{idHash}/split": {
+            "post": {
+                "description": "move the book out of its work into a new work",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Split book work",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Work"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/sites/{siteName}/books/{idHash}/work": {
+            "get": {
+                "description": "get the work of the book with every source",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Get book work",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Work"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/works/{workID}": {
+            "get": {
+                "description": "get work info with every source",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Get work info",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "work id",
+                        "name": "workID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Work"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/works/{workID}/download": {
+            "get": {
+                "description": "download the work from its best source in txt, epub, fb2, html or markdown format",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Download work",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "work id",
+                        "name": "workID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "epub",
+                            "fb2",
+                            "html",
+                            "markdown"
+                        ],
+                        "type": "string",
+                        "description": "format of content",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of content",
+                        "name": "script",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the book content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/works/{workID}/merge": {
+            "post": {
+                "description": "merge source work into the work",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Merge works",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "target work id",
+                        "name": "workID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "source work",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/router.mergeWorkReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Work"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/writers/search": {
+            "get": {
+                "description": "search writers by name, writers with more books come first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Search writers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of writer",
+                        "name": "writer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of page, from next or prev of last response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page, at most 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.writersResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/writers/top": {
+            "get": {
+                "description": "list writers with most books or latest updates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "List top writers",
+                "parameters": [
+                    {
+                        "enum": [
+                            "books",
+                            "updated"
+                        ],
+                        "type": "string",
+                        "description": "order of writers",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of page, from next or prev of last response",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page, at most 100",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/router.writersResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/book-spider/writers/{writerID}": {
+            "get": {
+                "description": "get writer info with books across sites grouped by work",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "book-spider-api"
+                ],
+                "summary": "Get writer info",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "writer id",
+                        "name": "writerID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "title",
+                            "id",
+                            "discovered"
+                        ],
+                        "type": "string",
+                        "description": "order of books",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WriterCatalog"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/router.errResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/": {
+            "get": {
+                "description": "home page",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Home page",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/genres": {
+            "get": {
+                "description": "genres books can be browsed by",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Genres page",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/genres/{genre}": {
+            "get": {
+                "description": "books of genre filtered by site and status",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Genre page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "genre of books",
+                        "name": "genre",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "site of books",
+                        "name": "site",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "INPROGRESS",
+                            "END"
+                        ],
+                        "type": "string",
+                        "description": "status of books",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "updated",
+                            "newest",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "order of books",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/sites/{siteName}": {
+            "get": {
+                "description": "site info page",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "site info page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/sites/{siteName}/books/{idHash}": {
+            "get": {
+                "description": "book info page",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Book info page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/sites/{siteName}/books/{idHash}/cover": {
+            "get": {
+                "description": "generated cover image of book shown in book cards",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Book cover",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "id and hash in format \u003cid\u003e[-\u003chash\u003e]. -\u003chash is optional",
+                        "name": "idHash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of title and writer",
+                        "name": "script",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "file"
                         }
                     }
                 }
@@ -402,11 +1470,17 @@ const docTemplate = `{
                         "required": true
                     },
                     {
+                        "enum": [
+                            "txt",
+                            "epub",
+                            "fb2",
+                            "html",
+                            "markdown"
+                        ],
                         "type": "string",
-                        "description": "txt (default) or epub",
+                        "description": "format of content",
                         "name": "format",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -419,6 +1493,68 @@ const docTemplate = `{
                 }
             }
         },
+        "/lite/book-spider/sites/{siteName}/bundle": {
+            "get": {
+                "description": "download all downloaded books of search result as a zip with manifest.json",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Search result bundle",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "site name",
+                        "name": "siteName",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "title of books, either title or writer is required",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "writer of books, either title or writer is required",
+                        "name": "writer",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "epub"
+                        ],
+                        "type": "string",
+                        "description": "format of books",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "s",
+                            "t"
+                        ],
+                        "type": "string",
+                        "description": "script of books",
+                        "name": "script",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
         "/lite/book-spider/sites/{siteName}/random": {
             "get": {
                 "description": "random result page",
@@ -436,6 +1572,12 @@ const docTemplate = `{
                         "name": "siteName",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "genre of books",
+                        "name": "genre",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -465,6 +1607,91 @@ const docTemplate = `{
                         "name": "siteName",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "genre of books",
+                        "name": "genre",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/writers": {
+            "get": {
+                "description": "writers with most books or latest updates, or writers matching the name",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Writers page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of writer",
+                        "name": "writer",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "books",
+                            "updated"
+                        ],
+                        "type": "string",
+                        "description": "order of writers",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "items per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/lite/book-spider/writers/{writerID}": {
+            "get": {
+                "description": "writer page listing books across sites grouped by work",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "book-spider-lite"
+                ],
+                "summary": "Writer page",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "writer id",
+                        "name": "writerID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -510,11 +1737,73 @@ const docTemplate = `{
                 "updateDate": {
                     "type": "string"
                 },
+                "workID": {
+                    "type": "integer"
+                },
                 "writer": {
                     "$ref": "#/definitions/model.Writer"
                 }
             }
         },
+        "model.HealthStatus": {
+            "type": "string",
+            "enum": [
+                "unknown",
+                "healthy",
+                "broken"
+            ],
+            "x-enum-varnames": [
+                "HealthUnknown",
+                "HealthHealthy",
+                "HealthBroken"
+            ]
+        },
+        "model.SiteHealth": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "phase": {
+                    "type": "string"
+                },
+                "site": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.HealthStatus"
+                }
+            }
+        },
+        "model.Work": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Book"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "writer": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Writer": {
             "type": "object",
             "properties": {
@@ -526,6 +1815,43 @@ const docTemplate = `{
                 }
             }
         },
+        "model.WriterCatalog": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WriterSummary": {
+            "type": "object",
+            "properties": {
+                "book_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latest_update": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "repo.Summary": {
             "type": "object",
             "properties": {
@@ -538,6 +1864,14 @@ const docTemplate = `{
                 "errorCount": {
                     "type": "integer"
                 },
+                "health": {
+                    "description": "nil if selectors of site are never checked",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteHealth"
+                        }
+                    ]
+                },
                 "latestSuccessID": {
                     "type": "integer"
                 },
@@ -566,6 +1900,50 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/model.Book"
                     }
+                },
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "sources": {
+                    "description": "books of the works by work id",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/model.Book"
+                        }
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "router.chapterResp": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "router.chaptersResp": {
+            "type": "object",
+            "properties": {
+                "chapters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/router.chapterResp"
+                    }
                 }
             }
         },
@@ -576,6 +1954,67 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "router.genreResp": {
+            "type": "object",
+            "properties": {
+                "genre": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "router.genresResp": {
+            "type": "object",
+            "properties": {
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/router.genreResp"
+                    }
+                }
+            }
+        },
+        "router.mergeWorkReq": {
+            "type": "object",
+            "properties": {
+                "source_work_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "router.sessionResp": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "router.writersResp": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "writers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WriterSummary"
+                    }
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResponseCache keeps decoded bodies of successful responses on disk as
+// {dir}/{first 2 chars of sha256 of url}/{sha256 of url}.gz, an entry is
+// fresh until ttl passed since its modification time
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewResponseCache returns nil if directory or ttl is not set, nil cache
+// stores nothing and finds nothing
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	if dir == "" || ttl <= 0 {
+		return nil
+	}
+
+	return &ResponseCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+func (c *ResponseCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+
+	return filepath.Join(c.dir, key[:2], key+".gz")
+}
+
+func (c *ResponseCache) isExpired(info fs.FileInfo) bool {
+	return c.now().Sub(info.ModTime()) > c.ttl
+}
+
+// Get returns the cached body of url if it is still fresh
+func (c *ResponseCache) Get(url string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	file, err := os.Open(c.path(url))
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || c.isExpired(info) {
+		return "", false
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return "", false
+	}
+	defer gz.Close()
+
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		return "", false
+	}
+
+	return string(body), true
+}
+
+// Set saves body of url, the file is replaced by rename so concurrent
+// readers never see partial body
+func (c *ResponseCache) Set(url, body string) error {
+	if c == nil {
+		return nil
+	}
+
+	path := c.path(url)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("create response cache directory fail: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create response cache fail: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	gz := gzip.NewWriter(file)
+	_, writeErr := io.WriteString(gz, body)
+	closeErr := errors.Join(gz.Close(), file.Close())
+	if writeErr != nil || closeErr != nil {
+		return fmt.Errorf("write response cache fail: %w", errors.Join(writeErr, closeErr))
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("save response cache fail: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the entry of url, it is no-op if url is not cached
+func (c *ResponseCache) Delete(url string) error {
+	if c == nil {
+		return nil
+	}
+
+	if err := os.Remove(c.path(url)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete response cache fail: %w", err)
+	}
+
+	return nil
+}
+
+// Prune removes the expired entries, it returns the number of removed entries
+func (c *ResponseCache) Prune() (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+
+	removed := 0
+	err := filepath.WalkDir(c.dir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil || entry.IsDir() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !c.isExpired(info) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		removed++
+
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("prune response cache fail: %w", err)
+	}
+
+	return removed, nil
+}
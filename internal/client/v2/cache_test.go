@@ -0,0 +1,111 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestResponseCache returns cache with clock moved forward by tick
+func newTestResponseCache(t *testing.T, ttl time.Duration) (*ResponseCache, func(time.Duration)) {
+	t.Helper()
+
+	cache := NewResponseCache(t.TempDir(), ttl)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	return cache, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestNewResponseCache(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NewResponseCache("", time.Hour))
+	assert.Nil(t, NewResponseCache(t.TempDir(), 0))
+	assert.NotNil(t, NewResponseCache(t.TempDir(), time.Hour))
+}
+
+func TestResponseCache_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		url      string
+		elapsed  time.Duration
+		wantBody string
+		wantOK   bool
+	}{
+		{name: "fresh entry", url: "https://test.com/chapter/1", elapsed: 30 * time.Minute, wantBody: "第一章", wantOK: true},
+		{name: "expired entry", url: "https://test.com/chapter/1", elapsed: 2 * time.Hour, wantOK: false},
+		{name: "url not cached", url: "https://test.com/chapter/2", wantOK: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache, tick := newTestResponseCache(t, time.Hour)
+			assert.NoError(t, cache.Set("https://test.com/chapter/1", "第一章"))
+			tick(test.elapsed)
+
+			body, ok := cache.Get(test.url)
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.wantBody, body)
+		})
+	}
+}
+
+func TestResponseCache_Prune(t *testing.T) {
+	t.Parallel()
+
+	cache, tick := newTestResponseCache(t, time.Hour)
+	assert.NoError(t, cache.Set("https://test.com/chapter/1", "chapter 1"))
+	assert.NoError(t, cache.Set("https://test.com/chapter/2", "chapter 2"))
+
+	removed, err := cache.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+
+	tick(2 * time.Hour)
+	removed, err = cache.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	entries, err := filepath.Glob(filepath.Join(cache.dir, "*", "*.gz"))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestResponseCache_Delete(t *testing.T) {
+	t.Parallel()
+
+	cache, _ := newTestResponseCache(t, time.Hour)
+	assert.NoError(t, cache.Set("https://test.com/chapter/1", "chapter 1"))
+	assert.NoError(t, cache.Set("https://test.com/chapter/2", "chapter 2"))
+
+	assert.NoError(t, cache.Delete("https://test.com/chapter/1"))
+	assert.NoError(t, cache.Delete("https://test.com/chapter/3"))
+
+	_, ok := cache.Get("https://test.com/chapter/1")
+	assert.False(t, ok)
+	body, ok := cache.Get("https://test.com/chapter/2")
+	assert.True(t, ok)
+	assert.Equal(t, "chapter 2", body)
+}
+
+func TestResponseCache_Nil(t *testing.T) {
+	t.Parallel()
+
+	var cache *ResponseCache
+	assert.NoError(t, cache.Set("https://test.com/chapter/1", "chapter 1"))
+	assert.NoError(t, cache.Delete("https://test.com/chapter/1"))
+
+	_, ok := cache.Get("https://test.com/chapter/1")
+	assert.False(t, ok)
+
+	removed, err := cache.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
@@ -8,52 +8,204 @@ import (
 	"net/http"
 
 	"github.com/htchan/goclient"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client struct {
 	decoder Decoder
 	cli     *goclient.Client
+	cache   *ResponseCache
+	robots  *robots // nil if robots.txt is not followed
+
+	// validators of the conditional requests, kept in memory as worker is a
+	// long running process. the first request of url after restart or after
+	// its validators are evicted is a full request
+	validators *validatorCache
 }
 
-var _ BookClient = (*Client)(nil)
+var (
+	_ BookClient              = (*Client)(nil)
+	_ ResponseFetcher         = (*Client)(nil)
+	_ ValidatorForgetter      = (*Client)(nil)
+	_ CachedResponseForgetter = (*Client)(nil)
+)
+
+type ClientOption func(*Client)
 
-func NewClient(cli *goclient.Client, decodeMethod DecodeMethod) *Client {
-	return &Client{
-		decoder: NewDecoder(decodeMethod),
-		cli:     cli,
+// WithResponseCache serves the requests marked by WithCacheableResponse from
+// cache
+func WithResponseCache(cache *ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
 	}
 }
 
-func (c *Client) Get(ctx context.Context, url string) (string, error) {
+// WithMaxValidators limits the number of urls whose validators are kept
+func WithMaxValidators(size int) ClientOption {
+	return func(c *Client) {
+		c.validators = newValidatorCache(size)
+	}
+}
+
+func NewClient(cli *goclient.Client, decodeMethod DecodeMethod, opts ...ClientOption) *Client {
+	c := &Client{
+		decoder:    NewDecoder(decodeMethod),
+		cli:        cli,
+		validators: newValidatorCache(defaultMaxValidators),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+type contextKey string
+
+const (
+	contextKeyConditionalRequest contextKey = "conditional_request"
+	contextKeyCacheableResponse  contextKey = "cacheable_response"
+)
+
+// WithConditionalRequest marks the requests to send validators of previous
+// response of same url, ErrNotModified is returned if page is not modified
+func WithConditionalRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyConditionalRequest, true)
+}
+
+// IsConditionalRequest reports if requests of ctx are conditional
+func IsConditionalRequest(ctx context.Context) bool {
+	conditional, _ := ctx.Value(contextKeyConditionalRequest).(bool)
+	return conditional
+}
+
+// WithCacheableResponse marks the responses to be served from response cache
+// of client if any
+func WithCacheableResponse(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyCacheableResponse, true)
+}
+
+// IsCacheableResponse reports if responses of ctx can be served from cache
+func IsCacheableResponse(ctx context.Context) bool {
+	cacheable, _ := ctx.Value(contextKeyCacheableResponse).(bool)
+	return cacheable
+}
+
+func (c *Client) loadValidator(url string) (validator, bool) {
+	return c.validators.load(url)
+}
+
+func (c *Client) saveValidator(url string, header http.Header) {
+	v := validator{etag: header.Get("ETag"), lastModified: header.Get("Last-Modified")}
+	if v.etag == "" && v.lastModified == "" {
+		c.validators.delete(url)
+	} else {
+		c.validators.save(url, v)
+	}
+}
+
+// ForgetValidators drops the validators of url, so next conditional request
+// fetches the full page even if page is not modified
+func (c *Client) ForgetValidators(url string) {
+	c.validators.delete(url)
+}
+
+// ForgetCachedResponse drops the cached response of url, so next cacheable
+// request fetches the page again
+func (c *Client) ForgetCachedResponse(url string) {
+	// failure of delete only serves the same page until the entry expired
+	_ = c.cache.Delete(url)
+}
+
+// Response is the decoded response of vendor
+type Response struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       string
+	Encoding   string // encoding body decoded from
+}
+
+// Fetch returns the response of url, the response is also returned with
+// StatusCodeError if status code is not 2xx
+func (c *Client) Fetch(ctx context.Context, url string) (*Response, error) {
+	cacheable := c.cache != nil && IsCacheableResponse(ctx)
+	if cacheable {
+		if body, ok := c.cache.Get(url); ok {
+			return &Response{URL: url, StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, nil
+		}
+	}
+
+	if err := c.robots.check(ctx, url); err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	conditional := IsConditionalRequest(ctx)
+	if v, ok := c.loadValidator(url); conditional && ok {
+		if v.etag != "" {
+			req.Header.Set("If-None-Match", v.etag)
+		}
+		if v.lastModified != "" {
+			req.Header.Set("If-Modified-Since", v.lastModified)
+		}
 	}
 
 	resp, err := c.cli.Do(req)
 	if err != nil {
 		var timeoutError net.Error
 		if errors.As(err, &timeoutError) && timeoutError.Timeout() {
-			return "", ErrTimeout
+			return nil, ErrTimeout
 		}
 
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", StatusCodeError{StatusCode: resp.StatusCode}
+	result := &Response{URL: url, StatusCode: resp.StatusCode, Header: resp.Header}
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		return result, ErrNotModified
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, StatusCodeError{StatusCode: resp.StatusCode}
 	}
 
 	html, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	result, err := c.decoder.Decode(string(html))
+	result.Body, result.Encoding, err = c.decoder.DecodeResponse(html, resp.Header.Get("Content-Type"))
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Trace().Str("url", url).Str("encoding", result.Encoding).Msg("response decoded")
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("response.encoding", result.Encoding))
+
+	if conditional {
+		c.saveValidator(url, resp.Header)
+	}
+
+	// failure of cache only costs a refetch next time
+	if cacheable {
+		_ = c.cache.Set(url, result.Body)
 	}
 
 	return result, nil
 }
+
+func (c *Client) Get(ctx context.Context, url string) (string, error) {
+	resp, err := c.Fetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Body, nil
+}
@@ -2,9 +2,15 @@ package client
 
 import (
 	"flag"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/htchan/goclient"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/goleak"
 )
 
@@ -18,3 +24,146 @@ func TestMain(m *testing.M) {
 		os.Exit(m.Run())
 	}
 }
+
+// newTestClient returns client of server responding 304 if request has the
+// validators of its only page, and the number of requests server received
+func newTestClient(t *testing.T, header http.Header, opts ...ClientOption) (*Client, string, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if (header.Get("ETag") != "" && r.Header.Get("If-None-Match") == header.Get("ETag")) ||
+			(header.Get("Last-Modified") != "" && r.Header.Get("If-Modified-Since") == header.Get("Last-Modified")) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.Write([]byte("page"))
+	}))
+	t.Cleanup(server.Close)
+
+	cli := NewClient(goclient.NewClient(goclient.WithRequester(server.Client().Do)), DecodeMethodUTF8, opts...)
+
+	return cli, server.URL, &requests
+}
+
+func TestClient_Get_ConditionalRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		conditional bool
+		forget      bool
+		wantErrors  []error
+	}{
+		{
+			name:        "not modified by etag",
+			header:      http.Header{"Etag": {`"v1"`}},
+			conditional: true,
+			wantErrors:  []error{nil, ErrNotModified},
+		},
+		{
+			name:        "not modified by last modified",
+			header:      http.Header{"Last-Modified": {"Mon, 19 Oct 2026 00:00:00 GMT"}},
+			conditional: true,
+			wantErrors:  []error{nil, ErrNotModified},
+		},
+		{
+			name:        "full request without validators",
+			header:      http.Header{},
+			conditional: true,
+			wantErrors:  []error{nil, nil},
+		},
+		{
+			name:        "full request if not conditional",
+			header:      http.Header{"Etag": {`"v1"`}},
+			conditional: false,
+			wantErrors:  []error{nil, nil},
+		},
+		{
+			name:        "full request after validators forgotten",
+			header:      http.Header{"Etag": {`"v1"`}},
+			conditional: true,
+			forget:      true,
+			wantErrors:  []error{nil, nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli, url, requests := newTestClient(t, test.header)
+			ctx := t.Context()
+			if test.conditional {
+				ctx = WithConditionalRequest(ctx)
+			}
+
+			for i, wantErr := range test.wantErrors {
+				body, err := cli.Get(ctx, url)
+				assert.ErrorIs(t, err, wantErr)
+				if wantErr == nil {
+					assert.Equal(t, "page", body)
+				}
+
+				if i == 0 && test.forget {
+					cli.ForgetValidators(url)
+				}
+			}
+
+			assert.Equal(t, int64(len(test.wantErrors)), requests.Load())
+		})
+	}
+}
+
+func TestClient_Get_ResponseCache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cache        bool
+		cacheable    bool
+		forget       bool
+		wantRequests int64
+	}{
+		{name: "cacheable response served from cache", cache: true, cacheable: true, wantRequests: 1},
+		{name: "forgotten response fetched again", cache: true, cacheable: true, forget: true, wantRequests: 2},
+		{name: "not cacheable response", cache: true, cacheable: false, wantRequests: 2},
+		{name: "client without cache", cache: false, cacheable: true, forget: true, wantRequests: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var opts []ClientOption
+			if test.cache {
+				opts = append(opts, WithResponseCache(NewResponseCache(t.TempDir(), time.Hour)))
+			}
+
+			cli, url, requests := newTestClient(t, http.Header{}, opts...)
+			ctx := t.Context()
+			if test.cacheable {
+				ctx = WithCacheableResponse(ctx)
+			}
+
+			for range 2 {
+				body, err := cli.Get(ctx, url)
+				assert.NoError(t, err)
+				assert.Equal(t, "page", body)
+
+				if test.forget {
+					cli.ForgetCachedResponse(url)
+				}
+			}
+
+			assert.Equal(t, test.wantRequests, requests.Load())
+		})
+	}
+}
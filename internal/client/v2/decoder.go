@@ -1,22 +1,32 @@
 package client
 
 import (
+	"bytes"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
 
 type DecodeMethod string
 
 const (
-	DecodeMethodGBK  DecodeMethod = "gbk"
-	DecodeMethodBig5 DecodeMethod = "big5"
-	DecodeMethodUTF8 DecodeMethod = "utf8"
+	DecodeMethodGBK     DecodeMethod = "gbk"
+	DecodeMethodGB18030 DecodeMethod = "gb18030"
+	DecodeMethodBig5    DecodeMethod = "big5" // includes HKSCS extension
+	DecodeMethodUTF8    DecodeMethod = "utf8"
+	DecodeMethodAuto    DecodeMethod = "auto"
 )
 
 type Decoder struct {
 	decoder *encoding.Decoder
+	method  DecodeMethod
 }
 
 func NewDecoder(decodeMethod DecodeMethod) Decoder {
@@ -24,13 +34,15 @@ func NewDecoder(decodeMethod DecodeMethod) Decoder {
 	switch decodeMethod {
 	case DecodeMethodGBK:
 		decoder = simplifiedchinese.GBK.NewDecoder()
+	case DecodeMethodGB18030:
+		decoder = simplifiedchinese.GB18030.NewDecoder()
 	case DecodeMethodBig5:
 		decoder = traditionalchinese.Big5.NewDecoder()
 	default:
 		decoder = nil
 	}
 
-	return Decoder{decoder: decoder}
+	return Decoder{decoder: decoder, method: decodeMethod}
 }
 
 func (decoder Decoder) Decode(str string) (string, error) {
@@ -40,3 +52,138 @@ func (decoder Decoder) Decode(str string) (string, error) {
 	str, _, err := transform.String(decoder.decoder, str)
 	return str, err
 }
+
+// DecodeResponse decodes body of response, it returns the body in utf8 and
+// the name of encoding used. encoding is detected from the body and content
+// type for auto decode method
+func (decoder Decoder) DecodeResponse(body []byte, contentType string) (string, string, error) {
+	if decoder.method != DecodeMethodAuto {
+		name := string(decoder.method)
+		if name == "" {
+			name = string(DecodeMethodUTF8)
+		}
+
+		result, err := decoder.Decode(string(body))
+		return result, name, err
+	}
+
+	enc, name := DetectEncoding(body, contentType)
+	result, _, err := transform.Bytes(enc.NewDecoder(), body)
+	if err != nil {
+		return "", name, err
+	}
+
+	return string(result), name, nil
+}
+
+// legacyEncodings are the encodings compared by byte pattern if the page does
+// not declare its encoding. gb18030 is superset of gbk and gb2312
+var legacyEncodings = []struct {
+	name     string
+	encoding encoding.Encoding
+}{
+	{name: string(DecodeMethodGB18030), encoding: simplifiedchinese.GB18030},
+	{name: string(DecodeMethodBig5), encoding: traditionalchinese.Big5},
+}
+
+// DetectEncoding returns the encoding of page by the first matched rule of
+//   - byte order mark
+//   - valid utf8 with non ascii characters, as vendors switched to utf8 may
+//     keep the old charset declaration
+//   - charset of content type
+//   - <meta charset> or <meta http-equiv="Content-Type">
+//   - byte pattern of gb18030 and big5
+func DetectEncoding(body []byte, contentType string) (encoding.Encoding, string) {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM, "utf-8"
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "utf-16be"
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "utf-16le"
+	}
+
+	if isUTF8(body) {
+		return unicode.UTF8, "utf-8"
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		if enc, name := lookupEncoding(params["charset"]); enc != nil {
+			return enc, name
+		}
+	}
+
+	// without content type, charset only finds the declaration in meta
+	if _, label, _ := charset.DetermineEncoding(body, ""); label != "utf-8" && label != "windows-1252" {
+		if enc, name := lookupEncoding(label); enc != nil {
+			return enc, name
+		}
+	}
+
+	return detectLegacyEncoding(body)
+}
+
+// lookupEncoding returns the encoding of charset label, gbk and gb2312 are
+// decoded as gb18030 as it is their superset
+func lookupEncoding(label string) (encoding.Encoding, string) {
+	enc, name := charset.Lookup(label)
+	switch name {
+	case "":
+		return nil, ""
+	case "gbk", "gb18030":
+		return simplifiedchinese.GB18030, string(DecodeMethodGB18030)
+	case "big5":
+		return traditionalchinese.Big5, string(DecodeMethodBig5)
+	default:
+		return enc, name
+	}
+}
+
+// isUTF8 reports if body is valid utf8 with non ascii characters, partial
+// rune at the end of truncated body is ignored
+func isUTF8(body []byte) bool {
+	for i := len(body) - 1; i >= 0 && i > len(body)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(body[i]) {
+			if !utf8.FullRune(body[i:]) {
+				body = body[:i]
+			}
+			break
+		}
+	}
+
+	hasNonASCII := bytes.ContainsFunc(body, func(r rune) bool { return r >= utf8.RuneSelf })
+
+	return hasNonASCII && utf8.Valid(body)
+}
+
+// commonHanzi are the most frequent characters in both simplified and
+// traditional chinese novels, the decoded text of wrong encoding has few of
+// them
+const commonHanzi = "的一是不了人我在有他这這个個们們中来來上大为為和国國地到以说說时時要就出会會可也你对對生能而子那得于於着著下自之年过過发發后後作里裡"
+
+// detectLegacyEncoding picks the legacy encoding with least invalid bytes,
+// and most common characters if tie
+func detectLegacyEncoding(body []byte) (encoding.Encoding, string) {
+	best, bestInvalid, bestCommon := 0, -1, -1
+	for i, legacy := range legacyEncodings {
+		decoded, _, err := transform.Bytes(legacy.encoding.NewDecoder(), body)
+		if err != nil {
+			continue
+		}
+
+		text := string(decoded)
+		invalid := strings.Count(text, string(utf8.RuneError))
+		common := 0
+		for _, r := range text {
+			if strings.ContainsRune(commonHanzi, r) {
+				common++
+			}
+		}
+
+		if bestInvalid < 0 || invalid < bestInvalid || (invalid == bestInvalid && common > bestCommon) {
+			best, bestInvalid, bestCommon = i, invalid, common
+		}
+	}
+
+	return legacyEncodings[best].encoding, legacyEncodings[best].name
+}
@@ -4,8 +4,11 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
 )
 
 func Test_NewDecoder(t *testing.T) {
@@ -94,3 +97,141 @@ func TestDecoder_Decode(t *testing.T) {
 		})
 	}
 }
+
+func encodeString(t *testing.T, enc encoding.Encoding, str string) []byte {
+	t.Helper()
+
+	result, err := enc.NewEncoder().Bytes([]byte(str))
+	if err != nil {
+		t.Fatalf("encode %q fail: %v", str, err)
+	}
+
+	return result
+}
+
+func TestDetectEncoding(t *testing.T) {
+	t.Parallel()
+
+	simplified := "<html><body><p>他说这个时候我们的书已经来了，这是第一章的内容。</p></body></html>"
+	traditional := "<html><body><p>他說這個時候我們的書已經來了，這是第一章的內容。</p></body></html>"
+
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{
+			name: "utf8 byte order mark",
+			body: append([]byte{0xEF, 0xBB, 0xBF}, []byte(simplified)...),
+			want: "utf-8",
+		},
+		{
+			name: "utf16 byte order mark",
+			body: encodeString(t, unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), simplified),
+			want: "utf-16le",
+		},
+		{
+			name:        "valid utf8 with outdated charset",
+			body:        []byte(`<meta charset="gbk">` + simplified),
+			contentType: "text/html; charset=gbk",
+			want:        "utf-8",
+		},
+		{
+			name:        "charset of content type",
+			body:        encodeString(t, traditionalchinese.Big5, traditional),
+			contentType: "text/html; charset=big5",
+			want:        "big5",
+		},
+		{
+			name:        "gbk charset of content type decoded as gb18030",
+			body:        encodeString(t, simplifiedchinese.GBK, simplified),
+			contentType: "text/html; charset=GB2312",
+			want:        "gb18030",
+		},
+		{
+			name: "meta charset",
+			body: encodeString(t, simplifiedchinese.GBK, `<meta charset="gbk">`+simplified),
+			want: "gb18030",
+		},
+		{
+			name: "meta http-equiv content type",
+			body: encodeString(t, traditionalchinese.Big5, `<meta http-equiv="Content-Type" content="text/html; charset=big5">`+traditional),
+			want: "big5",
+		},
+		{
+			name: "gb18030 byte pattern",
+			body: encodeString(t, simplifiedchinese.GB18030, simplified),
+			want: "gb18030",
+		},
+		{
+			name: "big5 byte pattern",
+			body: encodeString(t, traditionalchinese.Big5, traditional),
+			want: "big5",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, got := DetectEncoding(test.body, test.contentType)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestDecoder_DecodeResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		decoder      Decoder
+		body         []byte
+		contentType  string
+		want         string
+		wantEncoding string
+	}{
+		{
+			name:         "auto decode big5 page",
+			decoder:      NewDecoder(DecodeMethodAuto),
+			body:         encodeString(t, traditionalchinese.Big5, "第一章 這個時候"),
+			contentType:  "text/html; charset=big5",
+			want:         "第一章 這個時候",
+			wantEncoding: "big5",
+		},
+		{
+			name:         "auto decode utf8 page with byte order mark",
+			decoder:      NewDecoder(DecodeMethodAuto),
+			body:         append([]byte{0xEF, 0xBB, 0xBF}, []byte("第一章")...),
+			want:         "第一章",
+			wantEncoding: "utf-8",
+		},
+		{
+			name:         "decode by fixed method",
+			decoder:      NewDecoder(DecodeMethodGB18030),
+			body:         encodeString(t, simplifiedchinese.GB18030, "第一章"),
+			contentType:  "text/html; charset=big5",
+			want:         "第一章",
+			wantEncoding: "gb18030",
+		},
+		{
+			name:         "decode by default method",
+			decoder:      NewDecoder(""),
+			body:         []byte("第一章"),
+			want:         "第一章",
+			wantEncoding: "utf8",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, encoding, err := test.decoder.DecodeResponse(test.body, test.contentType)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantEncoding, encoding)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 )
 
 type StatusCodeError struct {
@@ -15,6 +17,42 @@ func (err StatusCodeError) Error() string {
 	return fmt.Sprintf("status code is %d", err.StatusCode)
 }
 
+// NotFound reports if the page does not exist in vendor
+func (err StatusCodeError) NotFound() bool {
+	return err.StatusCode == http.StatusNotFound || err.StatusCode == http.StatusGone
+}
+
+// Temporary reports if the request may succeed later
+func (err StatusCodeError) Temporary() bool {
+	return IsRetryableStatus(err.StatusCode)
+}
+
 var (
-	ErrTimeout = errors.New("request timeout")
+	ErrTimeout     = errors.New("request timeout")
+	ErrNotModified = errors.New("not modified")
+
+	ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
 )
+
+// IsRetryableStatus reports if the request of status code may succeed if it
+// is sent again, e.g. throttled or server error. other 4xx are not retryable
+func IsRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	default:
+		return statusCode >= 500
+	}
+}
+
+// IsTemporary reports if err is a temporary failure of vendor, like timeout,
+// network error or retryable status code, instead of a missing page
+func IsTemporary(err error) bool {
+	var statusErr StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+
+	var netErr net.Error
+	return errors.Is(err, ErrTimeout) || errors.As(err, &netErr)
+}
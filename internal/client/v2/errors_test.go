@@ -0,0 +1,42 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTemporary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		err          error
+		want         bool
+		wantNotFound bool
+	}{
+		{name: "not found", err: StatusCodeError{StatusCode: 404}, want: false, wantNotFound: true},
+		{name: "gone", err: fmt.Errorf("get book page failed: %w", StatusCodeError{StatusCode: 410}), want: false, wantNotFound: true},
+		{name: "forbidden", err: StatusCodeError{StatusCode: 403}, want: false},
+		{name: "too many requests", err: StatusCodeError{StatusCode: 429}, want: true},
+		{name: "request timeout status", err: StatusCodeError{StatusCode: 408}, want: true},
+		{name: "server error", err: fmt.Errorf("get book page failed: %w", StatusCodeError{StatusCode: 502}), want: true},
+		{name: "timeout", err: ErrTimeout, want: true},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: true},
+		{name: "other error", err: errors.New("parse fail"), want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, IsTemporary(test.err))
+
+			var statusErr StatusCodeError
+			assert.Equal(t, test.wantNotFound, errors.As(test.err, &statusErr) && statusErr.NotFound())
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+// recordedHeaders are the headers kept in fixture, other headers like
+// cookies are dropped so the archive does not leak session of capture run
+var recordedHeaders = []string{
+	"Content-Type", "Content-Encoding", "Content-Language",
+	"Cache-Control", "ETag", "Last-Modified", "Expires", "Retry-After", "Location",
+}
+
+// Fixture is a recorded response. body is stored in archive by its sha256,
+// so pages shared by many urls (e.g. error page) are saved once
+type Fixture struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	BodySHA256 string      `json:"body_sha256,omitempty"`
+}
+
+// FixtureArchive keeps the recorded responses of a vendor, it is saved as
+// json so diff of fixtures is readable in code review
+type FixtureArchive struct {
+	lock     sync.RWMutex
+	fixtures map[string]Fixture
+	bodies   map[string]string
+}
+
+type fixtureArchiveFile struct {
+	Fixtures []Fixture         `json:"fixtures"`
+	Bodies   map[string]string `json:"bodies"`
+}
+
+func NewFixtureArchive() *FixtureArchive {
+	return &FixtureArchive{
+		fixtures: make(map[string]Fixture),
+		bodies:   make(map[string]string),
+	}
+}
+
+func LoadFixtureArchive(path string) (*FixtureArchive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture archive fail: %w", err)
+	}
+
+	var file fixtureArchiveFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode fixture archive fail: %w", err)
+	}
+
+	archive := NewFixtureArchive()
+	for _, fixture := range file.Fixtures {
+		if _, ok := file.Bodies[fixture.BodySHA256]; fixture.BodySHA256 != "" && !ok {
+			return nil, fmt.Errorf("body of fixture %s not found in archive", fixture.URL)
+		}
+
+		archive.fixtures[fixture.URL] = fixture
+	}
+
+	for sha, body := range file.Bodies {
+		archive.bodies[sha] = body
+	}
+
+	return archive, nil
+}
+
+// Save writes the archive to path, fixtures are sorted by url so recapture
+// of unchanged pages produces no diff
+func (archive *FixtureArchive) Save(path string) error {
+	archive.lock.RLock()
+	file := fixtureArchiveFile{
+		Fixtures: make([]Fixture, 0, len(archive.fixtures)),
+		Bodies:   make(map[string]string, len(archive.bodies)),
+	}
+	for _, fixture := range archive.fixtures {
+		file.Fixtures = append(file.Fixtures, fixture)
+		if fixture.BodySHA256 != "" {
+			file.Bodies[fixture.BodySHA256] = archive.bodies[fixture.BodySHA256]
+		}
+	}
+	archive.lock.RUnlock()
+
+	slices.SortFunc(file.Fixtures, func(a, b Fixture) int { return strings.Compare(a.URL, b.URL) })
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("encode fixture archive fail: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("create fixture archive directory fail: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write fixture archive fail: %w", err)
+	}
+
+	return nil
+}
+
+// Add saves the response into archive, existing fixture of same url is replaced
+func (archive *FixtureArchive) Add(resp *Response) {
+	fixture := Fixture{URL: resp.URL, StatusCode: resp.StatusCode}
+	for _, key := range recordedHeaders {
+		if values := resp.Header.Values(key); len(values) > 0 {
+			if fixture.Header == nil {
+				fixture.Header = make(http.Header)
+			}
+			fixture.Header[key] = values
+		}
+	}
+
+	archive.lock.Lock()
+	defer archive.lock.Unlock()
+
+	if resp.Body != "" {
+		sum := sha256.Sum256([]byte(resp.Body))
+		fixture.BodySHA256 = hex.EncodeToString(sum[:])
+		archive.bodies[fixture.BodySHA256] = resp.Body
+	}
+
+	archive.fixtures[resp.URL] = fixture
+}
+
+// Lookup returns the recorded response of url
+func (archive *FixtureArchive) Lookup(url string) (*Response, bool) {
+	archive.lock.RLock()
+	defer archive.lock.RUnlock()
+
+	fixture, ok := archive.fixtures[url]
+	if !ok {
+		return nil, false
+	}
+
+	return &Response{
+		URL:        fixture.URL,
+		StatusCode: fixture.StatusCode,
+		Header:     fixture.Header.Clone(),
+		Body:       archive.bodies[fixture.BodySHA256],
+	}, true
+}
+
+// Len returns number of recorded urls
+func (archive *FixtureArchive) Len() int {
+	archive.lock.RLock()
+	defer archive.lock.RUnlock()
+
+	return len(archive.fixtures)
+}
+
+// RecordClient saves every response of wrapped client into archive. transport
+// errors like timeout are not recorded as they are not reproducible
+type RecordClient struct {
+	cli     ResponseFetcher
+	archive *FixtureArchive
+}
+
+var _ BookClient = (*RecordClient)(nil)
+
+func NewRecordClient(cli ResponseFetcher, archive *FixtureArchive) *RecordClient {
+	return &RecordClient{cli: cli, archive: archive}
+}
+
+func (c *RecordClient) Get(ctx context.Context, url string) (string, error) {
+	resp, err := c.cli.Fetch(ctx, url)
+	if resp != nil {
+		c.archive.Add(resp)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Body, nil
+}
+
+// ReplayClient returns the recorded responses without network access
+type ReplayClient struct {
+	archive *FixtureArchive
+}
+
+var (
+	_ BookClient      = (*ReplayClient)(nil)
+	_ ResponseFetcher = (*ReplayClient)(nil)
+)
+
+func NewReplayClient(archive *FixtureArchive) *ReplayClient {
+	return &ReplayClient{archive: archive}
+}
+
+func (c *ReplayClient) Fetch(ctx context.Context, url string) (*Response, error) {
+	resp, ok := c.archive.Lookup(url)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFixtureNotFound, url)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, StatusCodeError{StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+func (c *ReplayClient) Get(ctx context.Context, url string) (string, error) {
+	resp, err := c.Fetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,174 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubFetcher map[string]*Response
+
+func (fetcher stubFetcher) Fetch(ctx context.Context, url string) (*Response, error) {
+	resp, ok := fetcher[url]
+	if !ok {
+		return nil, ErrTimeout
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, StatusCodeError{StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+func TestFixtureArchive_AddLookup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		resps    []*Response
+		url      string
+		want     *Response
+		wantOK   bool
+		wantBody int
+	}{
+		{
+			name: "drop headers not recorded",
+			resps: []*Response{
+				{
+					URL: "https://example.com/1", StatusCode: 200, Body: "body",
+					Header: http.Header{"Content-Type": {"text/html"}, "Set-Cookie": {"session=1"}},
+				},
+			},
+			url:      "https://example.com/1",
+			want:     &Response{URL: "https://example.com/1", StatusCode: 200, Body: "body", Header: http.Header{"Content-Type": {"text/html"}}},
+			wantOK:   true,
+			wantBody: 1,
+		},
+		{
+			name: "same body is saved once",
+			resps: []*Response{
+				{URL: "https://example.com/1", StatusCode: 200, Body: "same"},
+				{URL: "https://example.com/2", StatusCode: 200, Body: "same"},
+			},
+			url:      "https://example.com/2",
+			want:     &Response{URL: "https://example.com/2", StatusCode: 200, Body: "same"},
+			wantOK:   true,
+			wantBody: 1,
+		},
+		{
+			name:     "url not recorded",
+			resps:    []*Response{{URL: "https://example.com/1", StatusCode: 404}},
+			url:      "https://example.com/2",
+			wantOK:   false,
+			wantBody: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			archive := NewFixtureArchive()
+			for _, resp := range test.resps {
+				archive.Add(resp)
+			}
+
+			got, ok := archive.Lookup(test.url)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.wantBody, len(archive.bodies))
+		})
+	}
+}
+
+func TestFixtureArchive_SaveLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "fixtures", "vendor.json")
+
+	archive := NewFixtureArchive()
+	archive.Add(&Response{URL: "https://example.com/2", StatusCode: 200, Body: "<p>二</p>"})
+	archive.Add(&Response{URL: "https://example.com/1", StatusCode: 200, Body: "<p>一</p>"})
+	archive.Add(&Response{URL: "https://example.com/3", StatusCode: 404})
+
+	assert.NoError(t, archive.Save(path))
+
+	loaded, err := LoadFixtureArchive(path)
+	assert.NoError(t, err)
+	assert.Equal(t, archive.fixtures, loaded.fixtures)
+	assert.Equal(t, archive.bodies, loaded.bodies)
+	assert.Equal(t, 3, loaded.Len())
+
+	_, err = LoadFixtureArchive(filepath.Join(t.TempDir(), "not-exist.json"))
+	assert.Error(t, err)
+}
+
+func TestRecordClient_Get(t *testing.T) {
+	t.Parallel()
+
+	fetcher := stubFetcher{
+		"https://example.com/ok":        {URL: "https://example.com/ok", StatusCode: 200, Body: "ok"},
+		"https://example.com/not-found": {URL: "https://example.com/not-found", StatusCode: 404},
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		want       string
+		wantErr    error
+		wantRecord bool
+	}{
+		{name: "record success response", url: "https://example.com/ok", want: "ok", wantRecord: true},
+		{name: "record status code error", url: "https://example.com/not-found", wantErr: StatusCodeError{StatusCode: 404}, wantRecord: true},
+		{name: "not record transport error", url: "https://example.com/timeout", wantErr: ErrTimeout, wantRecord: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			archive := NewFixtureArchive()
+			cli := NewRecordClient(fetcher, archive)
+
+			got, err := cli.Get(context.Background(), test.url)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantErr)
+
+			_, ok := archive.Lookup(test.url)
+			assert.Equal(t, test.wantRecord, ok)
+		})
+	}
+}
+
+func TestReplayClient_Get(t *testing.T) {
+	t.Parallel()
+
+	archive := NewFixtureArchive()
+	archive.Add(&Response{URL: "https://example.com/ok", StatusCode: 200, Body: "ok"})
+	archive.Add(&Response{URL: "https://example.com/gone", StatusCode: 410})
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr error
+	}{
+		{name: "replay success response", url: "https://example.com/ok", want: "ok"},
+		{name: "replay status code error", url: "https://example.com/gone", wantErr: StatusCodeError{StatusCode: 410}},
+		{name: "url not recorded", url: "https://example.com/unknown", wantErr: ErrFixtureNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewReplayClient(archive).Get(context.Background(), test.url)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
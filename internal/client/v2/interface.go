@@ -6,3 +6,22 @@ import "context"
 type BookClient interface {
 	Get(ctx context.Context, url string) (string, error)
 }
+
+// ResponseFetcher returns the response with status and headers instead of body only
+type ResponseFetcher interface {
+	Fetch(ctx context.Context, url string) (*Response, error)
+}
+
+// ValidatorForgetter drops the validators of conditional request of url, it
+// is called when the page fetched could not be processed, so the page is
+// fetched again next time even if it is not modified
+type ValidatorForgetter interface {
+	ForgetValidators(url string)
+}
+
+// CachedResponseForgetter drops the cached response of url, it is called when
+// the cached page could not be processed, so the page is fetched again next
+// time instead of served from cache
+type CachedResponseForgetter interface {
+	ForgetCachedResponse(url string)
+}
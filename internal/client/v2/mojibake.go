@@ -0,0 +1,43 @@
+package client
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxMojibakeRatio is the max ratio of replacement characters in the text
+// decoded by correct encoding, text of wrong encoding usually has far more
+const maxMojibakeRatio = 0.05
+
+// garbledReplacement is the replacement characters in utf8 decoded as gbk
+const garbledReplacement = "锟斤拷"
+
+// MojibakeRatio returns the ratio of replacement characters in non space
+// characters of text, each character of "锟斤拷" counts as replacement
+func MojibakeRatio(text string) float64 {
+	total, replaced := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		total++
+		if r == utf8.RuneError {
+			replaced++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	replaced += strings.Count(text, garbledReplacement) * utf8.RuneCountInString(garbledReplacement)
+
+	return float64(replaced) / float64(total)
+}
+
+// IsMojibake reports if text is likely decoded by wrong encoding
+func IsMojibake(text string) bool {
+	return MojibakeRatio(text) > maxMojibakeRatio
+}
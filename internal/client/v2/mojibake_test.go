@@ -0,0 +1,41 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestMojibakeRatio(t *testing.T) {
+	t.Parallel()
+
+	// vendor switched to utf8 with gbk decode method configured
+	utf8AsGBK, _ := simplifiedchinese.GBK.NewDecoder().String("他说这个时候我们的书已经来了，这是第一章的内容。")
+
+	tests := []struct {
+		name         string
+		text         string
+		wantRatio    float64
+		wantMojibake bool
+	}{
+		{name: "empty text", text: "", wantRatio: 0, wantMojibake: false},
+		{name: "clean text", text: "第一章 這個時候", wantRatio: 0, wantMojibake: false},
+		{name: "few replacement characters", text: strings.Repeat("內容", 50) + "�", wantRatio: 1.0 / 101, wantMojibake: false},
+		{name: "garbled replacement characters", text: "锟斤拷锟斤拷第一章", wantRatio: 6.0 / 9, wantMojibake: true},
+		{name: "spaces are not counted", text: "�  \n\t內", wantRatio: 0.5, wantMojibake: true},
+		{name: "utf8 text decoded as gbk", text: utf8AsGBK, wantMojibake: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.wantRatio > 0 || !test.wantMojibake {
+				assert.InDelta(t, test.wantRatio, MojibakeRatio(test.text), 0.0001)
+			}
+			assert.Equal(t, test.wantMojibake, IsMojibake(test.text))
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultRobotsTTL = 24 * time.Hour
+	// unreachable robots.txt is fetched again after retry interval
+	robotsRetryInterval = time.Minute
+	// robots.txt beyond 500 KiB is ignored as RFC 9309 suggests
+	maxRobotsSize = 500 * 1024
+)
+
+// robots follows robots.txt of vendor hosts. robots.txt of a host is fetched
+// on first request and again after ttl, missing robots.txt (4xx) allows
+// everything. unreachable robots.txt (5xx or network error) keeps the
+// previous rules (RFC 9309 2.4), or fails requests of host until retry
+// interval if no rules loaded. crawl delay is the minimum interval between
+// the requests of host sent by client, on top of the rate limit of vendor
+type robots struct {
+	userAgent      string
+	ttl            time.Duration
+	ignoreDisallow bool
+	fetch          func(*http.Request) (*http.Response, error)
+	now            func() time.Time
+
+	lock  sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+type robotsHost struct {
+	lock      sync.Mutex
+	rules     *robotsRules
+	err       error // error of unreachable robots.txt before any rules loaded
+	expiredAt time.Time
+	nextSlot  time.Time
+}
+
+// WithRobots makes client follow robots.txt of the requested hosts with the
+// group of user agent ("*" if empty). ttl is 24 hours if zero. disallowed url
+// is not requested and ErrDisallowedByRobots is returned unless
+// ignoreDisallow is set
+func WithRobots(userAgent string, ttl time.Duration, ignoreDisallow bool) ClientOption {
+	return func(c *Client) {
+		if ttl <= 0 {
+			ttl = defaultRobotsTTL
+		}
+
+		c.robots = &robots{
+			userAgent:      userAgent,
+			ttl:            ttl,
+			ignoreDisallow: ignoreDisallow,
+			fetch:          c.cli.Do,
+			now:            time.Now,
+			hosts:          make(map[string]*robotsHost),
+		}
+	}
+}
+
+func (r *robots) host(key string) *robotsHost {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	h, ok := r.hosts[key]
+	if !ok {
+		h = new(robotsHost)
+		r.hosts[key] = h
+	}
+
+	return h
+}
+
+// check returns ErrDisallowedByRobots if target is disallowed, otherwise it
+// waits for the crawl delay of host. nil robots allows everything
+func (r *robots) check(ctx context.Context, target string) error {
+	if r == nil {
+		return nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+
+	h := r.host(u.Scheme + "://" + u.Host)
+
+	h.lock.Lock()
+	if !r.now().Before(h.expiredAt) {
+		rules, err := r.load(ctx, u)
+		switch {
+		case err == nil:
+			h.rules, h.err, h.expiredAt = rules, nil, r.now().Add(r.ttl)
+		case ctx.Err() != nil:
+			// cancelled request says nothing about robots.txt, nothing is cached
+			h.lock.Unlock()
+			return err
+		case h.rules != nil:
+			zerolog.Ctx(ctx).Warn().Err(err).Str("host", u.Host).Msg("refresh robots.txt failed, previous rules are kept")
+			h.expiredAt = r.now().Add(robotsRetryInterval)
+		case r.ignoreDisallow:
+			zerolog.Ctx(ctx).Warn().Err(err).Str("host", u.Host).Msg("load robots.txt failed, requests are sent as disallow is ignored")
+			h.rules, h.expiredAt = new(robotsRules), r.now().Add(robotsRetryInterval)
+		default:
+			h.err, h.expiredAt = err, r.now().Add(robotsRetryInterval)
+		}
+	}
+
+	if h.err != nil {
+		err := h.err
+		h.lock.Unlock()
+		return err
+	}
+
+	if !h.rules.allowed(u.RequestURI()) {
+		if !r.ignoreDisallow {
+			h.lock.Unlock()
+			zerolog.Ctx(ctx).Info().Str("url", target).Msg("request disallowed by robots.txt")
+			return ErrDisallowedByRobots
+		}
+
+		zerolog.Ctx(ctx).Debug().Str("url", target).Msg("request disallowed by robots.txt is sent as configured")
+	}
+
+	now := r.now()
+	slot := h.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	h.nextSlot = slot.Add(h.rules.crawlDelay)
+	h.lock.Unlock()
+
+	if wait := slot.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil
+}
+
+func (r *robots) load(ctx context.Context, target *url.URL) (*robotsRules, error) {
+	robotsURL := target.Scheme + "://" + target.Host + "/robots.txt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch robots.txt fail: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var rules *robotsRules
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		rules = parseRobots(io.LimitReader(resp.Body, maxRobotsSize), r.userAgent)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		rules = new(robotsRules)
+	default:
+		// server error hides the rules, nothing is requested until it is back
+		return nil, fmt.Errorf("fetch robots.txt fail: %w", StatusCodeError{StatusCode: resp.StatusCode})
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Str("url", robotsURL).
+		Int("status_code", resp.StatusCode).
+		Int("rules", len(rules.rules)).
+		Dur("crawl_delay", rules.crawlDelay).
+		Msg("robots.txt loaded")
+
+	return rules, nil
+}
+
+// robotsRules are the rules of the group matching user agent
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// allowed reports if path (with query) is allowed. the longest matching rule
+// wins, and allow wins the tie
+func (rules *robotsRules) allowed(path string) bool {
+	allowed, length := true, -1
+	for _, rule := range rules.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allowed, length = rule.allow, rule.length
+		}
+	}
+
+	return allowed
+}
+
+// parseRobots returns the rules of the group matching user agent, or the
+// group of "*" if no group matches
+func parseRobots(r io.Reader, userAgent string) *robotsRules {
+	type group struct {
+		agents []string
+		rules  robotsRules
+	}
+
+	var (
+		groups  []*group
+		current *group
+		inRules bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// consecutive user agents share the same group
+			if current == nil || inRules {
+				current = new(group)
+				groups = append(groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+		inRules = true
+
+		switch key {
+		case "allow", "disallow":
+			// empty disallow allows everything
+			if value == "" {
+				continue
+			}
+			current.rules.rules = append(current.rules.rules, robotsRule{
+				allow:   key == "allow",
+				length:  len(value),
+				pattern: compileRobotsPattern(value),
+			})
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				current.rules.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	userAgent = strings.ToLower(userAgent)
+
+	var matched, wildcard *robotsRules
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" && wildcard == nil {
+				wildcard = &g.rules
+			} else if agent != "*" && userAgent != "" && strings.Contains(userAgent, agent) && matched == nil {
+				matched = &g.rules
+			}
+		}
+	}
+
+	switch {
+	case matched != nil:
+		return matched
+	case wildcard != nil:
+		return wildcard
+	default:
+		return new(robotsRules)
+	}
+}
+
+// compileRobotsPattern converts path pattern to regexp, "*" matches any
+// characters and trailing "$" matches end of path
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
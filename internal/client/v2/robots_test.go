@@ -0,0 +1,289 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/htchan/goclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseRobots(t *testing.T) {
+	t.Parallel()
+
+	robotsTxt := `# comment
+User-agent: BookSpider
+User-agent: other-bot
+Disallow: /book/*/download
+Allow: /book/1/download
+Crawl-delay: 2.5
+
+User-agent: *
+Disallow: /admin/ # admin pages
+Disallow: /*.php$
+Disallow:
+`
+
+	tests := []struct {
+		name           string
+		userAgent      string
+		path           string
+		wantAllowed    bool
+		wantCrawlDelay time.Duration
+	}{
+		{
+			name:           "matched group disallows by wildcard",
+			userAgent:      "Mozilla/5.0 (compatible; bookspider/1.0)",
+			path:           "/book/2/download",
+			wantAllowed:    false,
+			wantCrawlDelay: 2500 * time.Millisecond,
+		},
+		{
+			name:           "longer allow wins in matched group",
+			userAgent:      "BookSpider",
+			path:           "/book/1/download",
+			wantAllowed:    true,
+			wantCrawlDelay: 2500 * time.Millisecond,
+		},
+		{
+			name:           "matched group ignores rules of wildcard group",
+			userAgent:      "BookSpider",
+			path:           "/admin/",
+			wantAllowed:    true,
+			wantCrawlDelay: 2500 * time.Millisecond,
+		},
+		{
+			name:        "wildcard group for unmatched user agent",
+			userAgent:   "",
+			path:        "/admin/users",
+			wantAllowed: false,
+		},
+		{
+			name:        "end anchor matches end of path",
+			userAgent:   "",
+			path:        "/index.php",
+			wantAllowed: false,
+		},
+		{
+			name:        "end anchor not match longer path",
+			userAgent:   "",
+			path:        "/index.php?id=1",
+			wantAllowed: true,
+		},
+		{
+			name:        "path without rules",
+			userAgent:   "",
+			path:        "/book/2/download",
+			wantAllowed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules := parseRobots(strings.NewReader(robotsTxt), test.userAgent)
+			assert.Equal(t, test.wantAllowed, rules.allowed(test.path))
+			assert.Equal(t, test.wantCrawlDelay, rules.crawlDelay)
+		})
+	}
+}
+
+// newRobotsTestClient returns client of server serving robots.txt by status
+// code and body, and the number of robots.txt and page requests it received
+func newRobotsTestClient(t *testing.T, statusCode int, robotsTxt string, ignoreDisallow bool) (*Client, string, *atomic.Int64, *atomic.Int64) {
+	t.Helper()
+
+	var robotsRequests, pageRequests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests.Add(1)
+			w.WriteHeader(statusCode)
+			w.Write([]byte(robotsTxt))
+			return
+		}
+
+		pageRequests.Add(1)
+		w.Write([]byte("page"))
+	}))
+	t.Cleanup(server.Close)
+
+	cli := NewClient(
+		goclient.NewClient(goclient.WithRequester(server.Client().Do)),
+		DecodeMethodUTF8,
+		WithRobots("bookspider", time.Hour, ignoreDisallow),
+	)
+
+	return cli, server.URL, &robotsRequests, &pageRequests
+}
+
+func TestClient_Get_Robots(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		statusCode         int
+		robotsTxt          string
+		ignoreDisallow     bool
+		path               string
+		wantError          error
+		wantStatusCode     int
+		wantPageRequests   int64
+		wantRobotsRequests int64
+	}{
+		{
+			name:               "allowed page",
+			statusCode:         http.StatusOK,
+			robotsTxt:          "User-agent: *\nDisallow: /private/",
+			path:               "/book/1",
+			wantPageRequests:   2,
+			wantRobotsRequests: 1,
+		},
+		{
+			name:               "disallowed page not requested",
+			statusCode:         http.StatusOK,
+			robotsTxt:          "User-agent: *\nDisallow: /private/",
+			path:               "/private/1",
+			wantError:          ErrDisallowedByRobots,
+			wantPageRequests:   0,
+			wantRobotsRequests: 1,
+		},
+		{
+			name:               "disallowed page requested if disallow is ignored",
+			statusCode:         http.StatusOK,
+			robotsTxt:          "User-agent: *\nDisallow: /private/",
+			ignoreDisallow:     true,
+			path:               "/private/1",
+			wantPageRequests:   2,
+			wantRobotsRequests: 1,
+		},
+		{
+			name:               "missing robots.txt allows everything",
+			statusCode:         http.StatusNotFound,
+			robotsTxt:          "User-agent: *\nDisallow: /",
+			path:               "/private/1",
+			wantPageRequests:   2,
+			wantRobotsRequests: 1,
+		},
+		{
+			name:               "unreachable robots.txt blocks requests",
+			statusCode:         http.StatusServiceUnavailable,
+			path:               "/book/1",
+			wantError:          StatusCodeError{StatusCode: http.StatusServiceUnavailable},
+			wantPageRequests:   0,
+			wantRobotsRequests: 1,
+		},
+		{
+			name:               "unreachable robots.txt ignored if disallow is ignored",
+			statusCode:         http.StatusServiceUnavailable,
+			ignoreDisallow:     true,
+			path:               "/book/1",
+			wantPageRequests:   2,
+			wantRobotsRequests: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli, url, robotsRequests, pageRequests := newRobotsTestClient(t, test.statusCode, test.robotsTxt, test.ignoreDisallow)
+
+			for range 2 {
+				_, err := cli.Get(t.Context(), url+test.path)
+				assert.ErrorIs(t, err, test.wantError)
+			}
+
+			assert.Equal(t, test.wantPageRequests, pageRequests.Load())
+			assert.Equal(t, test.wantRobotsRequests, robotsRequests.Load())
+		})
+	}
+
+	t.Run("requests paced by crawl delay", func(t *testing.T) {
+		t.Parallel()
+
+		cli, url, _, pageRequests := newRobotsTestClient(t, http.StatusOK, "User-agent: *\nCrawl-delay: 0.1", false)
+
+		start := time.Now()
+		for range 3 {
+			_, err := cli.Get(t.Context(), url+"/book/1")
+			assert.NoError(t, err)
+		}
+
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+		assert.Equal(t, int64(3), pageRequests.Load())
+	})
+
+	t.Run("unreachable robots.txt refreshed after retry interval", func(t *testing.T) {
+		t.Parallel()
+
+		var statusCode atomic.Int64
+		statusCode.Store(http.StatusOK)
+
+		var robotsRequests atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/robots.txt" {
+				robotsRequests.Add(1)
+				w.WriteHeader(int(statusCode.Load()))
+				w.Write([]byte("User-agent: *\nDisallow: /private/"))
+				return
+			}
+
+			w.Write([]byte("page"))
+		}))
+		t.Cleanup(server.Close)
+
+		cli := NewClient(
+			goclient.NewClient(goclient.WithRequester(server.Client().Do)),
+			DecodeMethodUTF8,
+			WithRobots("bookspider", time.Hour, false),
+		)
+
+		now := time.Now()
+		cli.robots.now = func() time.Time { return now }
+
+		_, err := cli.Get(t.Context(), server.URL+"/book/1")
+		assert.NoError(t, err)
+
+		// failed refresh keeps previous rules
+		statusCode.Store(http.StatusServiceUnavailable)
+		now = now.Add(time.Hour)
+		_, err = cli.Get(t.Context(), server.URL+"/book/1")
+		assert.NoError(t, err)
+		_, err = cli.Get(t.Context(), server.URL+"/private/1")
+		assert.ErrorIs(t, err, ErrDisallowedByRobots)
+		assert.Equal(t, int64(2), robotsRequests.Load())
+
+		// refreshed again after retry interval
+		statusCode.Store(http.StatusOK)
+		now = now.Add(robotsRetryInterval)
+		_, err = cli.Get(t.Context(), server.URL+"/book/1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), robotsRequests.Load())
+	})
+
+	t.Run("unreachable robots.txt fetched again after retry interval", func(t *testing.T) {
+		t.Parallel()
+
+		cli, url, robotsRequests, pageRequests := newRobotsTestClient(t, http.StatusServiceUnavailable, "", false)
+
+		now := time.Now()
+		cli.robots.now = func() time.Time { return now }
+
+		for range 2 {
+			_, err := cli.Get(t.Context(), url+"/book/1")
+			assert.ErrorIs(t, err, StatusCodeError{StatusCode: http.StatusServiceUnavailable})
+		}
+		assert.Equal(t, int64(1), robotsRequests.Load())
+
+		now = now.Add(robotsRetryInterval)
+		_, err := cli.Get(t.Context(), url+"/book/1")
+		assert.ErrorIs(t, err, StatusCodeError{StatusCode: http.StatusServiceUnavailable})
+		assert.Equal(t, int64(2), robotsRequests.Load())
+		assert.Equal(t, int64(0), pageRequests.Load())
+	})
+}
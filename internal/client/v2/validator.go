@@ -0,0 +1,78 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultMaxValidators bounds the validators kept by client, it is larger
+// than the pages a site checks in one update
+const defaultMaxValidators = 100000
+
+type validator struct {
+	etag         string
+	lastModified string
+}
+
+type validatorEntry struct {
+	url       string
+	validator validator
+}
+
+// validatorCache keeps validators of the recently used urls, the least
+// recently used one is dropped once it is full
+type validatorCache struct {
+	lock    sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // front is the most recently used
+}
+
+func newValidatorCache(size int) *validatorCache {
+	return &validatorCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *validatorCache) load(url string) (validator, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[url]
+	if !ok {
+		return validator{}, false
+	}
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*validatorEntry).validator, true
+}
+
+func (c *validatorCache) save(url string, v validator) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.entries[url]; ok {
+		elem.Value.(*validatorEntry).validator = v
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[url] = c.order.PushFront(&validatorEntry{url: url, validator: v})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*validatorEntry).url)
+	}
+}
+
+func (c *validatorCache) delete(url string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.entries[url]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, url)
+	}
+}
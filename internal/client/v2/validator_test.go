@@ -0,0 +1,86 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validatorCache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		size    int
+		operate func(c *validatorCache)
+		want    map[string]validator
+	}{
+		{
+			name: "keep validators within size",
+			size: 2,
+			operate: func(c *validatorCache) {
+				c.save("a", validator{etag: "a"})
+				c.save("b", validator{etag: "b"})
+			},
+			want: map[string]validator{"a": {etag: "a"}, "b": {etag: "b"}},
+		},
+		{
+			name: "evict least recently saved",
+			size: 2,
+			operate: func(c *validatorCache) {
+				c.save("a", validator{etag: "a"})
+				c.save("b", validator{etag: "b"})
+				c.save("c", validator{etag: "c"})
+			},
+			want: map[string]validator{"b": {etag: "b"}, "c": {etag: "c"}},
+		},
+		{
+			name: "loaded validator is recently used",
+			size: 2,
+			operate: func(c *validatorCache) {
+				c.save("a", validator{etag: "a"})
+				c.save("b", validator{etag: "b"})
+				c.load("a")
+				c.save("c", validator{etag: "c"})
+			},
+			want: map[string]validator{"a": {etag: "a"}, "c": {etag: "c"}},
+		},
+		{
+			name: "update existing validator",
+			size: 2,
+			operate: func(c *validatorCache) {
+				c.save("a", validator{etag: "a"})
+				c.save("b", validator{etag: "b"})
+				c.save("a", validator{lastModified: "a"})
+				c.save("c", validator{etag: "c"})
+			},
+			want: map[string]validator{"a": {lastModified: "a"}, "c": {etag: "c"}},
+		},
+		{
+			name: "delete validator",
+			size: 2,
+			operate: func(c *validatorCache) {
+				c.save("a", validator{etag: "a"})
+				c.delete("a")
+				c.delete("unknown")
+			},
+			want: map[string]validator{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newValidatorCache(test.size)
+			test.operate(c)
+
+			got := make(map[string]validator)
+			for url, elem := range c.entries {
+				got[url] = elem.Value.(*validatorEntry).validator
+			}
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, len(test.want), c.order.Len())
+		})
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"slices"
 
 	"github.com/htchan/BookSpider/internal/config/v2"
+	formatv1 "github.com/htchan/BookSpider/internal/format/v1"
 	repo "github.com/htchan/BookSpider/internal/repo/sqlc"
 	"github.com/htchan/BookSpider/internal/service"
 	service_v1 "github.com/htchan/BookSpider/internal/service/v1"
 	"github.com/htchan/BookSpider/internal/vendorservice/baling"
 	"github.com/htchan/BookSpider/internal/vendorservice/bestory"
 	"github.com/htchan/BookSpider/internal/vendorservice/ck101"
+	"github.com/htchan/BookSpider/internal/vendorservice/configured"
 	"github.com/htchan/BookSpider/internal/vendorservice/hjwzw"
 	"github.com/htchan/BookSpider/internal/vendorservice/uukanshu"
 	"github.com/htchan/BookSpider/internal/vendorservice/xbiquge"
@@ -52,6 +54,19 @@ func LoadServices(vendors []string, db *sql.DB, siteConf map[string]config.SiteC
 		result[uukanshu.Host] = uukanshu.NewService(rpo, publicSema, siteConf[uukanshu.Host])
 	}
 
+	// sites without vendor code are parsed by the urls and selectors in config
+	for _, name := range vendors {
+		if _, ok := result[name]; !ok && configured.IsConfigured(siteConf[name]) {
+			result[name] = configured.NewService(name, rpo, publicSema, siteConf[name])
+		}
+	}
+
+	for name, serv := range result {
+		if siteConf[name].MultiSourceDownload {
+			serv.RegisterSources(result)
+		}
+	}
+
 	return result
 }
 
@@ -60,3 +75,17 @@ func LoadReadDataService(db *sql.DB, siteConf map[string]config.SiteConfig) serv
 
 	return service_v1.NewReadDataService(rpo, siteConf)
 }
+
+// LoadImportService returns the service importing books to the storage of local site
+func LoadImportService(db *sql.DB, storage string) service.ImportService {
+	rpo := repo.NewRepo(db)
+
+	return service_v1.NewImportService(rpo, formatv1.NewService(config.EpubConfig{}, nil), storage)
+}
+
+// LoadChecksumService returns the service filling missing checksums of all sites
+func LoadChecksumService(db *sql.DB) service.ChecksumService {
+	rpo := repo.NewRepo(db)
+
+	return service_v1.NewChecksumService(rpo)
+}
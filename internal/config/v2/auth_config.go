@@ -0,0 +1,44 @@
+package config
+
+import "time"
+
+// AuthConfig controls the authentication of api routes. it is loaded from the
+// `auth` section of main.yaml, read routes stay public and admin routes are
+// rejected if no api key or user is configured
+type AuthConfig struct {
+	APIKeys       []APIKeyConfig        `yaml:"api_keys" validate:"dive"`
+	Users         []UserConfig          `yaml:"users" validate:"dive"`
+	AnonymousRole string                `yaml:"anonymous_role" validate:"omitempty,oneof=reader admin"`
+	SessionTTL    time.Duration         `yaml:"session_ttl" validate:"omitempty,min=1m"`
+	RateLimit     ClientRateLimitConfig `yaml:"rate_limit"`
+}
+
+// APIKeyConfig stores the sha256 of key only, so the config file does not
+// leak the key
+type APIKeyConfig struct {
+	Name      string                 `yaml:"name" validate:"min=1"`
+	KeySHA256 string                 `yaml:"key_sha256" validate:"len=64,hexadecimal"`
+	Role      string                 `yaml:"role" validate:"oneof=reader admin"`
+	RateLimit *ClientRateLimitConfig `yaml:"rate_limit"`
+}
+
+// UserConfig stores the bcrypt hash of password only, so the config file does
+// not leak the password
+type UserConfig struct {
+	Name           string                 `yaml:"name" validate:"min=1"`
+	PasswordBcrypt string                 `yaml:"password_bcrypt" validate:"startswith=$2"`
+	Role           string                 `yaml:"role" validate:"oneof=reader admin"`
+	RateLimit      *ClientRateLimitConfig `yaml:"rate_limit"`
+}
+
+// ClientRateLimitConfig is the token bucket of a client on expensive routes.
+// rate is the tokens refilled per second, zero rate is unlimited
+type ClientRateLimitConfig struct {
+	Rate  float64 `yaml:"rate" validate:"min=0"`
+	Burst int     `yaml:"burst" validate:"min=0"`
+}
+
+// Enabled returns true if any api key or user is configured
+func (conf AuthConfig) Enabled() bool {
+	return len(conf.APIKeys) > 0 || len(conf.Users) > 0
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/caarlos0/env/v6"
+	"github.com/htchan/BookSpider/internal/model"
 	"gopkg.in/yaml.v2"
 
 	"github.com/go-playground/validator/v10"
@@ -21,7 +22,12 @@ type APIConfig struct {
 	AvailableSiteNames []string              `env:"API_AVAILABLE_SITES,required" validate:"min=1,dive,min=1"`
 	SiteConfigs        map[string]SiteConfig `yaml:"sites" validate:"dive"`
 	DatabaseConfig     DatabaseConfig        `yaml:"database"`
-	ConfigDirectory    string                `env:"CONFIG_DIRECTORY,required" validate:"dir"`
+	EpubConfig         EpubConfig
+	ImportStorage      string `env:"IMPORT_STORAGE" validate:"omitempty,dir"`
+	ConfigDirectory    string `env:"CONFIG_DIRECTORY,required" validate:"dir"`
+	// CORSAllowedOrigins defaults to allow any origin if empty
+	CORSAllowedOrigins []string   `env:"API_CORS_ALLOWED_ORIGINS" validate:"dive,min=1"`
+	AuthConfig         AuthConfig `yaml:"auth" validate:"dive"`
 }
 
 type WorkerConfig struct {
@@ -33,11 +39,27 @@ type WorkerConfig struct {
 	ScheduleConfig     ScheduleConfig        `yaml:"schedule"`
 	ConfigDirectory    string                `env:"CONFIG_DIRECTORY,required" validate:"dir"`
 }
+
+// ImportConfig is the config of importing books from files
+type ImportConfig struct {
+	Storage        string `env:"IMPORT_STORAGE,required" validate:"dir"`
+	DatabaseConfig DatabaseConfig
+}
+
 type TraceConfig struct {
 	OtelURL         string `env:"OTEL_URL,required" validate:"url"`
 	OtelServiceName string `env:"OTEL_SERVICE_NAME,required" validate:"min=1"`
 }
 
+// EpubConfig controls the layout of generated epub.
+// empty writing mode is horizontal, stylesheet replaces the built in one.
+// cover font is the cjk font used to draw generated covers
+type EpubConfig struct {
+	WritingMode string `env:"EPUB_WRITING_MODE" validate:"omitempty,oneof=horizontal vertical"`
+	Stylesheet  string `env:"EPUB_STYLESHEET" validate:"omitempty,file"`
+	CoverFont   string `env:"EPUB_COVER_FONT" validate:"omitempty,file"`
+}
+
 type DatabaseConfig struct {
 	Host            string        `env:"PSQL_HOST,required" validate:"min=1"`
 	Port            string        `env:"PSQL_PORT,required" validate:"min=1"`
@@ -56,6 +78,7 @@ func LoadAPIConfig() (*APIConfig, error) {
 		func() error { return env.Parse(&conf) },
 		func() error { return env.Parse(&conf.DatabaseConfig) },
 		func() error { return env.Parse(&conf.TraceConfig) },
+		func() error { return env.Parse(&conf.EpubConfig) },
 		func() error {
 			var referenceData []byte
 
@@ -76,6 +99,7 @@ func LoadAPIConfig() (*APIConfig, error) {
 
 			fullConfig := struct {
 				Sites map[string]SiteConfig `yaml:"sites"`
+				Auth  AuthConfig            `yaml:"auth"`
 			}{}
 
 			err = yaml.Unmarshal(append(referenceData, configData...), &fullConfig)
@@ -84,6 +108,7 @@ func LoadAPIConfig() (*APIConfig, error) {
 			}
 
 			conf.SiteConfigs = fullConfig.Sites
+			conf.AuthConfig = fullConfig.Auth
 
 			return nil
 		},
@@ -102,6 +127,21 @@ func (conf *APIConfig) Validate() error {
 	return validator.New().Struct(conf)
 }
 
+// ReadSiteConfigs returns the site configs to read books, including the
+// local site of imported books if import storage is set
+func (conf *APIConfig) ReadSiteConfigs() map[string]SiteConfig {
+	result := make(map[string]SiteConfig, len(conf.SiteConfigs)+1)
+	for site, siteConf := range conf.SiteConfigs {
+		result[site] = siteConf
+	}
+
+	if conf.ImportStorage != "" {
+		result[model.LocalSite] = SiteConfig{Storage: conf.ImportStorage}
+	}
+
+	return result
+}
+
 func LoadWorkerConfig() (*WorkerConfig, error) {
 	var conf WorkerConfig
 
@@ -164,3 +204,24 @@ func (conf *WorkerConfig) Validate() error {
 
 	return nil
 }
+
+func LoadImportConfig() (*ImportConfig, error) {
+	var conf ImportConfig
+
+	loadConfigFuncs := []func() error{
+		func() error { return env.Parse(&conf) },
+		func() error { return env.Parse(&conf.DatabaseConfig) },
+	}
+
+	for _, f := range loadConfigFuncs {
+		if err := f(); err != nil {
+			return nil, fmt.Errorf("parse config: %w", err)
+		}
+	}
+
+	return &conf, nil
+}
+
+func (conf *ImportConfig) Validate() error {
+	return validator.New().Struct(conf)
+}
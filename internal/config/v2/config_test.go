@@ -160,6 +160,205 @@ func Test_validate_APIConfig(t *testing.T) {
 			},
 			valid: false,
 		},
+		{
+			name: "vertical epub writing mode",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				EpubConfig:      EpubConfig{WritingMode: "vertical"},
+				ConfigDirectory: ".",
+			},
+			valid: true,
+		},
+		{
+			name: "invalid epub writing mode",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				EpubConfig:      EpubConfig{WritingMode: "diagonal"},
+				ConfigDirectory: ".",
+			},
+			valid: false,
+		},
+		{
+			name: "cover font not exist",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				EpubConfig:      EpubConfig{CoverFont: "not-exist.ttf"},
+				ConfigDirectory: ".",
+			},
+			valid: false,
+		},
+		{
+			name: "import storage not exist",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				ImportStorage:   "./not-exist/",
+				ConfigDirectory: ".",
+			},
+			valid: false,
+		},
+		{
+			name: "auth with api keys and users",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				ConfigDirectory:    ".",
+				CORSAllowedOrigins: []string{"https://example.com"},
+				AuthConfig: AuthConfig{
+					APIKeys: []APIKeyConfig{{
+						Name: "app", KeySHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Role: "reader",
+						RateLimit: &ClientRateLimitConfig{Rate: 1, Burst: 5},
+					}},
+					Users:         []UserConfig{{Name: "admin", PasswordBcrypt: "$2a$10$20d59COhEgDX7wF6a.dbtehWQzSCkwMOQlVvHd1feOu3j9pCVLoWa", Role: "admin"}},
+					AnonymousRole: "reader",
+					SessionTTL:    time.Hour,
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "invalid api key hash",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				ConfigDirectory: ".",
+				AuthConfig: AuthConfig{
+					APIKeys: []APIKeyConfig{{Name: "app", KeySHA256: "plain key", Role: "reader"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "password not in bcrypt",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				ConfigDirectory: ".",
+				AuthConfig: AuthConfig{
+					Users: []UserConfig{{Name: "admin", PasswordBcrypt: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Role: "admin"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid auth role",
+			conf: APIConfig{
+				APIRoutePrefix:     "/data",
+				LiteRoutePrefix:    "/data",
+				AvailableSiteNames: []string{"data"},
+				SiteConfigs:        map[string]SiteConfig{},
+				TraceConfig: TraceConfig{
+					OtelURL:         "http://localhost:4317",
+					OtelServiceName: "test-service",
+				},
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+				ConfigDirectory: ".",
+				AuthConfig: AuthConfig{
+					Users: []UserConfig{{Name: "admin", PasswordBcrypt: "$2a$10$20d59COhEgDX7wF6a.dbtehWQzSCkwMOQlVvHd1feOu3j9pCVLoWa", Role: "owner"}},
+				},
+			},
+			valid: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -404,6 +603,101 @@ func Test_validate_BatchConfig(t *testing.T) {
 	}
 }
 
+func Test_APIConfig_ReadSiteConfigs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		conf APIConfig
+		want map[string]SiteConfig
+	}{
+		{
+			name: "without import storage",
+			conf: APIConfig{SiteConfigs: map[string]SiteConfig{"data": {Storage: "/data"}}},
+			want: map[string]SiteConfig{"data": {Storage: "/data"}},
+		},
+		{
+			name: "with import storage",
+			conf: APIConfig{SiteConfigs: map[string]SiteConfig{"data": {Storage: "/data"}}, ImportStorage: "/import"},
+			want: map[string]SiteConfig{"data": {Storage: "/data"}, "local": {Storage: "/import"}},
+		},
+		{
+			name: "no site configs",
+			conf: APIConfig{ImportStorage: "/import"},
+			want: map[string]SiteConfig{"local": {Storage: "/import"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.conf.ReadSiteConfigs())
+			if test.conf.ImportStorage != "" {
+				assert.NotContains(t, test.conf.SiteConfigs, "local")
+			}
+		})
+	}
+}
+
+func Test_validate_ImportConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		conf  ImportConfig
+		valid bool
+	}{
+		{
+			name: "valid conf",
+			conf: ImportConfig{
+				Storage: ".",
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "invalid Storage",
+			conf: ImportConfig{
+				Storage: "./not-exist/",
+				DatabaseConfig: DatabaseConfig{
+					Host:     "host",
+					Port:     "port",
+					User:     "user",
+					Password: "pwd",
+					Name:     "name",
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid DatabaseConfig",
+			conf: ImportConfig{
+				Storage:        ".",
+				DatabaseConfig: DatabaseConfig{},
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.conf.Validate()
+			if !assert.Equal(t, test.valid, err == nil) {
+				t.Errorf("getting error: %v", err)
+			}
+		})
+	}
+}
+
 func Test_validate_DatabaseConfig(t *testing.T) {
 	t.Parallel()
 
@@ -7,7 +7,7 @@ import (
 )
 
 type SiteConfig struct {
-	DecodeMethod   client.DecodeMethod `yaml:"decode_method" validate:"oneof=gbk big5 utf8"`
+	DecodeMethod   client.DecodeMethod `yaml:"decode_method" validate:"oneof=gbk gb18030 big5 utf8 auto"`
 	ClientConfig   ClientConfig        `yaml:"client" validate:"dive"`
 	RequestTimeout time.Duration       `yaml:"request_timeout" validate:"min=1s"`
 
@@ -17,8 +17,11 @@ type SiteConfig struct {
 	URL                    URLConfig              `yaml:"urls"`
 	MaxExploreError        int                    `yaml:"max_explore_error" validate:"min=1"`
 	MaxDownloadConcurrency int                    `yaml:"max_download_concurrency" validate:"min=1"`
+	MultiSourceDownload    bool                   `yaml:"multi_source_download"`
 	GoquerySelectorsConfig GoquerySelectorsConfig `yaml:"goquery_selectors"`
 	AvailabilityConfig     AvailabilityConfig     `yaml:"availability"`
+	HealthConfig           HealthConfig           `yaml:"health"`
+	SnapshotConfig         SnapshotConfig         `yaml:"snapshot"`
 	// UpdateDateLayour string    `yaml:"update_date_layout"`
 }
 
@@ -26,11 +29,33 @@ type ClientConfig struct {
 	RateLimit      RateLimitConfig      `yaml:"rate_limit" validate:"dive"`
 	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker" validate:"dive"`
 	Retry          RetryConfig          `yaml:"retry" validate:"dive"`
+	ResponseCache  ResponseCacheConfig  `yaml:"response_cache"`
+	Proxy          ProxyConfig          `yaml:"proxy"`
+	Identity       IdentityConfig       `yaml:"identity"`
+	Robots         RobotsConfig         `yaml:"robots"`
 }
 
 type RateLimitConfig struct {
-	QueueSize int           `yaml:"queue_size" validate:"min=1"`
-	Interval  time.Duration `yaml:"interval" validate:"min=100ms"`
+	QueueSize int                     `yaml:"queue_size" validate:"min=1"`
+	Interval  time.Duration           `yaml:"interval" validate:"min=100ms"`
+	Adaptive  AdaptiveRateLimitConfig `yaml:"adaptive"`
+}
+
+// AdaptiveRateLimitConfig adjusts the request interval of vendor between min
+// and max interval on top of the fixed interval, so fixed interval should not
+// exceed min interval. the rate grows by increase step (requests per second)
+// after every window of requests with p95 latency and error rate under target,
+// and it is multiplied by backoff ratio on unhealthy window, 429, 503 or
+// Retry-After header
+type AdaptiveRateLimitConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	MinInterval   time.Duration `yaml:"min_interval" validate:"required_if=Enabled true,min=0"`
+	MaxInterval   time.Duration `yaml:"max_interval" validate:"required_if=Enabled true,gtefield=MinInterval"`
+	Window        int           `yaml:"window" validate:"required_if=Enabled true,min=0"`
+	TargetLatency time.Duration `yaml:"target_latency" validate:"required_if=Enabled true,min=0"`
+	MaxErrorRate  float64       `yaml:"max_error_rate" validate:"gte=0,lte=1"`
+	IncreaseStep  float64       `yaml:"increase_step" validate:"required_if=Enabled true,gte=0"`
+	BackoffRatio  float64       `yaml:"backoff_ratio" validate:"required_if=Enabled true,gte=0,lt=1"`
 }
 
 type CircuitBreakerConfig struct {
@@ -40,10 +65,55 @@ type CircuitBreakerConfig struct {
 	OpenQueueRatio   float64       `yaml:"open_queue_ratio" validate:"gt=0,lte=1"`
 }
 
+// RetryConfig retries the requests failed temporarily, missing pages (404,
+// 410) and other 4xx are never retried. interval is extended to Retry-After
+// header, and a request stops retrying once total wait would exceed budget.
+// zero budget means no limit
 type RetryConfig struct {
 	MaxRetries   int           `yaml:"max_retries" validate:"min=0"`
 	BaseInterval time.Duration `yaml:"base_interval" validate:"min=100ms"`
 	IntervalType string        `yaml:"interval_type" validate:"oneof=static linear exponential"`
+	Budget       time.Duration `yaml:"budget" validate:"min=0"`
+}
+
+// ResponseCacheConfig keeps the chapter pages on disk, so download retry of
+// same book does not fetch the same chapters again. cache is disabled if
+// directory is empty or ttl is zero
+type ResponseCacheConfig struct {
+	Directory string        `yaml:"directory" validate:"omitempty,dir"`
+	TTL       time.Duration `yaml:"ttl" validate:"min=0"`
+}
+
+// ProxyConfig sends requests through a pool of http or socks5 proxies. each
+// proxy has its own circuit breaker instead of the vendor one, and proxies
+// failed the health check are skipped until next check. requests are sent
+// directly if no proxy is configured
+type ProxyConfig struct {
+	URLs           []string `yaml:"urls" validate:"dive,startswith=http://|startswith=https://|startswith=socks5://"`
+	Strategy       string   `yaml:"strategy" validate:"omitempty,oneof=round_robin random"`
+	HealthCheckURL string   `yaml:"health_check_url" validate:"omitempty,url"`
+}
+
+// IdentityConfig rotates the user agent and headers of every request by
+// profiles, and keeps cookies of vendor per proxy if cookie jar is enabled
+type IdentityConfig struct {
+	Profiles  []IdentityProfileConfig `yaml:"profiles" validate:"dive"`
+	CookieJar bool                    `yaml:"cookie_jar"`
+}
+
+type IdentityProfileConfig struct {
+	UserAgent string            `yaml:"user_agent" validate:"min=1"`
+	Headers   map[string]string `yaml:"headers"`
+}
+
+// RobotsConfig follows robots.txt of vendor hosts with the group of user
+// agent ("*" if empty). robots.txt is fetched again after ttl (24 hours if
+// zero). disallowed urls are never requested unless ignore disallow is set,
+// and crawl delay is the floor of request interval of the host
+type RobotsConfig struct {
+	UserAgent      string        `yaml:"user_agent"`
+	TTL            time.Duration `yaml:"ttl" validate:"min=0"`
+	IgnoreDisallow bool          `yaml:"ignore_disallow"`
 }
 
 type URLConfig struct {
@@ -57,6 +127,27 @@ type AvailabilityConfig struct {
 	CheckString string `yaml:"check_string" validate:"min=1"`
 }
 
+// HealthConfig detects the selectors broken by vendor html change. canary
+// books are known good books parsed before every phase, and update or
+// download is aborted once the parse failure rate of at least min samples
+// exceeds the max rate. explore is guarded by canary only, as unexplored ids
+// fail to parse naturally. zero max rate disables the abort
+type HealthConfig struct {
+	CanaryBookIDs       []int   `yaml:"canary_book_ids" validate:"dive,min=1"`
+	MaxParseFailureRate float64 `yaml:"max_parse_failure_rate" validate:"gte=0,lte=1"`
+	MinParseSamples     int     `yaml:"min_parse_samples" validate:"min=0"`
+}
+
+// SnapshotConfig keeps the fetched book pages and chapter lists for
+// reparse. snapshot is disabled if directory is empty. versions of a page
+// beyond max versions or older than max age are removed, zero means no
+// limit, and the latest version is always kept
+type SnapshotConfig struct {
+	Directory   string        `yaml:"directory" validate:"omitempty,dir"`
+	MaxVersions int           `yaml:"max_versions" validate:"min=0"`
+	MaxAge      time.Duration `yaml:"max_age" validate:"min=0"`
+}
+
 type GoquerySelectorsConfig struct {
 	Title            GoquerySelectorConfig `yaml:"title"`
 	Writer           GoquerySelectorConfig `yaml:"writer"`
@@ -0,0 +1,8 @@
+package format
+
+import "errors"
+
+var (
+	ErrNoChapter   = errors.New("no chapter found")
+	ErrInvalidEpub = errors.New("invalid epub")
+)
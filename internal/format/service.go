@@ -10,6 +10,16 @@ import (
 type Service interface {
 	ChaptersFromTxt(context.Context, io.Reader) (model.Chapters, error)
 
+	// import external books, the book only has the info found in file
+	ImportTxt(context.Context, io.Reader) (*model.Book, model.Chapters, error)
+	ImportEpub(context.Context, io.ReaderAt, int64) (*model.Book, model.Chapters, error)
+
 	WriteBookTxt(context.Context, *model.Book, model.Chapters, io.Writer) error
+	WriteBookTxtFromTxt(context.Context, *model.Book, io.Reader, io.Writer) error
 	WriteBookEpub(context.Context, *model.Book, model.Chapters, io.Writer) error
+	WriteBookEpubFromTxt(context.Context, *model.Book, io.Reader, io.Writer) error
+	WriteBookFB2(context.Context, *model.Book, model.Chapters, io.Writer) error
+	WriteBookHTML(context.Context, *model.Book, model.Chapters, io.Writer) error
+	WriteBookMarkdown(context.Context, *model.Book, model.Chapters, io.Writer) error
+	WriteBookCover(context.Context, *model.Book, io.Writer) error
 }
@@ -0,0 +1,247 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	coverWidth       = 600
+	coverHeight      = 800
+	coverMargin      = 60
+	coverBorder      = 24
+	coverBorderWidth = 4
+
+	coverTitleSize       = 56
+	coverWriterSize      = 32
+	coverSiteSize        = 24
+	coverTitleLines      = 5
+	coverTitleLineHeight = 72
+)
+
+// builtinCoverFont is used for runes missing in the configured cover font
+var builtinCoverFont = func() *opentype.Font {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(fmt.Errorf("parse builtin cover font failed: %w", err))
+	}
+
+	return f
+}()
+
+// loadCoverFonts returns the fonts in priority order. the configured font is
+// expected to be a cjk font, the builtin one only covers latin characters
+func (serv *serviceImpl) loadCoverFonts() ([]*opentype.Font, error) {
+	if serv.conf.CoverFont == "" {
+		return []*opentype.Font{builtinCoverFont}, nil
+	}
+
+	data, err := os.ReadFile(serv.conf.CoverFont)
+	if err != nil {
+		return nil, fmt.Errorf("read cover font failed: %w", err)
+	}
+
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse cover font failed: %w", err)
+	}
+
+	return []*opentype.Font{f, builtinCoverFont}, nil
+}
+
+// coverFace draws each rune with the first face containing its glyph
+type coverFace struct {
+	faces []font.Face
+}
+
+func newCoverFace(fonts []*opentype.Font, size float64) (*coverFace, error) {
+	face := &coverFace{}
+	for _, f := range fonts {
+		fontFace, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("create cover font face failed: %w", err)
+		}
+
+		face.faces = append(face.faces, fontFace)
+	}
+
+	return face, nil
+}
+
+func (face *coverFace) pick(r rune) font.Face {
+	for _, f := range face.faces {
+		if _, ok := f.GlyphAdvance(r); ok {
+			return f
+		}
+	}
+
+	return face.faces[0]
+}
+
+func (face *coverFace) measure(s string) fixed.Int26_6 {
+	var width fixed.Int26_6
+	for _, r := range s {
+		advance, _ := face.pick(r).GlyphAdvance(r)
+		width += advance
+	}
+
+	return width
+}
+
+// wrap breaks the text into lines fit in the width. the last line is
+// shortened with ellipsis if the text needs more than max lines
+func (face *coverFace) wrap(s string, width fixed.Int26_6, maxLines int) []string {
+	lines := make([]string, 0, maxLines)
+	var line []rune
+	for _, r := range strings.TrimSpace(s) {
+		if len(line) > 0 && face.measure(string(append(line, r))) > width {
+			lines = append(lines, strings.TrimSpace(string(line)))
+			line = nil
+		}
+
+		line = append(line, r)
+	}
+	if len(line) > 0 {
+		lines = append(lines, strings.TrimSpace(string(line)))
+	}
+
+	if len(lines) > maxLines {
+		last := []rune(lines[maxLines-1])
+		for len(last) > 0 && face.measure(string(last)+"…") > width {
+			last = last[:len(last)-1]
+		}
+
+		lines = append(lines[:maxLines-1], string(last)+"…")
+	}
+
+	return lines
+}
+
+// drawCentered draws the text centered horizontally with baseline at y
+func (face *coverFace) drawCentered(dst draw.Image, s string, y int, c color.Color) {
+	x := fixed.I(coverWidth)/2 - face.measure(s)/2
+	for _, r := range s {
+		f := face.pick(r)
+		drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: f, Dot: fixed.Point26_6{X: x, Y: fixed.I(y)}}
+		drawer.DrawString(string(r))
+		x = drawer.Dot.X
+	}
+}
+
+// hslColor converts hue in degree, saturation and lightness in [0, 1] to rgb color
+func hslColor(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 255,
+	}
+}
+
+// coverColors derives the background and accent color from book checksum,
+// so books with same title share the same colors across sites
+func coverColors(bk *model.Book) (color.RGBA, color.RGBA) {
+	key := bk.Checksum()
+	if key == "" {
+		key = bk.String()
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	hue := float64(h.Sum32() % 360)
+
+	return hslColor(hue, 0.45, 0.3), hslColor(math.Mod(hue+180, 360), 0.6, 0.7)
+}
+
+func (serv *serviceImpl) renderCover(bk *model.Book) (image.Image, error) {
+	fonts, err := serv.loadCoverFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	titleFace, err := newCoverFace(fonts, coverTitleSize)
+	if err != nil {
+		return nil, err
+	}
+	writerFace, err := newCoverFace(fonts, coverWriterSize)
+	if err != nil {
+		return nil, err
+	}
+	siteFace, err := newCoverFace(fonts, coverSiteSize)
+	if err != nil {
+		return nil, err
+	}
+
+	background, accent := coverColors(bk)
+	img := image.NewRGBA(image.Rect(0, 0, coverWidth, coverHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	frame := image.Rect(coverBorder, coverBorder, coverWidth-coverBorder, coverHeight-coverBorder)
+	draw.Draw(img, frame, image.NewUniform(accent), image.Point{}, draw.Src)
+	draw.Draw(img, frame.Inset(coverBorderWidth), image.NewUniform(background), image.Point{}, draw.Src)
+
+	textWidth := fixed.I(coverWidth - 2*coverMargin)
+	y := coverHeight / 4
+	for _, line := range titleFace.wrap(bk.Title, textWidth, coverTitleLines) {
+		titleFace.drawCentered(img, line, y, color.White)
+		y += coverTitleLineHeight
+	}
+
+	y += coverWriterSize
+	for _, line := range writerFace.wrap(bk.Writer.Name, textWidth, 1) {
+		writerFace.drawCentered(img, line, y, accent)
+	}
+
+	siteFace.drawCentered(img, bk.Site, coverHeight-coverMargin-coverSiteSize, accent)
+
+	return img, nil
+}
+
+// WriteBookCover writes the generated png cover showing the title, writer and site of book
+func (serv *serviceImpl) WriteBookCover(ctx context.Context, bk *model.Book, writer io.Writer) error {
+	img, err := serv.renderCover(bk)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(writer, img); err != nil {
+		return fmt.Errorf("encode cover failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+func Test_hslColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		h, s, l float64
+		want    color.RGBA
+	}{
+		{name: "red", h: 0, s: 1, l: 0.5, want: color.RGBA{R: 255, A: 255}},
+		{name: "green", h: 120, s: 1, l: 0.5, want: color.RGBA{G: 255, A: 255}},
+		{name: "blue", h: 240, s: 1, l: 0.5, want: color.RGBA{B: 255, A: 255}},
+		{name: "white", h: 0, s: 0, l: 1, want: color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+		{name: "grey", h: 200, s: 0, l: 0.5, want: color.RGBA{R: 128, G: 128, B: 128, A: 255}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, hslColor(test.h, test.s, test.l))
+		})
+	}
+}
+
+func Test_coverColors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		bk      *model.Book
+		otherBk *model.Book
+		same    bool
+	}{
+		{
+			name:    "same title in other site and script has same colors",
+			bk:      &model.Book{Site: "test", ID: 1, Title: "斗破苍穹"},
+			otherBk: &model.Book{Site: "test-2", ID: 2, Title: "鬥破蒼穹"},
+			same:    true,
+		},
+		{
+			name:    "different title has different colors",
+			bk:      &model.Book{Site: "test", ID: 1, Title: "title"},
+			otherBk: &model.Book{Site: "test", ID: 1, Title: "other title"},
+			same:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			background, accent := coverColors(test.bk)
+			otherBackground, otherAccent := coverColors(test.otherBk)
+			assert.NotEqual(t, background, accent)
+			if test.same {
+				assert.Equal(t, background, otherBackground)
+				assert.Equal(t, accent, otherAccent)
+			} else {
+				assert.NotEqual(t, background, otherBackground)
+			}
+		})
+	}
+}
+
+func Test_coverFace_wrap(t *testing.T) {
+	t.Parallel()
+
+	face, err := newCoverFace([]*opentype.Font{builtinCoverFont}, 10)
+	if err != nil {
+		t.Fatalf("create face failed: %v", err)
+	}
+	charWidth := face.measure("a")
+
+	tests := []struct {
+		name     string
+		s        string
+		width    fixed.Int26_6
+		maxLines int
+		want     []string
+	}{
+		{
+			name:     "fit in one line",
+			s:        "aaaa",
+			width:    charWidth * 4,
+			maxLines: 2,
+			want:     []string{"aaaa"},
+		},
+		{
+			name:     "break into lines",
+			s:        "aaaabbbbcc",
+			width:    charWidth * 4,
+			maxLines: 3,
+			want:     []string{"aaaa", "bbbb", "cc"},
+		},
+		{
+			name:     "shorten last line with ellipsis",
+			s:        "aaaabbbbcccc",
+			width:    charWidth * 4,
+			maxLines: 2,
+			want:     []string{"aaaa", "bb…"},
+		},
+		{
+			name:     "empty",
+			s:        " ",
+			width:    charWidth * 4,
+			maxLines: 2,
+			want:     []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, face.wrap(test.s, test.width, test.maxLines))
+		})
+	}
+}
+
+func Test_serviceImpl_WriteBookCover(t *testing.T) {
+	t.Parallel()
+
+	fontFile := filepath.Join(t.TempDir(), "cover.ttf")
+	os.WriteFile(fontFile, goregular.TTF, 0644)
+
+	bk := &model.Book{Site: "test", ID: 1, Title: "斗破苍穹 title", Writer: model.Writer{Name: "天蚕土豆"}}
+
+	tests := []struct {
+		name      string
+		serv      *serviceImpl
+		bk        *model.Book
+		wantError error
+	}{
+		{
+			name: "happy flow/builtin font",
+			serv: &serviceImpl{},
+			bk:   bk,
+		},
+		{
+			name: "happy flow/configured font",
+			serv: &serviceImpl{conf: config.EpubConfig{CoverFont: fontFile}},
+			bk:   bk,
+		},
+		{
+			name: "happy flow/long title",
+			serv: &serviceImpl{},
+			bk:   &model.Book{Site: "test", ID: 1, Title: string(bytes.Repeat([]byte("long title "), 50))},
+		},
+		{
+			name:      "font not exist",
+			serv:      &serviceImpl{conf: config.EpubConfig{CoverFont: "not-exist.ttf"}},
+			bk:        bk,
+			wantError: os.ErrNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := test.serv.WriteBookCover(context.Background(), test.bk, &buf)
+			assert.ErrorIs(t, err, test.wantError)
+			if test.wantError != nil {
+				return
+			}
+
+			img, err := png.Decode(&buf)
+			if assert.NoError(t, err) {
+				assert.Equal(t, image.Rect(0, 0, coverWidth, coverHeight), img.Bounds())
+
+				background, accent := coverColors(test.bk)
+				assert.Equal(t, background, color.RGBAModel.Convert(img.At(0, 0)))
+				assert.Equal(t, accent, color.RGBAModel.Convert(img.At(coverBorder, coverBorder)))
+			}
+		})
+	}
+}
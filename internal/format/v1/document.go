@@ -0,0 +1,64 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+const documentDateLayout = "2006-01-02"
+
+// bookChapter is a chapter in the templates of every format
+type bookChapter struct {
+	Number     int
+	Title      string
+	Paragraphs []string
+}
+
+// bookDocument is the whole book rendered by the single file formats
+type bookDocument struct {
+	Identifier string
+	Title      string
+	Writer     string
+	Type       string
+	Status     string
+	SourceURL  string
+	Date       string
+	Chapters   []bookChapter
+}
+
+func chapterParagraphs(content string) []string {
+	paragraphs := make([]string, 0)
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+
+	return paragraphs
+}
+
+func (serv *serviceImpl) newBookDocument(bk *model.Book, chapters model.Chapters) bookDocument {
+	doc := bookDocument{
+		Identifier: bookIdentifier(bk),
+		Title:      bk.Title,
+		Writer:     bk.Writer.Name,
+		Type:       bk.Type,
+		Status:     bk.Status.String(),
+		Date:       serv.now().UTC().Format(documentDateLayout),
+		Chapters:   make([]bookChapter, 0, len(chapters)),
+	}
+	if serv.bookURL != nil {
+		doc.SourceURL = serv.bookURL(bk)
+	}
+
+	for i, chapter := range chapters {
+		doc.Chapters = append(doc.Chapters, bookChapter{
+			Number:     i + 1,
+			Title:      chapter.Title,
+			Paragraphs: chapterParagraphs(chapter.Content),
+		})
+	}
+
+	return doc
+}
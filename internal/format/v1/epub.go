@@ -3,216 +3,237 @@ package format
 import (
 	"archive/zip"
 	"context"
+	"embed"
+	"errors"
 	"fmt"
 	"io"
-	"strings"
-
-	"html"
+	"os"
+	"text/template"
 
+	"github.com/google/uuid"
 	"github.com/htchan/BookSpider/internal/model"
 )
 
-func writeContainer(zipWriter *zip.Writer) error {
-	containerFile, createErr := zipWriter.Create("META-INF/container.xml")
-	if createErr != nil {
-		return fmt.Errorf("create container file failed: %w", createErr)
+//go:embed templates/epub/*
+var epubFiles embed.FS
+
+var epubTemplates = template.Must(template.ParseFS(epubFiles, "templates/epub/*.xml", "templates/epub/*.opf", "templates/epub/*.xhtml", "templates/epub/*.ncx"))
+
+const (
+	writingModeVertical = "vertical"
+	epubModifiedLayout  = "2006-01-02T15:04:05Z"
+)
+
+type epubBook struct {
+	Identifier string
+	Title      string
+	Writer     string
+	Type       string
+	Status     string
+	SourceURL  string
+	Modified   string
+	Vertical   bool
+	Chapters   []bookChapter
+}
+
+// epubWriter writes the epub container chapter by chapter.
+// package document and navigation are written on Close as they need every chapter title
+type epubWriter struct {
+	zipWriter *zip.Writer
+	book      epubBook
+}
+
+// bookIdentifier returns an identifier stays the same for the same site, id and hash
+func bookIdentifier(bk *model.Book) string {
+	return "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte("bookspider:"+bk.String())).String()
+}
+
+func (serv *serviceImpl) newEpubWriter(bk *model.Book, writer io.Writer) (*epubWriter, error) {
+	w := &epubWriter{
+		zipWriter: zip.NewWriter(writer),
+		book: epubBook{
+			Identifier: bookIdentifier(bk),
+			Title:      bk.Title,
+			Writer:     bk.Writer.Name,
+			Type:       bk.Type,
+			Status:     bk.Status.String(),
+			Modified:   serv.now().UTC().Format(epubModifiedLayout),
+			Vertical:   serv.conf.WritingMode == writingModeVertical,
+		},
+	}
+	if serv.bookURL != nil {
+		w.book.SourceURL = serv.bookURL(bk)
 	}
 
-	_, writeErr := containerFile.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
-	<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
-			<rootfiles>
-					<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml" />
-			</rootfiles>
-	</container>`))
-	if writeErr != nil {
-		return fmt.Errorf("write container file failed: %w", writeErr)
+	stylesheet, err := serv.stylesheet()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	// mimetype must be the first file and stored without compression
+	mimeFile, err := w.zipWriter.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, fmt.Errorf("create mimetype file failed: %w", err)
+	}
+	if _, err := io.WriteString(mimeFile, "application/epub+zip"); err != nil {
+		return nil, fmt.Errorf("write mimetype file failed: %w", err)
+	}
+
+	if err := w.writeTemplate("META-INF/container.xml", "container.xml", nil); err != nil {
+		return nil, err
+	}
+	if err := w.writeFile("OEBPS/styles/book.css", stylesheet); err != nil {
+		return nil, err
+	}
+	if err := w.writeCover(serv, bk); err != nil {
+		return nil, err
+	}
+	if err := w.writeTemplate("OEBPS/cover.xhtml", "cover.xhtml", w.book); err != nil {
+		return nil, err
+	}
+
+	return w, nil
 }
 
-func writeMimeType(zipWriter *zip.Writer) error {
-	mimeFile, createErr := zipWriter.Create("mimetype")
-	if createErr != nil {
-		return fmt.Errorf("create mimetype file failed: %w", createErr)
+func (serv *serviceImpl) stylesheet() ([]byte, error) {
+	if serv.conf.Stylesheet != "" {
+		stylesheet, err := os.ReadFile(serv.conf.Stylesheet)
+		if err != nil {
+			return nil, fmt.Errorf("read stylesheet failed: %w", err)
+		}
+
+		return stylesheet, nil
 	}
 
-	_, writeErr := mimeFile.Write([]byte(`application/epub+zip`))
-	if writeErr != nil {
-		return fmt.Errorf("write mimetype file failed: %w", writeErr)
+	if serv.conf.WritingMode == writingModeVertical {
+		return epubFiles.ReadFile("templates/epub/vertical.css")
 	}
 
-	return nil
+	return epubFiles.ReadFile("templates/epub/horizontal.css")
 }
 
-func writeToc(zipWriter *zip.Writer, bk *model.Book, chapters model.Chapters) error {
-	tocFile, createErr := zipWriter.Create("toc.ncx")
-	if createErr != nil {
-		return fmt.Errorf("create toc file failed: %w", createErr)
-	}
-
-	var chaptersContent strings.Builder
-	for i, chapter := range chapters {
-		chaptersContent.WriteString(fmt.Sprintf(
-			`<navPoint id="num_%d" playOrder="%d">
-			<navLabel><text>%s</text></navLabel>
-			<content src="OEBPS/chapters/chapter-%d.xhtml"/>
-			</navPoint>`,
-			i+1, i+1, chapter.Title, i+1,
-		))
-	}
-
-	_, writeErr := tocFile.Write(fmt.Appendf(nil,
-		`<?xml version="1.0" encoding="UTF-8"?>
-		<ncx version="2005-1" xml:lang="zh" xmlns="http://www.daisy.org/z3986/2005/ncx/">
-			<head>
-				<meta name="dtb:depth" content="2"/> <!-- 1 or higher -->
-				<meta name="dtb:totalPageCount" content="0"/> <!-- must be 0 -->
-				<meta name="dtb:maxPageNumber" content="0"/> <!-- must be 0 -->
-			</head>
-		
-			<docTitle>
-				<text>%s</text>
-			</docTitle>
-		
-			<navMap>
-				%s
-			</navMap>
-		</ncx>`,
-		bk.Title, chaptersContent.String(),
-	))
-	if writeErr != nil {
-		return fmt.Errorf("write toc file failed: %w", writeErr)
+func (w *epubWriter) writeFile(name string, content []byte) error {
+	file, err := w.zipWriter.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s failed: %w", name, err)
+	}
+
+	if _, err := file.Write(content); err != nil {
+		return fmt.Errorf("write %s failed: %w", name, err)
 	}
 
 	return nil
 }
 
-func writeCover(zipWriter *zip.Writer, bk *model.Book) error {
-	coverFile, createErr := zipWriter.Create("OEBPS/cover.xhtml")
-	if createErr != nil {
-		return fmt.Errorf("create cover file failed: %w", createErr)
-	}
-
-	_, writeErr := coverFile.Write(fmt.Appendf(nil,
-		`<?xml version='1.0' encoding='utf-8'?>
-		<html xmlns="http://www.w3.org/1999/xhtml">
-		<body>
-			<center>
-				<h1>%s</h1>
-				<hr/>
-				<h2>%s</h2>
-			</center>
-		</body>
-		</html>`,
-		bk.Title, bk.Writer.Name,
-	))
-	if writeErr != nil {
-		return fmt.Errorf("write cover file failed: %w", writeErr)
+func (w *epubWriter) writeCover(serv *serviceImpl, bk *model.Book) error {
+	name := "OEBPS/images/cover.png"
+	// png is compressed already
+	file, err := w.zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("create %s failed: %w", name, err)
+	}
+
+	if err := serv.WriteBookCover(context.Background(), bk, file); err != nil {
+		return fmt.Errorf("write %s failed: %w", name, err)
 	}
 
 	return nil
 }
 
-func writeContent(zipWriter *zip.Writer, bk *model.Book, chapters model.Chapters) error {
-	contentFile, createErr := zipWriter.Create("OEBPS/content.opf")
-	if createErr != nil {
-		return fmt.Errorf("create content file failed: %w", createErr)
-	}
-	var manifestContent strings.Builder
-	var spineContent strings.Builder
-
-	for i := range chapters {
-		manifestContent.WriteString(fmt.Sprintf(
-			`<item id="chapter-%d" href="chapters/chapter-%d.xhtml" media-type="application/xhtml+xml" />`,
-			i+1, i+1,
-		))
-		spineContent.WriteString(fmt.Sprintf(`<itemref idref="chapter-%d" />`, i+1))
-	}
-
-	_, writeErr := contentFile.Write(fmt.Appendf(nil,
-		`<?xml version="1.0" encoding="UTF-8"?>
-		<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
-				<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
-						<dc:title>%s</dc:title>
-						<dc:creator xmlns:ns0="http://www.idpf.org/2007/opf" ns0:role="aut" ns0:file-as="%s">%s</dc:creator>
-						<dc:language>zh</dc:language>
-				</metadata>
-				<manifest>
-						%s
-						<item id="toc" href="../toc.ncx" media-type="application/x-dtbncx+xml"/>
-						<item id="cover-page" href="cover.xhtml" media-type="application/xhtml+xml" properties="calibre:title-page"/>
-				</manifest>
-				<spine toc="toc">
-				<itemref idref="cover-page"/>
-				%s
-				</spine>
-		</package>`,
-		bk.Title, bk.Writer.Name, bk.Writer.Name, manifestContent.String(), spineContent.String(),
-	))
-	if writeErr != nil {
-		return fmt.Errorf("write content file failed: %w", writeErr)
+func (w *epubWriter) writeTemplate(name, templateName string, data any) error {
+	file, err := w.zipWriter.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s failed: %w", name, err)
+	}
+
+	if err := epubTemplates.ExecuteTemplate(file, templateName, data); err != nil {
+		return fmt.Errorf("write %s failed: %w", name, err)
 	}
 
 	return nil
 }
 
-func reformatChaptersForEpub(lines []string) []string {
-	result := make([]string, 0)
+func (w *epubWriter) WriteChapter(chapter model.Chapter) error {
+	epubChap := bookChapter{
+		Number:     len(w.book.Chapters) + 1,
+		Title:      chapter.Title,
+		Paragraphs: chapterParagraphs(chapter.Content),
+	}
 
-	for _, line := range lines {
-		data := strings.TrimSpace(line)
-		if len(data) > 0 {
-			result = append(result, fmt.Sprintf("<p>%s</p>", html.EscapeString(data)))
-		}
+	name := fmt.Sprintf("OEBPS/chapters/chapter-%d.xhtml", epubChap.Number)
+	if err := w.writeTemplate(name, "chapter.xhtml", epubChap); err != nil {
+		return err
 	}
 
-	return result
+	// keep titles only for navigation
+	epubChap.Paragraphs = nil
+	w.book.Chapters = append(w.book.Chapters, epubChap)
+
+	return nil
 }
 
-func writeChapters(zipWriter *zip.Writer, chapters model.Chapters) error {
-	for i, chapter := range chapters {
-		chapterFile, createErr := zipWriter.Create(fmt.Sprintf("OEBPS/chapters/chapter-%d.xhtml", i+1))
-		if createErr != nil {
-			return fmt.Errorf("create chapter %d file failed: %w", i+1, createErr)
-		}
+func (w *epubWriter) Close() error {
+	if err := w.writeTemplate("OEBPS/nav.xhtml", "nav.xhtml", w.book); err != nil {
+		return err
+	}
+	if err := w.writeTemplate("OEBPS/toc.ncx", "toc.ncx", w.book); err != nil {
+		return err
+	}
+	if err := w.writeTemplate("OEBPS/content.opf", "content.opf", w.book); err != nil {
+		return err
+	}
 
-		content := strings.Split(chapter.Content, "\n")
-		content = reformatChaptersForEpub(content)
-
-		_, writeErr := chapterFile.Write(fmt.Appendf(nil,
-			`<?xml version="1.0" encoding="UTF-8"?>
-			<!DOCTYPE html>
-			<html xmlns="http://www.w3.org/1999/xhtml">
-			<head>
-					<title>%s</title>
-			</head>
-			<body>
-			<h1>%s</h1>
-			%s
-			</body>
-			</html>`,
-			chapter.Title, chapter.Title, strings.Join(content, "\n"),
-		))
-		if writeErr != nil {
-			return fmt.Errorf("write chapter %d failed: %w", i+i, writeErr)
-		}
+	if err := w.zipWriter.Close(); err != nil {
+		return fmt.Errorf("close epub failed: %w", err)
 	}
 
 	return nil
 }
 
 func (serv *serviceImpl) WriteBookEpub(ctx context.Context, bk *model.Book, chapters model.Chapters, writer io.Writer) error {
-	// prepare the zip file
-	zipWriter := zip.NewWriter(writer)
-	defer zipWriter.Close()
+	epubWriter, err := serv.newEpubWriter(bk, writer)
+	if err != nil {
+		return err
+	}
+
+	for _, chapter := range chapters {
+		if err := epubWriter.WriteChapter(chapter); err != nil {
+			return err
+		}
+	}
 
-	writeContainer(zipWriter)
-	writeMimeType(zipWriter)
-	writeToc(zipWriter, bk, chapters)
-	writeCover(zipWriter, bk)
-	writeContent(zipWriter, bk, chapters)
-	writeChapters(zipWriter, chapters)
+	return epubWriter.Close()
+}
 
-	return nil
+// WriteBookEpubFromTxt writes the epub chapter by chapter while reading the
+// book file. only chapter titles are kept to build the navigation at the end
+func (serv *serviceImpl) WriteBookEpubFromTxt(ctx context.Context, bk *model.Book, reader io.Reader, writer io.Writer) error {
+	fileReader, err := model.NewBookFileReader(reader)
+	if err != nil {
+		return fmt.Errorf("write chapters failed: %w", err)
+	}
+
+	epubWriter, err := serv.newEpubWriter(bk, writer)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
+
+		chapter, err := fileReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
+
+		if err := epubWriter.WriteChapter(chapter); err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
+	}
+
+	return epubWriter.Close()
 }
@@ -0,0 +1,25 @@
+package format
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+//go:embed templates/fb2/book.fb2
+var fb2File string
+
+var fb2Template = template.Must(template.New("book.fb2").Parse(fb2File))
+
+// WriteBookFB2 writes the book as a FictionBook 2 document with one section per chapter
+func (serv *serviceImpl) WriteBookFB2(ctx context.Context, bk *model.Book, chapters model.Chapters, writer io.Writer) error {
+	if err := fb2Template.Execute(writer, serv.newBookDocument(bk, chapters)); err != nil {
+		return fmt.Errorf("write fb2 failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fb2Document struct {
+	XMLName  xml.Name `xml:"http://www.gribuser.ru/xml/fictionbook/2.0 FictionBook"`
+	Title    string   `xml:"description>title-info>book-title"`
+	Author   string   `xml:"description>title-info>author>nickname"`
+	ID       string   `xml:"description>document-info>id"`
+	Sections []struct {
+		ID         string   `xml:"id,attr"`
+		Title      string   `xml:"title>p"`
+		Paragraphs []string `xml:"p"`
+	} `xml:"body>section"`
+}
+
+func Test_serviceImpl_WriteBookFB2(t *testing.T) {
+	t.Parallel()
+
+	writeErr := errors.New("write failed")
+
+	tests := []struct {
+		name     string
+		serv     *serviceImpl
+		bk       *model.Book
+		chapters model.Chapters
+		golden   string
+		verify   func(t *testing.T, doc fb2Document)
+	}{
+		{
+			name:     "happy flow",
+			serv:     goldenService(),
+			bk:       goldenBook,
+			chapters: goldenChapters,
+			golden:   "book.fb2",
+			verify: func(t *testing.T, doc fb2Document) {
+				assert.Equal(t, goldenBook.Title, doc.Title)
+				assert.Equal(t, goldenBook.Writer.Name, doc.Author)
+				assert.Equal(t, bookIdentifier(goldenBook), doc.ID)
+				if assert.Len(t, doc.Sections, 3) {
+					assert.Equal(t, "chapter-1", doc.Sections[0].ID)
+					assert.Equal(t, "第一章 陨落的天才", doc.Sections[0].Title)
+					assert.Equal(t, []string{"第一段", "第二段 a < b & c > d"}, doc.Sections[0].Paragraphs)
+					assert.Empty(t, doc.Sections[2].Paragraphs)
+				}
+			},
+		},
+		{
+			name:   "no chapters",
+			serv:   goldenService(),
+			bk:     &model.Book{Site: "test", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}},
+			golden: "no-chapters.fb2",
+			verify: func(t *testing.T, doc fb2Document) {
+				assert.Len(t, doc.Sections, 1)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := test.serv.WriteBookFB2(context.Background(), test.bk, test.chapters, &buf)
+			assert.NoError(t, err)
+
+			assertGolden(t, test.golden, buf.Bytes())
+
+			var doc fb2Document
+			if assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc)) {
+				test.verify(t, doc)
+			}
+		})
+	}
+
+	t.Run("writer failed", func(t *testing.T) {
+		t.Parallel()
+
+		err := goldenService().WriteBookFB2(context.Background(), goldenBook, goldenChapters, failWriter{err: writeErr})
+		assert.ErrorIs(t, err, writeErr)
+	})
+}
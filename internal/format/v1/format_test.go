@@ -3,11 +3,17 @@ package format
 import (
 	"flag"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/goleak"
 )
 
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
 func TestMain(m *testing.M) {
 	leak := flag.Bool("leak", false, "check for memory leaks")
 	flag.Parse()
@@ -18,3 +24,48 @@ func TestMain(m *testing.M) {
 		os.Exit(m.Run())
 	}
 }
+
+// assertGolden compares the content with testdata/golden/<name>,
+// run the tests with -update to regenerate the golden files
+func assertGolden(t *testing.T, name string, content []byte) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", "golden", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatalf("create golden directory failed: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, content, 0644); err != nil {
+			t.Fatalf("update golden file failed: %v", err)
+		}
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file failed: %v", err)
+	}
+
+	assert.Equal(t, string(golden), string(content))
+}
+
+// goldenBook and goldenChapters cover escaping, empty chapter and multiple
+// paragraphs in the golden files of every format
+var (
+	goldenBook = &model.Book{
+		Site: "test", ID: 1, HashCode: 100,
+		Title: "斗破苍穹 <1> & *2*", Writer: model.Writer{Name: "天蚕土豆"},
+		Type: "玄幻", Status: model.StatusEnd,
+	}
+	goldenChapters = model.Chapters{
+		{Title: "第一章 陨落的天才", Content: "\n  第一段\n\n第二段 a < b & c > d\n"},
+		{Title: "第二章 #斗气_大陆", Content: "[link](url) `code` | 1\\2"},
+		{Title: "第三章 空白", Content: ""},
+	}
+)
+
+func goldenService() *serviceImpl {
+	return &serviceImpl{
+		bookURL: func(bk *model.Book) string { return "https://test.com/book/1?a=1&b=2" },
+		now:     func() time.Time { return epubTestTime },
+	}
+}
@@ -0,0 +1,40 @@
+package format
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+//go:embed templates/html/book.html
+var htmlFile string
+
+var htmlTemplate = template.Must(template.New("book.html").Parse(htmlFile))
+
+type htmlDocument struct {
+	bookDocument
+	Stylesheet template.CSS
+}
+
+// WriteBookHTML writes the book as a self contained html page with the
+// stylesheet inlined and a table of contents linking to every chapter
+func (serv *serviceImpl) WriteBookHTML(ctx context.Context, bk *model.Book, chapters model.Chapters, writer io.Writer) error {
+	stylesheet, err := serv.stylesheet()
+	if err != nil {
+		return err
+	}
+
+	doc := htmlDocument{
+		bookDocument: serv.newBookDocument(bk, chapters),
+		Stylesheet:   template.CSS(stylesheet),
+	}
+	if err := htmlTemplate.Execute(writer, doc); err != nil {
+		return fmt.Errorf("write html failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceImpl_WriteBookHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		serv     *serviceImpl
+		bk       *model.Book
+		chapters model.Chapters
+		golden   string
+	}{
+		{
+			name:     "happy flow",
+			serv:     goldenService(),
+			bk:       goldenBook,
+			chapters: goldenChapters,
+			golden:   "book.html",
+		},
+		{
+			name: "vertical",
+			serv: func() *serviceImpl {
+				serv := goldenService()
+				serv.conf = config.EpubConfig{WritingMode: "vertical"}
+				return serv
+			}(),
+			bk:       goldenBook,
+			chapters: goldenChapters[:1],
+			golden:   "vertical.html",
+		},
+		{
+			name:   "no chapters",
+			serv:   goldenService(),
+			bk:     &model.Book{Site: "test", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}},
+			golden: "no-chapters.html",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := test.serv.WriteBookHTML(context.Background(), test.bk, test.chapters, &buf)
+			assert.NoError(t, err)
+
+			assertGolden(t, test.golden, buf.Bytes())
+
+			// every toc link points to a chapter in the same page
+			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(buf.Bytes()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, len(test.chapters), doc.Find("nav li").Length())
+			doc.Find(`a[href^="#"]`).Each(func(_ int, link *goquery.Selection) {
+				href, _ := link.Attr("href")
+				assert.Equal(t, 1, doc.Find(href).Length(), "link target %s not found", href)
+			})
+		})
+	}
+}
+
+func Test_serviceImpl_WriteBookHTML_Error(t *testing.T) {
+	t.Parallel()
+
+	writeErr := errors.New("write failed")
+
+	tests := []struct {
+		name      string
+		serv      *serviceImpl
+		writer    failWriter
+		wantError error
+	}{
+		{
+			name:      "writer failed",
+			serv:      goldenService(),
+			writer:    failWriter{err: writeErr},
+			wantError: writeErr,
+		},
+		{
+			name: "stylesheet not exist",
+			serv: func() *serviceImpl {
+				serv := goldenService()
+				serv.conf = config.EpubConfig{Stylesheet: "not-exist.css"}
+				return serv
+			}(),
+			writer:    failWriter{err: writeErr},
+			wantError: os.ErrNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.serv.WriteBookHTML(context.Background(), goldenBook, goldenChapters, test.writer)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
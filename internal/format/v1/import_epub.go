@@ -0,0 +1,172 @@
+package format
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/htchan/BookSpider/internal/format"
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+type epubContainer struct {
+	RootFiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Metadata struct {
+		Titles   []string `xml:"title"`
+		Creators []string `xml:"creator"`
+		Subjects []string `xml:"subject"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	ItemRefs []struct {
+		IDRef  string `xml:"idref,attr"`
+		Linear string `xml:"linear,attr"`
+	} `xml:"spine>itemref"`
+}
+
+func firstNonEmpty(values []string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func decodeEpubXML(zipReader *zip.Reader, name string, v any) error {
+	file, err := zipReader.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: open %s failed: %w", format.ErrInvalidEpub, name, err)
+	}
+	defer file.Close()
+
+	if err := xml.NewDecoder(file).Decode(v); err != nil {
+		return fmt.Errorf("%w: decode %s failed: %w", format.ErrInvalidEpub, name, err)
+	}
+
+	return nil
+}
+
+// epubChapter reads the title and paragraphs of a content document.
+// ok is false for documents without text, like cover page
+func epubChapter(zipReader *zip.Reader, name string) (model.Chapter, bool, error) {
+	file, err := zipReader.Open(name)
+	if err != nil {
+		return model.Chapter{}, false, fmt.Errorf("%w: open %s failed: %w", format.ErrInvalidEpub, name, err)
+	}
+	defer file.Close()
+
+	doc, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		return model.Chapter{}, false, fmt.Errorf("%w: parse %s failed: %w", format.ErrInvalidEpub, name, err)
+	}
+
+	body := doc.Find("body")
+	heading := body.Find("h1, h2, h3").First()
+	chapter := model.Chapter{Title: strings.TrimSpace(heading.Text())}
+	heading.Remove()
+	body.Find("br").ReplaceWithHtml("\n")
+
+	var paragraphs []string
+	if p := body.Find("p"); p.Length() > 0 {
+		p.Each(func(_ int, s *goquery.Selection) {
+			paragraphs = append(paragraphs, s.Text())
+		})
+	} else {
+		paragraphs = strings.Split(body.Text(), "\n")
+	}
+	chapter.Content = strings.Join(chapterParagraphs(strings.Join(paragraphs, "\n")), "\n")
+	chapter.OptimizeContent()
+
+	if chapter.Title == "" && chapter.Content == "" {
+		return chapter, false, nil
+	}
+	if chapter.Title == "" {
+		chapter.Title = strings.TrimSpace(doc.Find("head title").Text())
+	}
+
+	return chapter, true, nil
+}
+
+// ImportEpub parses the chapters of epub in spine order. navigation and non
+// linear documents are skipped
+func (serv *serviceImpl) ImportEpub(ctx context.Context, reader io.ReaderAt, size int64) (*model.Book, model.Chapters, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", format.ErrInvalidEpub, err)
+	}
+
+	var container epubContainer
+	if err := decodeEpubXML(zipReader, "META-INF/container.xml", &container); err != nil {
+		return nil, nil, err
+	}
+	if len(container.RootFiles) == 0 {
+		return nil, nil, fmt.Errorf("%w: no package document", format.ErrInvalidEpub)
+	}
+
+	opfPath := container.RootFiles[0].FullPath
+	var pkg epubPackage
+	if err := decodeEpubXML(zipReader, opfPath, &pkg); err != nil {
+		return nil, nil, err
+	}
+
+	bk := &model.Book{
+		Title:  firstNonEmpty(pkg.Metadata.Titles),
+		Writer: model.Writer{Name: firstNonEmpty(pkg.Metadata.Creators)},
+		Type:   firstNonEmpty(pkg.Metadata.Subjects),
+	}
+
+	chapters := make(model.Chapters, 0, len(pkg.ItemRefs))
+	for _, itemRef := range pkg.ItemRefs {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, fmt.Errorf("read chapters failed: %w", err)
+		}
+
+		if itemRef.Linear == "no" {
+			continue
+		}
+
+		for _, item := range pkg.Manifest {
+			if item.ID != itemRef.IDRef || strings.Contains(item.Properties, "nav") ||
+				(item.MediaType != "application/xhtml+xml" && item.MediaType != "text/html") {
+				continue
+			}
+
+			href, err := url.PathUnescape(item.Href)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: invalid href %s", format.ErrInvalidEpub, item.Href)
+			}
+
+			chapter, ok, err := epubChapter(zipReader, path.Join(path.Dir(opfPath), href))
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				chapter.Index = len(chapters)
+				chapters = append(chapters, chapter)
+			}
+		}
+	}
+
+	if len(chapters) == 0 {
+		return nil, nil, format.ErrNoChapter
+	}
+
+	return bk, chapters, nil
+}
@@ -0,0 +1,136 @@
+package format
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/format"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildEpub zips the files into an epub, names are the path in zip
+func buildEpub(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+		file.Write([]byte(content))
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("close zip failed: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func Test_serviceImpl_ImportEpub(t *testing.T) {
+	t.Parallel()
+
+	var generated bytes.Buffer
+	if err := goldenService().WriteBookEpub(context.Background(), goldenBook, goldenChapters, &generated); err != nil {
+		t.Fatalf("write epub failed: %v", err)
+	}
+
+	container := `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="content/book.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`
+
+	tests := []struct {
+		name         string
+		content      []byte
+		wantBook     *model.Book
+		wantChapters model.Chapters
+		wantError    error
+	}{
+		{
+			name:     "happy flow/generated epub",
+			content:  generated.Bytes(),
+			wantBook: &model.Book{Title: goldenBook.Title, Writer: goldenBook.Writer, Type: goldenBook.Type},
+			wantChapters: model.Chapters{
+				{Index: 0, Title: "第一章 陨落的天才", Content: "第一段\n\n第二段 a < b & c > d"},
+				{Index: 1, Title: "第二章 #斗气_大陆", Content: "[link](url) `code` | 1\\2"},
+				{Index: 2, Title: "第三章 空白", Content: ""},
+			},
+		},
+		{
+			name: "happy flow/epub 2 without paragraphs",
+			content: buildEpub(t, map[string]string{
+				"META-INF/container.xml": container,
+				"content/book.opf": `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>title</dc:title>
+    <dc:creator>writer</dc:creator>
+  </metadata>
+  <manifest>
+    <item id="toc" href="toc%20page.html" media-type="text/html"/>
+    <item id="c1" href="text/c1.html" media-type="text/html"/>
+    <item id="c2" href="text/c2.html" media-type="application/xhtml+xml"/>
+    <item id="img" href="cover.png" media-type="image/png"/>
+  </manifest>
+  <spine>
+    <itemref idref="toc" linear="no"/>
+    <itemref idref="img"/>
+    <itemref idref="c1"/>
+    <itemref idref="c2"/>
+  </spine>
+</package>`,
+				"content/toc page.html": `<html><body><h1>目录</h1></body></html>`,
+				"content/text/c1.html":  `<html><head><title>第一章</title></head><body><div>line 1<br/>line 2</div></body></html>`,
+				"content/text/c2.html":  `<html><body><h3>第二章</h3><p>line 3</p></body></html>`,
+			}),
+			wantBook: &model.Book{Title: "title", Writer: model.Writer{Name: "writer"}},
+			wantChapters: model.Chapters{
+				{Index: 0, Title: "第一章", Content: "line 1\n\nline 2"},
+				{Index: 1, Title: "第二章", Content: "line 3"},
+			},
+		},
+		{
+			name:      "not zip",
+			content:   []byte("not zip"),
+			wantError: format.ErrInvalidEpub,
+		},
+		{
+			name:      "missing container",
+			content:   buildEpub(t, map[string]string{"mimetype": "application/epub+zip"}),
+			wantError: format.ErrInvalidEpub,
+		},
+		{
+			name: "missing chapter file",
+			content: buildEpub(t, map[string]string{
+				"META-INF/container.xml": container,
+				"content/book.opf": `<package><manifest><item id="c1" href="c1.html" media-type="text/html"/></manifest>
+<spine><itemref idref="c1"/></spine></package>`,
+			}),
+			wantError: format.ErrInvalidEpub,
+		},
+		{
+			name: "no chapter",
+			content: buildEpub(t, map[string]string{
+				"META-INF/container.xml": container,
+				"content/book.opf":       `<package><manifest/><spine/></package>`,
+			}),
+			wantError: format.ErrNoChapter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			bk, chapters, err := (&serviceImpl{}).ImportEpub(context.Background(), bytes.NewReader(test.content), int64(len(test.content)))
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantBook, bk)
+			assert.Equal(t, test.wantChapters, chapters)
+		})
+	}
+}
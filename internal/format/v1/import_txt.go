@@ -0,0 +1,145 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/htchan/BookSpider/internal/format"
+	"github.com/htchan/BookSpider/internal/model"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+const (
+	importTitleMaxLength = 40
+	importPrefaceTitle   = "前言"
+)
+
+var (
+	importChapterTitleRegex = regexp.MustCompile(
+		`^(第[0-9０-９零〇一二两三四五六七八九十百千万]+[章节節回卷集部篇]|序章|序言|楔子|引子|番外|尾声|尾聲|后记|後記|终章|終章|完本感言)([\s:：、.].*)?$`,
+	)
+	importBookTitleRegex  = regexp.MustCompile(`^(?:书名|書名)\s*[:：]\s*(.+)$|^《(.+?)》`)
+	importBookWriterRegex = regexp.MustCompile(`作\s*者\s*[:：]\s*(.+)$`)
+)
+
+// decodeImportTxt returns the text in utf8. files not in utf8 are most
+// likely saved by chinese windows, so they are decoded as gb18030
+func decodeImportTxt(data []byte) (string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("decode txt failed: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
+func isImportChapterTitle(line string) bool {
+	return utf8.RuneCountInString(line) <= importTitleMaxLength && importChapterTitleRegex.MatchString(line)
+}
+
+// removeTocChapters removes the table of contents at the beginning of file,
+// which are chapters without content and with the title used again later
+func removeTocChapters(chapters model.Chapters) model.Chapters {
+	lastIndex := make(map[string]int)
+	for i, chapter := range chapters {
+		lastIndex[chapter.Title] = i
+	}
+
+	result := make(model.Chapters, 0, len(chapters))
+	for i, chapter := range chapters {
+		if chapter.Content == "" && lastIndex[chapter.Title] > i {
+			continue
+		}
+
+		chapter.Index = len(result)
+		result = append(result, chapter)
+	}
+
+	return result
+}
+
+// ImportTxt parses a plain text novel. chapters start with title lines like
+// 第一章, and lines before the first chapter may contain the title and writer
+func (serv *serviceImpl) ImportTxt(ctx context.Context, reader io.Reader) (*model.Book, model.Chapters, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read txt failed: %w", err)
+	}
+
+	content, err := decodeImportTxt(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bk := new(model.Book)
+	var (
+		preface  []string
+		chapters model.Chapters
+		lines    []string
+	)
+	flush := func() {
+		if len(chapters) == 0 {
+			return
+		}
+
+		chapter := &chapters[len(chapters)-1]
+		chapter.Content = strings.Join(lines, "\n")
+		chapter.OptimizeContent()
+		lines = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+
+		if isImportChapterTitle(line) {
+			flush()
+			chapters = append(chapters, model.Chapter{Title: line})
+			continue
+		}
+
+		if len(chapters) > 0 {
+			lines = append(lines, line)
+			continue
+		}
+
+		// title and writer can be in the same line, like 《title》作者：writer
+		titleMatch := importBookTitleRegex.FindStringSubmatch(line)
+		if titleMatch != nil && bk.Title == "" {
+			bk.Title = strings.TrimSpace(titleMatch[1] + titleMatch[2])
+		}
+		writerMatch := importBookWriterRegex.FindStringSubmatch(line)
+		if writerMatch != nil && bk.Writer.Name == "" {
+			bk.Writer.Name = strings.TrimSpace(writerMatch[1])
+		}
+		if titleMatch == nil && writerMatch == nil && line != "" {
+			preface = append(preface, line)
+		}
+	}
+	flush()
+
+	if len(chapters) == 0 {
+		return nil, nil, format.ErrNoChapter
+	}
+
+	chapters = removeTocChapters(chapters)
+	if len(preface) > 0 {
+		prefaceChapter := model.Chapter{Title: importPrefaceTitle, Content: strings.Join(preface, "\n")}
+		prefaceChapter.OptimizeContent()
+		chapters = append(model.Chapters{prefaceChapter}, chapters...)
+		for i := range chapters {
+			chapters[i].Index = i
+		}
+	}
+
+	return bk, chapters, nil
+}
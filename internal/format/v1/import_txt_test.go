@@ -0,0 +1,111 @@
+package format
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/format"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func Test_isImportChapterTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{line: "第一章 陨落的天才", want: true},
+		{line: "第123章", want: true},
+		{line: "第１２章：标题", want: true},
+		{line: "第二卷 斗气大陆", want: true},
+		{line: "楔子", want: true},
+		{line: "番外 一", want: true},
+		{line: "第一章说的是一个很长很长的故事，这一行显然是正文而不是章节的标题，因为它太长了", want: false},
+		{line: "他说第一章很好看", want: false},
+		{line: "第一章节奏很快", want: false},
+		{line: "", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, isImportChapterTitle(test.line))
+		})
+	}
+}
+
+func Test_serviceImpl_ImportTxt(t *testing.T) {
+	t.Parallel()
+
+	gbkContent, _ := simplifiedchinese.GBK.NewEncoder().String("第一章 开始\n内容\n")
+
+	tests := []struct {
+		name         string
+		content      string
+		wantBook     *model.Book
+		wantChapters model.Chapters
+		wantError    error
+	}{
+		{
+			name: "happy flow",
+			content: "\xef\xbb\xbf《斗破苍穹》\r\n作者：天蚕土豆\r\n\r\n" +
+				"第一章 陨落的天才\r\n\r\n　　第一段\r\n第二段\r\n" +
+				"第二章 斗气大陆\r\n第三段\r\n",
+			wantBook: &model.Book{Title: "斗破苍穹", Writer: model.Writer{Name: "天蚕土豆"}},
+			wantChapters: model.Chapters{
+				{Index: 0, Title: "第一章 陨落的天才", Content: "第一段\n\n第二段"},
+				{Index: 1, Title: "第二章 斗气大陆", Content: "第三段"},
+			},
+		},
+		{
+			name:     "title and writer in same line",
+			content:  "《斗破苍穹》作者：天蚕土豆\n第一章 开始\n内容\n",
+			wantBook: &model.Book{Title: "斗破苍穹", Writer: model.Writer{Name: "天蚕土豆"}},
+			wantChapters: model.Chapters{
+				{Index: 0, Title: "第一章 开始", Content: "内容"},
+			},
+		},
+		{
+			name: "preface and table of contents",
+			content: "书名：title\n简介\n" +
+				"第一章 开始\n第二章 结束\n" +
+				"第一章 开始\n内容 1\n第二章 结束\n\n第三章 空白\n",
+			wantBook: &model.Book{Title: "title"},
+			wantChapters: model.Chapters{
+				{Index: 0, Title: "前言", Content: "简介"},
+				{Index: 1, Title: "第一章 开始", Content: "内容 1"},
+				{Index: 2, Title: "第二章 结束", Content: ""},
+				{Index: 3, Title: "第三章 空白", Content: ""},
+			},
+		},
+		{
+			name:     "gbk encoding",
+			content:  gbkContent,
+			wantBook: &model.Book{},
+			wantChapters: model.Chapters{
+				{Index: 0, Title: "第一章 开始", Content: "内容"},
+			},
+		},
+		{
+			name:      "no chapter",
+			content:   "just some text\nwithout chapter\n",
+			wantError: format.ErrNoChapter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			bk, chapters, err := (&serviceImpl{}).ImportTxt(context.Background(), strings.NewReader(test.content))
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantBook, bk)
+			assert.Equal(t, test.wantChapters, chapters)
+		})
+	}
+}
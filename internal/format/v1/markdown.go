@@ -0,0 +1,34 @@
+package format
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+//go:embed templates/markdown/book.md
+var markdownFile string
+
+// markdownEscaper escapes characters which would be read as markdown syntax
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `#`, `\#`,
+	`[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `|`, `\|`,
+)
+
+var markdownTemplate = template.Must(
+	template.New("book.md").Funcs(template.FuncMap{"md": markdownEscaper.Replace}).Parse(markdownFile),
+)
+
+// WriteBookMarkdown writes the book as markdown with one heading per chapter
+func (serv *serviceImpl) WriteBookMarkdown(ctx context.Context, bk *model.Book, chapters model.Chapters, writer io.Writer) error {
+	if err := markdownTemplate.Execute(writer, serv.newBookDocument(bk, chapters)); err != nil {
+		return fmt.Errorf("write markdown failed: %w", err)
+	}
+
+	return nil
+}
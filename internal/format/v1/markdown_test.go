@@ -0,0 +1,81 @@
+package format
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceImpl_WriteBookMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		serv     *serviceImpl
+		bk       *model.Book
+		chapters model.Chapters
+		golden   string
+	}{
+		{
+			name:     "happy flow",
+			serv:     goldenService(),
+			bk:       goldenBook,
+			chapters: goldenChapters,
+			golden:   "book.md",
+		},
+		{
+			name:   "no chapters",
+			serv:   &serviceImpl{now: goldenService().now},
+			bk:     &model.Book{Site: "test", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}},
+			golden: "no-chapters.md",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := test.serv.WriteBookMarkdown(context.Background(), test.bk, test.chapters, &buf)
+			assert.NoError(t, err)
+
+			assertGolden(t, test.golden, buf.Bytes())
+		})
+	}
+
+	t.Run("writer failed", func(t *testing.T) {
+		t.Parallel()
+
+		writeErr := errors.New("write failed")
+		err := goldenService().WriteBookMarkdown(context.Background(), goldenBook, goldenChapters, failWriter{err: writeErr})
+		assert.ErrorIs(t, err, writeErr)
+	})
+}
+
+func Test_markdownEscaper(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain text", s: "斗破苍穹", want: "斗破苍穹"},
+		{name: "heading and emphasis", s: "# *a* _b_", want: `\# \*a\* \_b\_`},
+		{name: "link and code", s: "[a](b) `c`", want: "\\[a\\](b) \\`c\\`"},
+		{name: "html and table", s: "<a> | b", want: `\<a\> \| b`},
+		{name: "backslash", s: `a\b`, want: `a\\b`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, markdownEscaper.Replace(test.s))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/htchan/BookSpider/internal/config/v2"
 	"github.com/htchan/BookSpider/internal/format"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -16,11 +17,13 @@ func TestNewService(t *testing.T) {
 
 	tests := []struct {
 		name string
+		conf config.EpubConfig
 		want format.Service
 	}{
 		{
 			name: "happy flow",
-			want: &serviceImpl{},
+			conf: config.EpubConfig{WritingMode: "vertical"},
+			want: &serviceImpl{conf: config.EpubConfig{WritingMode: "vertical"}},
 		},
 	}
 
@@ -28,7 +31,11 @@ func TestNewService(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			got := NewService()
+			got := NewService(test.conf, nil)
+			if assert.IsType(t, &serviceImpl{}, got) {
+				assert.NotNil(t, got.(*serviceImpl).now)
+				got.(*serviceImpl).now = nil
+			}
 			assert.Equal(t, test.want, got)
 		})
 	}
@@ -65,8 +72,8 @@ content 2
 --------------------
 `),
 			wantChapters: model.Chapters{
-				{Index: 0, Title: "chapter 1", Content: "content 1\n\ncontent 1\n"},
-				{Index: 1, Title: "chapter 2", Content: "content 2\n\ncontent 2\n\n"},
+				{Index: 0, Title: "chapter 1", Content: "content 1\n\ncontent 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2\n\ncontent 2"},
 			},
 			wantError: nil,
 		},
@@ -90,8 +97,8 @@ content 2
 content 2
 `),
 			wantChapters: model.Chapters{
-				{Index: 0, Title: "chapter 1", Content: "content 1\n\ncontent 1\n\n"},
-				{Index: 1, Title: "chapter 2", Content: "content 2\n\ncontent 2\n\n"},
+				{Index: 0, Title: "chapter 1", Content: "content 1\n\ncontent 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2\n\ncontent 2"},
 			},
 			wantError: nil,
 		},
@@ -119,8 +126,8 @@ content 2
 --------------------
 `),
 			wantChapters: model.Chapters{
-				{Index: 0, Title: "chapter 1", Content: "content 1\n"},
-				{Index: 1, Title: "chapter 2", Content: "content 2\n\n"},
+				{Index: 0, Title: "chapter 1", Content: "content 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2"},
 			},
 			wantError: nil,
 		},
@@ -140,8 +147,8 @@ chapter 2
 content 2
 `),
 			wantChapters: model.Chapters{
-				{Index: 0, Title: "chapter 1", Content: "content 1\n\n"},
-				{Index: 1, Title: "chapter 2", Content: "content 2\n\n"},
+				{Index: 0, Title: "chapter 1", Content: "content 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2"},
 			},
 			wantError: nil,
 		},
@@ -162,8 +169,8 @@ chapter 2
 --------------------
 `),
 			wantChapters: model.Chapters{
-				{Index: 0, Title: "chapter 1", Content: "\n"},
-				{Index: 1, Title: "chapter 2", Content: "\n\n"},
+				{Index: 0, Title: "chapter 1", Content: ""},
+				{Index: 1, Title: "chapter 2", Content: ""},
 			},
 			wantError: nil,
 		},
@@ -183,8 +190,8 @@ chapter 2
 
 `),
 			wantChapters: model.Chapters{
-				{Index: 0, Title: "chapter 1", Content: "\n\n"},
-				{Index: 1, Title: "chapter 2", Content: "\n\n"},
+				{Index: 0, Title: "chapter 1", Content: ""},
+				{Index: 1, Title: "chapter 2", Content: ""},
 			},
 			wantError: nil,
 		},
@@ -2,16 +2,55 @@ package format
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
 	"github.com/htchan/BookSpider/internal/model"
 )
 
+// WriteBookTxt writes the book as plain text, the book file format is only
+// for storage and not exposed to readers
 func (serv *serviceImpl) WriteBookTxt(ctx context.Context, bk *model.Book, chapters model.Chapters, writer io.Writer) error {
-	// write title and writer
-	writer.Write([]byte(bk.HeaderInfo()))
+	if _, err := io.WriteString(writer, bk.HeaderInfo()); err != nil {
+		return fmt.Errorf("write header failed: %w", err)
+	}
+
 	for _, chapter := range chapters {
-		writer.Write([]byte(chapter.ContentString()))
+		if _, err := io.WriteString(writer, chapter.ContentString()); err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
 	}
+
 	return nil
 }
+
+// WriteBookTxtFromTxt writes the plain text chapter by chapter while reading
+// the book file
+func (serv *serviceImpl) WriteBookTxtFromTxt(ctx context.Context, bk *model.Book, reader io.Reader, writer io.Writer) error {
+	fileReader, err := model.NewBookFileReader(reader)
+	if err != nil {
+		return fmt.Errorf("write chapters failed: %w", err)
+	}
+
+	if _, err := io.WriteString(writer, bk.HeaderInfo()); err != nil {
+		return fmt.Errorf("write header failed: %w", err)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
+
+		chapter, err := fileReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
+
+		if _, err := io.WriteString(writer, chapter.ContentString()); err != nil {
+			return fmt.Errorf("write chapters failed: %w", err)
+		}
+	}
+}
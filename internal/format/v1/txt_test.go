@@ -3,6 +3,7 @@ package format
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/htchan/BookSpider/internal/model"
@@ -59,3 +60,111 @@ content 2
 		})
 	}
 }
+
+func TestWriteBookTxtFromTxt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		serv        *serviceImpl
+		bk          *model.Book
+		txt         string
+		wantContent string
+		wantError   error
+	}{
+		{
+			name: "book file in current version",
+			serv: &serviceImpl{},
+			bk:   &model.Book{Title: "title", Writer: model.Writer{Name: "writer"}},
+			txt: `BOOKSPIDER/2
+title: "title"
+writer: "writer"
+site: "test"
+hash: "0"
+chapters: 2
+--------------------
+
+[chapter 0 title:9 content:19]
+chapter 1
+content 1
+content 1
+--------------------
+[chapter 1 title:9 content:9]
+chapter 2
+content 2
+--------------------
+`,
+			wantContent: `title
+writer
+--------------------
+
+chapter 1
+--------------------
+content 1
+content 1
+--------------------
+chapter 2
+--------------------
+content 2
+--------------------
+`,
+			wantError: nil,
+		},
+		{
+			name: "legacy book file",
+			serv: &serviceImpl{},
+			bk:   &model.Book{Title: "title", Writer: model.Writer{Name: "writer"}},
+			txt: `title
+writer
+--------------------
+
+chapter 1
+--------------------
+content 1
+--------------------
+`,
+			wantContent: `title
+writer
+--------------------
+
+chapter 1
+--------------------
+content 1
+--------------------
+`,
+			wantError: nil,
+		},
+		{
+			name: "corrupted book file",
+			serv: &serviceImpl{},
+			bk:   &model.Book{Title: "title", Writer: model.Writer{Name: "writer"}},
+			txt: `BOOKSPIDER/2
+title: "title"
+writer: "writer"
+site: "test"
+hash: "0"
+chapters: 1
+--------------------
+
+[chapter 0 title:9 content:100]
+chapter 1
+content 1
+--------------------
+`,
+			wantContent: "title\nwriter\n--------------------\n\n",
+			wantError:   model.ErrBookFileCorrupt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+
+			err := test.serv.WriteBookTxtFromTxt(context.Background(), test.bk, strings.NewReader(test.txt), &buffer)
+			assert.Equal(t, test.wantContent, buffer.String())
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
@@ -85,6 +85,34 @@ func (mr *MockRepositoryMockRecorder) CreateBook(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBook", reflect.TypeOf((*MockRepository)(nil).CreateBook), arg0, arg1)
 }
 
+// CreateBookWithNextID mocks base method.
+func (m *MockRepository) CreateBookWithNextID(arg0 context.Context, arg1 *model.Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBookWithNextID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBookWithNextID indicates an expected call of CreateBookWithNextID.
+func (mr *MockRepositoryMockRecorder) CreateBookWithNextID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBookWithNextID", reflect.TypeOf((*MockRepository)(nil).CreateBookWithNextID), arg0, arg1)
+}
+
+// CreateWork mocks base method.
+func (m *MockRepository) CreateWork(arg0 context.Context, arg1 *model.Work) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWork", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWork indicates an expected call of CreateWork.
+func (mr *MockRepositoryMockRecorder) CreateWork(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWork", reflect.TypeOf((*MockRepository)(nil).CreateWork), arg0, arg1)
+}
+
 // DBStats mocks base method.
 func (m *MockRepository) DBStats(arg0 context.Context) sql.DBStats {
 	m.ctrl.T.Helper()
@@ -189,19 +217,34 @@ func (mr *MockRepositoryMockRecorder) FindBookGroupByIDHash(ctx, site, id, hashC
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookGroupByIDHash", reflect.TypeOf((*MockRepository)(nil).FindBookGroupByIDHash), ctx, site, id, hashCode)
 }
 
+// FindBooksByGenre mocks base method.
+func (m *MockRepository) FindBooksByGenre(ctx context.Context, params repo.BrowseParams, page repo.PageParams) (*repo.BookPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBooksByGenre", ctx, params, page)
+	ret0, _ := ret[0].(*repo.BookPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBooksByGenre indicates an expected call of FindBooksByGenre.
+func (mr *MockRepositoryMockRecorder) FindBooksByGenre(ctx, params, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByGenre", reflect.TypeOf((*MockRepository)(nil).FindBooksByGenre), ctx, params, page)
+}
+
 // FindBooksByRandom mocks base method.
-func (m *MockRepository) FindBooksByRandom(ctx context.Context, limit int) ([]model.Book, error) {
+func (m *MockRepository) FindBooksByRandom(ctx context.Context, genre model.Genre, sort model.BookSort, limit int) ([]model.Book, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindBooksByRandom", ctx, limit)
+	ret := m.ctrl.Call(m, "FindBooksByRandom", ctx, genre, sort, limit)
 	ret0, _ := ret[0].([]model.Book)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindBooksByRandom indicates an expected call of FindBooksByRandom.
-func (mr *MockRepositoryMockRecorder) FindBooksByRandom(ctx, limit any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) FindBooksByRandom(ctx, genre, sort, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByRandom", reflect.TypeOf((*MockRepository)(nil).FindBooksByRandom), ctx, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByRandom", reflect.TypeOf((*MockRepository)(nil).FindBooksByRandom), ctx, genre, sort, limit)
 }
 
 // FindBooksByStatus mocks base method.
@@ -220,18 +263,33 @@ func (mr *MockRepositoryMockRecorder) FindBooksByStatus(ctx, status any) *gomock
 }
 
 // FindBooksByTitleWriter mocks base method.
-func (m *MockRepository) FindBooksByTitleWriter(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error) {
+func (m *MockRepository) FindBooksByTitleWriter(ctx context.Context, title, writer string, genre model.Genre, page repo.PageParams) (*repo.BookPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindBooksByTitleWriter", ctx, title, writer, limit, offset)
-	ret0, _ := ret[0].([]model.Book)
+	ret := m.ctrl.Call(m, "FindBooksByTitleWriter", ctx, title, writer, genre, page)
+	ret0, _ := ret[0].(*repo.BookPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindBooksByTitleWriter indicates an expected call of FindBooksByTitleWriter.
-func (mr *MockRepositoryMockRecorder) FindBooksByTitleWriter(ctx, title, writer, limit, offset any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) FindBooksByTitleWriter(ctx, title, writer, genre, page any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByTitleWriter", reflect.TypeOf((*MockRepository)(nil).FindBooksByTitleWriter), ctx, title, writer, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByTitleWriter", reflect.TypeOf((*MockRepository)(nil).FindBooksByTitleWriter), ctx, title, writer, genre, page)
+}
+
+// FindBooksByWriterID mocks base method.
+func (m *MockRepository) FindBooksByWriterID(ctx context.Context, writerID int, sort model.BookSort) ([]model.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBooksByWriterID", ctx, writerID, sort)
+	ret0, _ := ret[0].([]model.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBooksByWriterID indicates an expected call of FindBooksByWriterID.
+func (mr *MockRepositoryMockRecorder) FindBooksByWriterID(ctx, writerID, sort any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByWriterID", reflect.TypeOf((*MockRepository)(nil).FindBooksByWriterID), ctx, writerID, sort)
 }
 
 // FindBooksForDownload mocks base method.
@@ -264,6 +322,139 @@ func (mr *MockRepositoryMockRecorder) FindBooksForUpdate(ctx, site any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksForUpdate", reflect.TypeOf((*MockRepository)(nil).FindBooksForUpdate), ctx, site)
 }
 
+// FindBooksWithoutChecksum mocks base method.
+func (m *MockRepository) FindBooksWithoutChecksum(ctx context.Context) (<-chan model.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBooksWithoutChecksum", ctx)
+	ret0, _ := ret[0].(<-chan model.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBooksWithoutChecksum indicates an expected call of FindBooksWithoutChecksum.
+func (mr *MockRepositoryMockRecorder) FindBooksWithoutChecksum(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksWithoutChecksum", reflect.TypeOf((*MockRepository)(nil).FindBooksWithoutChecksum), ctx)
+}
+
+// FindBooksWithoutWork mocks base method.
+func (m *MockRepository) FindBooksWithoutWork(ctx context.Context, site string) (<-chan model.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBooksWithoutWork", ctx, site)
+	ret0, _ := ret[0].(<-chan model.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBooksWithoutWork indicates an expected call of FindBooksWithoutWork.
+func (mr *MockRepositoryMockRecorder) FindBooksWithoutWork(ctx, site any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksWithoutWork", reflect.TypeOf((*MockRepository)(nil).FindBooksWithoutWork), ctx, site)
+}
+
+// FindWorkByID mocks base method.
+func (m *MockRepository) FindWorkByID(ctx context.Context, id int) (*model.Work, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWorkByID", ctx, id)
+	ret0, _ := ret[0].(*model.Work)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWorkByID indicates an expected call of FindWorkByID.
+func (mr *MockRepositoryMockRecorder) FindWorkByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWorkByID", reflect.TypeOf((*MockRepository)(nil).FindWorkByID), ctx, id)
+}
+
+// FindWorkCandidates mocks base method.
+func (m *MockRepository) FindWorkCandidates(ctx context.Context, bk *model.Book) ([]model.Work, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWorkCandidates", ctx, bk)
+	ret0, _ := ret[0].([]model.Work)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWorkCandidates indicates an expected call of FindWorkCandidates.
+func (mr *MockRepositoryMockRecorder) FindWorkCandidates(ctx, bk any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWorkCandidates", reflect.TypeOf((*MockRepository)(nil).FindWorkCandidates), ctx, bk)
+}
+
+// FindWriterByID mocks base method.
+func (m *MockRepository) FindWriterByID(ctx context.Context, id int) (*model.Writer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWriterByID", ctx, id)
+	ret0, _ := ret[0].(*model.Writer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWriterByID indicates an expected call of FindWriterByID.
+func (mr *MockRepositoryMockRecorder) FindWriterByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWriterByID", reflect.TypeOf((*MockRepository)(nil).FindWriterByID), ctx, id)
+}
+
+// FindWriters mocks base method.
+func (m *MockRepository) FindWriters(ctx context.Context, name string, page repo.WriterPageParams) (*repo.WriterPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWriters", ctx, name, page)
+	ret0, _ := ret[0].(*repo.WriterPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWriters indicates an expected call of FindWriters.
+func (mr *MockRepositoryMockRecorder) FindWriters(ctx, name, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWriters", reflect.TypeOf((*MockRepository)(nil).FindWriters), ctx, name, page)
+}
+
+// FindWritersWithoutChecksum mocks base method.
+func (m *MockRepository) FindWritersWithoutChecksum(ctx context.Context) (<-chan model.Writer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWritersWithoutChecksum", ctx)
+	ret0, _ := ret[0].(<-chan model.Writer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWritersWithoutChecksum indicates an expected call of FindWritersWithoutChecksum.
+func (mr *MockRepositoryMockRecorder) FindWritersWithoutChecksum(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWritersWithoutChecksum", reflect.TypeOf((*MockRepository)(nil).FindWritersWithoutChecksum), ctx)
+}
+
+// LinkBookToWork mocks base method.
+func (m *MockRepository) LinkBookToWork(ctx context.Context, bk *model.Book, workID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkBookToWork", ctx, bk, workID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkBookToWork indicates an expected call of LinkBookToWork.
+func (mr *MockRepositoryMockRecorder) LinkBookToWork(ctx, bk, workID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkBookToWork", reflect.TypeOf((*MockRepository)(nil).LinkBookToWork), ctx, bk, workID)
+}
+
+// MergeWorks mocks base method.
+func (m *MockRepository) MergeWorks(ctx context.Context, targetID, sourceID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeWorks", ctx, targetID, sourceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeWorks indicates an expected call of MergeWorks.
+func (mr *MockRepositoryMockRecorder) MergeWorks(ctx, targetID, sourceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeWorks", reflect.TypeOf((*MockRepository)(nil).MergeWorks), ctx, targetID, sourceID)
+}
+
 // SaveError mocks base method.
 func (m *MockRepository) SaveError(arg0 context.Context, arg1 *model.Book, arg2 error) error {
 	m.ctrl.T.Helper()
@@ -278,6 +469,20 @@ func (mr *MockRepositoryMockRecorder) SaveError(arg0, arg1, arg2 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveError", reflect.TypeOf((*MockRepository)(nil).SaveError), arg0, arg1, arg2)
 }
 
+// SaveSiteHealth mocks base method.
+func (m *MockRepository) SaveSiteHealth(arg0 context.Context, arg1 *model.SiteHealth) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSiteHealth", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSiteHealth indicates an expected call of SaveSiteHealth.
+func (mr *MockRepositoryMockRecorder) SaveSiteHealth(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSiteHealth", reflect.TypeOf((*MockRepository)(nil).SaveSiteHealth), arg0, arg1)
+}
+
 // SaveWriter mocks base method.
 func (m *MockRepository) SaveWriter(arg0 context.Context, arg1 *model.Writer) error {
 	m.ctrl.T.Helper()
@@ -306,6 +511,21 @@ func (mr *MockRepositoryMockRecorder) Stats(ctx, site any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockRepository)(nil).Stats), ctx, site)
 }
 
+// SyncGenres mocks base method.
+func (m *MockRepository) SyncGenres(ctx context.Context, site string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncGenres", ctx, site)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncGenres indicates an expected call of SyncGenres.
+func (mr *MockRepositoryMockRecorder) SyncGenres(ctx, site any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncGenres", reflect.TypeOf((*MockRepository)(nil).SyncGenres), ctx, site)
+}
+
 // UpdateBook mocks base method.
 func (m *MockRepository) UpdateBook(arg0 context.Context, arg1 *model.Book) error {
 	m.ctrl.T.Helper()
@@ -320,6 +540,20 @@ func (mr *MockRepositoryMockRecorder) UpdateBook(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBook", reflect.TypeOf((*MockRepository)(nil).UpdateBook), arg0, arg1)
 }
 
+// UpdateBookChecksum mocks base method.
+func (m *MockRepository) UpdateBookChecksum(arg0 context.Context, arg1 *model.Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBookChecksum", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBookChecksum indicates an expected call of UpdateBookChecksum.
+func (mr *MockRepositoryMockRecorder) UpdateBookChecksum(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookChecksum", reflect.TypeOf((*MockRepository)(nil).UpdateBookChecksum), arg0, arg1)
+}
+
 // UpdateBooksStatus mocks base method.
 func (m *MockRepository) UpdateBooksStatus(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -333,3 +567,17 @@ func (mr *MockRepositoryMockRecorder) UpdateBooksStatus(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBooksStatus", reflect.TypeOf((*MockRepository)(nil).UpdateBooksStatus), arg0)
 }
+
+// UpdateWriterChecksum mocks base method.
+func (m *MockRepository) UpdateWriterChecksum(arg0 context.Context, arg1 *model.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWriterChecksum", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWriterChecksum indicates an expected call of UpdateWriterChecksum.
+func (mr *MockRepositoryMockRecorder) UpdateWriterChecksum(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWriterChecksum", reflect.TypeOf((*MockRepository)(nil).UpdateWriterChecksum), arg0, arg1)
+}
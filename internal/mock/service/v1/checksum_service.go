@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/htchan/BookSpider/internal/service (interfaces: ChecksumService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mock/service/v1/checksum_service.go -package=mockservice . ChecksumService
+//
+
+// Package mockservice is a generated GoMock package.
+package mockservice
+
+import (
+	context "context"
+	reflect "reflect"
+
+	service "github.com/htchan/BookSpider/internal/service"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockChecksumService is a mock of ChecksumService interface.
+type MockChecksumService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChecksumServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockChecksumServiceMockRecorder is the mock recorder for MockChecksumService.
+type MockChecksumServiceMockRecorder struct {
+	mock *MockChecksumService
+}
+
+// NewMockChecksumService creates a new mock instance.
+func NewMockChecksumService(ctrl *gomock.Controller) *MockChecksumService {
+	mock := &MockChecksumService{ctrl: ctrl}
+	mock.recorder = &MockChecksumServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChecksumService) EXPECT() *MockChecksumServiceMockRecorder {
+	return m.recorder
+}
+
+// RebuildChecksums mocks base method.
+func (m *MockChecksumService) RebuildChecksums(ctx context.Context, dryRun bool, stats *service.ChecksumStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildChecksums", ctx, dryRun, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildChecksums indicates an expected call of RebuildChecksums.
+func (mr *MockChecksumServiceMockRecorder) RebuildChecksums(ctx, dryRun, stats any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildChecksums", reflect.TypeOf((*MockChecksumService)(nil).RebuildChecksums), ctx, dryRun, stats)
+}
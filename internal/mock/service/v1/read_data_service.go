@@ -12,6 +12,7 @@ package mockservice
 import (
 	context "context"
 	sql "database/sql"
+	os "os"
 	reflect "reflect"
 
 	model "github.com/htchan/BookSpider/internal/model"
@@ -73,19 +74,19 @@ func (mr *MockReadDataServiceMockRecorder) BookChapters(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookChapters", reflect.TypeOf((*MockReadDataService)(nil).BookChapters), arg0, arg1)
 }
 
-// BookContent mocks base method.
-func (m *MockReadDataService) BookContent(arg0 context.Context, arg1 *model.Book) (string, error) {
+// BookFile mocks base method.
+func (m *MockReadDataService) BookFile(arg0 context.Context, arg1 *model.Book) (*os.File, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BookContent", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "BookFile", arg0, arg1)
+	ret0, _ := ret[0].(*os.File)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BookContent indicates an expected call of BookContent.
-func (mr *MockReadDataServiceMockRecorder) BookContent(arg0, arg1 any) *gomock.Call {
+// BookFile indicates an expected call of BookFile.
+func (mr *MockReadDataServiceMockRecorder) BookFile(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookContent", reflect.TypeOf((*MockReadDataService)(nil).BookContent), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookFile", reflect.TypeOf((*MockReadDataService)(nil).BookFile), arg0, arg1)
 }
 
 // BookGroup mocks base method.
@@ -104,6 +105,21 @@ func (mr *MockReadDataServiceMockRecorder) BookGroup(ctx, site, id, hash any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookGroup", reflect.TypeOf((*MockReadDataService)(nil).BookGroup), ctx, site, id, hash)
 }
 
+// BrowseBooks mocks base method.
+func (m *MockReadDataService) BrowseBooks(arg0 context.Context, arg1 repo.BrowseParams, arg2 repo.PageParams) (*repo.BookPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BrowseBooks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*repo.BookPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BrowseBooks indicates an expected call of BrowseBooks.
+func (mr *MockReadDataServiceMockRecorder) BrowseBooks(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BrowseBooks", reflect.TypeOf((*MockReadDataService)(nil).BrowseBooks), arg0, arg1, arg2)
+}
+
 // DBStats mocks base method.
 func (m *MockReadDataService) DBStats(arg0 context.Context) sql.DBStats {
 	m.ctrl.T.Helper()
@@ -118,34 +134,78 @@ func (mr *MockReadDataServiceMockRecorder) DBStats(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DBStats", reflect.TypeOf((*MockReadDataService)(nil).DBStats), arg0)
 }
 
+// MergeWorks mocks base method.
+func (m *MockReadDataService) MergeWorks(ctx context.Context, targetID, sourceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeWorks", ctx, targetID, sourceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeWorks indicates an expected call of MergeWorks.
+func (mr *MockReadDataServiceMockRecorder) MergeWorks(ctx, targetID, sourceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeWorks", reflect.TypeOf((*MockReadDataService)(nil).MergeWorks), ctx, targetID, sourceID)
+}
+
 // RandomBooks mocks base method.
-func (m *MockReadDataService) RandomBooks(ctx context.Context, limit int) ([]model.Book, error) {
+func (m *MockReadDataService) RandomBooks(ctx context.Context, genre model.Genre, sort model.BookSort, limit int) ([]model.Book, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RandomBooks", ctx, limit)
+	ret := m.ctrl.Call(m, "RandomBooks", ctx, genre, sort, limit)
 	ret0, _ := ret[0].([]model.Book)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RandomBooks indicates an expected call of RandomBooks.
-func (mr *MockReadDataServiceMockRecorder) RandomBooks(ctx, limit any) *gomock.Call {
+func (mr *MockReadDataServiceMockRecorder) RandomBooks(ctx, genre, sort, limit any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RandomBooks", reflect.TypeOf((*MockReadDataService)(nil).RandomBooks), ctx, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RandomBooks", reflect.TypeOf((*MockReadDataService)(nil).RandomBooks), ctx, genre, sort, limit)
 }
 
 // SearchBooks mocks base method.
-func (m *MockReadDataService) SearchBooks(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error) {
+func (m *MockReadDataService) SearchBooks(ctx context.Context, title, writer string, genre model.Genre, page repo.PageParams) (*repo.BookPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchBooks", ctx, title, writer, limit, offset)
-	ret0, _ := ret[0].([]model.Book)
+	ret := m.ctrl.Call(m, "SearchBooks", ctx, title, writer, genre, page)
+	ret0, _ := ret[0].(*repo.BookPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SearchBooks indicates an expected call of SearchBooks.
-func (mr *MockReadDataServiceMockRecorder) SearchBooks(ctx, title, writer, limit, offset any) *gomock.Call {
+func (mr *MockReadDataServiceMockRecorder) SearchBooks(ctx, title, writer, genre, page any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBooks", reflect.TypeOf((*MockReadDataService)(nil).SearchBooks), ctx, title, writer, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBooks", reflect.TypeOf((*MockReadDataService)(nil).SearchBooks), ctx, title, writer, genre, page)
+}
+
+// SearchWriters mocks base method.
+func (m *MockReadDataService) SearchWriters(ctx context.Context, name string, page repo.WriterPageParams) (*repo.WriterPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchWriters", ctx, name, page)
+	ret0, _ := ret[0].(*repo.WriterPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchWriters indicates an expected call of SearchWriters.
+func (mr *MockReadDataServiceMockRecorder) SearchWriters(ctx, name, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchWriters", reflect.TypeOf((*MockReadDataService)(nil).SearchWriters), ctx, name, page)
+}
+
+// SplitBookWork mocks base method.
+func (m *MockReadDataService) SplitBookWork(arg0 context.Context, arg1 *model.Book) (*model.Work, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SplitBookWork", arg0, arg1)
+	ret0, _ := ret[0].(*model.Work)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SplitBookWork indicates an expected call of SplitBookWork.
+func (mr *MockReadDataServiceMockRecorder) SplitBookWork(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SplitBookWork", reflect.TypeOf((*MockReadDataService)(nil).SplitBookWork), arg0, arg1)
 }
 
 // Stats mocks base method.
@@ -161,3 +221,63 @@ func (mr *MockReadDataServiceMockRecorder) Stats(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockReadDataService)(nil).Stats), arg0, arg1)
 }
+
+// TopWriters mocks base method.
+func (m *MockReadDataService) TopWriters(ctx context.Context, page repo.WriterPageParams) (*repo.WriterPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopWriters", ctx, page)
+	ret0, _ := ret[0].(*repo.WriterPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopWriters indicates an expected call of TopWriters.
+func (mr *MockReadDataServiceMockRecorder) TopWriters(ctx, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopWriters", reflect.TypeOf((*MockReadDataService)(nil).TopWriters), ctx, page)
+}
+
+// Work mocks base method.
+func (m *MockReadDataService) Work(ctx context.Context, workID string) (*model.Work, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Work", ctx, workID)
+	ret0, _ := ret[0].(*model.Work)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Work indicates an expected call of Work.
+func (mr *MockReadDataServiceMockRecorder) Work(ctx, workID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Work", reflect.TypeOf((*MockReadDataService)(nil).Work), ctx, workID)
+}
+
+// WorkSource mocks base method.
+func (m *MockReadDataService) WorkSource(arg0 context.Context, arg1 *model.Work) (*model.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkSource", arg0, arg1)
+	ret0, _ := ret[0].(*model.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkSource indicates an expected call of WorkSource.
+func (mr *MockReadDataServiceMockRecorder) WorkSource(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkSource", reflect.TypeOf((*MockReadDataService)(nil).WorkSource), arg0, arg1)
+}
+
+// Writer mocks base method.
+func (m *MockReadDataService) Writer(ctx context.Context, writerID string, sort model.BookSort) (*model.WriterCatalog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Writer", ctx, writerID, sort)
+	ret0, _ := ret[0].(*model.WriterCatalog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Writer indicates an expected call of Writer.
+func (mr *MockReadDataServiceMockRecorder) Writer(ctx, writerID, sort any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Writer", reflect.TypeOf((*MockReadDataService)(nil).Writer), ctx, writerID, sort)
+}
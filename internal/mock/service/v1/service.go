@@ -42,6 +42,20 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// Backup mocks base method.
+func (m *MockService) Backup(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backup", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Backup indicates an expected call of Backup.
+func (mr *MockServiceMockRecorder) Backup(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backup", reflect.TypeOf((*MockService)(nil).Backup), arg0)
+}
+
 // BookInfo mocks base method.
 func (m *MockService) BookInfo(arg0 context.Context, arg1 *model.Book) string {
 	m.ctrl.T.Helper()
@@ -56,6 +70,35 @@ func (mr *MockServiceMockRecorder) BookInfo(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookInfo", reflect.TypeOf((*MockService)(nil).BookInfo), arg0, arg1)
 }
 
+// BookURL mocks base method.
+func (m *MockService) BookURL(arg0 *model.Book) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookURL", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// BookURL indicates an expected call of BookURL.
+func (mr *MockServiceMockRecorder) BookURL(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookURL", reflect.TypeOf((*MockService)(nil).BookURL), arg0)
+}
+
+// ChapterList mocks base method.
+func (m *MockService) ChapterList(arg0 context.Context, arg1 *model.Book) (model.Chapters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChapterList", arg0, arg1)
+	ret0, _ := ret[0].(model.Chapters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChapterList indicates an expected call of ChapterList.
+func (mr *MockServiceMockRecorder) ChapterList(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChapterList", reflect.TypeOf((*MockService)(nil).ChapterList), arg0, arg1)
+}
+
 // CheckAvailability mocks base method.
 func (m *MockService) CheckAvailability(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -70,6 +113,20 @@ func (mr *MockServiceMockRecorder) CheckAvailability(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAvailability", reflect.TypeOf((*MockService)(nil).CheckAvailability), arg0)
 }
 
+// CheckSelectors mocks base method.
+func (m *MockService) CheckSelectors(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSelectors", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSelectors indicates an expected call of CheckSelectors.
+func (mr *MockServiceMockRecorder) CheckSelectors(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSelectors", reflect.TypeOf((*MockService)(nil).CheckSelectors), arg0)
+}
+
 // Download mocks base method.
 func (m *MockService) Download(arg0 context.Context, arg1 *service.DownloadStats) error {
 	m.ctrl.T.Helper()
@@ -98,6 +155,20 @@ func (mr *MockServiceMockRecorder) DownloadBook(arg0, arg1, arg2 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadBook", reflect.TypeOf((*MockService)(nil).DownloadBook), arg0, arg1, arg2)
 }
 
+// DownloadChapter mocks base method.
+func (m *MockService) DownloadChapter(arg0 context.Context, arg1 *model.Chapter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadChapter", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadChapter indicates an expected call of DownloadChapter.
+func (mr *MockServiceMockRecorder) DownloadChapter(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadChapter", reflect.TypeOf((*MockService)(nil).DownloadChapter), arg0, arg1)
+}
+
 // Explore mocks base method.
 func (m *MockService) Explore(arg0 context.Context, arg1 *service.UpdateStats) error {
 	m.ctrl.T.Helper()
@@ -126,6 +197,49 @@ func (mr *MockServiceMockRecorder) ExploreBook(arg0, arg1, arg2 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExploreBook", reflect.TypeOf((*MockService)(nil).ExploreBook), arg0, arg1, arg2)
 }
 
+// LinkBookWork mocks base method.
+func (m *MockService) LinkBookWork(arg0 context.Context, arg1 *model.Book, arg2 *service.LinkWorkStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkBookWork", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkBookWork indicates an expected call of LinkBookWork.
+func (mr *MockServiceMockRecorder) LinkBookWork(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkBookWork", reflect.TypeOf((*MockService)(nil).LinkBookWork), arg0, arg1, arg2)
+}
+
+// LinkWorks mocks base method.
+func (m *MockService) LinkWorks(arg0 context.Context, arg1 *service.LinkWorkStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkWorks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkWorks indicates an expected call of LinkWorks.
+func (mr *MockServiceMockRecorder) LinkWorks(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkWorks", reflect.TypeOf((*MockService)(nil).LinkWorks), arg0, arg1)
+}
+
+// MissingBookIDs mocks base method.
+func (m *MockService) MissingBookIDs(arg0 context.Context) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MissingBookIDs", arg0)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MissingBookIDs indicates an expected call of MissingBookIDs.
+func (mr *MockServiceMockRecorder) MissingBookIDs(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MissingBookIDs", reflect.TypeOf((*MockService)(nil).MissingBookIDs), arg0)
+}
+
 // Name mocks base method.
 func (m *MockService) Name() string {
 	m.ctrl.T.Helper()
@@ -196,6 +310,61 @@ func (mr *MockServiceMockRecorder) ProcessBook(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBook", reflect.TypeOf((*MockService)(nil).ProcessBook), arg0, arg1)
 }
 
+// RegisterSources mocks base method.
+func (m *MockService) RegisterSources(arg0 map[string]service.Service) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterSources", arg0)
+}
+
+// RegisterSources indicates an expected call of RegisterSources.
+func (mr *MockServiceMockRecorder) RegisterSources(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterSources", reflect.TypeOf((*MockService)(nil).RegisterSources), arg0)
+}
+
+// Reparse mocks base method.
+func (m *MockService) Reparse(arg0 context.Context, arg1 *service.ReparseStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reparse", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reparse indicates an expected call of Reparse.
+func (mr *MockServiceMockRecorder) Reparse(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reparse", reflect.TypeOf((*MockService)(nil).Reparse), arg0, arg1)
+}
+
+// ReparseBook mocks base method.
+func (m *MockService) ReparseBook(arg0 context.Context, arg1 *model.Book, arg2 *service.ReparseStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReparseBook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReparseBook indicates an expected call of ReparseBook.
+func (mr *MockServiceMockRecorder) ReparseBook(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReparseBook", reflect.TypeOf((*MockService)(nil).ReparseBook), arg0, arg1, arg2)
+}
+
+// SyncGenres mocks base method.
+func (m *MockService) SyncGenres(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncGenres", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncGenres indicates an expected call of SyncGenres.
+func (mr *MockServiceMockRecorder) SyncGenres(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncGenres", reflect.TypeOf((*MockService)(nil).SyncGenres), arg0)
+}
+
 // Update mocks base method.
 func (m *MockService) Update(arg0 context.Context, arg1 *service.UpdateStats) error {
 	m.ctrl.T.Helper()
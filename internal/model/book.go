@@ -13,6 +13,9 @@ import (
 	"github.com/siongui/gojianfan"
 )
 
+// LocalSite is the pseudo site of books imported from files
+const LocalSite = "local"
+
 type Book struct {
 	Site          string
 	ID            int
@@ -23,6 +26,7 @@ type Book struct {
 	UpdateChapter string
 	Status        StatusCode
 	IsDownloaded  bool
+	WorkID        int
 
 	Writer Writer
 	Error  error
@@ -42,6 +46,7 @@ func GenerateHash() int {
 	return int(time.Now().Unix())
 }
 
+// HeaderInfo returns the header in legacy book file format
 func (bk *Book) HeaderInfo() string {
 	return bk.Title + "\n" + bk.Writer.Name + "\n" + CONTENT_SEP + "\n\n"
 }
@@ -62,13 +67,14 @@ func (bk Book) MarshalJSON() ([]byte, error) {
 		UpdateChapter string `json:"update_chapter"`
 		Status        string `json:"status"`
 		IsDownloaded  bool   `json:"is_downloaded"`
+		WorkID        int    `json:"work_id,omitempty"`
 		Error         string `json:"error"`
 	}{
 		Site: bk.Site, ID: bk.ID, HashCode: bk.FormatHashCode(),
 		Title: bk.Title, Writer: bk.Writer.Name, Type: bk.Type,
 		UpdateDate: bk.UpdateDate, UpdateChapter: bk.UpdateChapter,
 		Status: bk.Status.String(), IsDownloaded: bk.IsDownloaded,
-		Error: errString,
+		WorkID: bk.WorkID, Error: errString,
 	})
 }
 
@@ -0,0 +1,417 @@
+package model
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// book file is the on disk format of downloaded book.
+//
+// version 2 starts with a header of quoted values, then every chapter is
+// framed by a line with its index and the byte length of title and content,
+// so title and content can contain any text including CONTENT_SEP:
+//
+//	BOOKSPIDER/2
+//	title: "title"
+//	writer: "writer"
+//	site: "site"
+//	hash: "hash"
+//	chapters: 1
+//	--------------------
+//
+//	[chapter 0 title:15 content:7]
+//	chapter title 1
+//	content
+//	--------------------
+//
+// the lengths are kept when the file is converted to other script, as the
+// conversion skips characters which cannot be converted to one with the same
+// byte length (see Script.Convert).
+//
+// version 1 is the legacy format without framing and is read only.
+const (
+	BookFileVersion = 2
+
+	bookFileMagic       = "BOOKSPIDER/"
+	bookFileFrameFormat = "[chapter %d title:%d content:%d]"
+)
+
+var (
+	ErrBookFileVersion  = errors.New("unsupported book file version")
+	ErrBookFileCorrupt  = errors.New("book file corrupted")
+	ErrBookFileComplete = errors.New("book file has all chapters written")
+)
+
+// BookFileHeader is the book info saved with chapters. legacy files only
+// have title and writer, and chapter count is -1 as it is unknown
+type BookFileHeader struct {
+	Version      int
+	Title        string
+	Writer       string
+	Site         string
+	HashCode     string
+	ChapterCount int
+}
+
+// BookFileWriter writes the book file chapter by chapter
+type BookFileWriter struct {
+	writer       io.Writer
+	chapterCount int
+	written      int
+}
+
+func NewBookFileWriter(writer io.Writer, bk *Book, chapterCount int) (*BookFileWriter, error) {
+	header := fmt.Sprintf(
+		"%s%d\ntitle: %q\nwriter: %q\nsite: %q\nhash: %q\nchapters: %d\n%s\n\n",
+		bookFileMagic, BookFileVersion, bk.Title, bk.Writer.Name, bk.Site, bk.FormatHashCode(), chapterCount, CONTENT_SEP,
+	)
+	if _, err := io.WriteString(writer, header); err != nil {
+		return nil, fmt.Errorf("write book file header failed: %w", err)
+	}
+
+	return &BookFileWriter{writer: writer, chapterCount: chapterCount}, nil
+}
+
+func (w *BookFileWriter) WriteChapter(chapter Chapter) error {
+	if w.written >= w.chapterCount {
+		return ErrBookFileComplete
+	}
+
+	frame := fmt.Sprintf(bookFileFrameFormat, chapter.Index, len(chapter.Title), len(chapter.Content))
+	content := frame + "\n" + chapter.Title + "\n" + chapter.Content + "\n" + CONTENT_SEP + "\n"
+	if _, err := io.WriteString(w.writer, content); err != nil {
+		return fmt.Errorf("write chapter %d failed: %w", chapter.Index, err)
+	}
+	w.written++
+
+	return nil
+}
+
+// Close checks every chapter in header is written
+func (w *BookFileWriter) Close() error {
+	if w.written != w.chapterCount {
+		return fmt.Errorf("%w: %d of %d chapters written", ErrBookFileCorrupt, w.written, w.chapterCount)
+	}
+
+	return nil
+}
+
+func WriteBookFile(writer io.Writer, bk *Book, chapters Chapters) error {
+	fileWriter, err := NewBookFileWriter(writer, bk, len(chapters))
+	if err != nil {
+		return err
+	}
+
+	for _, chapter := range chapters {
+		if err := fileWriter.WriteChapter(chapter); err != nil {
+			return err
+		}
+	}
+
+	return fileWriter.Close()
+}
+
+// BookFileReader reads chapters of book file in any version one by one
+type BookFileReader struct {
+	reader *bufio.Reader
+	header BookFileHeader
+	read   int
+	legacy *legacyChapterScanner
+}
+
+func NewBookFileReader(reader io.Reader) (*BookFileReader, error) {
+	r := &BookFileReader{reader: bufio.NewReader(reader)}
+
+	magic, err := r.reader.Peek(len(bookFileMagic))
+	if err != nil || string(magic) != bookFileMagic {
+		r.header, r.legacy = newLegacyChapterScanner(r.reader)
+		return r, nil
+	}
+
+	if err := r.readHeader(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *BookFileReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("%w: %w", ErrBookFileCorrupt, io.ErrUnexpectedEOF)
+	} else if err != nil {
+		return "", fmt.Errorf("read book file failed: %w", err)
+	}
+
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// readHeaderValue reads a header line in format `<key>: <value>`
+func (r *BookFileReader) readHeaderValue(key string) (string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := strings.CutPrefix(line, key+": ")
+	if !ok {
+		return "", fmt.Errorf("%w: missing header %s", ErrBookFileCorrupt, key)
+	}
+
+	return value, nil
+}
+
+func (r *BookFileReader) readHeader() error {
+	versionLine, err := r.readLine()
+	if err != nil {
+		return err
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(versionLine, bookFileMagic))
+	if err != nil || version != BookFileVersion {
+		return fmt.Errorf("%w: %s", ErrBookFileVersion, versionLine)
+	}
+	r.header.Version = version
+
+	for _, field := range []struct {
+		key   string
+		value *string
+	}{
+		{key: "title", value: &r.header.Title},
+		{key: "writer", value: &r.header.Writer},
+		{key: "site", value: &r.header.Site},
+		{key: "hash", value: &r.header.HashCode},
+	} {
+		quoted, err := r.readHeaderValue(field.key)
+		if err != nil {
+			return err
+		}
+
+		*field.value, err = strconv.Unquote(quoted)
+		if err != nil {
+			return fmt.Errorf("%w: invalid header %s", ErrBookFileCorrupt, field.key)
+		}
+	}
+
+	count, err := r.readHeaderValue("chapters")
+	if err != nil {
+		return err
+	}
+	r.header.ChapterCount, err = strconv.Atoi(count)
+	if err != nil || r.header.ChapterCount < 0 {
+		return fmt.Errorf("%w: invalid chapter count %s", ErrBookFileCorrupt, count)
+	}
+
+	for _, want := range []string{CONTENT_SEP, ""} {
+		if line, err := r.readLine(); err != nil {
+			return err
+		} else if line != want {
+			return fmt.Errorf("%w: invalid header end", ErrBookFileCorrupt)
+		}
+	}
+
+	return nil
+}
+
+func (r *BookFileReader) Header() BookFileHeader {
+	return r.header
+}
+
+// readBytes reads n bytes followed by the suffix. the content is copied
+// instead of allocated by n, so corrupted length can't exhaust the memory
+func (r *BookFileReader) readBytes(n int, suffix string) (string, error) {
+	var buf strings.Builder
+	if _, err := io.CopyN(&buf, r.reader, int64(n)+int64(len(suffix))); errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("%w: %w", ErrBookFileCorrupt, io.ErrUnexpectedEOF)
+	} else if err != nil {
+		return "", fmt.Errorf("read book file failed: %w", err)
+	}
+
+	content := buf.String()
+	if content[n:] != suffix {
+		return "", fmt.Errorf("%w: invalid chapter frame", ErrBookFileCorrupt)
+	}
+
+	return content[:n], nil
+}
+
+// Next returns the next chapter, or io.EOF after the last chapter
+func (r *BookFileReader) Next() (Chapter, error) {
+	if r.legacy != nil {
+		return r.legacy.next()
+	}
+
+	if r.read >= r.header.ChapterCount {
+		if _, err := r.reader.ReadByte(); !errors.Is(err, io.EOF) {
+			return Chapter{}, fmt.Errorf("%w: content after last chapter", ErrBookFileCorrupt)
+		}
+
+		return Chapter{}, io.EOF
+	}
+
+	frame, err := r.readLine()
+	if err != nil {
+		return Chapter{}, err
+	}
+
+	var (
+		chapter                    Chapter
+		titleLength, contentLength int
+	)
+	_, err = fmt.Sscanf(frame, bookFileFrameFormat, &chapter.Index, &titleLength, &contentLength)
+	if err != nil || titleLength < 0 || contentLength < 0 || fmt.Sprintf(bookFileFrameFormat, chapter.Index, titleLength, contentLength) != frame {
+		return Chapter{}, fmt.Errorf("%w: invalid chapter frame %q", ErrBookFileCorrupt, frame)
+	}
+
+	if chapter.Title, err = r.readBytes(titleLength, "\n"); err != nil {
+		return Chapter{}, err
+	}
+	if chapter.Content, err = r.readBytes(contentLength, "\n"+CONTENT_SEP+"\n"); err != nil {
+		return Chapter{}, err
+	}
+	r.read++
+
+	return chapter, nil
+}
+
+// ReadBookFile reads the whole book file
+func ReadBookFile(reader io.Reader) (BookFileHeader, Chapters, error) {
+	fileReader, err := NewBookFileReader(reader)
+	if err != nil {
+		return BookFileHeader{}, nil, err
+	}
+
+	chapters := make(Chapters, 0)
+	for {
+		chapter, err := fileReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fileReader.Header(), nil, err
+		}
+
+		chapters = append(chapters, chapter)
+	}
+
+	return fileReader.Header(), chapters, nil
+}
+
+// legacyChapterScanner reads the legacy book file. chapters are in format
+// "title\n<sep>\ncontent\n<sep>\n", or "title\n<sep>\ncontent\n\n" in older
+// files, so the title is guessed by looking at lines around the separator
+type legacyChapterScanner struct {
+	reader  *bufio.Reader
+	prev    string
+	lines   []string
+	eof     bool
+	err     error
+	chapter *Chapter
+	count   int
+}
+
+func newLegacyChapterScanner(reader *bufio.Reader) (BookFileHeader, *legacyChapterScanner) {
+	s := &legacyChapterScanner{reader: reader}
+
+	header := BookFileHeader{Version: 1, ChapterCount: -1}
+	header.Title, _ = s.peek(0)
+	header.Writer, _ = s.peek(1)
+
+	// skip title, writer and separator
+	for range 3 {
+		s.advance()
+	}
+
+	return header, s
+}
+
+func (s *legacyChapterScanner) fill(n int) {
+	for len(s.lines) < n && !s.eof {
+		line, err := s.reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			s.eof = true
+		} else if err != nil {
+			s.eof, s.err = true, err
+			return
+		}
+
+		s.lines = append(s.lines, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+// peek returns the k-th line after current line, current line is 0
+func (s *legacyChapterScanner) peek(k int) (string, bool) {
+	s.fill(k + 1)
+	if k >= len(s.lines) {
+		return "", false
+	}
+
+	return s.lines[k], true
+}
+
+func (s *legacyChapterScanner) advance() {
+	s.fill(1)
+	if len(s.lines) > 0 {
+		s.prev = s.lines[0]
+		s.lines = s.lines[1:]
+	}
+}
+
+func legacyChapter(chapter *Chapter) Chapter {
+	result := *chapter
+	result.Content = strings.TrimRight(result.Content, "\n")
+
+	return result
+}
+
+func (s *legacyChapterScanner) next() (Chapter, error) {
+	for {
+		line, ok := s.peek(0)
+		if !ok {
+			break
+		}
+
+		nextLine, _ := s.peek(1)
+		_, hasLines := s.peek(3)
+
+		var completed *Chapter
+		if line == CONTENT_SEP {
+			if nextLine == CONTENT_SEP && s.chapter != nil {
+				s.chapter.Content += "\n"
+			}
+		} else if hasLines && nextLine == CONTENT_SEP {
+			thirdLine, _ := s.peek(3)
+			if s.chapter != nil && s.chapter.Content == "" {
+				s.chapter.Content += line + "\n"
+			} else if s.chapter != nil && thirdLine == CONTENT_SEP && s.prev != CONTENT_SEP {
+				s.chapter.Content += line + "\n"
+			} else {
+				completed = s.chapter
+				s.chapter = &Chapter{Title: line, Index: s.count}
+				s.count++
+			}
+		} else if s.chapter != nil {
+			s.chapter.Content += line + "\n"
+		}
+
+		s.advance()
+		if completed != nil {
+			return legacyChapter(completed), nil
+		}
+	}
+
+	if s.err != nil {
+		return Chapter{}, fmt.Errorf("read book file failed: %w", s.err)
+	}
+
+	if s.chapter != nil {
+		completed := s.chapter
+		s.chapter = nil
+		return legacyChapter(completed), nil
+	}
+
+	return Chapter{}, io.EOF
+}
@@ -0,0 +1,300 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBookFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		bk          *Book
+		chapters    Chapters
+		wantContent string
+	}{
+		{
+			name: "happy flow",
+			bk:   &Book{Site: "test", ID: 1, HashCode: 36, Title: "title", Writer: Writer{Name: "writer"}},
+			chapters: Chapters{
+				{Index: 0, Title: "chapter 1", Content: "content 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2\n" + CONTENT_SEP + "\ncontent 2"},
+			},
+			wantContent: `BOOKSPIDER/2
+title: "title"
+writer: "writer"
+site: "test"
+hash: "10"
+chapters: 2
+--------------------
+
+[chapter 0 title:9 content:9]
+chapter 1
+content 1
+--------------------
+[chapter 1 title:9 content:40]
+chapter 2
+content 2
+--------------------
+content 2
+--------------------
+`,
+		},
+		{
+			name: "happy flow/quote header",
+			bk:   &Book{Site: "test", Title: "title \"1\"\n", Writer: Writer{Name: "writer"}},
+			wantContent: `BOOKSPIDER/2
+title: "title \"1\"\n"
+writer: "writer"
+site: "test"
+hash: "0"
+chapters: 0
+--------------------
+
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := WriteBookFile(&buf, test.bk, test.chapters)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantContent, buf.String())
+		})
+	}
+}
+
+func TestBookFileWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		chapterCount int
+		chapters     Chapters
+		wantWriteErr error
+		wantCloseErr error
+	}{
+		{
+			name:         "happy flow",
+			chapterCount: 1,
+			chapters:     Chapters{{Title: "chapter 1"}},
+		},
+		{
+			name:         "more chapters than header",
+			chapterCount: 1,
+			chapters:     Chapters{{Title: "chapter 1"}, {Title: "chapter 2"}},
+			wantWriteErr: ErrBookFileComplete,
+		},
+		{
+			name:         "less chapters than header",
+			chapterCount: 2,
+			chapters:     Chapters{{Title: "chapter 1"}},
+			wantCloseErr: ErrBookFileCorrupt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			writer, err := NewBookFileWriter(io.Discard, &Book{}, test.chapterCount)
+			assert.NoError(t, err)
+
+			var writeErr error
+			for _, chapter := range test.chapters {
+				if writeErr = writer.WriteChapter(chapter); writeErr != nil {
+					break
+				}
+			}
+			assert.ErrorIs(t, writeErr, test.wantWriteErr)
+			if test.wantWriteErr == nil {
+				assert.ErrorIs(t, writer.Close(), test.wantCloseErr)
+			}
+		})
+	}
+}
+
+func TestReadBookFile(t *testing.T) {
+	t.Parallel()
+
+	validFile := `BOOKSPIDER/2
+title: "title"
+writer: "writer"
+site: "test"
+hash: "10"
+chapters: 2
+--------------------
+
+[chapter 0 title:9 content:9]
+chapter 1
+content 1
+--------------------
+[chapter 1 title:9 content:40]
+chapter 2
+content 2
+--------------------
+content 2
+--------------------
+`
+
+	tests := []struct {
+		name         string
+		content      string
+		wantHeader   BookFileHeader
+		wantChapters Chapters
+		wantError    error
+	}{
+		{
+			name:    "happy flow/version 2",
+			content: validFile,
+			wantHeader: BookFileHeader{
+				Version: 2, Title: "title", Writer: "writer", Site: "test", HashCode: "10", ChapterCount: 2,
+			},
+			wantChapters: Chapters{
+				{Index: 0, Title: "chapter 1", Content: "content 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2\n" + CONTENT_SEP + "\ncontent 2"},
+			},
+		},
+		{
+			name: "happy flow/legacy",
+			content: `title
+writer
+--------------------
+
+chapter 1
+--------------------
+content 1
+--------------------
+chapter 2
+--------------------
+content 2
+--------------------
+`,
+			wantHeader: BookFileHeader{Version: 1, Title: "title", Writer: "writer", ChapterCount: -1},
+			wantChapters: Chapters{
+				{Index: 0, Title: "chapter 1", Content: "content 1"},
+				{Index: 1, Title: "chapter 2", Content: "content 2"},
+			},
+		},
+		{
+			name:         "happy flow/empty file",
+			content:      "",
+			wantHeader:   BookFileHeader{Version: 1, ChapterCount: -1},
+			wantChapters: Chapters{},
+		},
+		{
+			name:      "unsupported version",
+			content:   "BOOKSPIDER/3\n" + strings.SplitN(validFile, "\n", 2)[1],
+			wantError: ErrBookFileVersion,
+		},
+		{
+			name:      "invalid header",
+			content:   strings.Replace(validFile, `title: "title"`, `title: title`, 1),
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "invalid chapter count",
+			content:   strings.Replace(validFile, "chapters: 2", "chapters: -1", 1),
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "truncated file",
+			content:   validFile[:len(validFile)-30],
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "missing chapter",
+			content:   strings.Replace(validFile, "chapters: 2", "chapters: 3", 1),
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "content after last chapter",
+			content:   strings.Replace(validFile, "chapters: 2", "chapters: 1", 1),
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "wrong content length",
+			content:   strings.Replace(validFile, "content:9]", "content:8]", 1),
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "huge content length",
+			content:   strings.Replace(validFile, "content:9]", "content:9999999999]", 1),
+			wantError: ErrBookFileCorrupt,
+		},
+		{
+			name:      "non canonical frame",
+			content:   strings.Replace(validFile, "content:9]", "content:09]", 1),
+			wantError: ErrBookFileCorrupt,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			header, chapters, err := ReadBookFile(strings.NewReader(test.content))
+			assert.ErrorIs(t, err, test.wantError)
+			if test.wantError == nil {
+				assert.Equal(t, test.wantHeader, header)
+				assert.Equal(t, test.wantChapters, chapters)
+			}
+		})
+	}
+}
+
+func FuzzBookFileRoundTrip(f *testing.F) {
+	f.Add("title", "writer", "chapter 1", "content 1", "chapter 2", "content 2")
+	f.Add("", "", "", "", "", "")
+	f.Add("title\n"+CONTENT_SEP, "\"writer\"", CONTENT_SEP, "\n"+CONTENT_SEP+"\n", "[chapter 1 title:0 content:0]", "\n\n")
+
+	f.Fuzz(func(t *testing.T, title, writer, title1, content1, title2, content2 string) {
+		bk := &Book{Site: "test", Title: title, Writer: Writer{Name: writer}}
+		chapters := Chapters{
+			{Index: 0, Title: title1, Content: content1},
+			{Index: 1, Title: title2, Content: content2},
+		}
+
+		var buf bytes.Buffer
+		if err := WriteBookFile(&buf, bk, chapters); err != nil {
+			t.Fatalf("write book file failed: %v", err)
+		}
+
+		header, result, err := ReadBookFile(&buf)
+		if err != nil {
+			t.Fatalf("read book file failed: %v", err)
+		}
+
+		assert.Equal(t, BookFileHeader{
+			Version: BookFileVersion, Title: title, Writer: writer, Site: "test", HashCode: "0", ChapterCount: 2,
+		}, header)
+		assert.Equal(t, chapters, result)
+	})
+}
+
+func FuzzReadBookFile(f *testing.F) {
+	var buf bytes.Buffer
+	WriteBookFile(&buf, &Book{Title: "title"}, Chapters{{Title: "chapter 1", Content: "content 1"}})
+	f.Add(buf.Bytes())
+	f.Add([]byte("title\nwriter\n" + CONTENT_SEP + "\n\nchapter 1\n" + CONTENT_SEP + "\ncontent 1\n"))
+	f.Add([]byte(""))
+
+	f.Fuzz(func(t *testing.T, content []byte) {
+		_, chapters, err := ReadBookFile(bytes.NewReader(content))
+		if err != nil && !errors.Is(err, ErrBookFileCorrupt) && !errors.Is(err, ErrBookFileVersion) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err != nil && chapters != nil {
+			t.Errorf("chapters returned with error: %v", err)
+		}
+	})
+}
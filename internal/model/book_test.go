@@ -84,6 +84,17 @@ func TestBook_MarshalJSON(t *testing.T) {
 			expect:    `{"site":"test","id":1,"hash_code":"0","title":"title","writer":"writer","type":"type","update_date":"date","update_chapter":"chapter","status":"INPROGRESS","is_downloaded":true,"error":"error"}`,
 			expectErr: false,
 		},
+		{
+			name: "works with work id",
+			bk: Book{
+				Site: "test", ID: 1, HashCode: 0,
+				Title: "title", Writer: Writer{ID: 1, Name: "writer"}, Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter",
+				Status: StatusEnd, IsDownloaded: false, WorkID: 5,
+			},
+			expect:    `{"site":"test","id":1,"hash_code":"0","title":"title","writer":"writer","type":"type","update_date":"date","update_chapter":"chapter","status":"END","is_downloaded":false,"work_id":5,"error":""}`,
+			expectErr: false,
+		},
 	}
 
 	for _, test := range tests {
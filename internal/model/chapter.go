@@ -1,8 +1,9 @@
 package model
 
 import (
-	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -17,16 +18,14 @@ var ChapterEndKeywords = []string{
 	"外传", "结尾",
 }
 
-var (
-	ErrCannotParseContent = errors.New("cannot parse content")
-)
-
 type Chapter struct {
 	Index   int
 	URL     string
 	Title   string
 	Content string
 	Error   error
+	// Source is the book the content is downloaded from, empty if it is the book itself
+	Source string
 }
 
 type Chapters []Chapter
@@ -44,10 +43,62 @@ func (c *Chapter) OptimizeContent() {
 	c.Content = strings.Join(lines, "\n\n")
 }
 
+// ContentString returns the chapter in legacy book file format
 func (c *Chapter) ContentString() string {
 	return fmt.Sprintf("%s\n%s\n%s\n%s\n", c.Title, CONTENT_SEP, c.Content, CONTENT_SEP)
 }
 
+var chapterNumberRegex = regexp.MustCompile(`第([0-9零〇一二两三四五六七八九十百千万]+)([章节回卷集])`)
+
+var chineseDigits = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+var chineseUnits = map[rune]int{'十': 10, '百': 100, '千': 1000, '万': 10000}
+
+// chineseNumberToInt converts numbers like "一百二十三" or "123" to int
+func chineseNumberToInt(s string) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+
+	total, section, digit := 0, 0, 0
+	for _, r := range s {
+		if d, ok := chineseDigits[r]; ok {
+			digit = d
+			continue
+		}
+
+		unit := chineseUnits[r]
+		if unit == 10000 {
+			total += (section + digit) * unit
+			section, digit = 0, 0
+			continue
+		}
+
+		if digit == 0 {
+			digit = 1
+		}
+		section += digit * unit
+		digit = 0
+	}
+
+	return total + section + digit
+}
+
+// NormalizeChapterTitle returns a key to align chapters from different sources.
+// it ignores script, width and punctuation, and unifies chapter numbers like
+// "第一百章" and "第100章"
+func NormalizeChapterTitle(title string) string {
+	key := normalizeKey(title)
+
+	return chapterNumberRegex.ReplaceAllStringFunc(key, func(match string) string {
+		groups := chapterNumberRegex.FindStringSubmatch(match)
+		return fmt.Sprintf("第%d%s", chineseNumberToInt(groups[1]), groups[2])
+	})
+}
+
 func removeEmptyLines(lines []string) []string {
 	result := make([]string, 0)
 
@@ -60,43 +111,3 @@ func removeEmptyLines(lines []string) []string {
 
 	return result
 }
-
-func StringToChapters(content string) (Chapters, error) {
-	splitedContent := strings.Split(content, CONTENT_SEP)
-	if len(splitedContent) < 3 {
-		return nil, ErrCannotParseContent
-	}
-	chapters := make(Chapters, 0)
-	chapter := Chapter{
-		Index:   0,
-		Title:   strings.Join(removeEmptyLines(strings.Split(splitedContent[1], "\n")), "\n\n"),
-		Content: strings.Join(removeEmptyLines(strings.Split(splitedContent[2], "\n")), "\n\n"),
-	}
-	chapter.OptimizeContent()
-	chapters = append(chapters, chapter)
-	chapter = Chapter{}
-	index := 1
-
-	for i, con := range splitedContent[3:] {
-		lines := removeEmptyLines(strings.Split(con, "\n"))
-		if i == len(splitedContent)-4 && len(lines) == 0 {
-			continue
-		}
-		if len(lines) == 1 && chapter.Title == "" {
-			chapter.Title = lines[0]
-		} else {
-			if chapter.Title == "" {
-				lastChapterLines := strings.Split(chapters[len(chapters)-1].Content, "\n")
-				chapter.Title = lastChapterLines[len(lastChapterLines)-1]
-				chapters[len(chapters)-1].Content = strings.Join(lastChapterLines[:len(lastChapterLines)-2], "\n")
-			}
-			chapter.Content = strings.Join(lines, "\n\n")
-			chapter.Index = index
-			index++
-			chapters = append(chapters, chapter)
-			chapter = Chapter{}
-		}
-	}
-
-	return chapters, nil
-}
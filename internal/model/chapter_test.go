@@ -118,88 +118,51 @@ func Test_removeEmptyLines(t *testing.T) {
 	}
 }
 
-func Test_StringToContent(t *testing.T) {
+func Test_chineseNumberToInt(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name      string
-		content   string
-		expect    Chapters
-		expectErr bool
+		name   string
+		s      string
+		expect int
 	}{
-		{
-			name: "works for new version",
-			content: `
-			title
-			writer
-			--------------------
-			
-			chapter title 1
-			--------------------
-			content1
-			
-			content2
-			--------------------
-			chapter title 2
-			--------------------
-			content3
-			--------------------
-			chapter title 3
-			--------------------
-			content4
-			
-			content5
-			content6
-			--------------------
-			`,
-			expect: Chapters{
-				Chapter{Index: 0, Title: "chapter title 1", Content: "content1\n\ncontent2"},
-				Chapter{Index: 1, Title: "chapter title 2", Content: "content3"},
-				Chapter{Index: 2, Title: "chapter title 3", Content: "content4\n\ncontent5\n\ncontent6"},
-			},
-			expectErr: false,
-		},
-		{
-			name: "works for old version",
-			content: `
-			title
-			writer
-			--------------------
-			
-			chapter title 1
-			--------------------
-			content1
-			
-			content2
-
-			chapter title 2
-			--------------------
-			content3
-			
-			chapter title 3
-			--------------------
-			content4
-			
-			content5
-			content6`,
-			expect: Chapters{
-				Chapter{Index: 0, Title: "chapter title 1", Content: "content1\n\ncontent2"},
-				Chapter{Index: 1, Title: "chapter title 2", Content: "content3"},
-				Chapter{Index: 2, Title: "chapter title 3", Content: "content4\n\ncontent5\n\ncontent6"},
-			},
-			expectErr: false,
-		},
+		{name: "arabic number", s: "123", expect: 123},
+		{name: "single digit", s: "九", expect: 9},
+		{name: "ten", s: "十", expect: 10},
+		{name: "tens", s: "二十一", expect: 21},
+		{name: "hundreds with zero", s: "一百零五", expect: 105},
+		{name: "thousands", s: "一千二百三十四", expect: 1234},
+		{name: "ten thousands", s: "一万零一", expect: 10001},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			result, err := StringToChapters(test.content)
-			if (err != nil) != test.expectErr {
-				t.Errorf("got error: %v, expect error: %v", err, test.expectErr)
-			}
-			assert.Equal(t, result, test.expect)
+			assert.Equal(t, test.expect, chineseNumberToInt(test.s))
+		})
+	}
+}
+
+func Test_NormalizeChapterTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		a, b   string
+		expect bool
+	}{
+		{name: "chinese and arabic chapter number", a: "第一百二十章 决战", b: "第120章 決戰", expect: true},
+		{name: "punctuation and width", a: "第3章：重逢！", b: "第三章 重逢", expect: true},
+		{name: "different chapter number", a: "第3章 重逢", b: "第4章 重逢", expect: false},
+		{name: "bracketed parts are kept", a: "第3章 重逢(上)", b: "第3章 重逢(下)", expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, NormalizeChapterTitle(test.a) == NormalizeChapterTitle(test.b))
 		})
 	}
 }
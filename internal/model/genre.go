@@ -0,0 +1,128 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+// Genre is the canonical category of book, vendor types like `都市小说` and
+// `都市言情` are normalized into it
+type Genre string
+
+const (
+	GenreFantasy  Genre = "fantasy"
+	GenreXianxia  Genre = "xianxia"
+	GenreWuxia    Genre = "wuxia"
+	GenreUrban    Genre = "urban"
+	GenreRomance  Genre = "romance"
+	GenreHistory  Genre = "history"
+	GenreMilitary Genre = "military"
+	GenreGame     Genre = "game"
+	GenreScifi    Genre = "scifi"
+	GenreHorror   Genre = "horror"
+	GenreFanfic   Genre = "fanfic"
+	GenreOther    Genre = "other"
+)
+
+// Genres lists every genre in the display order
+var Genres = []Genre{
+	GenreFantasy, GenreXianxia, GenreWuxia, GenreUrban, GenreRomance, GenreHistory,
+	GenreMilitary, GenreGame, GenreScifi, GenreHorror, GenreFanfic, GenreOther,
+}
+
+var genreNames = map[Genre]string{
+	GenreFantasy:  "玄幻奇幻",
+	GenreXianxia:  "仙侠修真",
+	GenreWuxia:    "武侠",
+	GenreUrban:    "都市",
+	GenreRomance:  "言情",
+	GenreHistory:  "历史",
+	GenreMilitary: "军事",
+	GenreGame:     "游戏竞技",
+	GenreScifi:    "科幻",
+	GenreHorror:   "灵异悬疑",
+	GenreFanfic:   "同人",
+	GenreOther:    "其他",
+}
+
+// genreKeywords are matched in order against the simplified vendor type, so
+// the more specific keyword goes first, e.g. `都市言情` is romance
+var genreKeywords = []struct {
+	keyword string
+	genre   Genre
+}{
+	{"言情", GenreRomance},
+	{"女生", GenreRomance},
+	{"女频", GenreRomance},
+	{"穿越", GenreRomance},
+	{"同人", GenreFanfic},
+	{"二次元", GenreFanfic},
+	{"耽美", GenreFanfic},
+	{"网游", GenreGame},
+	{"游戏", GenreGame},
+	{"竞技", GenreGame},
+	{"体育", GenreGame},
+	{"科幻", GenreScifi},
+	{"未来", GenreScifi},
+	{"末世", GenreScifi},
+	{"灵异", GenreHorror},
+	{"悬疑", GenreHorror},
+	{"恐怖", GenreHorror},
+	{"推理", GenreHorror},
+	{"侦探", GenreHorror},
+	{"仙侠", GenreXianxia},
+	{"修真", GenreXianxia},
+	{"修仙", GenreXianxia},
+	{"武侠", GenreWuxia},
+	{"玄幻", GenreFantasy},
+	{"奇幻", GenreFantasy},
+	{"魔法", GenreFantasy},
+	{"异界", GenreFantasy},
+	{"军事", GenreMilitary},
+	{"战争", GenreMilitary},
+	{"历史", GenreHistory},
+	{"架空", GenreHistory},
+	{"都市", GenreUrban},
+	{"现代", GenreUrban},
+	{"职场", GenreUrban},
+	{"官场", GenreUrban},
+}
+
+var ErrInvalidGenre = errors.New("invalid genre")
+
+// ParseGenre parses the genre slug, empty string is returned for empty slug
+// so that it can be used as no filter
+func ParseGenre(s string) (Genre, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	if _, ok := genreNames[Genre(s)]; !ok {
+		return "", ErrInvalidGenre
+	}
+
+	return Genre(s), nil
+}
+
+// Name returns the display name of genre
+func (genre Genre) Name() string {
+	return genreNames[genre]
+}
+
+// NormalizeGenre maps the raw type of vendor to genre by keywords. the result
+// is stored per site and type in database, so a wrong mapping can be
+// corrected there without changing the rules
+func NormalizeGenre(bookType string) Genre {
+	bookType = simplified(strings.ReplaceAll(bookType, " ", ""))
+	if bookType == "" {
+		return GenreOther
+	}
+
+	for _, rule := range genreKeywords {
+		if strings.Contains(bookType, rule.keyword) {
+			return rule.genre
+		}
+	}
+
+	return GenreOther
+}
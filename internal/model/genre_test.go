@@ -0,0 +1,69 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseGenre(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		s         string
+		expect    Genre
+		expectErr error
+	}{
+		{name: "empty string is no filter", s: "", expect: ""},
+		{name: "valid genre", s: "xianxia", expect: GenreXianxia},
+		{name: "other genre", s: "other", expect: GenreOther},
+		{name: "invalid genre", s: "unknown", expectErr: ErrInvalidGenre},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ParseGenre(test.s)
+			assert.Equal(t, test.expect, result)
+			assert.ErrorIs(t, err, test.expectErr)
+		})
+	}
+}
+
+func Test_NormalizeGenre(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		bookType string
+		expect   Genre
+	}{
+		{name: "urban", bookType: "都市小说", expect: GenreUrban},
+		{name: "romance goes before urban", bookType: "都市言情", expect: GenreRomance},
+		{name: "fantasy", bookType: "玄幻魔法", expect: GenreFantasy},
+		{name: "traditional type", bookType: "仙俠", expect: GenreXianxia},
+		{name: "game", bookType: "网游竞技小说", expect: GenreGame},
+		{name: "type with space", bookType: "武 侠", expect: GenreWuxia},
+		{name: "unknown type", bookType: "其它小说", expect: GenreOther},
+		{name: "empty type", bookType: "", expect: GenreOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, NormalizeGenre(test.bookType))
+		})
+	}
+}
+
+func Test_Genres(t *testing.T) {
+	t.Parallel()
+
+	for _, genre := range Genres {
+		assert.NotEmpty(t, genre.Name(), genre)
+	}
+	assert.Len(t, Genres, len(genreNames))
+}
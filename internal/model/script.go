@@ -0,0 +1,228 @@
+package model
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/siongui/gojianfan"
+)
+
+type Script string
+
+const (
+	ScriptOriginal    Script = "original"
+	ScriptSimplified  Script = "s"
+	ScriptTraditional Script = "t"
+)
+
+var ErrInvalidScript = errors.New("invalid script")
+
+var (
+	traditionalToSimplified = make(map[rune]rune)
+	simplifiedToTraditional = make(map[rune]rune)
+)
+
+func init() {
+	for index, traditionalRune := range gojianfan.ChT {
+		simplifiedRune, _ := utf8.DecodeRuneInString(gojianfan.ChS[index:])
+		traditionalToSimplified[traditionalRune] = simplifiedRune
+		simplifiedToTraditional[simplifiedRune] = traditionalRune
+	}
+}
+
+func ParseScript(s string) (Script, error) {
+	switch Script(s) {
+	case "", ScriptOriginal:
+		return ScriptOriginal, nil
+	case ScriptSimplified, ScriptTraditional:
+		return Script(s), nil
+	default:
+		return "", ErrInvalidScript
+	}
+}
+
+func (script Script) mapping() map[rune]rune {
+	switch script {
+	case ScriptSimplified:
+		return traditionalToSimplified
+	case ScriptTraditional:
+		return simplifiedToTraditional
+	default:
+		return nil
+	}
+}
+
+// Convert returns s in the script. the conversion is character based and
+// never changes the byte length, so it is safe to convert a long content
+// chunk by chunk or at any offset. a character is kept unconverted if its
+// converted character has another byte length, no such character is in the
+// current mapping
+func (script Script) Convert(s string) string {
+	mapping := script.mapping()
+	if mapping == nil {
+		return s
+	}
+
+	return string(convertBytes([]byte(s), mapping))
+}
+
+// convertBytes converts b in place. invalid utf8 bytes are kept as is
+func convertBytes(b []byte, mapping map[rune]rune) []byte {
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if converted, ok := mapping[r]; ok && utf8.RuneLen(converted) == size {
+			utf8.EncodeRune(b[i:], converted)
+		}
+		i += size
+	}
+
+	return b
+}
+
+// ScriptVariants returns the distinct original, simplified and traditional
+// forms of s, so a query matches content in any script
+func ScriptVariants(s string) []string {
+	variants := []string{s}
+	for _, variant := range []string{ScriptSimplified.Convert(s), ScriptTraditional.Convert(s)} {
+		if !slices.Contains(variants, variant) {
+			variants = append(variants, variant)
+		}
+	}
+
+	return variants
+}
+
+type scriptWriter struct {
+	writer  io.Writer
+	script  Script
+	pending []byte
+}
+
+// NewScriptWriter returns a writer converting everything written to the script.
+// incomplete utf8 characters are kept until the next write or Close
+func NewScriptWriter(writer io.Writer, script Script) io.WriteCloser {
+	return &scriptWriter{writer: writer, script: script}
+}
+
+func (w *scriptWriter) Write(p []byte) (int, error) {
+	if w.script == ScriptOriginal || w.script == "" {
+		return w.writer.Write(p)
+	}
+
+	data := append(w.pending, p...)
+
+	// find the end of the last complete character
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+
+	w.pending = append([]byte(nil), data[end:]...)
+
+	_, err := io.WriteString(w.writer, w.script.Convert(string(data[:end])))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *scriptWriter) Close() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	_, err := w.writer.Write(w.pending)
+	w.pending = nil
+
+	return err
+}
+
+type scriptReader struct {
+	reader  io.ReaderAt
+	size    int64
+	offset  int64
+	mapping map[rune]rune
+}
+
+// NewScriptReader returns a seekable reader of the content in the script.
+// as conversion keeps the byte length, every offset of the result maps to
+// the same offset of the original content
+func NewScriptReader(reader io.ReaderAt, size int64, script Script) io.ReadSeeker {
+	mapping := script.mapping()
+	if mapping == nil {
+		return io.NewSectionReader(reader, 0, size)
+	}
+
+	return &scriptReader{reader: reader, size: size, mapping: mapping}
+}
+
+func (r *scriptReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// read extra bytes around the range to convert complete characters only
+	start := max(r.offset-utf8.UTFMax+1, 0)
+	end := min(r.offset+int64(len(p))+utf8.UTFMax-1, r.size)
+	buf := make([]byte, end-start)
+	n, err := r.reader.ReadAt(buf, start)
+	if n < len(buf) {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	head := int(r.offset - start)
+	for head > 0 && !utf8.RuneStart(buf[head]) {
+		head--
+	}
+
+	tail := len(buf)
+	if end < r.size {
+		for i := len(buf) - 1; i >= 0 && i >= len(buf)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(buf[i]) {
+				if !utf8.FullRune(buf[i:]) {
+					tail = i
+				}
+				break
+			}
+		}
+	}
+
+	converted := convertBytes(buf[head:tail], r.mapping)
+	n = copy(p, converted[int(r.offset-start)-head:])
+	r.offset += int64(n)
+
+	return n, nil
+}
+
+func (r *scriptReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+
+	return offset, nil
+}
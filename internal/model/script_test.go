@@ -0,0 +1,213 @@
+package model
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		s         string
+		expect    Script
+		expectErr error
+	}{
+		{name: "empty is original", s: "", expect: ScriptOriginal},
+		{name: "original", s: "original", expect: ScriptOriginal},
+		{name: "simplified", s: "s", expect: ScriptSimplified},
+		{name: "traditional", s: "t", expect: ScriptTraditional},
+		{name: "invalid", s: "x", expect: "", expectErr: ErrInvalidScript},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ParseScript(test.s)
+			assert.Equal(t, test.expect, result)
+			assert.ErrorIs(t, err, test.expectErr)
+		})
+	}
+}
+
+func TestScript_Convert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script Script
+		s      string
+		expect string
+	}{
+		{name: "to simplified", script: ScriptSimplified, s: "鬥破蒼穹", expect: "斗破苍穹"},
+		{name: "to traditional", script: ScriptTraditional, s: "斗破苍穹", expect: "鬥破蒼穹"},
+		{name: "original", script: ScriptOriginal, s: "斗破蒼穹", expect: "斗破蒼穹"},
+		{name: "keep invalid bytes", script: ScriptSimplified, s: "蒼\xff穹", expect: "苍\xff穹"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.script.Convert(test.s))
+		})
+	}
+}
+
+func TestScript_mapping(t *testing.T) {
+	t.Parallel()
+
+	// Convert skips characters converted to another byte length, every
+	// character in mapping is expected to be converted
+	for _, script := range []Script{ScriptSimplified, ScriptTraditional} {
+		for from, to := range script.mapping() {
+			assert.Equal(t, utf8.RuneLen(from), utf8.RuneLen(to), "%s: %c to %c", script, from, to)
+		}
+	}
+}
+
+func Test_ScriptVariants(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		s      string
+		expect []string
+	}{
+		{name: "traditional query", s: "蒼穹", expect: []string{"蒼穹", "苍穹"}},
+		{name: "simplified query", s: "苍穹", expect: []string{"苍穹", "蒼穹"}},
+		{name: "no chinese", s: "abc", expect: []string{"abc"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, ScriptVariants(test.s))
+		})
+	}
+}
+
+func Test_ScriptWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script Script
+		chunks [][]byte
+		expect string
+	}{
+		{
+			name:   "convert character split across writes",
+			script: ScriptSimplified,
+			// "鬥" is e9 ac a5
+			chunks: [][]byte{{0xe9}, {0xac}, append([]byte{0xa5}, []byte("破蒼穹")...)},
+			expect: "斗破苍穹",
+		},
+		{
+			name:   "original script writes as is",
+			script: ScriptOriginal,
+			chunks: [][]byte{[]byte("鬥破"), []byte("蒼穹")},
+			expect: "鬥破蒼穹",
+		},
+		{
+			name:   "flush incomplete character on close",
+			script: ScriptTraditional,
+			chunks: [][]byte{append([]byte("苍穹"), 0xe9)},
+			expect: "蒼穹\xe9",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			writer := NewScriptWriter(&buf, test.script)
+			for _, chunk := range test.chunks {
+				n, err := writer.Write(chunk)
+				assert.NoError(t, err)
+				assert.Equal(t, len(chunk), n)
+			}
+			assert.NoError(t, writer.Close())
+
+			assert.Equal(t, test.expect, buf.String())
+		})
+	}
+}
+
+func Test_ScriptReader(t *testing.T) {
+	t.Parallel()
+
+	content := "鬥破蒼穹\n第一章 蕭炎\nabc\xe9"
+
+	tests := []struct {
+		name      string
+		script    Script
+		offset    int64
+		chunkSize int
+		expect    string
+	}{
+		{
+			name:      "read all in one chunk",
+			script:    ScriptSimplified,
+			chunkSize: 100,
+			expect:    ScriptSimplified.Convert(content),
+		},
+		{
+			name:      "read byte by byte",
+			script:    ScriptSimplified,
+			chunkSize: 1,
+			expect:    ScriptSimplified.Convert(content),
+		},
+		{
+			name:      "read from middle of character",
+			script:    ScriptSimplified,
+			offset:    4,
+			chunkSize: 2,
+			expect:    ScriptSimplified.Convert(content)[4:],
+		},
+		{
+			name:      "original script",
+			script:    ScriptOriginal,
+			offset:    3,
+			chunkSize: 5,
+			expect:    content[3:],
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := NewScriptReader(strings.NewReader(content), int64(len(content)), test.script)
+			pos, err := reader.Seek(test.offset, io.SeekStart)
+			assert.NoError(t, err)
+			assert.Equal(t, test.offset, pos)
+
+			var result []byte
+			buf := make([]byte, test.chunkSize)
+			for {
+				n, err := reader.Read(buf)
+				result = append(result, buf[:n]...)
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expect, string(result))
+
+			size, err := reader.Seek(0, io.SeekEnd)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(len(content)), size)
+		})
+	}
+}
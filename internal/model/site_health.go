@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// HealthStatus shows if the selectors of a site still parse the vendor pages
+type HealthStatus string
+
+const (
+	HealthUnknown HealthStatus = "unknown"
+	HealthHealthy HealthStatus = "healthy"
+	HealthBroken  HealthStatus = "broken"
+)
+
+// SiteHealth is the result of latest selector check of a site. phase is the
+// canary check or the operation which parsed the pages, failed is the number
+// of pages fail to parse in checked pages
+type SiteHealth struct {
+	Site      string       `json:"site"`
+	Status    HealthStatus `json:"status"`
+	Phase     string       `json:"phase"`
+	Checked   int          `json:"checked"`
+	Failed    int          `json:"failed"`
+	Error     string       `json:"error,omitempty"`
+	CheckedAt time.Time    `json:"checked_at"`
+}
+
+// FailureRate returns the ratio of pages fail to parse, 0 if nothing is checked
+func (health SiteHealth) FailureRate() float64 {
+	if health.Checked == 0 {
+		return 0
+	}
+
+	return float64(health.Failed) / float64(health.Checked)
+}
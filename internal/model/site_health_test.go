@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteHealth_FailureRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		health SiteHealth
+		want   float64
+	}{
+		{
+			name:   "nothing checked",
+			health: SiteHealth{},
+			want:   0,
+		},
+		{
+			name:   "some pages failed",
+			health: SiteHealth{Checked: 4, Failed: 1},
+			want:   0.25,
+		},
+		{
+			name:   "all pages failed",
+			health: SiteHealth{Checked: 3, Failed: 3},
+			want:   1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.health.FailureRate())
+		})
+	}
+}
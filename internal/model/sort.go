@@ -0,0 +1,32 @@
+package model
+
+import "errors"
+
+// BookSort is the order of books in listings, books are listed in
+// descending order except title
+type BookSort string
+
+const (
+	BookSortUpdated    BookSort = "updated"
+	BookSortTitle      BookSort = "title"
+	BookSortID         BookSort = "id"
+	BookSortDiscovered BookSort = "discovered"
+)
+
+var ErrInvalidBookSort = errors.New("invalid book sort")
+
+func ParseBookSort(s string) (BookSort, error) {
+	switch BookSort(s) {
+	case "", BookSortUpdated:
+		return BookSortUpdated, nil
+	case BookSortTitle, BookSortID, BookSortDiscovered:
+		return BookSort(s), nil
+	default:
+		return "", ErrInvalidBookSort
+	}
+}
+
+// Ascending reports whether the books are listed in ascending order of the sort key
+func (sort BookSort) Ascending() bool {
+	return sort == BookSortTitle
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseBookSort(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		s         string
+		expect    BookSort
+		expectErr error
+	}{
+		{name: "empty string is updated", s: "", expect: BookSortUpdated},
+		{name: "title", s: "title", expect: BookSortTitle},
+		{name: "id", s: "id", expect: BookSortID},
+		{name: "discovered", s: "discovered", expect: BookSortDiscovered},
+		{name: "invalid sort", s: "random", expectErr: ErrInvalidBookSort},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ParseBookSort(test.s)
+			assert.Equal(t, test.expect, result)
+			assert.ErrorIs(t, err, test.expectErr)
+		})
+	}
+}
+
+func Test_BookSort_Ascending(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, BookSortTitle.Ascending())
+	assert.False(t, BookSortUpdated.Ascending())
+	assert.False(t, BookSortID.Ascending())
+	assert.False(t, BookSortDiscovered.Ascending())
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// WorkMatchThreshold is the minimum MatchScore for a book to join an existing work.
+// identical title alone can't reach it, so books of the same common title by
+// different writers are not linked. books of another pen name are linked by
+// merging works manually
+const WorkMatchThreshold = 0.9
+
+const (
+	workTitleWeight  = 0.7
+	workWriterWeight = 0.3
+)
+
+// Work is a canonical novel which links the same book across sites and hash versions
+type Work struct {
+	ID     int       `json:"id"`
+	Title  string    `json:"title"`
+	Writer string    `json:"writer"`
+	Books  BookGroup `json:"books"`
+}
+
+var bracketPairs = map[rune]rune{
+	'(': ')', '（': '）', '[': ']', '【': '】', '〔': '〕', '「': '」', '『': '』',
+}
+
+var writerPrefixes = []string{"作者：", "作者:", "作者", "著：", "著:"}
+
+// removeBracketContent drops bracketed segments like "(精校版)" or "【完结】",
+// but keeps the content if removing it would leave nothing
+func removeBracketContent(s string) string {
+	var (
+		result  strings.Builder
+		closing []rune
+	)
+
+	for _, r := range s {
+		if closeRune, ok := bracketPairs[r]; ok {
+			closing = append(closing, closeRune)
+			continue
+		}
+
+		if len(closing) > 0 {
+			if r == closing[len(closing)-1] {
+				closing = closing[:len(closing)-1]
+			}
+			continue
+		}
+
+		result.WriteRune(r)
+	}
+
+	if strings.TrimSpace(result.String()) == "" {
+		return s
+	}
+
+	return result.String()
+}
+
+func normalizeKey(s string) string {
+	s = width.Fold.String(simplified(s))
+
+	var result strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			result.WriteRune(r)
+		}
+	}
+
+	return result.String()
+}
+
+// NormalizeTitle returns a title key which ignores script, width, punctuation
+// and bracketed edition markers
+func NormalizeTitle(title string) string {
+	return normalizeKey(removeBracketContent(strings.Trim(strings.TrimSpace(title), "《》")))
+}
+
+// NormalizeWriter returns a writer key which ignores script, width, punctuation
+// and prefixes like "作者："
+func NormalizeWriter(writer string) string {
+	writer = strings.TrimSpace(writer)
+	for _, prefix := range writerPrefixes {
+		writer = strings.TrimPrefix(writer, prefix)
+	}
+
+	return normalizeKey(removeBracketContent(writer))
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// Similarity returns a score between 0 and 1 of two normalized keys
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	if a == "" || b == "" {
+		return 0
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	maxLen := max(len(ra), len(rb))
+
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+// MatchScore returns how likely the book belongs to the work
+func (w Work) MatchScore(bk Book) float64 {
+	titleScore := Similarity(NormalizeTitle(w.Title), NormalizeTitle(bk.Title))
+	writerScore := Similarity(NormalizeWriter(w.Writer), NormalizeWriter(bk.Writer.Name))
+
+	return titleScore*workTitleWeight + writerScore*workWriterWeight
+}
+
+// BestSource returns the book to read or download the work from.
+// downloaded books are preferred, followed by ended books and the latest update
+func (w Work) BestSource() *Book {
+	var best *Book
+
+	rank := func(bk *Book) int {
+		switch {
+		case bk.IsDownloaded:
+			return 2
+		case bk.Status == StatusEnd:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	for i := range w.Books {
+		bk := &w.Books[i]
+		if bk.Status == StatusError {
+			continue
+		}
+
+		if best == nil || rank(bk) > rank(best) ||
+			(rank(bk) == rank(best) && bk.UpdateDate > best.UpdateDate) {
+			best = bk
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,202 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizeTitle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		title  string
+		expect string
+	}{
+		{
+			name:   "remove punctuation and spaces",
+			title:  "神印王座 II：皓月当空！",
+			expect: "神印王座ii皓月当空",
+		},
+		{
+			name:   "remove edition suffix",
+			title:  "斗破苍穹(精校版)",
+			expect: "斗破苍穹",
+		},
+		{
+			name:   "remove full width bracket and book title marks",
+			title:  "《斗破蒼穹》【完結】",
+			expect: "斗破苍穹",
+		},
+		{
+			name:   "keep content if title only contains brackets",
+			title:  "【斗破苍穹】",
+			expect: "斗破苍穹",
+		},
+		{
+			name:   "fold full width characters",
+			title:  "ＡＢＣ１２３",
+			expect: "abc123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, NormalizeTitle(test.title))
+		})
+	}
+}
+
+func Test_NormalizeWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		writer string
+		expect string
+	}{
+		{
+			name:   "remove writer prefix",
+			writer: "作者：天蠶土豆",
+			expect: "天蚕土豆",
+		},
+		{
+			name:   "remove spaces",
+			writer: " 唐家 三少 ",
+			expect: "唐家三少",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, NormalizeWriter(test.writer))
+		})
+	}
+}
+
+func Test_Similarity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		a, b   string
+		expect float64
+	}{
+		{name: "identical", a: "abcd", b: "abcd", expect: 1},
+		{name: "empty", a: "", b: "abcd", expect: 0},
+		{name: "one edit", a: "abcd", b: "abce", expect: 0.75},
+		{name: "completely different", a: "ab", b: "cd", expect: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.InDelta(t, test.expect, Similarity(test.a, test.b), 0.0001)
+		})
+	}
+}
+
+func TestWork_MatchScore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		work      Work
+		bk        Book
+		wantMatch bool
+	}{
+		{
+			name:      "same book in different script and edition",
+			work:      Work{Title: "斗破苍穹", Writer: "天蚕土豆"},
+			bk:        Book{Title: "鬥破蒼穹(精校版)", Writer: Writer{Name: "天蠶土豆"}},
+			wantMatch: true,
+		},
+		{
+			name:      "same title with different pen name",
+			work:      Work{Title: "斗破苍穹", Writer: "天蚕土豆"},
+			bk:        Book{Title: "斗破苍穹", Writer: Writer{Name: "土豆"}},
+			wantMatch: false,
+		},
+		{
+			name:      "same title by unrelated writer",
+			work:      Work{Title: "斗破苍穹", Writer: "天蚕土豆"},
+			bk:        Book{Title: "《斗破苍穹》", Writer: Writer{Name: "作者：番茄"}},
+			wantMatch: false,
+		},
+		{
+			name:      "sequel title with different pen name",
+			work:      Work{Title: "斗破苍穹", Writer: "天蚕土豆"},
+			bk:        Book{Title: "斗破苍穹2", Writer: Writer{Name: "番茄"}},
+			wantMatch: false,
+		},
+		{
+			name:      "same title with similar pen name",
+			work:      Work{Title: "斗破苍穹", Writer: "天蚕土豆"},
+			bk:        Book{Title: "斗破苍穹", Writer: Writer{Name: "天蚕土豆丶"}},
+			wantMatch: true,
+		},
+		{
+			name:      "different book by the same writer",
+			work:      Work{Title: "斗破苍穹", Writer: "天蚕土豆"},
+			bk:        Book{Title: "武动乾坤", Writer: Writer{Name: "天蚕土豆"}},
+			wantMatch: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			score := test.work.MatchScore(test.bk)
+			assert.Equal(t, test.wantMatch, score >= WorkMatchThreshold, "score: %v", score)
+		})
+	}
+}
+
+func TestWork_BestSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		work   Work
+		expect *Book
+	}{
+		{
+			name:   "empty work",
+			work:   Work{},
+			expect: nil,
+		},
+		{
+			name: "prefer downloaded book",
+			work: Work{Books: BookGroup{
+				{Site: "a", ID: 1, Status: StatusEnd, UpdateDate: "2024"},
+				{Site: "b", ID: 2, Status: StatusEnd, IsDownloaded: true, UpdateDate: "2020"},
+				{Site: "c", ID: 3, Status: StatusInProgress, UpdateDate: "2025"},
+			}},
+			expect: &Book{Site: "b", ID: 2, Status: StatusEnd, IsDownloaded: true, UpdateDate: "2020"},
+		},
+		{
+			name: "prefer latest update if none downloaded",
+			work: Work{Books: BookGroup{
+				{Site: "a", ID: 1, Status: StatusInProgress, UpdateDate: "2024"},
+				{Site: "b", ID: 2, Status: StatusInProgress, UpdateDate: "2025"},
+				{Site: "c", ID: 3, Status: StatusError, UpdateDate: "2026"},
+			}},
+			expect: &Book{Site: "b", ID: 2, Status: StatusInProgress, UpdateDate: "2025"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.work.BestSource())
+		})
+	}
+}
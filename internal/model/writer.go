@@ -33,3 +33,59 @@ func (w Writer) Checksum() string {
 	writerName := strings.ReplaceAll(w.Name, " ", "")
 	return strToShortHex(simplified(writerName))
 }
+
+type WriterOrder string
+
+const (
+	WriterOrderBookCount    WriterOrder = "books"
+	WriterOrderLatestUpdate WriterOrder = "updated"
+)
+
+var ErrInvalidWriterOrder = errors.New("invalid writer order")
+
+func ParseWriterOrder(s string) (WriterOrder, error) {
+	switch WriterOrder(s) {
+	case "", WriterOrderBookCount:
+		return WriterOrderBookCount, nil
+	case WriterOrderLatestUpdate:
+		return WriterOrderLatestUpdate, nil
+	default:
+		return "", ErrInvalidWriterOrder
+	}
+}
+
+// WriterSummary is a writer with the count of books and the latest update date of them
+type WriterSummary struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	BookCount    int    `json:"book_count"`
+	LatestUpdate string `json:"latest_update"`
+}
+
+// WriterCatalog is every book of a writer across sites. books of the same
+// work are in one group, books without work are in a group of their own
+type WriterCatalog struct {
+	ID     int         `json:"id"`
+	Name   string      `json:"name"`
+	Groups []BookGroup `json:"groups"`
+}
+
+// NewWriterCatalog groups the books by work in the order of their first appearance
+func NewWriterCatalog(writer Writer, bks []Book) *WriterCatalog {
+	catalog := &WriterCatalog{ID: writer.ID, Name: writer.Name, Groups: make([]BookGroup, 0, len(bks))}
+
+	workGroups := make(map[int]int)
+	for _, bk := range bks {
+		if bk.WorkID > 0 {
+			if i, ok := workGroups[bk.WorkID]; ok {
+				catalog.Groups[i] = append(catalog.Groups[i], bk)
+				continue
+			}
+			workGroups[bk.WorkID] = len(catalog.Groups)
+		}
+
+		catalog.Groups = append(catalog.Groups, BookGroup{bk})
+	}
+
+	return catalog
+}
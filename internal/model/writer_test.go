@@ -72,3 +72,74 @@ func Test_Writer_Checksum(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParseWriterOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		s         string
+		want      WriterOrder
+		wantError error
+	}{
+		{name: "empty", s: "", want: WriterOrderBookCount},
+		{name: "books", s: "books", want: WriterOrderBookCount},
+		{name: "updated", s: "updated", want: WriterOrderLatestUpdate},
+		{name: "invalid", s: "name", wantError: ErrInvalidWriterOrder},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseWriterOrder(test.s)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func Test_NewWriterCatalog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		writer Writer
+		bks    []Book
+		want   *WriterCatalog
+	}{
+		{
+			name:   "group books by work",
+			writer: Writer{ID: 1, Name: "writer"},
+			bks: []Book{
+				{Site: "a", ID: 1, WorkID: 5},
+				{Site: "a", ID: 2},
+				{Site: "b", ID: 1, WorkID: 5},
+				{Site: "b", ID: 2},
+				{Site: "b", ID: 3, WorkID: 6},
+			},
+			want: &WriterCatalog{
+				ID: 1, Name: "writer",
+				Groups: []BookGroup{
+					{{Site: "a", ID: 1, WorkID: 5}, {Site: "b", ID: 1, WorkID: 5}},
+					{{Site: "a", ID: 2}},
+					{{Site: "b", ID: 2}},
+					{{Site: "b", ID: 3, WorkID: 6}},
+				},
+			},
+		},
+		{
+			name:   "no books",
+			writer: Writer{ID: 1, Name: "writer"},
+			want:   &WriterCatalog{ID: 1, Name: "writer", Groups: []BookGroup{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, NewWriterCatalog(test.writer, test.bks))
+		})
+	}
+}
@@ -5,14 +5,23 @@ import (
 
 	"github.com/htchan/BookSpider/internal/config/v2"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
+	metricsdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
+func newResource(conf config.TraceConfig) *resource.Resource {
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(conf.OtelServiceName),
+	)
+}
+
 func NewProvider(conf config.TraceConfig) (*tracesdk.TracerProvider, error) {
 	exp, err := otlptrace.New(
 		context.Background(),
@@ -27,10 +36,7 @@ func NewProvider(conf config.TraceConfig) (*tracesdk.TracerProvider, error) {
 
 	tp := tracesdk.NewTracerProvider(
 		tracesdk.WithBatcher(exp),
-		tracesdk.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(conf.OtelServiceName),
-		)),
+		tracesdk.WithResource(newResource(conf)),
 	)
 
 	otel.SetTracerProvider(tp)
@@ -38,3 +44,24 @@ func NewProvider(conf config.TraceConfig) (*tracesdk.TracerProvider, error) {
 
 	return tp, nil
 }
+
+// NewMeterProvider exports metrics to the same collector of traces
+func NewMeterProvider(conf config.TraceConfig) (*metricsdk.MeterProvider, error) {
+	exp, err := otlpmetrichttp.New(
+		context.Background(),
+		otlpmetrichttp.WithEndpoint(conf.OtelURL),
+		otlpmetrichttp.WithInsecure(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mp := metricsdk.NewMeterProvider(
+		metricsdk.WithReader(metricsdk.NewPeriodicReader(exp)),
+		metricsdk.WithResource(newResource(conf)),
+	)
+
+	otel.SetMeterProvider(mp)
+
+	return mp, nil
+}
@@ -0,0 +1,10 @@
+package repo
+
+import "github.com/htchan/BookSpider/internal/model"
+
+// BrowseParams filters the books of a genre, site and status are optional
+type BrowseParams struct {
+	Genre  model.Genre
+	Site   string // books of all sites if empty
+	Status string // status key like END, books of any status except ERROR if empty
+}
@@ -3,5 +3,6 @@ package repo
 import "errors"
 
 var (
-	ErrBookNotExist = errors.New("no records found")
+	ErrBookNotExist   = errors.New("no records found")
+	ErrBookIDConflict = errors.New("book id taken by concurrent insert")
 )
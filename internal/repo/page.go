@@ -0,0 +1,189 @@
+package repo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageParams is the keyset pagination of book listings
+type PageParams struct {
+	Sort   model.BookSort
+	Limit  int
+	Cursor string // cursor returned in BookPage, list the first page if empty
+}
+
+// BookPage is a page of books with the cursors of pages around it
+type BookPage struct {
+	Books      []model.Book
+	Total      int
+	NextCursor string                  // empty if it is the last page
+	PrevCursor string                  // empty if it is the first page
+	Sources    map[int]model.BookGroup // books of the works in page by work id, only filled by search
+}
+
+// Cursor is the position of a book in listing. the books after it in the
+// sort order are listed, or the books before it if it is backward
+type Cursor struct {
+	Sort     model.BookSort `json:"o"`
+	Key      string         `json:"k"`
+	Site     string         `json:"s"`
+	ID       int            `json:"i"`
+	HashCode int            `json:"h"`
+	Backward bool           `json:"b,omitempty"`
+}
+
+func (cursor Cursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Ascending tells the order to query the books next to the cursor
+func (cursor Cursor) Ascending() bool {
+	return cursor.Sort.Ascending() != cursor.Backward
+}
+
+// DecodeCursor returns nil for empty string. cursor of another sort is
+// invalid as its key cannot be compared
+func DecodeCursor(s string, sort model.BookSort) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// NewBookPage builds the page from books queried next to the cursor in the
+// direction of cursor. keys are the sort keys of books, and one more book than
+// the limit is expected to be queried to tell if there are more books
+func NewBookPage(bks []model.Book, keys []string, params PageParams, cursor *Cursor, total int) *BookPage {
+	backward := cursor != nil && cursor.Backward
+	hasMore := len(bks) > params.Limit
+	if hasMore {
+		bks, keys = bks[:params.Limit], keys[:params.Limit]
+	}
+	if backward {
+		slices.Reverse(bks)
+		slices.Reverse(keys)
+	}
+
+	page := &BookPage{Books: bks, Total: total}
+	if len(bks) == 0 {
+		return page
+	}
+
+	newCursor := func(i int, backward bool) string {
+		return Cursor{
+			Sort: params.Sort, Key: keys[i], Site: bks[i].Site, ID: bks[i].ID, HashCode: bks[i].HashCode,
+			Backward: backward,
+		}.Encode()
+	}
+
+	if hasMore || backward {
+		page.NextCursor = newCursor(len(bks)-1, false)
+	}
+	if (hasMore && backward) || (cursor != nil && !backward) {
+		page.PrevCursor = newCursor(0, true)
+	}
+
+	return page
+}
+
+// WriterPageParams is the keyset pagination of writer listings
+type WriterPageParams struct {
+	Order  model.WriterOrder
+	Limit  int
+	Cursor string // cursor returned in WriterPage, list the first page if empty
+}
+
+// WriterPage is a page of writers with the cursors of pages around it
+type WriterPage struct {
+	Writers    []model.WriterSummary
+	NextCursor string // empty if it is the last page
+	PrevCursor string // empty if it is the first page
+}
+
+// WriterCursor is the position of a writer in listing. the writers after it
+// in the order are listed, or the writers before it if it is backward
+type WriterCursor struct {
+	Order        model.WriterOrder `json:"o"`
+	BookCount    int               `json:"c"`
+	LatestUpdate string            `json:"u,omitempty"`
+	ID           int               `json:"i"`
+	Backward     bool              `json:"b,omitempty"`
+}
+
+func (cursor WriterCursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeWriterCursor returns nil for empty string. cursor of another order is
+// invalid as its keys cannot be compared
+func DecodeWriterCursor(s string, order model.WriterOrder) (*WriterCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor WriterCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Order != order {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// NewWriterPage builds the page from writers queried next to the cursor in
+// the direction of cursor. one more writer than the limit is expected to be
+// queried to tell if there are more writers
+func NewWriterPage(writers []model.WriterSummary, params WriterPageParams, cursor *WriterCursor) *WriterPage {
+	backward := cursor != nil && cursor.Backward
+	hasMore := len(writers) > params.Limit
+	if hasMore {
+		writers = writers[:params.Limit]
+	}
+	if backward {
+		slices.Reverse(writers)
+	}
+
+	page := &WriterPage{Writers: writers}
+	if len(writers) == 0 {
+		return page
+	}
+
+	newCursor := func(writer model.WriterSummary, backward bool) string {
+		return WriterCursor{
+			Order: params.Order, BookCount: writer.BookCount, LatestUpdate: writer.LatestUpdate, ID: writer.ID,
+			Backward: backward,
+		}.Encode()
+	}
+
+	if hasMore || backward {
+		page.NextCursor = newCursor(writers[len(writers)-1], false)
+	}
+	if (hasMore && backward) || (cursor != nil && !backward) {
+		page.PrevCursor = newCursor(writers[0], true)
+	}
+
+	return page
+}
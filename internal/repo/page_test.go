@@ -0,0 +1,211 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DecodeCursor(t *testing.T) {
+	t.Parallel()
+
+	cursor := Cursor{Sort: model.BookSortTitle, Key: "title", Site: "test", ID: 1, HashCode: 100, Backward: true}
+
+	tests := []struct {
+		name      string
+		s         string
+		sort      model.BookSort
+		expect    *Cursor
+		expectErr error
+	}{
+		{name: "empty string is first page", s: "", sort: model.BookSortTitle, expect: nil},
+		{name: "encoded cursor", s: cursor.Encode(), sort: model.BookSortTitle, expect: &cursor},
+		{name: "cursor of other sort", s: cursor.Encode(), sort: model.BookSortID, expectErr: ErrInvalidCursor},
+		{name: "not base64", s: "!!!", sort: model.BookSortTitle, expectErr: ErrInvalidCursor},
+		{name: "not json", s: "bm90IGpzb24", sort: model.BookSortTitle, expectErr: ErrInvalidCursor},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := DecodeCursor(test.s, test.sort)
+			assert.Equal(t, test.expect, result)
+			assert.ErrorIs(t, err, test.expectErr)
+		})
+	}
+}
+
+func Test_Cursor_Ascending(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Cursor{Sort: model.BookSortTitle}.Ascending())
+	assert.False(t, Cursor{Sort: model.BookSortTitle, Backward: true}.Ascending())
+	assert.False(t, Cursor{Sort: model.BookSortUpdated}.Ascending())
+	assert.True(t, Cursor{Sort: model.BookSortUpdated, Backward: true}.Ascending())
+}
+
+func Test_NewBookPage(t *testing.T) {
+	t.Parallel()
+
+	bks := func(ids ...int) []model.Book {
+		result := make([]model.Book, 0, len(ids))
+		for _, id := range ids {
+			result = append(result, model.Book{Site: "test", ID: id})
+		}
+		return result
+	}
+	keys := func(ids ...string) []string { return ids }
+	cursor := func(key string, id int, backward bool) string {
+		return Cursor{Sort: model.BookSortID, Key: key, Site: "test", ID: id, Backward: backward}.Encode()
+	}
+	params := PageParams{Sort: model.BookSortID, Limit: 2}
+
+	tests := []struct {
+		name   string
+		bks    []model.Book
+		keys   []string
+		cursor *Cursor
+		expect *BookPage
+	}{
+		{
+			name: "first page with more books",
+			bks:  bks(3, 2, 1), keys: keys("3", "2", "1"),
+			expect: &BookPage{Books: bks(3, 2), Total: 3, NextCursor: cursor("2", 2, false)},
+		},
+		{
+			name: "only page",
+			bks:  bks(3, 2), keys: keys("3", "2"),
+			expect: &BookPage{Books: bks(3, 2), Total: 3},
+		},
+		{
+			name: "last page",
+			bks:  bks(1), keys: keys("1"),
+			cursor: &Cursor{Sort: model.BookSortID, Key: "2", Site: "test", ID: 2},
+			expect: &BookPage{Books: bks(1), Total: 3, PrevCursor: cursor("1", 1, true)},
+		},
+		{
+			name: "previous page is reversed",
+			bks:  bks(3, 4, 5), keys: keys("3", "4", "5"),
+			cursor: &Cursor{Sort: model.BookSortID, Key: "2", Site: "test", ID: 2, Backward: true},
+			expect: &BookPage{
+				Books: bks(4, 3), Total: 3,
+				NextCursor: cursor("3", 3, false), PrevCursor: cursor("4", 4, true),
+			},
+		},
+		{
+			name: "previous page is the first page",
+			bks:  bks(3), keys: keys("3"),
+			cursor: &Cursor{Sort: model.BookSortID, Key: "2", Site: "test", ID: 2, Backward: true},
+			expect: &BookPage{Books: bks(3), Total: 3, NextCursor: cursor("3", 3, false)},
+		},
+		{
+			name: "empty page",
+			bks:  bks(), keys: keys(),
+			cursor: &Cursor{Sort: model.BookSortID, Key: "1", Site: "test", ID: 1},
+			expect: &BookPage{Books: bks(), Total: 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, NewBookPage(test.bks, test.keys, params, test.cursor, 3))
+		})
+	}
+}
+
+func Test_DecodeWriterCursor(t *testing.T) {
+	t.Parallel()
+
+	cursor := WriterCursor{Order: model.WriterOrderLatestUpdate, BookCount: 3, LatestUpdate: "date", ID: 1, Backward: true}
+
+	tests := []struct {
+		name      string
+		s         string
+		order     model.WriterOrder
+		expect    *WriterCursor
+		expectErr error
+	}{
+		{name: "empty string is first page", s: "", order: model.WriterOrderLatestUpdate, expect: nil},
+		{name: "encoded cursor", s: cursor.Encode(), order: model.WriterOrderLatestUpdate, expect: &cursor},
+		{name: "cursor of other order", s: cursor.Encode(), order: model.WriterOrderBookCount, expectErr: ErrInvalidCursor},
+		{name: "not base64", s: "!!!", order: model.WriterOrderLatestUpdate, expectErr: ErrInvalidCursor},
+		{name: "not json", s: "bm90IGpzb24", order: model.WriterOrderLatestUpdate, expectErr: ErrInvalidCursor},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := DecodeWriterCursor(test.s, test.order)
+			assert.Equal(t, test.expect, result)
+			assert.ErrorIs(t, err, test.expectErr)
+		})
+	}
+}
+
+func Test_NewWriterPage(t *testing.T) {
+	t.Parallel()
+
+	writers := func(ids ...int) []model.WriterSummary {
+		result := make([]model.WriterSummary, 0, len(ids))
+		for _, id := range ids {
+			result = append(result, model.WriterSummary{ID: id, BookCount: 1})
+		}
+		return result
+	}
+	cursor := func(id int, backward bool) string {
+		return WriterCursor{Order: model.WriterOrderBookCount, BookCount: 1, ID: id, Backward: backward}.Encode()
+	}
+	params := WriterPageParams{Order: model.WriterOrderBookCount, Limit: 2}
+
+	tests := []struct {
+		name    string
+		writers []model.WriterSummary
+		cursor  *WriterCursor
+		expect  *WriterPage
+	}{
+		{
+			name:    "first page with more writers",
+			writers: writers(1, 2, 3),
+			expect:  &WriterPage{Writers: writers(1, 2), NextCursor: cursor(2, false)},
+		},
+		{
+			name:    "only page",
+			writers: writers(1, 2),
+			expect:  &WriterPage{Writers: writers(1, 2)},
+		},
+		{
+			name:    "last page",
+			writers: writers(3),
+			cursor:  &WriterCursor{Order: model.WriterOrderBookCount, BookCount: 1, ID: 2},
+			expect:  &WriterPage{Writers: writers(3), PrevCursor: cursor(3, true)},
+		},
+		{
+			name:    "previous page is reversed",
+			writers: writers(3, 2, 1),
+			cursor:  &WriterCursor{Order: model.WriterOrderBookCount, BookCount: 1, ID: 4, Backward: true},
+			expect: &WriterPage{
+				Writers:    writers(2, 3),
+				NextCursor: cursor(3, false), PrevCursor: cursor(2, true),
+			},
+		},
+		{
+			name:    "empty page",
+			writers: writers(),
+			cursor:  &WriterCursor{Order: model.WriterOrderBookCount, BookCount: 1, ID: 3},
+			expect:  &WriterPage{Writers: writers()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, NewWriterPage(test.writers, params, test.cursor))
+		})
+	}
+}
@@ -17,6 +17,7 @@ func GetTracer() trace.Tracer {
 type Repository interface {
 	// book related
 	CreateBook(context.Context, *model.Book) error
+	CreateBookWithNextID(context.Context, *model.Book) error // book id is set to the next id of site
 	UpdateBook(context.Context, *model.Book) error
 	// the system will not delete exiting books
 
@@ -26,8 +27,9 @@ type Repository interface {
 	FindAllBooks(ctx context.Context, site string) (<-chan model.Book, error)
 	FindBooksForUpdate(ctx context.Context, site string) (<-chan model.Book, error)
 	FindBooksForDownload(ctx context.Context, site string) (<-chan model.Book, error)
-	FindBooksByTitleWriter(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error)
-	FindBooksByRandom(ctx context.Context, limit int) ([]model.Book, error)
+	FindBooksByTitleWriter(ctx context.Context, title, writer string, genre model.Genre, page PageParams) (*BookPage, error) // any genre if genre is empty
+	FindBooksByRandom(ctx context.Context, genre model.Genre, sort model.BookSort, limit int) ([]model.Book, error)          // any genre if genre is empty
+	FindBooksByGenre(ctx context.Context, params BrowseParams, page PageParams) (*BookPage, error)
 	UpdateBooksStatus(context.Context) error
 
 	FindBookGroupByID(ctx context.Context, site string, id int) (model.BookGroup, error)
@@ -35,13 +37,36 @@ type Repository interface {
 
 	FindAllBookIDs(ctx context.Context, site string) ([]int, error)
 
+	FindBooksWithoutChecksum(ctx context.Context) (<-chan model.Book, error) // non error books of all sites
+	UpdateBookChecksum(context.Context, *model.Book) error
+
+	// work related
+	CreateWork(context.Context, *model.Work) error // create and update id in work
+	FindWorkByID(ctx context.Context, id int) (*model.Work, error)
+	FindWorkCandidates(ctx context.Context, bk *model.Book) ([]model.Work, error)
+	FindBooksWithoutWork(ctx context.Context, site string) (<-chan model.Book, error)
+	LinkBookToWork(ctx context.Context, bk *model.Book, workID int) error // unlink if work id is 0
+	MergeWorks(ctx context.Context, targetID, sourceID int) error
+
 	// writer related
 	SaveWriter(context.Context, *model.Writer) error // create and update id in writer
 	// the system will not delete / update existing writers
 
+	FindWriterByID(ctx context.Context, id int) (*model.Writer, error)
+	FindBooksByWriterID(ctx context.Context, writerID int, sort model.BookSort) ([]model.Book, error) // include books of writers with same checksum
+	FindWriters(ctx context.Context, name string, page WriterPageParams) (*WriterPage, error)         // list all writers if name is empty
+	FindWritersWithoutChecksum(ctx context.Context) (<-chan model.Writer, error)
+	UpdateWriterChecksum(context.Context, *model.Writer) error
+
+	// genre related
+	SyncGenres(ctx context.Context, site string) (int, error) // map the new book types of site to genres, return the count of new types
+
 	// error related
 	SaveError(context.Context, *model.Book, error) error // create / update / delete errors depends on error content
 
+	// health related
+	SaveSiteHealth(context.Context, *model.SiteHealth) error // create / replace the health of site
+
 	// database
 	Backup(ctx context.Context, site, path string) error
 	DBStats(context.Context) sql.DBStats // return empty if repo is not based on db
@@ -37,6 +37,22 @@ func toSqlBool(b bool) sql.NullBool {
 	return sql.NullBool{Bool: b, Valid: true}
 }
 
+// searchPatterns returns like patterns of s in all scripts,
+// an empty s returns no pattern so it never matches
+func searchPatterns(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	variants := model.ScriptVariants(s)
+	patterns := make([]string, len(variants))
+	for i, variant := range variants {
+		patterns[i] = fmt.Sprintf("%%%s%%", variant)
+	}
+
+	return patterns
+}
+
 func NewRepo(db *sql.DB) *SqlcRepo {
 	return &SqlcRepo{
 		db:      db,
@@ -104,6 +120,46 @@ func (r *SqlcRepo) CreateBook(ctx context.Context, bk *model.Book) error {
 	return nil
 }
 
+// createBookMaxAttempts limits the retries when the next id is taken by
+// concurrent insert
+const createBookMaxAttempts = 10
+
+func (r *SqlcRepo) CreateBookWithNextID(ctx context.Context, bk *model.Book) error {
+	_, span := repo.GetTracer().Start(ctx, "create book with next id")
+	defer span.End()
+
+	params := sqlc.CreateBookWithNextIDParams{
+		Site:           bk.Site,
+		Title:          toSqlString(bk.Title),
+		WriterID:       toSqlInt(bk.Writer.ID),
+		WriterChecksum: toSqlString(bk.Writer.Checksum()),
+		Type:           toSqlString(bk.Type),
+		UpdateDate:     toSqlString(bk.UpdateDate),
+		UpdateChapter:  toSqlString(bk.UpdateChapter),
+		Status:         bk.Status.String(),
+		IsDownloaded:   bk.IsDownloaded,
+		Checksum:       toSqlString(bk.Checksum()),
+	}
+	jsonByte, jsonErr := json.Marshal(params)
+	if jsonErr == nil {
+		span.SetAttributes(attribute.String("params", string(jsonByte)))
+	}
+
+	for range createBookMaxAttempts {
+		result, err := r.queries.CreateBookWithNextID(ctx, params)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("fail to insert book: %w", err)
+		}
+
+		bk.ID, bk.HashCode = int(result.ID), int(result.HashCode)
+		return nil
+	}
+
+	return fmt.Errorf("fail to insert book: %w", repo.ErrBookIDConflict)
+}
+
 func (r *SqlcRepo) UpdateBook(ctx context.Context, bk *model.Book) error {
 	_, span := repo.GetTracer().Start(ctx, "update book")
 	defer span.End()
@@ -380,35 +436,199 @@ func (r *SqlcRepo) FindBooksForDownload(ctx context.Context, site string) (<-cha
 
 	return bkChan, nil
 }
-func (r *SqlcRepo) FindBooksByTitleWriter(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error) {
+
+// listedBookRow is the columns of book listing queries, the queries of every
+// listing and sort return the same columns
+type listedBookRow sqlc.ListBooksByTitleWriterUpdatedRow
+
+type listedBookRowTypes interface {
+	sqlc.ListBooksByTitleWriterUpdatedRow | sqlc.ListBooksByTitleWriterTitleRow |
+		sqlc.ListBooksByTitleWriterIDRow | sqlc.ListBooksByTitleWriterDiscoveredRow |
+		sqlc.ListBooksByGenreUpdatedRow | sqlc.ListBooksByGenreTitleRow |
+		sqlc.ListBooksByGenreIDRow | sqlc.ListBooksByGenreDiscoveredRow
+}
+
+func toListedBookRows[T listedBookRowTypes](rows []T, err error) ([]listedBookRow, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]listedBookRow, len(rows))
+	for i, row := range rows {
+		result[i] = listedBookRow(row)
+	}
+
+	return result, nil
+}
+
+// listedBooks returns the books of rows and their sort keys
+func listedBooks(rows []listedBookRow) ([]model.Book, []string) {
+	bks, keys := make([]model.Book, len(rows)), make([]string, len(rows))
+	for i := range rows {
+		var bkErr error
+		if rows[i].ErrorData != "" {
+			bkErr = errors.New(rows[i].ErrorData)
+		}
+
+		bks[i] = model.Book{
+			Site:     rows[i].Site,
+			ID:       int(rows[i].ID),
+			HashCode: int(rows[i].HashCode),
+			Title:    rows[i].Title.String,
+			Writer: model.Writer{
+				ID:   int(rows[i].WriterID.Int32),
+				Name: rows[i].WriterName,
+			},
+			Type:          rows[i].Type.String,
+			UpdateDate:    rows[i].UpdateDate.String,
+			UpdateChapter: rows[i].UpdateChapter.String,
+			Status:        model.StatusFromString(rows[i].Status),
+			IsDownloaded:  rows[i].IsDownloaded,
+			WorkID:        int(rows[i].WorkID),
+			Error:         bkErr,
+		}
+		keys[i] = rows[i].SortKey
+	}
+
+	return bks, keys
+}
+
+// listBooksByTitleWriter runs the query of sort, the sort key of cursor in
+// params is ignored if books are sorted by id
+func (r *SqlcRepo) listBooksByTitleWriter(ctx context.Context, params sqlc.ListBooksByTitleWriterUpdatedParams, sort model.BookSort) ([]listedBookRow, error) {
+	switch sort {
+	case model.BookSortTitle:
+		return toListedBookRows(r.queries.ListBooksByTitleWriterTitle(ctx, sqlc.ListBooksByTitleWriterTitleParams(params)))
+	case model.BookSortDiscovered:
+		return toListedBookRows(r.queries.ListBooksByTitleWriterDiscovered(ctx, sqlc.ListBooksByTitleWriterDiscoveredParams(params)))
+	case model.BookSortID:
+		return toListedBookRows(r.queries.ListBooksByTitleWriterID(ctx, sqlc.ListBooksByTitleWriterIDParams{
+			Titles:         params.Titles,
+			Writers:        params.Writers,
+			Genre:          params.Genre,
+			HasCursor:      params.HasCursor,
+			Ascending:      params.Ascending,
+			CursorID:       params.CursorID,
+			CursorSite:     params.CursorSite,
+			CursorHashCode: params.CursorHashCode,
+			LimitCount:     params.LimitCount,
+		}))
+	default:
+		return toListedBookRows(r.queries.ListBooksByTitleWriterUpdated(ctx, params))
+	}
+}
+
+// listBooksByGenre runs the query of sort, the sort key of cursor in params
+// is ignored if books are sorted by id
+func (r *SqlcRepo) listBooksByGenre(ctx context.Context, params sqlc.ListBooksByGenreUpdatedParams, sort model.BookSort) ([]listedBookRow, error) {
+	switch sort {
+	case model.BookSortTitle:
+		return toListedBookRows(r.queries.ListBooksByGenreTitle(ctx, sqlc.ListBooksByGenreTitleParams(params)))
+	case model.BookSortDiscovered:
+		return toListedBookRows(r.queries.ListBooksByGenreDiscovered(ctx, sqlc.ListBooksByGenreDiscoveredParams(params)))
+	case model.BookSortID:
+		return toListedBookRows(r.queries.ListBooksByGenreID(ctx, sqlc.ListBooksByGenreIDParams{
+			Genre:          params.Genre,
+			Site:           params.Site,
+			Status:         params.Status,
+			HasCursor:      params.HasCursor,
+			Ascending:      params.Ascending,
+			CursorID:       params.CursorID,
+			CursorSite:     params.CursorSite,
+			CursorHashCode: params.CursorHashCode,
+			LimitCount:     params.LimitCount,
+		}))
+	default:
+		return toListedBookRows(r.queries.ListBooksByGenreUpdated(ctx, params))
+	}
+}
+
+func (r *SqlcRepo) FindBooksByTitleWriter(ctx context.Context, title, writer string, genre model.Genre, page repo.PageParams) (*repo.BookPage, error) {
 	_, span := repo.GetTracer().Start(ctx, "find books by title and writer")
 	defer span.End()
 
 	span.SetAttributes(
 		attribute.String("title", title),
 		attribute.String("writer", writer),
-		attribute.Int("limit", limit),
-		attribute.Int("offset", offset),
+		attribute.String("genre", string(genre)),
+		attribute.String("sort", string(page.Sort)),
+		attribute.Int("limit", page.Limit),
+		attribute.String("cursor", page.Cursor),
 	)
 
-	results, err := r.queries.ListBooksByTitleWriter(ctx, sqlc.ListBooksByTitleWriterParams{
-		Column1: toSqlString(fmt.Sprintf("%%%s%%", title)),
-		Column2: toSqlString(fmt.Sprintf("%%%s%%", writer)),
-		Limit:   int32(limit),
-		Offset:  int32(offset),
-	})
+	cursor, err := repo.DecodeCursor(page.Cursor, page.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	var position repo.Cursor
+	if cursor != nil {
+		position = *cursor
+	}
+
+	results, err := r.listBooksByTitleWriter(ctx, sqlc.ListBooksByTitleWriterUpdatedParams{
+		Titles:         searchPatterns(title),
+		Writers:        searchPatterns(writer),
+		Genre:          string(genre),
+		HasCursor:      cursor != nil,
+		Ascending:      position.Ascending(),
+		CursorKey:      position.Key,
+		CursorSite:     position.Site,
+		CursorID:       int32(position.ID),
+		CursorHashCode: int32(position.HashCode),
+		LimitCount:     int32(page.Limit + 1),
+	}, page.Sort)
 	if err != nil {
 		return nil, fmt.Errorf("fail to query book by site id: %w", err)
 	}
 
-	bks := make([]model.Book, len(results))
+	total, err := r.queries.CountBooksByTitleWriter(ctx, sqlc.CountBooksByTitleWriterParams{
+		Titles:  searchPatterns(title),
+		Writers: searchPatterns(writer),
+		Genre:   string(genre),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to count book by title and writer: %w", err)
+	}
+
+	bks, keys := listedBooks(results)
+	bkPage := repo.NewBookPage(bks, keys, page, cursor, int(total))
+
+	bkPage.Sources, err = r.findWorkSources(ctx, bkPage.Books)
+	if err != nil {
+		return nil, err
+	}
+
+	return bkPage, nil
+}
+
+// findWorkSources returns the books of works of bks by work id
+func (r *SqlcRepo) findWorkSources(ctx context.Context, bks []model.Book) (map[int]model.BookGroup, error) {
+	var workIDs []int32
+	for _, bk := range bks {
+		if bk.WorkID > 0 {
+			workIDs = append(workIDs, int32(bk.WorkID))
+		}
+	}
+
+	if len(workIDs) == 0 {
+		return nil, nil
+	}
+
+	results, err := r.queries.ListBooksByWorkIDs(ctx, workIDs)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books by work ids: %w", err)
+	}
+
+	sources := make(map[int]model.BookGroup, len(workIDs))
 	for i := range results {
 		var bkErr error
 		if results[i].Data != "" {
 			bkErr = errors.New(results[i].Data)
 		}
 
-		bks[i] = model.Book{
+		workID := int(results[i].WorkID)
+		sources[workID] = append(sources[workID], model.Book{
 			Site:     results[i].Site,
 			ID:       int(results[i].ID),
 			HashCode: int(results[i].HashCode),
@@ -422,21 +642,29 @@ func (r *SqlcRepo) FindBooksByTitleWriter(ctx context.Context, title, writer str
 			UpdateChapter: results[i].UpdateChapter.String,
 			Status:        model.StatusFromString(results[i].Status),
 			IsDownloaded:  results[i].IsDownloaded,
+			WorkID:        workID,
 			Error:         bkErr,
-		}
+		})
 	}
 
-	return bks, nil
+	return sources, nil
 }
-func (r *SqlcRepo) FindBooksByRandom(ctx context.Context, limit int) ([]model.Book, error) {
+
+func (r *SqlcRepo) FindBooksByRandom(ctx context.Context, genre model.Genre, sort model.BookSort, limit int) ([]model.Book, error) {
 	_, span := repo.GetTracer().Start(ctx, "find books by random")
 	defer span.End()
 
 	span.SetAttributes(
+		attribute.String("genre", string(genre)),
+		attribute.String("sort", string(sort)),
 		attribute.Int("limit", limit),
 	)
 
-	results, err := r.queries.ListRandomBooks(ctx, limit)
+	results, err := r.queries.ListRandomBooks(ctx, sqlc.ListRandomBooksParams{
+		Genre:      string(genre),
+		SortBy:     string(sort),
+		LimitCount: limit,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("fail to query book by site id: %w", err)
 	}
@@ -444,8 +672,8 @@ func (r *SqlcRepo) FindBooksByRandom(ctx context.Context, limit int) ([]model.Bo
 	bks := make([]model.Book, len(results))
 	for i := range results {
 		var bkErr error
-		if results[i].Data != "" {
-			bkErr = errors.New(results[i].Data)
+		if results[i].ErrorData != "" {
+			bkErr = errors.New(results[i].ErrorData)
 		}
 
 		bks[i] = model.Book{
@@ -455,7 +683,7 @@ func (r *SqlcRepo) FindBooksByRandom(ctx context.Context, limit int) ([]model.Bo
 			Title:    results[i].Title.String,
 			Writer: model.Writer{
 				ID:   int(results[i].WriterID.Int32),
-				Name: results[i].Name,
+				Name: results[i].WriterName,
 			},
 			Type:          results[i].Type.String,
 			UpdateDate:    results[i].UpdateDate.String,
@@ -469,6 +697,59 @@ func (r *SqlcRepo) FindBooksByRandom(ctx context.Context, limit int) ([]model.Bo
 	return bks, nil
 }
 
+func (r *SqlcRepo) FindBooksByGenre(ctx context.Context, params repo.BrowseParams, page repo.PageParams) (*repo.BookPage, error) {
+	_, span := repo.GetTracer().Start(ctx, "find books by genre")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("genre", string(params.Genre)),
+		attribute.String("site", params.Site),
+		attribute.String("status", params.Status),
+		attribute.String("sort", string(page.Sort)),
+		attribute.Int("limit", page.Limit),
+		attribute.String("cursor", page.Cursor),
+	)
+
+	cursor, err := repo.DecodeCursor(page.Cursor, page.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	var position repo.Cursor
+	if cursor != nil {
+		position = *cursor
+	}
+
+	results, err := r.listBooksByGenre(ctx, sqlc.ListBooksByGenreUpdatedParams{
+		Genre:          string(params.Genre),
+		Site:           params.Site,
+		Status:         params.Status,
+		HasCursor:      cursor != nil,
+		Ascending:      position.Ascending(),
+		CursorKey:      position.Key,
+		CursorSite:     position.Site,
+		CursorID:       int32(position.ID),
+		CursorHashCode: int32(position.HashCode),
+		LimitCount:     int32(page.Limit + 1),
+	}, page.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books by genre: %w", err)
+	}
+
+	total, err := r.queries.CountBooksByGenre(ctx, sqlc.CountBooksByGenreParams{
+		Genre:  string(params.Genre),
+		Site:   params.Site,
+		Status: params.Status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to count books by genre: %w", err)
+	}
+
+	bks, keys := listedBooks(results)
+
+	return repo.NewBookPage(bks, keys, page, cursor, int(total)), nil
+}
+
 func (r *SqlcRepo) FindBookGroupByID(ctx context.Context, site string, id int) (model.BookGroup, error) {
 	_, span := repo.GetTracer().Start(ctx, "find book group by id")
 	defer span.End()
@@ -504,6 +785,7 @@ func (r *SqlcRepo) FindBookGroupByID(ctx context.Context, site string, id int) (
 			UpdateChapter: results[i].UpdateChapter.String,
 			Status:        model.StatusFromString(results[i].Status),
 			IsDownloaded:  results[i].IsDownloaded,
+			WorkID:        int(results[i].WorkID),
 			Error:         bkErr,
 		}
 	}
@@ -550,6 +832,7 @@ func (r *SqlcRepo) FindBookGroupByIDHash(ctx context.Context, site string, id, h
 			UpdateChapter: results[i].UpdateChapter.String,
 			Status:        model.StatusFromString(results[i].Status),
 			IsDownloaded:  results[i].IsDownloaded,
+			WorkID:        int(results[i].WorkID),
 			Error:         bkErr,
 		}
 	}
@@ -564,6 +847,56 @@ func (r *SqlcRepo) UpdateBooksStatus(ctx context.Context) error {
 	return r.queries.UpdateBooksStatus(ctx, toSqlString(strconv.Itoa(time.Now().Year()-1)))
 }
 
+// FindBooksWithoutChecksum returns the non error books of all sites with
+// empty checksum, only site, id, hash code and title are loaded
+func (r *SqlcRepo) FindBooksWithoutChecksum(ctx context.Context) (<-chan model.Book, error) {
+	_, span := repo.GetTracer().Start(ctx, "find books without checksum")
+	defer span.End()
+
+	results, err := r.queries.ListBooksWithoutChecksum(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books without checksum: %w", err)
+	}
+
+	bkChan := make(chan model.Book)
+
+	go func() {
+		for i := range results {
+			bkChan <- model.Book{
+				Site:     results[i].Site,
+				ID:       int(results[i].ID),
+				HashCode: int(results[i].HashCode),
+				Title:    results[i].Title,
+			}
+		}
+		close(bkChan)
+	}()
+
+	return bkChan, nil
+}
+
+func (r *SqlcRepo) UpdateBookChecksum(ctx context.Context, bk *model.Book) error {
+	_, span := repo.GetTracer().Start(ctx, "update book checksum")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("book", bk.String()))
+
+	err := r.queries.UpdateBookChecksum(ctx, sqlc.UpdateBookChecksumParams{
+		Site:     bk.Site,
+		ID:       int32(bk.ID),
+		HashCode: int32(bk.HashCode),
+		Checksum: toSqlString(bk.Checksum()),
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return fmt.Errorf("fail to update book checksum: %w", err)
+	}
+
+	return nil
+}
+
 func (r *SqlcRepo) FindAllBookIDs(ctx context.Context, site string) ([]int, error) {
 	_, span := repo.GetTracer().Start(ctx, "find all book ids")
 	defer span.End()
@@ -583,6 +916,218 @@ func (r *SqlcRepo) FindAllBookIDs(ctx context.Context, site string) ([]int, erro
 	return results, nil
 }
 
+// work related
+func (r *SqlcRepo) CreateWork(ctx context.Context, work *model.Work) error {
+	_, span := repo.GetTracer().Start(ctx, "create work")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("params.title", work.Title),
+		attribute.String("params.writer", work.Writer),
+	)
+
+	result, err := r.queries.CreateWork(ctx, sqlc.CreateWorkParams{
+		Title:     work.Title,
+		Writer:    work.Writer,
+		TitleKey:  model.NormalizeTitle(work.Title),
+		WriterKey: model.NormalizeWriter(work.Writer),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to create work: %w", err)
+	}
+
+	work.ID = int(result.ID)
+
+	return nil
+}
+
+func (r *SqlcRepo) FindWorkByID(ctx context.Context, id int) (*model.Work, error) {
+	_, span := repo.GetTracer().Start(ctx, "find work by id")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("id", id))
+
+	result, err := r.queries.GetWork(ctx, int32(id))
+	if err != nil {
+		return nil, fmt.Errorf("fail to query work by id: %w", err)
+	}
+
+	results, err := r.queries.ListBooksByWorkID(ctx, toSqlInt(id))
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books by work id: %w", err)
+	}
+
+	work := &model.Work{
+		ID:     int(result.ID),
+		Title:  result.Title,
+		Writer: result.Writer,
+		Books:  make(model.BookGroup, len(results)),
+	}
+	for i := range results {
+		var bkErr error
+		if results[i].Data != "" {
+			bkErr = errors.New(results[i].Data)
+		}
+
+		work.Books[i] = model.Book{
+			Site:     results[i].Site,
+			ID:       int(results[i].ID),
+			HashCode: int(results[i].HashCode),
+			Title:    results[i].Title.String,
+			Writer: model.Writer{
+				ID:   int(results[i].WriterID.Int32),
+				Name: results[i].Name,
+			},
+			Type:          results[i].Type.String,
+			UpdateDate:    results[i].UpdateDate.String,
+			UpdateChapter: results[i].UpdateChapter.String,
+			Status:        model.StatusFromString(results[i].Status),
+			IsDownloaded:  results[i].IsDownloaded,
+			WorkID:        int(results[i].WorkID),
+			Error:         bkErr,
+		}
+	}
+
+	return work, nil
+}
+
+func (r *SqlcRepo) FindWorkCandidates(ctx context.Context, bk *model.Book) ([]model.Work, error) {
+	_, span := repo.GetTracer().Start(ctx, "find work candidates")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("book", bk.String()))
+
+	results, err := r.queries.ListWorkCandidates(ctx, sqlc.ListWorkCandidatesParams{
+		TitleKey:  model.NormalizeTitle(bk.Title),
+		WriterKey: model.NormalizeWriter(bk.Writer.Name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query work candidates: %w", err)
+	}
+
+	works := make([]model.Work, len(results))
+	for i := range results {
+		works[i] = model.Work{
+			ID:     int(results[i].ID),
+			Title:  results[i].Title,
+			Writer: results[i].Writer,
+		}
+	}
+
+	return works, nil
+}
+
+func (r *SqlcRepo) FindBooksWithoutWork(ctx context.Context, site string) (<-chan model.Book, error) {
+	_, span := repo.GetTracer().Start(ctx, "find books without work")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("site", site))
+
+	results, err := r.queries.ListBooksWithoutWork(ctx, site)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books without work: %w", err)
+	}
+
+	bkChan := make(chan model.Book)
+
+	go func() {
+		for i := range results {
+			var bkErr error
+			if results[i].Data != "" {
+				bkErr = errors.New(results[i].Data)
+			}
+
+			bkChan <- model.Book{
+				Site:     results[i].Site,
+				ID:       int(results[i].ID),
+				HashCode: int(results[i].HashCode),
+				Title:    results[i].Title.String,
+				Writer: model.Writer{
+					ID:   int(results[i].WriterID.Int32),
+					Name: results[i].Name,
+				},
+				Type:          results[i].Type.String,
+				UpdateDate:    results[i].UpdateDate.String,
+				UpdateChapter: results[i].UpdateChapter.String,
+				Status:        model.StatusFromString(results[i].Status),
+				IsDownloaded:  results[i].IsDownloaded,
+				Error:         bkErr,
+			}
+		}
+		close(bkChan)
+	}()
+
+	return bkChan, nil
+}
+
+// LinkBookToWork sets the work of the book. workID 0 removes the book from its work
+func (r *SqlcRepo) LinkBookToWork(ctx context.Context, bk *model.Book, workID int) error {
+	_, span := repo.GetTracer().Start(ctx, "link book to work")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("book", bk.String()),
+		attribute.Int("work_id", workID),
+	)
+
+	param := sql.NullInt32{}
+	if workID > 0 {
+		param = toSqlInt(workID)
+	}
+
+	err := r.queries.UpdateBookWork(ctx, sqlc.UpdateBookWorkParams{
+		Site:     bk.Site,
+		ID:       int32(bk.ID),
+		HashCode: int32(bk.HashCode),
+		WorkID:   param,
+	})
+	if err != nil {
+		return fmt.Errorf("fail to link book to work: %w", err)
+	}
+
+	bk.WorkID = workID
+
+	return nil
+}
+
+// MergeWorks moves all books of source work to target work and removes the source work
+func (r *SqlcRepo) MergeWorks(ctx context.Context, targetID, sourceID int) error {
+	_, span := repo.GetTracer().Start(ctx, "merge works")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("target_id", targetID),
+		attribute.Int("source_id", sourceID),
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("fail to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+
+	err = queries.MoveWorkBooks(ctx, sqlc.MoveWorkBooksParams{
+		WorkID:   toSqlInt(targetID),
+		WorkID_2: toSqlInt(sourceID),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to move work books: %w", err)
+	}
+
+	err = queries.DeleteWork(ctx, int32(sourceID))
+	if err != nil {
+		return fmt.Errorf("fail to delete work: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("fail to commit merge works: %w", err)
+	}
+
+	return nil
+}
+
 // writer related
 func (r *SqlcRepo) SaveWriter(ctx context.Context, writer *model.Writer) error {
 	_, span := repo.GetTracer().Start(ctx, "save writer")
@@ -606,6 +1151,222 @@ func (r *SqlcRepo) SaveWriter(ctx context.Context, writer *model.Writer) error {
 	return nil
 }
 
+func (r *SqlcRepo) FindWriterByID(ctx context.Context, id int) (*model.Writer, error) {
+	_, span := repo.GetTracer().Start(ctx, "find writer by id")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("id", id))
+
+	result, err := r.queries.GetWriter(ctx, int32(id))
+	if err != nil {
+		return nil, fmt.Errorf("fail to query writer by id: %w", err)
+	}
+
+	return &model.Writer{ID: int(result.ID), Name: result.Name}, nil
+}
+
+func (r *SqlcRepo) FindBooksByWriterID(ctx context.Context, writerID int, sort model.BookSort) ([]model.Book, error) {
+	_, span := repo.GetTracer().Start(ctx, "find books by writer id")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("writer_id", writerID), attribute.String("sort", string(sort)))
+
+	results, err := r.queries.ListBooksByWriter(ctx, sqlc.ListBooksByWriterParams{
+		WriterID: toSqlInt(writerID),
+		SortBy:   string(sort),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books by writer id: %w", err)
+	}
+
+	bks := make([]model.Book, len(results))
+	for i := range results {
+		var bkErr error
+		if results[i].ErrorData != "" {
+			bkErr = errors.New(results[i].ErrorData)
+		}
+
+		bks[i] = model.Book{
+			Site:     results[i].Site,
+			ID:       int(results[i].ID),
+			HashCode: int(results[i].HashCode),
+			Title:    results[i].Title.String,
+			Writer: model.Writer{
+				ID:   int(results[i].WriterID.Int32),
+				Name: results[i].WriterName,
+			},
+			Type:          results[i].Type.String,
+			UpdateDate:    results[i].UpdateDate.String,
+			UpdateChapter: results[i].UpdateChapter.String,
+			Status:        model.StatusFromString(results[i].Status),
+			IsDownloaded:  results[i].IsDownloaded,
+			WorkID:        int(results[i].WorkID),
+			Error:         bkErr,
+		}
+	}
+
+	return bks, nil
+}
+
+func (r *SqlcRepo) FindWriters(ctx context.Context, name string, page repo.WriterPageParams) (*repo.WriterPage, error) {
+	_, span := repo.GetTracer().Start(ctx, "find writers")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("name", name),
+		attribute.String("order", string(page.Order)),
+		attribute.Int("limit", page.Limit),
+		attribute.String("cursor", page.Cursor),
+	)
+
+	cursor, err := repo.DecodeWriterCursor(page.Cursor, page.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	var position repo.WriterCursor
+	if cursor != nil {
+		position = *cursor
+	}
+
+	// rows of both orders have the same columns
+	var results []sqlc.ListWritersByBookCountRow
+	if page.Order == model.WriterOrderLatestUpdate {
+		var rows []sqlc.ListWritersByLatestUpdateRow
+		rows, err = r.queries.ListWritersByLatestUpdate(ctx, sqlc.ListWritersByLatestUpdateParams{
+			Names:              searchPatterns(name),
+			HasCursor:          cursor != nil,
+			Backward:           position.Backward,
+			CursorLatestUpdate: position.LatestUpdate,
+			CursorBookCount:    int64(position.BookCount),
+			CursorID:           int32(position.ID),
+			LimitCount:         int32(page.Limit + 1),
+		})
+		for _, row := range rows {
+			results = append(results, sqlc.ListWritersByBookCountRow(row))
+		}
+	} else {
+		results, err = r.queries.ListWritersByBookCount(ctx, sqlc.ListWritersByBookCountParams{
+			Names:           searchPatterns(name),
+			HasCursor:       cursor != nil,
+			Backward:        position.Backward,
+			CursorBookCount: int64(position.BookCount),
+			CursorID:        int32(position.ID),
+			LimitCount:      int32(page.Limit + 1),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to query writers: %w", err)
+	}
+
+	writers := make([]model.WriterSummary, len(results))
+	for i := range results {
+		writers[i] = model.WriterSummary{
+			ID:           int(results[i].ID),
+			Name:         results[i].Name,
+			BookCount:    int(results[i].BookCount),
+			LatestUpdate: results[i].LatestUpdate,
+		}
+	}
+
+	return repo.NewWriterPage(writers, page, cursor), nil
+}
+
+// FindWritersWithoutChecksum returns the writers with empty checksum
+func (r *SqlcRepo) FindWritersWithoutChecksum(ctx context.Context) (<-chan model.Writer, error) {
+	_, span := repo.GetTracer().Start(ctx, "find writers without checksum")
+	defer span.End()
+
+	results, err := r.queries.ListWritersWithoutChecksum(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query writers without checksum: %w", err)
+	}
+
+	writerChan := make(chan model.Writer)
+
+	go func() {
+		for i := range results {
+			writerChan <- model.Writer{ID: int(results[i].ID), Name: results[i].Name}
+		}
+		close(writerChan)
+	}()
+
+	return writerChan, nil
+}
+
+func (r *SqlcRepo) UpdateWriterChecksum(ctx context.Context, writer *model.Writer) error {
+	_, span := repo.GetTracer().Start(ctx, "update writer checksum")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("writer_id", writer.ID))
+
+	err := r.queries.UpdateWriterChecksum(ctx, sqlc.UpdateWriterChecksumParams{
+		ID:       int32(writer.ID),
+		Checksum: toSqlString(writer.Checksum()),
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return fmt.Errorf("fail to update writer checksum: %w", err)
+	}
+
+	return nil
+}
+
+// genre related
+func (r *SqlcRepo) SyncGenres(ctx context.Context, site string) (int, error) {
+	_, span := repo.GetTracer().Start(ctx, "sync genres")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("site", site))
+
+	types, err := r.queries.ListUnmappedBookTypes(ctx, site)
+	if err != nil {
+		return 0, fmt.Errorf("fail to query unmapped book types: %w", err)
+	}
+
+	for _, bookType := range types {
+		err := r.queries.CreateGenre(ctx, sqlc.CreateGenreParams{
+			Site:  site,
+			Type:  bookType,
+			Genre: string(model.NormalizeGenre(bookType)),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("fail to create genre of %s: %w", bookType, err)
+		}
+	}
+
+	return len(types), nil
+}
+
+// health related
+func (r *SqlcRepo) SaveSiteHealth(ctx context.Context, health *model.SiteHealth) error {
+	_, span := repo.GetTracer().Start(ctx, "save site health")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("params.site", health.Site),
+		attribute.String("params.status", string(health.Status)),
+	)
+
+	err := r.queries.SaveSiteHealth(ctx, sqlc.SaveSiteHealthParams{
+		Site:      health.Site,
+		Status:    string(health.Status),
+		Phase:     health.Phase,
+		Checked:   int32(health.Checked),
+		Failed:    int32(health.Failed),
+		Error:     health.Error,
+		CheckedAt: health.CheckedAt,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("fail to save site health: %w", err)
+	}
+
+	return nil
+}
+
 // error related
 func (r *SqlcRepo) SaveError(ctx context.Context, bk *model.Book, e error) error {
 	_, span := repo.GetTracer().Start(ctx, "save error")
@@ -762,6 +1523,23 @@ func (r *SqlcRepo) Stats(ctx context.Context, site string) repo.Summary {
 	writerStat, _ := r.queries.WritersStat(ctx, site)
 	writerStatSpan.End()
 
+	_, healthSpan := repo.GetTracer().Start(ctx, "get site health")
+	healthStat, healthErr := r.queries.GetSiteHealth(ctx, site)
+	healthSpan.End()
+
+	var health *model.SiteHealth
+	if healthErr == nil {
+		health = &model.SiteHealth{
+			Site:      healthStat.Site,
+			Status:    model.HealthStatus(healthStat.Status),
+			Phase:     healthStat.Phase,
+			Checked:   int(healthStat.Checked),
+			Failed:    int(healthStat.Failed),
+			Error:     healthStat.Error,
+			CheckedAt: healthStat.CheckedAt,
+		}
+	}
+
 	StatusCount := make(map[model.StatusCode]int)
 	for i := range bkStatusStat {
 		StatusCount[model.StatusFromString(bkStatusStat[i].Status)] = int(bkStatusStat[i].Count)
@@ -776,6 +1554,7 @@ func (r *SqlcRepo) Stats(ctx context.Context, site string) repo.Summary {
 		DownloadCount:   int(downloadedBkStat),
 		WriterCount:     int(writerStat),
 		StatusCount:     StatusCount,
+		Health:          health,
 	}
 }
 
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/htchan/BookSpider/internal/model"
@@ -173,6 +176,50 @@ func TestSqlcRepo_CreateBook(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_CreateBookWithNextID(t *testing.T) {
+	db, err := OpenDatabaseByConfig(conf)
+	if !assert.NoError(t, err, "Failed to open database") {
+		t.FailNow()
+	}
+
+	site := "bk/create-next-id"
+
+	t.Cleanup(func() {
+		db.Exec("delete from books where site=$1", site)
+
+		db.Close()
+	})
+
+	t.Parallel()
+
+	r := NewRepo(db)
+
+	bk := model.Book{Site: site, ID: 100, HashCode: 100, Title: "first", Status: model.StatusEnd}
+	assert.NoError(t, r.CreateBookWithNextID(context.Background(), &bk))
+	assert.Equal(t, model.Book{Site: site, ID: 1, HashCode: 0, Title: "first", Status: model.StatusEnd}, bk)
+
+	t.Run("concurrent creates take different ids", func(t *testing.T) {
+		const count = 5
+
+		ids := make([]int, count)
+		var wg sync.WaitGroup
+		for i := range count {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				bk := model.Book{Site: site, Title: "concurrent", Status: model.StatusEnd}
+				assert.NoError(t, r.CreateBookWithNextID(context.Background(), &bk))
+				ids[i] = bk.ID
+			}()
+		}
+		wg.Wait()
+
+		slices.Sort(ids)
+		assert.Equal(t, []int{2, 3, 4, 5, 6}, ids)
+	})
+}
+
 func TestSqlcRepo_UpdateBook(t *testing.T) {
 	db, err := OpenDatabaseByConfig(conf)
 	if !assert.NoError(t, err, "Failed to open database") {
@@ -549,15 +596,26 @@ func TestSqlcRepo_FindBooksByTitleWriter(t *testing.T) {
 	bksDB := stubData(t, NewRepo(db), site)
 	bksDBV2 := stubData(t, NewRepo(db), siteV2)
 
+	traditionalBk := model.Book{
+		Site: site, ID: 5, HashCode: 0,
+		Title: "鬥破蒼穹", Writer: model.Writer{Name: site + " 天蠶土豆"}, Type: "type 5",
+		UpdateDate: "date 0", UpdateChapter: "chapter 5",
+		Status: model.StatusInProgress, IsDownloaded: false, Error: nil,
+	}
+	if !assert.NoError(t, NewRepo(db).SaveWriter(t.Context(), &traditionalBk.Writer)) ||
+		!assert.NoError(t, NewRepo(db).CreateBook(t.Context(), &traditionalBk)) {
+		t.FailNow()
+	}
+
 	t.Parallel()
 	tests := []struct {
 		name         string
 		r            repo.Repository
 		title        string
 		writer       string
-		limit        int
-		offset       int
+		page         repo.PageParams
 		expectResult []model.Book
+		expectTotal  int
 		expectErr    bool
 	}{
 		{
@@ -565,57 +623,147 @@ func TestSqlcRepo_FindBooksByTitleWriter(t *testing.T) {
 			r:      NewRepo(db),
 			title:  "title",
 			writer: "writer",
-			limit:  10,
-			offset: 0,
+			page:   repo.PageParams{Sort: model.BookSortUpdated, Limit: 10},
 			expectResult: []model.Book{
 				bksDBV2[3], bksDB[3],
 				bksDBV2[2], bksDB[2],
 				bksDBV2[1], bksDB[1],
 				bksDBV2[0], bksDB[0],
 			},
-			expectErr: false,
+			expectTotal: 8,
+			expectErr:   false,
 		},
 		{
 			name:         "works with limit",
 			r:            NewRepo(db),
 			title:        "title",
 			writer:       "writer",
-			limit:        1,
-			offset:       0,
+			page:         repo.PageParams{Sort: model.BookSortUpdated, Limit: 1},
 			expectResult: []model.Book{bksDBV2[3]},
+			expectTotal:  8,
 			expectErr:    false,
 		},
 		{
-			name:         "works with offset",
-			r:            NewRepo(db),
-			title:        "title",
-			writer:       "writer",
-			limit:        1,
-			offset:       1,
-			expectResult: []model.Book{bksDB[3]},
-			expectErr:    false,
+			name:   "works with sort by id",
+			r:      NewRepo(db),
+			title:  "title",
+			writer: "writer",
+			page:   repo.PageParams{Sort: model.BookSortID, Limit: 3},
+			expectResult: []model.Book{
+				bksDBV2[3], bksDB[3], bksDBV2[2],
+			},
+			expectTotal: 8,
+			expectErr:   false,
 		},
 		{
 			name:         "return all books match either title or writer",
 			r:            NewRepo(db),
 			title:        "title 1",
 			writer:       "writer 3",
-			limit:        5,
-			offset:       0,
+			page:         repo.PageParams{Sort: model.BookSortUpdated, Limit: 5},
 			expectResult: []model.Book{bksDBV2[3], bksDB[3], bksDBV2[0], bksDB[0]},
+			expectTotal:  4,
+			expectErr:    false,
+		},
+		{
+			name:         "match title in other script",
+			r:            NewRepo(db),
+			title:        "斗破苍穹",
+			writer:       "",
+			page:         repo.PageParams{Sort: model.BookSortUpdated, Limit: 5},
+			expectResult: []model.Book{traditionalBk},
+			expectTotal:  1,
+			expectErr:    false,
+		},
+		{
+			name:         "match writer in other script",
+			r:            NewRepo(db),
+			title:        "",
+			writer:       "天蚕土豆",
+			page:         repo.PageParams{Sort: model.BookSortUpdated, Limit: 5},
+			expectResult: []model.Book{traditionalBk},
+			expectTotal:  1,
 			expectErr:    false,
 		},
+		{
+			name:      "invalid cursor",
+			r:         NewRepo(db),
+			title:     "title",
+			page:      repo.PageParams{Sort: model.BookSortUpdated, Limit: 5, Cursor: "invalid"},
+			expectErr: true,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := test.r.FindBooksByTitleWriter(context.Background(), test.title, test.writer, test.limit, test.offset)
+			result, err := test.r.FindBooksByTitleWriter(context.Background(), test.title, test.writer, "", test.page)
 			if (err != nil) != test.expectErr {
 				t.Errorf("got error: %v; want err: %v", err, test.expectErr)
 			}
-			assert.Equal(t, test.expectResult, result)
+			if test.expectErr {
+				return
+			}
+			assert.Equal(t, test.expectResult, result.Books)
+			assert.Equal(t, test.expectTotal, result.Total)
 		})
 	}
+
+	t.Run("walk pages by cursor", func(t *testing.T) {
+		r := NewRepo(db)
+		params := repo.PageParams{Sort: model.BookSortUpdated, Limit: 3}
+
+		first, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Book{bksDBV2[3], bksDB[3], bksDBV2[2]}, first.Books)
+		assert.Empty(t, first.PrevCursor)
+
+		params.Cursor = first.NextCursor
+		second, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Book{bksDB[2], bksDBV2[1], bksDB[1]}, second.Books)
+
+		params.Cursor = second.NextCursor
+		last, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", params)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Book{bksDBV2[0], bksDB[0]}, last.Books)
+		assert.Empty(t, last.NextCursor)
+
+		params.Cursor = last.PrevCursor
+		prev, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", params)
+		assert.NoError(t, err)
+		assert.Equal(t, second.Books, prev.Books)
+	})
+
+	t.Run("walk pages of every sort", func(t *testing.T) {
+		r := NewRepo(db)
+		for _, sort := range []model.BookSort{
+			model.BookSortUpdated, model.BookSortTitle, model.BookSortID, model.BookSortDiscovered,
+		} {
+			all, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", repo.PageParams{Sort: sort, Limit: 10})
+			if !assert.NoError(t, err, sort) || !assert.Len(t, all.Books, 8, sort) {
+				continue
+			}
+
+			params := repo.PageParams{Sort: sort, Limit: 3}
+			var walked []model.Book
+			var lastPage *repo.BookPage
+			for range 3 {
+				page, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", params)
+				if !assert.NoError(t, err, sort) {
+					break
+				}
+				walked = append(walked, page.Books...)
+				lastPage, params.Cursor = page, page.NextCursor
+			}
+			assert.Equal(t, all.Books, walked, sort)
+			assert.Empty(t, lastPage.NextCursor, sort)
+
+			params.Cursor = lastPage.PrevCursor
+			prev, err := r.FindBooksByTitleWriter(t.Context(), "title", "writer", "", params)
+			assert.NoError(t, err, sort)
+			assert.Equal(t, all.Books[3:6], prev.Books, sort)
+		}
+	})
 }
 
 func TestSqlcRepo_FindBooksByRandom(t *testing.T) {
@@ -647,6 +795,7 @@ func TestSqlcRepo_FindBooksByRandom(t *testing.T) {
 	tests := []struct {
 		name         string
 		r            repo.Repository
+		sort         model.BookSort
 		limit        int
 		expectLength int
 		expectErr    bool
@@ -654,6 +803,15 @@ func TestSqlcRepo_FindBooksByRandom(t *testing.T) {
 		{
 			name:         "works",
 			r:            NewRepo(db),
+			sort:         model.BookSortUpdated,
+			limit:        10,
+			expectLength: 4,
+			expectErr:    false,
+		},
+		{
+			name:         "works with sort by title",
+			r:            NewRepo(db),
+			sort:         model.BookSortTitle,
 			limit:        10,
 			expectLength: 4,
 			expectErr:    false,
@@ -662,7 +820,7 @@ func TestSqlcRepo_FindBooksByRandom(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := test.r.FindBooksByRandom(context.Background(), test.limit)
+			result, err := test.r.FindBooksByRandom(context.Background(), "", test.sort, test.limit)
 			if (err != nil) != test.expectErr {
 				t.Errorf("got error: %v; want err: %v", err, test.expectErr)
 			}
@@ -840,6 +998,84 @@ func TestSqlcRepo_FindAllBookIDs(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_Works(t *testing.T) {
+	db, err := OpenDatabaseByConfig(conf)
+	if !assert.NoError(t, err, "Failed to open database") {
+		t.FailNow()
+	}
+	site := "work/link"
+
+	t.Cleanup(func() {
+		db.Exec("delete from works where id in (select work_id from books where site=$1)", site)
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
+
+		db.Close()
+	})
+
+	r := NewRepo(db)
+	bksDB := stubData(t, r, site)
+
+	work := &model.Work{Title: bksDB[0].Title, Writer: bksDB[0].Writer.Name}
+	err = r.CreateWork(t.Context(), work)
+	if !assert.NoError(t, err) || !assert.Greater(t, work.ID, 0) {
+		t.FailNow()
+	}
+
+	otherWork := &model.Work{Title: bksDB[1].Title, Writer: bksDB[1].Writer.Name}
+	err = r.CreateWork(t.Context(), otherWork)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	candidates, err := r.FindWorkCandidates(t.Context(), &bksDB[0])
+	assert.NoError(t, err)
+	assert.Contains(t, candidates, model.Work{ID: work.ID, Title: work.Title, Writer: work.Writer})
+
+	assert.NoError(t, r.LinkBookToWork(t.Context(), &bksDB[0], work.ID))
+	assert.NoError(t, r.LinkBookToWork(t.Context(), &bksDB[1], otherWork.ID))
+	assert.Equal(t, work.ID, bksDB[0].WorkID)
+
+	group, err := r.FindBookGroupByID(t.Context(), site, 3)
+	assert.NoError(t, err)
+	assert.Len(t, group, 1)
+
+	assert.NoError(t, r.LinkBookToWork(t.Context(), &bksDB[3], work.ID))
+	group, err = r.FindBookGroupByID(t.Context(), site, 3)
+	assert.NoError(t, err)
+	assert.Len(t, group, 2)
+
+	// search lists the work once with its sources
+	bkPage, err := r.FindBooksByTitleWriter(t.Context(), "", site+" writer", "", repo.PageParams{Sort: model.BookSortUpdated, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bksDB[3], bksDB[2]}, bkPage.Books)
+	assert.Equal(t, 3, bkPage.Total)
+	assert.Equal(t, map[int]model.BookGroup{work.ID: {bksDB[0], bksDB[3]}}, bkPage.Sources)
+
+	bkPage, err = r.FindBooksByTitleWriter(t.Context(), "", site+" writer", "", repo.PageParams{Sort: model.BookSortUpdated, Limit: 2, Cursor: bkPage.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bksDB[1]}, bkPage.Books)
+	assert.Equal(t, map[int]model.BookGroup{otherWork.ID: {bksDB[1]}}, bkPage.Sources)
+
+	bkChan, err := r.FindBooksWithoutWork(t.Context(), site)
+	assert.NoError(t, err)
+	var unlinked []string
+	for bk := range bkChan {
+		unlinked = append(unlinked, bk.String())
+	}
+	assert.Equal(t, []string{bksDB[2].String()}, unlinked)
+
+	assert.NoError(t, r.MergeWorks(t.Context(), work.ID, otherWork.ID))
+
+	merged, err := r.FindWorkByID(t.Context(), work.ID)
+	assert.NoError(t, err)
+	assert.Len(t, merged.Books, 3)
+
+	_, err = r.FindWorkByID(t.Context(), otherWork.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
 func TestSqlcRepo_SaveWriter(t *testing.T) {
 	db, err := OpenDatabaseByConfig(conf)
 	if !assert.NoError(t, err, "Failed to open database") {
@@ -891,6 +1127,272 @@ func TestSqlcRepo_SaveWriter(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_Writers(t *testing.T) {
+	db, err := OpenDatabaseByConfig(conf)
+	if !assert.NoError(t, err, "Failed to open database") {
+		t.FailNow()
+	}
+	site := "writer/find"
+
+	t.Cleanup(func() {
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
+
+		db.Close()
+	})
+
+	r := NewRepo(db)
+	bksDB := stubData(t, r, site)
+
+	writer, err := r.FindWriterByID(t.Context(), bksDB[0].Writer.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, &bksDB[0].Writer, writer)
+
+	_, err = r.FindWriterByID(t.Context(), -1)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	bks, err := r.FindBooksByWriterID(t.Context(), bksDB[1].Writer.ID, model.BookSortUpdated)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bksDB[1]}, bks)
+
+	writerPage, err := r.FindWriters(t.Context(), site+" writer 2", repo.WriterPageParams{Order: model.WriterOrderBookCount, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.WriterSummary{
+		{ID: bksDB[1].Writer.ID, Name: bksDB[1].Writer.Name, BookCount: 1, LatestUpdate: bksDB[1].UpdateDate},
+		{ID: bksDB[2].Writer.ID, Name: bksDB[2].Writer.Name, BookCount: 1, LatestUpdate: bksDB[2].UpdateDate},
+	}, writerPage.Writers)
+	assert.Empty(t, writerPage.NextCursor)
+	assert.Empty(t, writerPage.PrevCursor)
+
+	names := func(writers []model.WriterSummary) []string {
+		var names []string
+		for _, writer := range writers {
+			names = append(names, writer.Name)
+		}
+		return names
+	}
+
+	params := repo.WriterPageParams{Order: model.WriterOrderLatestUpdate, Limit: 2}
+	writerPage, err = r.FindWriters(t.Context(), site+" writer", params)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{bksDB[3].Writer.Name, bksDB[2].Writer.Name}, names(writerPage.Writers))
+	assert.Empty(t, writerPage.PrevCursor)
+
+	params.Cursor = writerPage.NextCursor
+	writerPage, err = r.FindWriters(t.Context(), site+" writer", params)
+	assert.NoError(t, err)
+	assert.Equal(t, bksDB[1].Writer.Name, writerPage.Writers[0].Name)
+
+	params.Cursor = writerPage.PrevCursor
+	writerPage, err = r.FindWriters(t.Context(), site+" writer", params)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{bksDB[3].Writer.Name, bksDB[2].Writer.Name}, names(writerPage.Writers))
+
+	params.Cursor = "invalid"
+	_, err = r.FindWriters(t.Context(), site+" writer", params)
+	assert.ErrorIs(t, err, repo.ErrInvalidCursor)
+}
+
+func TestSqlcRepo_Checksums(t *testing.T) {
+	db, err := OpenDatabaseByConfig(conf)
+	if !assert.NoError(t, err, "Failed to open database") {
+		t.FailNow()
+	}
+	site := "checksum/rebuild"
+
+	t.Cleanup(func() {
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
+
+		db.Close()
+	})
+
+	r := NewRepo(db)
+	bksDB := stubData(t, r, site)
+
+	_, err = db.Exec("update books set checksum='' where site=$1", site)
+	assert.NoError(t, err)
+	_, err = db.Exec("update writers set checksum=null where id=$1", bksDB[0].Writer.ID)
+	assert.NoError(t, err)
+
+	bkChan, err := r.FindBooksWithoutChecksum(t.Context())
+	assert.NoError(t, err)
+	var bks []string
+	for bk := range bkChan {
+		if bk.Site == site {
+			bks = append(bks, bk.String())
+		}
+	}
+	assert.Equal(t, []string{bksDB[0].String(), bksDB[1].String(), bksDB[2].String(), bksDB[3].String()}, bks)
+
+	writerChan, err := r.FindWritersWithoutChecksum(t.Context())
+	assert.NoError(t, err)
+	var writers []model.Writer
+	for writer := range writerChan {
+		writers = append(writers, writer)
+	}
+	assert.Contains(t, writers, bksDB[0].Writer)
+
+	assert.NoError(t, r.UpdateBookChecksum(t.Context(), &bksDB[0]))
+	assert.NoError(t, r.UpdateWriterChecksum(t.Context(), &bksDB[0].Writer))
+
+	var bkChecksum, writerChecksum string
+	err = db.QueryRow("select checksum from books where site=$1 and id=$2 and hash_code=$3", site, bksDB[0].ID, bksDB[0].HashCode).Scan(&bkChecksum)
+	assert.NoError(t, err)
+	assert.Equal(t, bksDB[0].Checksum(), bkChecksum)
+
+	err = db.QueryRow("select checksum from writers where id=$1", bksDB[0].Writer.ID).Scan(&writerChecksum)
+	assert.NoError(t, err)
+	assert.Equal(t, bksDB[0].Writer.Checksum(), writerChecksum)
+}
+
+func TestSqlcRepo_Genres(t *testing.T) {
+	db, err := OpenDatabaseByConfig(conf)
+	if !assert.NoError(t, err, "Failed to open database") {
+		t.FailNow()
+	}
+	site := "genre/find"
+
+	t.Cleanup(func() {
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from genres where site=$1", site)
+
+		db.Close()
+	})
+
+	r := NewRepo(db)
+	bks := []model.Book{
+		{
+			Site: site, ID: 1, Title: "genre book 1", Writer: model.Writer{Name: site + " writer"},
+			Type: "都市小说", UpdateDate: "date 1", Status: model.StatusEnd, IsDownloaded: true,
+		},
+		{
+			Site: site, ID: 2, Title: "genre book 2", Writer: model.Writer{Name: site + " writer"},
+			Type: "都市言情", UpdateDate: "date 2", Status: model.StatusInProgress,
+		},
+		{
+			Site: site, ID: 3, Title: "genre book 3", Writer: model.Writer{Name: site + " writer"},
+			Type: "玄幻魔法", UpdateDate: "date 3", Status: model.StatusEnd, IsDownloaded: true,
+		},
+		{
+			Site: site, ID: 4, Title: "genre book 0", Writer: model.Writer{Name: site + " writer"},
+			Type: "都市小说", UpdateDate: "date 0", Status: model.StatusInProgress, IsDownloaded: true,
+		},
+	}
+	for i := range bks {
+		if !assert.NoError(t, r.SaveWriter(t.Context(), &bks[i].Writer)) ||
+			!assert.NoError(t, r.CreateBook(t.Context(), &bks[i])) {
+			t.FailNow()
+		}
+	}
+
+	page := repo.PageParams{Sort: model.BookSortUpdated, Limit: 10}
+
+	// unmapped types are browsed as other genre
+	result, err := r.FindBooksByGenre(t.Context(), repo.BrowseParams{Genre: model.GenreUrban, Site: site}, page)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Books)
+	assert.Equal(t, 0, result.Total)
+
+	count, err := r.SyncGenres(t.Context(), site)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = r.SyncGenres(t.Context(), site)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	result, err = r.FindBooksByGenre(t.Context(), repo.BrowseParams{Genre: model.GenreUrban, Site: site}, page)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[0], bks[3]}, result.Books)
+	assert.Equal(t, 2, result.Total)
+
+	result, err = r.FindBooksByGenre(
+		t.Context(), repo.BrowseParams{Genre: model.GenreUrban, Site: site},
+		repo.PageParams{Sort: model.BookSortTitle, Limit: 1},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[3]}, result.Books)
+	assert.Equal(t, 2, result.Total)
+
+	result, err = r.FindBooksByGenre(
+		t.Context(), repo.BrowseParams{Genre: model.GenreUrban, Site: site},
+		repo.PageParams{Sort: model.BookSortTitle, Limit: 1, Cursor: result.NextCursor},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[0]}, result.Books)
+	assert.Empty(t, result.NextCursor)
+
+	result, err = r.FindBooksByGenre(
+		t.Context(), repo.BrowseParams{Genre: model.GenreUrban, Site: site},
+		repo.PageParams{Sort: model.BookSortID, Limit: 10},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[3], bks[0]}, result.Books)
+
+	result, err = r.FindBooksByGenre(
+		t.Context(), repo.BrowseParams{Genre: model.GenreUrban, Site: site, Status: model.StatusEndKey}, page,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[0]}, result.Books)
+
+	result, err = r.FindBooksByGenre(t.Context(), repo.BrowseParams{Genre: model.GenreRomance, Site: site}, page)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[1]}, result.Books)
+
+	result, err = r.FindBooksByTitleWriter(t.Context(), "genre book", "", model.GenreFantasy, page)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[2]}, result.Books)
+
+	randomResult, err := r.FindBooksByRandom(t.Context(), model.GenreFantasy, model.BookSortUpdated, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Book{bks[2]}, randomResult)
+}
+
+func TestSqlcRepo_SaveSiteHealth(t *testing.T) {
+	db, err := OpenDatabaseByConfig(conf)
+	if !assert.NoError(t, err, "Failed to open database") {
+		t.FailNow()
+	}
+	site := "health/save"
+
+	t.Cleanup(func() {
+		db.Exec("delete from site_health where site=$1", site)
+
+		db.Close()
+	})
+
+	r := NewRepo(db)
+	assert.Nil(t, r.Stats(t.Context(), site).Health)
+
+	// timestamp is read back in other location, compare the instant only
+	assertHealth := func(want model.SiteHealth) {
+		got := r.Stats(t.Context(), site).Health
+		if assert.NotNil(t, got) {
+			assert.True(t, want.CheckedAt.Equal(got.CheckedAt))
+			got.CheckedAt = want.CheckedAt
+			assert.Equal(t, want, *got)
+		}
+	}
+
+	checkedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	healthy := model.SiteHealth{
+		Site: site, Status: model.HealthHealthy, Phase: "canary", Checked: 3, CheckedAt: checkedAt,
+	}
+	assert.NoError(t, r.SaveSiteHealth(t.Context(), &healthy))
+	assertHealth(healthy)
+
+	broken := model.SiteHealth{
+		Site: site, Status: model.HealthBroken, Phase: "update", Checked: 20, Failed: 18,
+		Error: "book fields not found", CheckedAt: checkedAt.Add(time.Hour),
+	}
+	assert.NoError(t, r.SaveSiteHealth(t.Context(), &broken))
+	assertHealth(broken)
+}
+
 func TestSqlcRepo_SaveError(t *testing.T) {
 	db, err := OpenDatabaseByConfig(conf)
 	if !assert.NoError(t, err, "Failed to open database") {
@@ -11,4 +11,5 @@ type Summary struct {
 	LatestSuccessID int
 	DownloadCount   int
 	StatusCount     map[model.StatusCode]int
+	Health          *model.SiteHealth `json:",omitempty"` // nil if selectors of site are never checked
 }
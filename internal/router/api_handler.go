@@ -3,9 +3,15 @@ package router
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/BookSpider/internal/format"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
@@ -50,6 +56,10 @@ func SiteInfoAPIHandler(res http.ResponseWriter, req *http.Request) {
 // @Accept			json
 // @Produce		json
 // @Param			siteName	path		string	true	"site name"
+// @Param			genre		query		string	false	"genre of books"
+// @Param			sort		query		string	false	"order of books"	Enums(updated, title, id, discovered)
+// @Param			cursor		query		string	false	"cursor of page, from next or prev of last response"
+// @Param			per_page	query		int		false	"items per page, at most 100"
 // @Success		200			{object}	booksResp
 // @Failure		400			{object}	errResp
 // @Router			/api/book-spider/sites/{siteName}/books/search [get]
@@ -58,24 +68,45 @@ func BookSearchAPIHandler(res http.ResponseWriter, req *http.Request) {
 	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
 	title := req.Context().Value(ContextKeyTitle).(string)
 	writer := req.Context().Value(ContextKeyWriter).(string)
-	limit := req.Context().Value(ContextKeyLimit).(int)
-	offset := req.Context().Value(ContextKeyOffset).(int)
 
-	bks, err := serv.SearchBooks(req.Context(), title, writer, limit, offset)
+	page, err := serv.SearchBooks(req.Context(), title, writer, genreFromContext(req.Context()), pageParamsFromContext(req.Context()))
 	if err != nil {
 		logger.Error().Err(err).Msg("query books failed")
 		writeError(res, 400, err)
 	} else {
-		json.NewEncoder(res).Encode(booksResp{bks})
+		json.NewEncoder(res).Encode(newBooksPageResp(req, page))
 	}
 }
 
+// @Summary		Download search result bundle
+// @description	download all downloaded books of search result as a zip with manifest.json
+// @Tags			book-spider-api
+// @Produce		application/zip
+// @Param			siteName	path		string	true	"site name"
+// @Param			title		query		string	false	"title of books, either title or writer is required"
+// @Param			writer		query		string	false	"writer of books, either title or writer is required"
+// @Param			format		query		string	false	"format of books"	Enums(txt, epub)
+// @Param			script		query		string	false	"script of books"	Enums(original, s, t)
+// @Success		200			{file}		binary
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/bundle [get]
+func BookBundleAPIHandler(res http.ResponseWriter, req *http.Request) {
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	title := req.Context().Value(ContextKeyTitle).(string)
+	writer := req.Context().Value(ContextKeyWriter).(string)
+
+	serveBookBundle(res, req, serv, title, writer, downloadFormatFromContext(req.Context()), scriptFromContext(req.Context()))
+}
+
 // @Summary		List random books
 // @description	list random books
 // @Tags			book-spider-api
 // @Accept			json
 // @Produce		json
 // @Param			siteName	path		string	true	"site name"
+// @Param			genre		query		string	false	"genre of books"
+// @Param			sort		query		string	false	"order of books"	Enums(updated, title, id, discovered)
+// @Param			per_page	query		int		false	"items per page, at most 100"
 // @Success		200			{object}	booksResp
 // @Failure		400			{object}	errResp
 // @Router			/api/book-spider/sites/{siteName}/books/random [get]
@@ -84,12 +115,12 @@ func BookRandomAPIHandler(res http.ResponseWriter, req *http.Request) {
 	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
 	limit := req.Context().Value(ContextKeyLimit).(int)
 
-	bks, err := serv.RandomBooks(req.Context(), limit)
+	bks, err := serv.RandomBooks(req.Context(), genreFromContext(req.Context()), bookSortFromContext(req.Context()), limit)
 	if err != nil {
 		logger.Error().Err(err).Msg("random books railed")
 		writeError(res, 400, err)
 	} else {
-		json.NewEncoder(res).Encode(booksResp{bks})
+		json.NewEncoder(res).Encode(booksResp{Books: bks})
 	}
 }
 
@@ -109,12 +140,14 @@ func BookInfoAPIHandler(res http.ResponseWriter, req *http.Request) {
 }
 
 // @Summary		Download book
-// @description	download book in txt format
+// @description	download book in txt, epub, fb2, html or markdown format
 // @Tags			book-spider-api
 // @Accept			json
 // @Produce		json
 // @Param			siteName	path		string	true	"site name"
 // @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Param			format		query		string	false	"format of content"	Enums(txt, epub, fb2, html, markdown)
+// @Param			script		query		string	false	"script of content"	Enums(original, s, t)
 // @Success		200			{string}	string "the book content"
 // @Failure		400			{object}	errResp
 // @Router			/api/book-spider/sites/{siteName}/books/{idHash}/download [get]
@@ -122,15 +155,372 @@ func BookDownloadAPIHandler(res http.ResponseWriter, req *http.Request) {
 	logger := zerolog.Ctx(req.Context())
 	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
 	bk := req.Context().Value(ContextKeyBook).(*model.Book)
-	content, err := serv.BookContent(req.Context(), bk)
+	file, err := serv.BookFile(req.Context(), bk)
 	if err != nil {
 		logger.Error().Err(err).Msg("book content failed")
 		writeError(res, 400, err)
+		return
+	}
+	defer file.Close()
+
+	serveBookContent(res, req, bk, file, downloadFormatFromContext(req.Context()), scriptFromContext(req.Context()))
+}
+
+// @Summary		Book cover
+// @description	generated cover image of book showing title, writer and site
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		png
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Param			script		query		string	false	"script of title and writer"	Enums(original, s, t)
+// @Success		200			{file}		binary	"the cover image"
+// @Success		304			{string}	string	"cover not modified"
+// @Failure		500			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/cover [get]
+func BookCoverAPIHandler(res http.ResponseWriter, req *http.Request) {
+	bk := req.Context().Value(ContextKeyBook).(*model.Book)
+	serveBookCover(res, req, bk, scriptFromContext(req.Context()))
+}
+
+// @Summary		List book chapters
+// @description	list chapter titles of downloaded book
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Param			script		query		string	false	"script of titles"	Enums(original, s, t)
+// @Success		200			{object}	chaptersResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/chapters [get]
+func BookChaptersAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	bk := req.Context().Value(ContextKeyBook).(*model.Book)
+	script := scriptFromContext(req.Context())
+
+	chapters, err := serv.BookChapters(req.Context(), bk)
+	if err != nil {
+		logger.Error().Err(err).Msg("book chapters failed")
+		writeError(res, 400, err)
+		return
+	}
+
+	resp := chaptersResp{Chapters: make([]chapterResp, len(chapters))}
+	for i, chapter := range chapters {
+		resp.Chapters[i] = chapterResp{Index: i, Title: script.Convert(chapter.Title)}
+	}
+	json.NewEncoder(res).Encode(resp)
+}
+
+// @Summary		Read book chapter
+// @description	read a chapter of downloaded book
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Param			index		path		int		true	"chapter index"
+// @Param			script		query		string	false	"script of content"	Enums(original, s, t)
+// @Success		200			{object}	chapterResp
+// @Failure		400			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/chapters/{index} [get]
+func BookChapterAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	bk := req.Context().Value(ContextKeyBook).(*model.Book)
+	script := scriptFromContext(req.Context())
+
+	index, err := strconv.Atoi(chi.URLParam(req, "index"))
+	if err != nil {
+		writeError(res, 400, InvalidParamsError)
+		return
+	}
+
+	if index < 0 {
+		writeError(res, http.StatusNotFound, RecordNotFoundError)
+		return
+	}
+
+	file, err := serv.BookFile(req.Context(), bk)
+	if err != nil {
+		logger.Error().Err(err).Msg("book file failed")
+		writeError(res, 400, err)
+		return
+	}
+	defer file.Close()
+
+	// chapters after the index are not read
+	fileReader, err := model.NewBookFileReader(file)
+	if err != nil {
+		logger.Error().Err(err).Msg("read book file failed")
+		writeError(res, 400, err)
+		return
+	}
+
+	var chapter model.Chapter
+	for i := 0; i <= index; i++ {
+		chapter, err = fileReader.Next()
+		if errors.Is(err, io.EOF) {
+			writeError(res, http.StatusNotFound, RecordNotFoundError)
+			return
+		} else if err != nil {
+			logger.Error().Err(err).Msg("read book chapter failed")
+			writeError(res, 400, err)
+			return
+		}
+	}
+
+	json.NewEncoder(res).Encode(chapterResp{
+		Index:   index,
+		Title:   script.Convert(chapter.Title),
+		Content: script.Convert(chapter.Content),
+	})
+}
+
+// @Summary		Get book work
+// @description	get the work of the book with every source
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Success		200			{object}	model.Work
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/work [get]
+func BookWorkAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	bk := req.Context().Value(ContextKeyBook).(*model.Book)
+	if bk.WorkID <= 0 {
+		writeError(res, http.StatusNotFound, RecordNotFoundError)
+		return
+	}
+
+	work, err := serv.Work(req.Context(), strconv.Itoa(bk.WorkID))
+	if err != nil {
+		logger.Error().Err(err).Msg("get book work failed")
+		writeError(res, http.StatusNotFound, RecordNotFoundError)
 	} else {
-		fileName := fmt.Sprintf("%s-%s.txt", bk.Title, bk.Writer.Name)
-		res.Header().Set("Content-Type", "text/txt; charset=utf-8")
-		res.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+"\"")
-		fmt.Fprint(res, content)
+		json.NewEncoder(res).Encode(work)
+	}
+}
+
+// @Summary		Split book work
+// @description	move the book out of its work into a new work
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Success		200			{object}	model.Work
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/split [post]
+func BookSplitWorkAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	bk := req.Context().Value(ContextKeyBook).(*model.Book)
+
+	work, err := serv.SplitBookWork(req.Context(), bk)
+	if err != nil {
+		logger.Error().Err(err).Msg("split book work failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(work)
+	}
+}
+
+// @Summary		Get work info
+// @description	get work info with every source
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			workID	path		int	true	"work id"
+// @Success		200		{object}	model.Work
+// @Failure		404		{object}	errResp
+// @Router			/api/book-spider/works/{workID} [get]
+func WorkInfoAPIHandler(res http.ResponseWriter, req *http.Request) {
+	work := req.Context().Value(ContextKeyWork).(*model.Work)
+	json.NewEncoder(res).Encode(work)
+}
+
+// @Summary		Download work
+// @description	download the work from its best source in txt, epub, fb2, html or markdown format
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			workID	path		int		true	"work id"
+// @Param			format	query		string	false	"format of content"	Enums(txt, epub, fb2, html, markdown)
+// @Param			script	query		string	false	"script of content"	Enums(original, s, t)
+// @Success		200		{string}	string	"the book content"
+// @Failure		400		{object}	errResp
+// @Router			/api/book-spider/works/{workID}/download [get]
+func WorkDownloadAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	work := req.Context().Value(ContextKeyWork).(*model.Work)
+	bk, err := serv.WorkSource(req.Context(), work)
+	if err != nil {
+		logger.Error().Err(err).Msg("work source failed")
+		writeError(res, 400, err)
+		return
+	}
+
+	file, err := serv.BookFile(req.Context(), bk)
+	if err != nil {
+		logger.Error().Err(err).Msg("work content failed")
+		writeError(res, 400, err)
+		return
+	}
+	defer file.Close()
+
+	serveBookContent(res, req, bk, file, downloadFormatFromContext(req.Context()), scriptFromContext(req.Context()))
+}
+
+// @Summary		Merge works
+// @description	merge source work into the work
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			workID	path		int				true	"target work id"
+// @Param			body	body		mergeWorkReq	true	"source work"
+// @Success		200		{object}	model.Work
+// @Failure		400		{object}	errResp
+// @Router			/api/book-spider/works/{workID}/merge [post]
+func WorkMergeAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	work := req.Context().Value(ContextKeyWork).(*model.Work)
+
+	var body mergeWorkReq
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(res, 400, InvalidParamsError)
+		return
+	}
+
+	targetID := strconv.Itoa(work.ID)
+	err := serv.MergeWorks(req.Context(), targetID, body.SourceWorkID)
+	if err != nil {
+		logger.Error().Err(err).Msg("merge works failed")
+		writeError(res, 400, err)
+		return
+	}
+
+	merged, err := serv.Work(req.Context(), targetID)
+	if err != nil {
+		logger.Error().Err(err).Msg("get merged work failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(merged)
+	}
+}
+
+// @Summary		Get writer info
+// @description	get writer info with books across sites grouped by work
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			writerID	path		int		true	"writer id"
+// @Param			sort		query		string	false	"order of books"	Enums(updated, title, id, discovered)
+// @Success		200			{object}	model.WriterCatalog
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/writers/{writerID} [get]
+func WriterInfoAPIHandler(res http.ResponseWriter, req *http.Request) {
+	catalog := req.Context().Value(ContextKeyWriterInfo).(*model.WriterCatalog)
+	json.NewEncoder(res).Encode(catalog)
+}
+
+// @Summary		Search writers
+// @description	search writers by name, writers with more books come first
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			writer		query		string	false	"name of writer"
+// @Param			cursor		query		string	false	"cursor of page, from next or prev of last response"
+// @Param			per_page	query		int		false	"items per page, at most 100"
+// @Success		200			{object}	writersResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/writers/search [get]
+func WriterSearchAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	name := req.Context().Value(ContextKeyWriter).(string)
+
+	page, err := serv.SearchWriters(req.Context(), name, writerPageParamsFromContext(req.Context()))
+	if err != nil {
+		logger.Error().Err(err).Msg("query writers failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(newWritersPageResp(req, page))
+	}
+}
+
+// @Summary		List top writers
+// @description	list writers with most books or latest updates
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			order		query		string	false	"order of writers"	Enums(books, updated)
+// @Param			cursor		query		string	false	"cursor of page, from next or prev of last response"
+// @Param			per_page	query		int		false	"items per page, at most 100"
+// @Success		200			{object}	writersResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/writers/top [get]
+func WriterTopAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+
+	page, err := serv.TopWriters(req.Context(), writerPageParamsFromContext(req.Context()))
+	if err != nil {
+		logger.Error().Err(err).Msg("query top writers failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(newWritersPageResp(req, page))
+	}
+}
+
+// @Summary		List genres
+// @description	list genres books can be browsed by
+// @Tags			book-spider-api
+// @Produce		json
+// @Success		200	{object}	genresResp
+// @Router			/api/book-spider/genres [get]
+func GenreListAPIHandler(res http.ResponseWriter, req *http.Request) {
+	genres := make([]genreResp, 0, len(model.Genres))
+	for _, genre := range model.Genres {
+		genres = append(genres, genreResp{Genre: string(genre), Name: genre.Name()})
+	}
+
+	json.NewEncoder(res).Encode(genresResp{genres})
+}
+
+// @Summary		Browse books by genre
+// @description	list books of genre filtered by site and status
+// @Tags			book-spider-api
+// @Produce		json
+// @Param			genre		path		string	true	"genre of books"
+// @Param			site		query		string	false	"site of books"
+// @Param			status		query		string	false	"status of books"	Enums(INPROGRESS, END)
+// @Param			sort		query		string	false	"order of books"	Enums(updated, title, id, discovered)
+// @Param			cursor		query		string	false	"cursor of page, from next or prev of last response"
+// @Param			per_page	query		int		false	"items per page, at most 100"
+// @Success		200			{object}	booksResp
+// @Failure		400			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/genres/{genre}/books [get]
+func GenreBooksAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	params := req.Context().Value(ContextKeyBrowse).(repo.BrowseParams)
+
+	page, err := serv.BrowseBooks(req.Context(), params, pageParamsFromContext(req.Context()))
+	if err != nil {
+		logger.Error().Err(err).Str("genre", string(params.Genre)).Msg("browse books failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(newBooksPageResp(req, page))
 	}
 }
 
@@ -149,3 +539,118 @@ func DBStatsAPIHandler(service service.ReadDataService) http.HandlerFunc {
 		json.NewEncoder(res).Encode(dbStatsResp{[]sql.DBStats{service.DBStats(req.Context())}})
 	}
 }
+
+// importMaxFileSize limits the uploaded file, novels are rarely larger than 64MB
+const importMaxFileSize = 64 << 20
+
+// importFormat returns the format in params, or the format by file extension
+func importFormat(formatStr, fileName string) string {
+	if formatStr != "" {
+		return formatStr
+	}
+
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+}
+
+// @Summary		Import book
+// @description	import txt or epub file as book of local site
+// @Tags			book-spider-api
+// @Accept			mpfd
+// @Produce		json
+// @Param			file	formData	file	true	"book file"
+// @Param			format	formData	string	false	"format of file, default by file extension"	Enums(txt, epub)
+// @Param			title	formData	string	false	"title of book, default by file content"
+// @Param			writer	formData	string	false	"writer of book, default by file content"
+// @Param			type	formData	string	false	"type of book"
+// @Success		200		{object}	model.Book
+// @Failure		400		{object}	errResp
+// @Failure		500		{object}	errResp
+// @Router			/api/book-spider/books/import [post]
+func BookImportAPIHandler(importServ service.ImportService) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := zerolog.Ctx(req.Context())
+
+		req.Body = http.MaxBytesReader(res, req.Body, importMaxFileSize)
+		file, header, err := req.FormFile("file")
+		if err != nil {
+			logger.Error().Err(err).Msg("read import file failed")
+			writeError(res, http.StatusBadRequest, InvalidParamsError)
+			return
+		}
+		defer file.Close()
+
+		bk, err := importServ.ImportBook(req.Context(), service.ImportParams{
+			Format: importFormat(req.FormValue("format"), header.Filename),
+			File:   file,
+			Size:   header.Size,
+			Title:  req.FormValue("title"),
+			Writer: req.FormValue("writer"),
+			Type:   req.FormValue("type"),
+		})
+		if errors.Is(err, service.ErrImportFormat) || errors.Is(err, service.ErrImportTitleMissing) ||
+			errors.Is(err, format.ErrNoChapter) || errors.Is(err, format.ErrInvalidEpub) {
+			logger.Error().Err(err).Str("file", header.Filename).Msg("import book failed")
+			writeError(res, http.StatusBadRequest, err)
+		} else if err != nil {
+			logger.Error().Err(err).Str("file", header.Filename).Msg("import book failed")
+			writeError(res, http.StatusInternalServerError, err)
+		} else {
+			json.NewEncoder(res).Encode(bk)
+		}
+	}
+}
+
+// @Summary		Create session
+// @description	create session cookie by basic auth of user, so the password is not sent in every request
+// @Tags			book-spider-api
+// @Produce		json
+// @Success		200	{object}	sessionResp
+// @Failure		401	{object}	errResp
+// @Router			/api/book-spider/auth/session [post]
+func SessionCreateAPIHandler(auth *Authenticator) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := zerolog.Ctx(req.Context())
+		user, password, ok := req.BasicAuth()
+		if !ok {
+			writeError(res, http.StatusUnauthorized, UnauthorizedError)
+			return
+		}
+
+		client, err := auth.authenticateUser(user, password)
+		if err != nil {
+			logger.Warn().Str("user", user).Msg("create session failed")
+			writeError(res, http.StatusUnauthorized, UnauthorizedError)
+			return
+		}
+
+		token, expiredAt, err := auth.CreateSession(*client)
+		if err != nil {
+			logger.Error().Err(err).Msg("create session failed")
+			writeError(res, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.SetCookie(res, &http.Cookie{
+			Name: sessionCookieName, Value: token, Path: "/", Expires: expiredAt,
+			HttpOnly: true, Secure: req.TLS != nil, SameSite: http.SameSiteStrictMode,
+		})
+		logger.Info().Str("client", client.Name).Msg("session created")
+		json.NewEncoder(res).Encode(sessionResp{Client: client.Name, Role: string(client.Role), ExpiredAt: expiredAt})
+	}
+}
+
+// @Summary		Delete session
+// @description	delete the session of cookie
+// @Tags			book-spider-api
+// @Success		204
+// @Router			/api/book-spider/auth/session [delete]
+func SessionDeleteAPIHandler(auth *Authenticator) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if cookie, err := req.Cookie(sessionCookieName); err == nil {
+			auth.DeleteSession(cookie.Value)
+		}
+
+		http.SetCookie(res, &http.Cookie{Name: sessionCookieName, Path: "/", MaxAge: -1, HttpOnly: true})
+		res.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1,15 +1,29 @@
 package router
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/format"
+	formatv1 "github.com/htchan/BookSpider/internal/format/v1"
 	mockservice "github.com/htchan/BookSpider/internal/mock/service/v1"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
@@ -95,6 +109,23 @@ func Test_SiteInfoAPIHandler(t *testing.T) {
 			url:       "https://localhost/data",
 			expectRes: `{"BookCount":0,"WriterCount":0,"ErrorCount":0,"UniqueBookCount":0,"MaxBookID":0,"LatestSuccessID":0,"DownloadCount":0,"StatusCount":null}`,
 		},
+		{
+			name: "works with selector health",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().Stats(gomock.Any(), "test").Return(repo.Summary{
+					Health: &model.SiteHealth{
+						Site: "test", Status: model.HealthBroken, Phase: "update", Checked: 10, Failed: 9,
+						Error: "book fields not found", CheckedAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+					},
+				})
+
+				return serv
+			},
+			url: "https://localhost/data",
+			expectRes: `{"BookCount":0,"WriterCount":0,"ErrorCount":0,"UniqueBookCount":0,"MaxBookID":0,"LatestSuccessID":0,"DownloadCount":0,"StatusCount":null,` +
+				`"Health":{"site":"test","status":"broken","phase":"update","checked":10,"failed":9,"error":"book fields not found","checked_at":"2026-10-19T12:00:00Z"}}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -129,37 +160,94 @@ func Test_BookSearchAPIHandler(t *testing.T) {
 		setupServ     func(ctrl *gomock.Controller) service.ReadDataService
 		url           string
 		title, writer string
-		limit, offset int
+		genre         model.Genre
+		sort          model.BookSort
+		limit         int
+		cursor        string
 		expectRes     string
 	}{
 		{
 			name: "works",
 			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
-				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "writer 1", 10, 0).Return([]model.Book{}, nil)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "writer 1", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: 10}).
+					Return(&repo.BookPage{Books: []model.Book{}}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data",
+			title:     "title 1",
+			writer:    "writer 1",
+			sort:      model.BookSortUpdated,
+			limit:     10,
+			expectRes: `{"books":[]}`,
+		},
+		{
+			name: "works with genre",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "writer 1", model.GenreUrban, repo.PageParams{Sort: model.BookSortUpdated, Limit: 10}).
+					Return(&repo.BookPage{Books: []model.Book{}}, nil)
 
 				return serv
 			},
 			url:       "https://localhost/data",
 			title:     "title 1",
 			writer:    "writer 1",
+			genre:     model.GenreUrban,
+			sort:      model.BookSortUpdated,
 			limit:     10,
-			offset:    0,
 			expectRes: `{"books":[]}`,
 		},
+		{
+			name: "works with cursor and links",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "", model.Genre(""), repo.PageParams{Sort: model.BookSortTitle, Limit: 1, Cursor: "abc"}).
+					Return(&repo.BookPage{Books: []model.Book{}, Total: 3, NextCursor: "next", PrevCursor: "prev"}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data?title=title+1&sort=title&per_page=1&page=2&cursor=abc",
+			title:     "title 1",
+			sort:      model.BookSortTitle,
+			limit:     1,
+			cursor:    "abc",
+			expectRes: `{"books":[],"total":3,"next":"/data?cursor=next\u0026per_page=1\u0026sort=title\u0026title=title+1","prev":"/data?cursor=prev\u0026per_page=1\u0026sort=title\u0026title=title+1"}`,
+		},
+		{
+			name: "works with sources of works",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: 10}).
+					Return(&repo.BookPage{
+						Books:   []model.Book{{Site: "a", ID: 1, WorkID: 5}},
+						Sources: map[int]model.BookGroup{5: {{Site: "a", ID: 1, WorkID: 5}, {Site: "b", ID: 2, WorkID: 5}}},
+						Total:   1,
+					}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data",
+			title:     "title 1",
+			sort:      model.BookSortUpdated,
+			limit:     10,
+			expectRes: `{"books":[{"site":"a","id":1,"hash_code":"0","title":"","writer":"","type":"","update_date":"","update_chapter":"","status":"ERROR","is_downloaded":false,"work_id":5,"error":""}],"sources":{"5":[{"site":"a","id":1,"hash_code":"0","title":"","writer":"","type":"","update_date":"","update_chapter":"","status":"ERROR","is_downloaded":false,"work_id":5,"error":""},{"site":"b","id":2,"hash_code":"0","title":"","writer":"","type":"","update_date":"","update_chapter":"","status":"ERROR","is_downloaded":false,"work_id":5,"error":""}]},"total":1}`,
+		},
 		{
 			name: "error",
 			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
-				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "writer 1", 10, 0).Return(nil, errors.New("some error"))
+				serv.EXPECT().SearchBooks(gomock.Any(), "title 1", "writer 1", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: 10}).
+					Return(nil, errors.New("some error"))
 
 				return serv
 			},
 			url:       "https://localhost/data",
 			title:     "title 1",
 			writer:    "writer 1",
+			sort:      model.BookSortUpdated,
 			limit:     10,
-			offset:    0,
 			expectRes: `{"error":"some error"}`,
 		},
 	}
@@ -179,8 +267,10 @@ func Test_BookSearchAPIHandler(t *testing.T) {
 			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
 			ctx = context.WithValue(ctx, ContextKeyTitle, test.title)
 			ctx = context.WithValue(ctx, ContextKeyWriter, test.writer)
+			ctx = context.WithValue(ctx, ContextKeyGenre, test.genre)
+			ctx = context.WithValue(ctx, ContextKeySort, test.sort)
 			ctx = context.WithValue(ctx, ContextKeyLimit, test.limit)
-			ctx = context.WithValue(ctx, ContextKeyOffset, test.offset)
+			ctx = context.WithValue(ctx, ContextKeyCursor, test.cursor)
 			req = req.WithContext(ctx)
 
 			res := httptest.NewRecorder()
@@ -195,36 +285,52 @@ func Test_BookRandomAPIHandler(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name          string
-		setupServ     func(ctrl *gomock.Controller) service.ReadDataService
-		url           string
-		limit, offset int
-		expectRes     string
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		url       string
+		genre     model.Genre
+		sort      model.BookSort
+		limit     int
+		expectRes string
 	}{
 		{
 			name: "works",
 			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
-				serv.EXPECT().RandomBooks(gomock.Any(), 10).Return([]model.Book{}, nil)
+				serv.EXPECT().RandomBooks(gomock.Any(), model.Genre(""), model.BookSortUpdated, 10).Return([]model.Book{}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data",
+			sort:      model.BookSortUpdated,
+			limit:     10,
+			expectRes: `{"books":[]}`,
+		},
+		{
+			name: "works with genre and sort",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().RandomBooks(gomock.Any(), model.GenreFantasy, model.BookSortTitle, 10).Return([]model.Book{}, nil)
 
 				return serv
 			},
 			url:       "https://localhost/data",
+			genre:     model.GenreFantasy,
+			sort:      model.BookSortTitle,
 			limit:     10,
-			offset:    0,
 			expectRes: `{"books":[]}`,
 		},
 		{
 			name: "error",
 			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
-				serv.EXPECT().RandomBooks(gomock.Any(), 10).Return(nil, errors.New("some error"))
+				serv.EXPECT().RandomBooks(gomock.Any(), model.Genre(""), model.BookSortUpdated, 10).Return(nil, errors.New("some error"))
 
 				return serv
 			},
 			url:       "https://localhost/data",
+			sort:      model.BookSortUpdated,
 			limit:     10,
-			offset:    0,
 			expectRes: `{"error":"some error"}`,
 		},
 	}
@@ -242,8 +348,9 @@ func Test_BookRandomAPIHandler(t *testing.T) {
 				return
 			}
 			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, ContextKeyGenre, test.genre)
+			ctx = context.WithValue(ctx, ContextKeySort, test.sort)
 			ctx = context.WithValue(ctx, ContextKeyLimit, test.limit)
-			ctx = context.WithValue(ctx, ContextKeyOffset, test.offset)
 			req = req.WithContext(ctx)
 
 			res := httptest.NewRecorder()
@@ -299,47 +406,171 @@ func Test_BookInfoAPIHandler(t *testing.T) {
 func Test_BookDownloadAPIHandler(t *testing.T) {
 	t.Parallel()
 
-	os.Mkdir("storage", os.ModePerm)
-	os.WriteFile("storage/1.txt", []byte("data"), os.ModePerm)
+	dir := t.TempDir()
+	writeBookFile := func(name string, bk *model.Book, chapters model.Chapters) {
+		var content bytes.Buffer
+		model.WriteBookFile(&content, bk, chapters)
+		os.WriteFile(filepath.Join(dir, name), content.Bytes(), os.ModePerm)
+	}
+	writeBookFile("1.txt", &model.Book{Title: "title"}, model.Chapters{{Title: "chapter 1", Content: "data"}})
+	writeBookFile("2.txt", &model.Book{Title: "鬥破蒼穹"}, model.Chapters{{Title: "第一章", Content: "鬥破蒼穹"}})
 
-	t.Cleanup(func() {
-		os.RemoveAll("storage")
-	})
+	openFile := func(t *testing.T, name string) *os.File {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("cannot open file: %v", err)
+		}
+		return file
+	}
 
 	tests := []struct {
-		name      string
-		url       string
-		setupServ func(ctrl *gomock.Controller) service.ReadDataService
-		bk        *model.Book
-		expectRes string
+		name         string
+		setupServ    func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService
+		bk           *model.Book
+		format       string
+		script       model.Script
+		reqHeader    map[string]string
+		expectStatus int
+		expectHeader map[string]string
+		expectETag   func(t *testing.T, etag string)
+		expectRes    string
 	}{
 		{
 			name: "works",
-			url:  "https://localhost/data",
-			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, Title: "title", Status: model.StatusEnd, IsDownloaded: true}).
+					Return(openFile(t, "1.txt"), nil)
+
+				return serv
+			},
+			bk:           &model.Book{Site: "test", ID: 1, Title: "title", Status: model.StatusEnd, IsDownloaded: true},
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Disposition": `attachment; filename="title-.txt"`,
+				"Accept-Ranges":       "none",
+			},
+			expectRes: "title\n\n--------------------\n\nchapter 1\n--------------------\ndata\n--------------------",
+		},
+		{
+			name: "works with script",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 2, Title: "鬥破蒼穹", Status: model.StatusEnd, IsDownloaded: true}).
+					Return(openFile(t, "2.txt"), nil)
+
+				return serv
+			},
+			bk:           &model.Book{Site: "test", ID: 2, Title: "鬥破蒼穹", Status: model.StatusEnd, IsDownloaded: true},
+			script:       model.ScriptSimplified,
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Disposition": `attachment; filename="斗破苍穹-.txt"`,
+			},
+			expectRes: "斗破苍穹\n\n--------------------\n\n第一章\n--------------------\n斗破苍穹\n--------------------",
+		},
+		{
+			name: "range is ignored",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 2, Status: model.StatusEnd, IsDownloaded: true}).
+					Return(openFile(t, "2.txt"), nil)
+
+				return serv
+			},
+			bk:           &model.Book{Site: "test", ID: 2, Status: model.StatusEnd, IsDownloaded: true},
+			script:       model.ScriptSimplified,
+			reqHeader:    map[string]string{"Range": "bytes=61-66"},
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Range":    "",
+				"Content-Encoding": "",
+				"Accept-Ranges":    "none",
+			},
+			expectETag: func(t *testing.T, etag string) {
+				assert.False(t, strings.HasSuffix(etag, `-gzip"`), etag)
+			},
+			expectRes: "--------------------\n\n第一章\n--------------------\n斗破苍穹\n--------------------",
+		},
+		{
+			name: "works with gzip",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, Status: model.StatusEnd, IsDownloaded: true}).
+					Return(openFile(t, "1.txt"), nil)
+
+				return serv
+			},
+			bk:           &model.Book{Site: "test", ID: 1, Status: model.StatusEnd, IsDownloaded: true},
+			reqHeader:    map[string]string{"Accept-Encoding": "gzip, deflate"},
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Encoding": "gzip",
+				"Content-Length":   "",
+				"Vary":             "Accept-Encoding",
+			},
+			expectETag: func(t *testing.T, etag string) {
+				assert.True(t, strings.HasPrefix(etag, `"test-1-`), etag)
+				assert.True(t, strings.HasSuffix(etag, `-gzip"`), etag)
+			},
+			expectRes: "--------------------\n\nchapter 1\n--------------------\ndata\n--------------------",
+		},
+		{
+			name: "works with format",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 2, Title: "鬥破蒼穹", Status: model.StatusEnd, IsDownloaded: true}).
+					Return(openFile(t, "2.txt"), nil)
+
+				return serv
+			},
+			bk:           &model.Book{Site: "test", ID: 2, Title: "鬥破蒼穹", Status: model.StatusEnd, IsDownloaded: true},
+			format:       "markdown",
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":        "text/markdown; charset=utf-8",
+				"Content-Disposition": `attachment; filename="鬥破蒼穹-.md"`,
+			},
+			expectETag: func(t *testing.T, etag string) {
+				assert.True(t, strings.HasPrefix(etag, `"test-2-`), etag)
+				assert.True(t, strings.HasSuffix(etag, `-markdown"`), etag)
+				assert.Equal(t, 2, strings.Count(etag, `"`), etag)
+			},
+			expectRes: "# 鬥破蒼穹\n\n\n\n## 第一章\n\n鬥破蒼穹",
+		},
+		{
+			name: "not modified",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
 				serv.EXPECT().
-					BookContent(gomock.Any(), &model.Book{Site: "test", ID: 1, HashCode: 0, Status: model.StatusEnd, IsDownloaded: true}).
-					Return("data", nil)
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, Status: model.StatusEnd, IsDownloaded: true}).
+					Return(openFile(t, "1.txt"), nil)
 
 				return serv
 			},
-			bk:        &model.Book{Site: "test", ID: 1, HashCode: 0, Status: model.StatusEnd, IsDownloaded: true},
-			expectRes: `data`,
+			bk:           &model.Book{Site: "test", ID: 1, Status: model.StatusEnd, IsDownloaded: true},
+			reqHeader:    map[string]string{"If-Modified-Since": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)},
+			expectStatus: http.StatusNotModified,
+			expectRes:    ``,
 		},
 		{
 			name: "bk is not download",
-			url:  "https://localhost/data",
-			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
 				serv.EXPECT().
-					BookContent(gomock.Any(), &model.Book{Site: "test", ID: 1, HashCode: 0}).
-					Return("", errors.New("some error"))
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, HashCode: 0}).
+					Return(nil, errors.New("some error"))
 
 				return serv
 			},
-			bk:        &model.Book{Site: "test", ID: 1, HashCode: 0},
-			expectRes: `{"error":"some error"}`,
+			bk:           &model.Book{Site: "test", ID: 1, HashCode: 0},
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"some error"}`,
 		},
 	}
 
@@ -350,42 +581,189 @@ func Test_BookDownloadAPIHandler(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			req, err := http.NewRequest("GET", test.url, nil)
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
 			if err != nil {
 				t.Errorf("cannot init request: %v", err)
 				return
 			}
-			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			for key, value := range test.reqHeader {
+				req.Header.Set(key, value)
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(t, ctrl))
 			ctx = context.WithValue(ctx, ContextKeyBook, test.bk)
+			if test.format != "" {
+				ctx = context.WithValue(ctx, ContextKeyFormat, test.format)
+			}
+			if test.script != "" {
+				ctx = context.WithValue(ctx, ContextKeyScript, test.script)
+			}
 			req = req.WithContext(ctx)
 
 			res := httptest.NewRecorder()
 			BookDownloadAPIHandler(res, req)
 
-			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+			assert.Equal(t, test.expectStatus, res.Code)
+			for key, value := range test.expectHeader {
+				assert.Equal(t, value, res.Header().Get(key), key)
+			}
+			if test.expectETag != nil {
+				test.expectETag(t, res.Header().Get("ETag"))
+			}
+
+			body := res.Body.String()
+			if res.Header().Get("Content-Encoding") == "gzip" {
+				reader, err := gzip.NewReader(res.Body)
+				assert.NoError(t, err)
+				content, err := io.ReadAll(reader)
+				assert.NoError(t, err)
+				body = string(content)
+			}
+			assert.Equal(t, test.expectRes, strings.Trim(body, "\n"))
+			// book file is for storage only, its frames are never exposed
+			assert.NotContains(t, body, "BOOKSPIDER/")
+			assert.NotContains(t, body, "[chapter ")
 		})
 	}
 }
 
-func Test_DBStatAPIHandler(t *testing.T) {
+func Test_BookBundleAPIHandler(t *testing.T) {
 	t.Parallel()
 
+	dir := t.TempDir()
+	var txtContent bytes.Buffer
+	model.WriteBookFile(&txtContent, &model.Book{Title: "鬥破蒼穹"}, model.Chapters{{Title: "第一章", Content: "鬥破蒼穹 content"}})
+	os.WriteFile(filepath.Join(dir, "1.txt"), txtContent.Bytes(), os.ModePerm)
+	epubBk := &model.Book{Site: "test", ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer"}, IsDownloaded: true}
+	var epubContent bytes.Buffer
+	model.WriteBookFile(&epubContent, epubBk, model.Chapters{{Title: "chapter", Content: "content"}})
+	os.WriteFile(filepath.Join(dir, "2.txt"), epubContent.Bytes(), os.ModePerm)
+
+	openFile := func(t *testing.T, name string) *os.File {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("cannot open file: %v", err)
+		}
+		return file
+	}
+
+	txtBk := model.Book{Site: "test", ID: 1, HashCode: 100, Title: "鬥破蒼穹", Writer: model.Writer{Name: "天蠶土豆"}, Status: model.StatusEnd, IsDownloaded: true}
+	notDownloadedBk := model.Book{Site: "test", ID: 3, Title: "title 3", Status: model.StatusInProgress}
+	brokenBk := model.Book{Site: "other", ID: 4, Title: "title/4", IsDownloaded: true}
+	searchErr := errors.New("search failed")
+
 	tests := []struct {
-		name      string
-		url       string
-		setupServ func(ctrl *gomock.Controller) service.ReadDataService
-		expectRes string
+		name           string
+		setupServ      func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService
+		title          string
+		writer         string
+		format         string
+		script         model.Script
+		expectStatus   int
+		expectHeader   map[string]string
+		expectFiles    map[string]string
+		expectEpubs    []string
+		expectManifest *bundleManifestContent
+		expectRes      string
 	}{
 		{
-			name: "works",
-			url:  "https://localhost/data",
-			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+			name: "works with txt",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
 				serv := mockservice.NewMockReadDataService(ctrl)
-				serv.EXPECT().DBStats(gomock.Any()).Return(sql.DBStats{})
+				serv.EXPECT().SearchBooks(gomock.Any(), "", "天蠶土豆", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: bundlePageSize}).
+					Return(&repo.BookPage{Books: []model.Book{txtBk, notDownloadedBk, brokenBk}}, nil)
+				serv.EXPECT().BookFile(gomock.Any(), &txtBk).Return(openFile(t, "1.txt"), nil)
+				serv.EXPECT().BookFile(gomock.Any(), &brokenBk).Return(nil, service.ErrBookFileNotFound)
 
 				return serv
 			},
-			expectRes: `{"stats":[{"MaxOpenConnections":0,"OpenConnections":0,"InUse":0,"Idle":0,"WaitCount":0,"WaitDuration":0,"MaxIdleClosed":0,"MaxIdleTimeClosed":0,"MaxLifetimeClosed":0}]}`,
+			writer:       "天蠶土豆",
+			script:       model.ScriptSimplified,
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":        "application/zip",
+				"Content-Disposition": `attachment; filename="bundle-天蚕土豆.zip"`,
+			},
+			expectFiles: map[string]string{
+				"test-1-100-斗破苍穹-天蚕土豆.txt": "斗破苍穹\n天蚕土豆\n--------------------\n\n第一章\n--------------------\n斗破苍穹 content\n--------------------\n",
+			},
+			expectManifest: &bundleManifestContent{
+				Writer: "天蠶土豆", Format: "txt", Script: "s",
+				Books: []bundleManifestBook{
+					{Site: "test", ID: 1, HashCode: "2s", Title: "鬥破蒼穹", Writer: "天蠶土豆", Status: "END", File: "test-1-100-斗破苍穹-天蚕土豆.txt"},
+					{Site: "test", ID: 3, HashCode: "0", Title: "title 3", Status: "INPROGRESS", Skipped: bundleSkipReason},
+					{Site: "other", ID: 4, HashCode: "0", Title: "title/4", Status: "ERROR", Error: "open book file failed: " + service.ErrBookFileNotFound.Error()},
+				},
+			},
+		},
+		{
+			name: "works with epub",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title", "", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: bundlePageSize}).
+					Return(&repo.BookPage{Books: []model.Book{*epubBk}}, nil)
+				serv.EXPECT().BookFile(gomock.Any(), epubBk).Return(openFile(t, "2.txt"), nil)
+
+				return serv
+			},
+			title:        "title",
+			format:       "epub",
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{"Content-Disposition": `attachment; filename="bundle-title.zip"`},
+			expectEpubs:  []string{"test-2-title 2-writer.epub"},
+			expectManifest: &bundleManifestContent{
+				Title: "title", Format: "epub", Script: "original",
+				Books: []bundleManifestBook{
+					{Site: "test", ID: 2, HashCode: "0", Title: "title 2", Writer: "writer", Status: "ERROR", File: "test-2-title 2-writer.epub"},
+				},
+			},
+		},
+		{
+			name: "truncated by max books",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				params := repo.PageParams{Sort: model.BookSortUpdated, Limit: bundlePageSize}
+				for i := 0; i < bundleMaxBooks/bundlePageSize; i++ {
+					next := fmt.Sprintf("cursor-%d", i)
+					serv.EXPECT().SearchBooks(gomock.Any(), "title", "", model.Genre(""), params).
+						Return(&repo.BookPage{Books: make([]model.Book, bundlePageSize), NextCursor: next}, nil)
+					params.Cursor = next
+				}
+
+				return serv
+			},
+			title:        "title",
+			expectStatus: http.StatusOK,
+			expectFiles:  map[string]string{},
+		},
+		{
+			name: "missing title and writer",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				return mockservice.NewMockReadDataService(ctrl)
+			},
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"invalid params"}`,
+		},
+		{
+			name: "unsupported format",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				return mockservice.NewMockReadDataService(ctrl)
+			},
+			title:        "title",
+			format:       "fb2",
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"invalid params"}`,
+		},
+		{
+			name: "search failed",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title", "", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: bundlePageSize}).Return(nil, searchErr)
+
+				return serv
+			},
+			title:        "title",
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"search failed"}`,
 		},
 	}
 
@@ -394,18 +772,1036 @@ func Test_DBStatAPIHandler(t *testing.T) {
 			t.Parallel()
 
 			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
 
-			req, err := http.NewRequest("GET", test.url, nil)
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
 			if err != nil {
 				t.Errorf("cannot init request: %v", err)
 				return
 			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(t, ctrl))
+			ctx = context.WithValue(ctx, ContextKeyTitle, test.title)
+			ctx = context.WithValue(ctx, ContextKeyWriter, test.writer)
+			if test.format != "" {
+				ctx = context.WithValue(ctx, ContextKeyFormat, test.format)
+			}
+			if test.script != "" {
+				ctx = context.WithValue(ctx, ContextKeyScript, test.script)
+			}
+			req = req.WithContext(ctx)
 
 			res := httptest.NewRecorder()
-			DBStatsAPIHandler(test.setupServ(ctrl)).ServeHTTP(res, req)
+			BookBundleAPIHandler(res, req)
 
-			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+			assert.Equal(t, test.expectStatus, res.Code)
+			for key, value := range test.expectHeader {
+				assert.Equal(t, value, res.Header().Get(key), key)
+			}
+
+			if test.expectStatus != http.StatusOK {
+				assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+				return
+			}
+
+			zipReader, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+			if err != nil {
+				t.Fatalf("cannot read bundle: %v", err)
+			}
+
+			files := make(map[string]string)
+			var manifest bundleManifestContent
+			for _, file := range zipReader.File {
+				reader, err := file.Open()
+				assert.NoError(t, err)
+				content, err := io.ReadAll(reader)
+				assert.NoError(t, err)
+				reader.Close()
+
+				switch {
+				case file.Name == bundleManifest:
+					assert.NoError(t, json.Unmarshal(content, &manifest))
+				case strings.HasSuffix(file.Name, ".epub"):
+					assert.Contains(t, test.expectEpubs, file.Name)
+					assert.Equal(t, zip.Store, file.Method)
+					_, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+					assert.NoError(t, err)
+				default:
+					files[file.Name] = string(content)
+				}
+			}
+
+			assert.Equal(t, zipReader.File[len(zipReader.File)-1].Name, bundleManifest)
+			if test.expectFiles != nil {
+				assert.Equal(t, test.expectFiles, files)
+			}
+			if test.expectManifest != nil {
+				assert.Equal(t, *test.expectManifest, manifest)
+			} else {
+				assert.True(t, manifest.Truncated)
+				assert.Len(t, manifest.Books, bundleMaxBooks)
+			}
+		})
+	}
+}
+
+func Test_BookCoverAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	bk := &model.Book{Site: "test", ID: 1, HashCode: 100, Title: "鬥破蒼穹", Writer: model.Writer{Name: "天蠶土豆"}}
+
+	tests := []struct {
+		name         string
+		formatServ   format.Service
+		bk           *model.Book
+		script       model.Script
+		reqHeader    map[string]string
+		expectStatus int
+		expectHeader map[string]string
+		expectImage  bool
+		expectRes    string
+	}{
+		{
+			name:         "works",
+			bk:           bk,
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":  "image/png",
+				"ETag":          coverETag(bk, model.ScriptOriginal),
+				"Cache-Control": "public, max-age=86400",
+			},
+			expectImage: true,
+		},
+		{
+			name:         "works with script",
+			bk:           bk,
+			script:       model.ScriptSimplified,
+			expectStatus: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type": "image/png",
+				"ETag":         coverETag(bk, model.ScriptSimplified),
+			},
+			expectImage: true,
+		},
+		{
+			name:         "not modified",
+			bk:           bk,
+			reqHeader:    map[string]string{"If-None-Match": coverETag(bk, model.ScriptOriginal)},
+			expectStatus: http.StatusNotModified,
+			expectRes:    ``,
+		},
+		{
+			name:         "if modified since is ignored",
+			bk:           bk,
+			reqHeader:    map[string]string{"If-Modified-Since": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)},
+			expectStatus: http.StatusOK,
+			expectImage:  true,
+		},
+		{
+			name:         "render cover failed",
+			formatServ:   formatv1.NewService(config.EpubConfig{CoverFont: "not-exist.ttf"}, nil),
+			bk:           bk,
+			expectStatus: http.StatusInternalServerError,
+			expectHeader: map[string]string{"ETag": ""},
+			expectRes:    `{"error":"read cover font failed: open not-exist.ttf: no such file or directory"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			for key, value := range test.reqHeader {
+				req.Header.Set(key, value)
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyBook, test.bk)
+			if test.formatServ != nil {
+				ctx = context.WithValue(ctx, ContextKeyFormatServ, test.formatServ)
+			}
+			if test.script != "" {
+				ctx = context.WithValue(ctx, ContextKeyScript, test.script)
+			}
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			BookCoverAPIHandler(res, req)
+
+			assert.Equal(t, test.expectStatus, res.Code)
+			for key, value := range test.expectHeader {
+				assert.Equal(t, value, res.Header().Get(key), key)
+			}
+
+			if test.expectImage {
+				_, err := png.Decode(res.Body)
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+			}
+		})
+	}
+}
+
+func Test_BookChapterAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var content bytes.Buffer
+	model.WriteBookFile(&content, &model.Book{Title: "title"}, model.Chapters{
+		{Index: 0, Title: "第一章", Content: "鬥破蒼穹"},
+		{Index: 1, Title: "第二章", Content: "content"},
+	})
+	os.WriteFile(filepath.Join(dir, "1.txt"), content.Bytes(), os.ModePerm)
+	// the last chapter is cut, chapters before it can still be read
+	os.WriteFile(filepath.Join(dir, "2.txt"), content.Bytes()[:content.Len()-10], os.ModePerm)
+
+	openFile := func(t *testing.T, name string) *os.File {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("cannot open file: %v", err)
+		}
+		return file
+	}
+
+	tests := []struct {
+		name      string
+		index     string
+		setupServ func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService
+		bk        *model.Book
+		script    model.Script
+		expectRes string
+	}{
+		{
+			name:  "works",
+			index: "0",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, IsDownloaded: true}).
+					Return(openFile(t, "1.txt"), nil)
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 1, IsDownloaded: true},
+			expectRes: `{"index":0,"title":"第一章","content":"鬥破蒼穹"}`,
+		},
+		{
+			name:  "works with script",
+			index: "0",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, IsDownloaded: true}).
+					Return(openFile(t, "1.txt"), nil)
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 1, IsDownloaded: true},
+			script:    model.ScriptSimplified,
+			expectRes: `{"index":0,"title":"第一章","content":"斗破苍穹"}`,
+		},
+		{
+			name:  "works with chapters after index not readable",
+			index: "0",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 2, IsDownloaded: true}).
+					Return(openFile(t, "2.txt"), nil)
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 2, IsDownloaded: true},
+			expectRes: `{"index":0,"title":"第一章","content":"鬥破蒼穹"}`,
+		},
+		{
+			name:  "chapter not readable",
+			index: "1",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 2, IsDownloaded: true}).
+					Return(openFile(t, "2.txt"), nil)
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 2, IsDownloaded: true},
+			expectRes: `{"error":"book file corrupted: unexpected EOF"}`,
+		},
+		{
+			name:  "index out of range",
+			index: "2",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1, IsDownloaded: true}).
+					Return(openFile(t, "1.txt"), nil)
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 1, IsDownloaded: true},
+			expectRes: `{"error":"record not found"}`,
+		},
+		{
+			name:  "negative index",
+			index: "-1",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				return mockservice.NewMockReadDataService(ctrl)
+			},
+			bk:        &model.Book{Site: "test", ID: 1, IsDownloaded: true},
+			expectRes: `{"error":"record not found"}`,
+		},
+		{
+			name:  "book file failed",
+			index: "0",
+			setupServ: func(t *testing.T, ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().
+					BookFile(gomock.Any(), &model.Book{Site: "test", ID: 1}).
+					Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 1},
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "https://localhost/chapters/"+test.index, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("index", test.index)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+			ctx = context.WithValue(ctx, ContextKeyReadDataServ, test.setupServ(t, ctrl))
+			ctx = context.WithValue(ctx, ContextKeyBook, test.bk)
+			if test.script != "" {
+				ctx = context.WithValue(ctx, ContextKeyScript, test.script)
+			}
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			BookChapterAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_DBStatAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		url       string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		expectRes string
+	}{
+		{
+			name: "works",
+			url:  "https://localhost/data",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().DBStats(gomock.Any()).Return(sql.DBStats{})
+
+				return serv
+			},
+			expectRes: `{"stats":[{"MaxOpenConnections":0,"OpenConnections":0,"InUse":0,"Idle":0,"WaitCount":0,"WaitDuration":0,"MaxIdleClosed":0,"MaxIdleTimeClosed":0,"MaxLifetimeClosed":0}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			res := httptest.NewRecorder()
+			DBStatsAPIHandler(test.setupServ(ctrl)).ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_BookWorkAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		bk        *model.Book
+		expectRes string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().Work(gomock.Any(), "5").Return(&model.Work{
+					ID: 5, Title: "title", Writer: "writer",
+					Books: model.BookGroup{{Site: "test", ID: 1, Status: model.StatusEnd, WorkID: 5}},
+				}, nil)
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 1, WorkID: 5},
+			expectRes: `{"id":5,"title":"title","writer":"writer","books":[{"site":"test","id":1,"hash_code":"0","title":"","writer":"","type":"","update_date":"","update_chapter":"","status":"END","is_downloaded":false,"work_id":5,"error":""}]}`,
+		},
+		{
+			name: "book without work",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				return mockservice.NewMockReadDataService(ctrl)
+			},
+			bk:        &model.Book{Site: "test", ID: 1},
+			expectRes: `{"error":"record not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, ContextKeyBook, test.bk)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			BookWorkAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_WorkInfoAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		work      *model.Work
+		expectRes string
+	}{
+		{
+			name:      "works",
+			work:      &model.Work{ID: 5, Title: "title", Writer: "writer", Books: model.BookGroup{}},
+			expectRes: `{"id":5,"title":"title","writer":"writer","books":[]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyWork, test.work)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			WorkInfoAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_WorkMergeAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		work      *model.Work
+		expectRes string
+	}{
+		{
+			name: "works",
+			body: `{"source_work_id":"6"}`,
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().MergeWorks(gomock.Any(), "5", "6").Return(nil)
+				serv.EXPECT().Work(gomock.Any(), "5").Return(
+					&model.Work{ID: 5, Title: "title", Writer: "writer", Books: model.BookGroup{}}, nil,
+				)
+
+				return serv
+			},
+			work:      &model.Work{ID: 5},
+			expectRes: `{"id":5,"title":"title","writer":"writer","books":[]}`,
+		},
+		{
+			name: "invalid body",
+			body: `not json`,
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				return mockservice.NewMockReadDataService(ctrl)
+			},
+			work:      &model.Work{ID: 5},
+			expectRes: `{"error":"invalid params"}`,
+		},
+		{
+			name: "merge fail",
+			body: `{"source_work_id":"5"}`,
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().MergeWorks(gomock.Any(), "5", "5").Return(service.ErrInvalidWorkID)
+
+				return serv
+			},
+			work:      &model.Work{ID: 5},
+			expectRes: `{"error":"invalid work id"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("POST", "https://localhost/data", strings.NewReader(test.body))
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, ContextKeyWork, test.work)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			WorkMergeAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_WriterInfoAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		catalog   *model.WriterCatalog
+		expectRes string
+	}{
+		{
+			name: "works",
+			catalog: &model.WriterCatalog{
+				ID: 5, Name: "writer",
+				Groups: []model.BookGroup{{{Site: "test", ID: 1, Title: "title", Writer: model.Writer{ID: 5, Name: "writer"}, WorkID: 2}}},
+			},
+			expectRes: `{"id":5,"name":"writer","groups":[[{"site":"test","id":1,"hash_code":"0","title":"title","writer":"writer","type":"","update_date":"","update_chapter":"","status":"ERROR","is_downloaded":false,"work_id":2,"error":""}]]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyWriterInfo, test.catalog)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			WriterInfoAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_WriterSearchAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		url       string
+		writer    string
+		limit     int
+		cursor    string
+		expectRes string
+	}{
+		{
+			name: "works with cursor and links",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchWriters(gomock.Any(), "writer", repo.WriterPageParams{
+					Order: model.WriterOrderBookCount, Limit: 10, Cursor: "abc",
+				}).Return(&repo.WriterPage{
+					Writers:    []model.WriterSummary{{ID: 5, Name: "writer", BookCount: 3, LatestUpdate: "date"}},
+					NextCursor: "next", PrevCursor: "prev",
+				}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data?writer=writer&page=2&cursor=abc",
+			writer:    "writer",
+			limit:     10,
+			cursor:    "abc",
+			expectRes: `{"writers":[{"id":5,"name":"writer","book_count":3,"latest_update":"date"}],"next":"/data?cursor=next\u0026writer=writer","prev":"/data?cursor=prev\u0026writer=writer"}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchWriters(gomock.Any(), "writer", repo.WriterPageParams{Order: model.WriterOrderBookCount, Limit: 10}).
+					Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			url:       "https://localhost/data",
+			writer:    "writer",
+			limit:     10,
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, ContextKeyWriter, test.writer)
+			ctx = context.WithValue(ctx, ContextKeyLimit, test.limit)
+			ctx = context.WithValue(ctx, ContextKeyCursor, test.cursor)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			WriterSearchAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_WriterTopAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		order     model.WriterOrder
+		expectRes string
+	}{
+		{
+			name: "default order by book count",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().TopWriters(gomock.Any(), repo.WriterPageParams{Order: model.WriterOrderBookCount, Limit: 10}).
+					Return(&repo.WriterPage{Writers: []model.WriterSummary{}}, nil)
+
+				return serv
+			},
+			expectRes: `{"writers":[]}`,
+		},
+		{
+			name: "order by latest update",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().TopWriters(gomock.Any(), repo.WriterPageParams{Order: model.WriterOrderLatestUpdate, Limit: 10}).
+					Return(&repo.WriterPage{
+						Writers:    []model.WriterSummary{{ID: 5, Name: "writer", BookCount: 3, LatestUpdate: "date"}},
+						NextCursor: "next",
+					}, nil)
+
+				return serv
+			},
+			order:     model.WriterOrderLatestUpdate,
+			expectRes: `{"writers":[{"id":5,"name":"writer","book_count":3,"latest_update":"date"}],"next":"/data?cursor=next"}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().TopWriters(gomock.Any(), repo.WriterPageParams{Order: model.WriterOrderBookCount, Limit: 10}).
+					Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			if test.order != "" {
+				ctx = context.WithValue(ctx, ContextKeyWriterOrder, test.order)
+			}
+			ctx = context.WithValue(ctx, ContextKeyLimit, 10)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			WriterTopAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GenreListAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest("GET", "https://localhost/data", nil)
+	if err != nil {
+		t.Errorf("cannot init request: %v", err)
+		return
+	}
+
+	res := httptest.NewRecorder()
+	GenreListAPIHandler(res, req)
+
+	assert.Contains(t, res.Body.String(), `{"genres":[{"genre":"fantasy","name":"玄幻奇幻"},{"genre":"xianxia","name":"仙侠修真"},`)
+	assert.Contains(t, res.Body.String(), `{"genre":"other","name":"其他"}]}`)
+}
+
+func Test_GenreBooksAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.ReadDataService
+		params    repo.BrowseParams
+		expectRes string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().BrowseBooks(
+					gomock.Any(),
+					repo.BrowseParams{Genre: model.GenreUrban, Site: "test", Status: model.StatusEndKey},
+					repo.PageParams{Sort: model.BookSortDiscovered, Limit: 10, Cursor: "abc"},
+				).Return(&repo.BookPage{Books: []model.Book{}, Total: 12, NextCursor: "next"}, nil)
+
+				return serv
+			},
+			params:    repo.BrowseParams{Genre: model.GenreUrban, Site: "test", Status: model.StatusEndKey},
+			expectRes: `{"books":[],"total":12,"next":"/data?cursor=next\u0026sort=discovered"}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().BrowseBooks(
+					gomock.Any(),
+					repo.BrowseParams{Genre: model.GenreUrban},
+					repo.PageParams{Sort: model.BookSortDiscovered, Limit: 10, Cursor: "abc"},
+				).Return(nil, repo.ErrInvalidCursor)
+
+				return serv
+			},
+			params:    repo.BrowseParams{Genre: model.GenreUrban},
+			expectRes: `{"error":"invalid cursor"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "https://localhost/data?sort=discovered&page=1&cursor=abc", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, ContextKeyBrowse, test.params)
+			ctx = context.WithValue(ctx, ContextKeySort, model.BookSortDiscovered)
+			ctx = context.WithValue(ctx, ContextKeyLimit, 10)
+			ctx = context.WithValue(ctx, ContextKeyCursor, "abc")
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			GenreBooksAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_BookImportAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	multipartBody := func(fileName, content string, fields map[string]string) (io.Reader, string) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for key, value := range fields {
+			writer.WriteField(key, value)
+		}
+		if fileName != "" {
+			file, _ := writer.CreateFormFile("file", fileName)
+			file.Write([]byte(content))
+		}
+		writer.Close()
+
+		return &buf, writer.FormDataContentType()
+	}
+
+	tests := []struct {
+		name         string
+		fileName     string
+		content      string
+		fields       map[string]string
+		setupServ    func(ctrl *gomock.Controller) service.ImportService
+		expectStatus int
+		expectRes    string
+	}{
+		{
+			name:     "works",
+			fileName: "book.TXT",
+			content:  "第一章\n内容\n",
+			fields:   map[string]string{"title": "title", "writer": "writer"},
+			setupServ: func(ctrl *gomock.Controller) service.ImportService {
+				serv := mockservice.NewMockImportService(ctrl)
+				serv.EXPECT().ImportBook(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, params service.ImportParams) (*model.Book, error) {
+						content, _ := io.ReadAll(io.NewSectionReader(params.File, 0, params.Size))
+						assert.Equal(t, "第一章\n内容\n", string(content))
+						assert.Equal(t, "txt", params.Format)
+						assert.Equal(t, "title", params.Title)
+						assert.Equal(t, "writer", params.Writer)
+
+						return &model.Book{Site: "local", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Status: model.StatusEnd, IsDownloaded: true}, nil
+					},
+				)
+
+				return serv
+			},
+			expectStatus: http.StatusOK,
+			expectRes:    `{"site":"local","id":1,"hash_code":"0","title":"title","writer":"writer","type":"","update_date":"","update_chapter":"","status":"END","is_downloaded":true,"error":""}`,
+		},
+		{
+			name:     "format in params",
+			fileName: "book",
+			fields:   map[string]string{"format": "epub"},
+			setupServ: func(ctrl *gomock.Controller) service.ImportService {
+				serv := mockservice.NewMockImportService(ctrl)
+				serv.EXPECT().ImportBook(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, params service.ImportParams) (*model.Book, error) {
+						assert.Equal(t, "epub", params.Format)
+
+						return nil, format.ErrInvalidEpub
+					},
+				)
+
+				return serv
+			},
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"invalid epub"}`,
+		},
+		{
+			name: "missing file",
+			setupServ: func(ctrl *gomock.Controller) service.ImportService {
+				return mockservice.NewMockImportService(ctrl)
+			},
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"invalid params"}`,
+		},
+		{
+			name:     "unsupported format",
+			fileName: "book.pdf",
+			setupServ: func(ctrl *gomock.Controller) service.ImportService {
+				serv := mockservice.NewMockImportService(ctrl)
+				serv.EXPECT().ImportBook(gomock.Any(), gomock.Any()).Return(nil, service.ErrImportFormat)
+
+				return serv
+			},
+			expectStatus: http.StatusBadRequest,
+			expectRes:    `{"error":"unsupported import format"}`,
+		},
+		{
+			name:     "import fail",
+			fileName: "book.txt",
+			setupServ: func(ctrl *gomock.Controller) service.ImportService {
+				serv := mockservice.NewMockImportService(ctrl)
+				serv.EXPECT().ImportBook(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectRes:    `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			body, contentType := multipartBody(test.fileName, test.content, test.fields)
+			req, err := http.NewRequest("POST", "https://localhost/data", body)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			req.Header.Set("Content-Type", contentType)
+
+			res := httptest.NewRecorder()
+			BookImportAPIHandler(test.setupServ(ctrl)).ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectStatus, res.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func TestAddAPIRoutes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		conf             config.AuthConfig
+		method           string
+		url              string
+		setupReq         func(req *http.Request)
+		setupServ        func(ctrl *gomock.Controller) service.ReadDataService
+		expectStatusCode int
+	}{
+		{
+			name:             "session route without credentials",
+			conf:             testAuthConfig(),
+			method:           http.MethodPost,
+			url:              "/api/auth/session",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:             "authenticated route without credentials",
+			conf:             testAuthConfig(),
+			method:           http.MethodGet,
+			url:              "/api/genres/",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:             "authenticated route with api key",
+			conf:             testAuthConfig(),
+			method:           http.MethodGet,
+			url:              "/api/genres/",
+			setupReq:         func(req *http.Request) { req.Header.Set(apiKeyHeader, "reader-secret") },
+			expectStatusCode: http.StatusOK,
+		},
+		{
+			name:             "admin route rejects reader",
+			conf:             testAuthConfig(),
+			method:           http.MethodGet,
+			url:              "/api/db-stats",
+			setupReq:         func(req *http.Request) { req.Header.Set(apiKeyHeader, "reader-secret") },
+			expectStatusCode: http.StatusForbidden,
+		},
+		{
+			name:   "admin route with admin key",
+			conf:   testAuthConfig(),
+			method: http.MethodGet,
+			url:    "/api/db-stats",
+			setupReq: func(req *http.Request) {
+				req.Header.Set(apiKeyHeader, "admin-secret")
+			},
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().DBStats(gomock.Any()).Return(sql.DBStats{})
+
+				return serv
+			},
+			expectStatusCode: http.StatusOK,
+		},
+		{
+			name:             "read route public if auth not configured",
+			conf:             config.AuthConfig{},
+			method:           http.MethodGet,
+			url:              "/api/genres/",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusOK,
+		},
+		{
+			name:             "admin route rejected if auth not configured",
+			conf:             config.AuthConfig{},
+			method:           http.MethodGet,
+			url:              "/api/db-stats",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			var readDataServ service.ReadDataService = mockservice.NewMockReadDataService(ctrl)
+			if test.setupServ != nil {
+				readDataServ = test.setupServ(ctrl)
+			}
+
+			conf := &config.APIConfig{APIRoutePrefix: "/api", AuthConfig: test.conf}
+			router := chi.NewRouter()
+			AddAPIRoutes(router, conf, NewAuthenticator(conf.AuthConfig), NewRateLimiter(), nil, readDataServ, nil)
+
+			req := httptest.NewRequest(test.method, test.url, nil)
+			test.setupReq(req)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectStatusCode, res.Code)
 		})
 	}
 }
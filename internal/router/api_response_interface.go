@@ -2,8 +2,11 @@ package router
 
 import (
 	"database/sql"
+	"net/http"
+	"time"
 
 	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
 )
 
 type errResp struct {
@@ -11,9 +14,81 @@ type errResp struct {
 }
 
 type booksResp struct {
-	Books []model.Book `json:"books"`
+	Books   []model.Book            `json:"books"`
+	Sources map[int]model.BookGroup `json:"sources,omitempty"` // books of the works by work id
+	Total   int                     `json:"total,omitempty"`
+	Next    string                  `json:"next,omitempty"`
+	Prev    string                  `json:"prev,omitempty"`
+}
+
+// pageLink keeps the query of request with another cursor, empty cursor has
+// no page to link
+func pageLink(req *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+
+	query := req.URL.Query()
+	query.Del("page")
+	query.Set("cursor", cursor)
+
+	return req.URL.Path + "?" + query.Encode()
+}
+
+// newBooksPageResp builds the response of page with links to the next and
+// previous page
+func newBooksPageResp(req *http.Request, page *repo.BookPage) booksResp {
+	return booksResp{
+		Books: page.Books, Sources: page.Sources, Total: page.Total,
+		Next: pageLink(req, page.NextCursor), Prev: pageLink(req, page.PrevCursor),
+	}
+}
+
+type genreResp struct {
+	Genre string `json:"genre"`
+	Name  string `json:"name"`
+}
+
+type genresResp struct {
+	Genres []genreResp `json:"genres"`
+}
+
+type writersResp struct {
+	Writers []model.WriterSummary `json:"writers"`
+	Next    string                `json:"next,omitempty"`
+	Prev    string                `json:"prev,omitempty"`
+}
+
+// newWritersPageResp builds the response of page with links to the next and
+// previous page
+func newWritersPageResp(req *http.Request, page *repo.WriterPage) writersResp {
+	return writersResp{
+		Writers: page.Writers,
+		Next:    pageLink(req, page.NextCursor),
+		Prev:    pageLink(req, page.PrevCursor),
+	}
 }
 
 type dbStatsResp struct {
 	Stats []sql.DBStats `json:"stats"`
 }
+
+type chapterResp struct {
+	Index   int    `json:"index"`
+	Title   string `json:"title"`
+	Content string `json:"content,omitempty"`
+}
+
+type chaptersResp struct {
+	Chapters []chapterResp `json:"chapters"`
+}
+
+type sessionResp struct {
+	Client    string    `json:"client"`
+	Role      string    `json:"role"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type mergeWorkReq struct {
+	SourceWorkID string `json:"source_work_id"`
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"slices"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	_ "github.com/htchan/BookSpider/docs" // docs is generated by Swag CLI, you have to import it.
 	"github.com/htchan/BookSpider/internal/config/v2"
+	formatv1 "github.com/htchan/BookSpider/internal/format/v1"
 	"github.com/htchan/BookSpider/internal/service"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
@@ -22,41 +24,109 @@ func writeError(res http.ResponseWriter, statusCode int, err error) {
 	json.NewEncoder(res).Encode(errResp{err.Error()})
 }
 
-func AddAPIRoutes(router chi.Router, conf *config.APIConfig, services map[string]service.Service, readDataServices service.ReadDataService) {
+// AddAPIRoutes adds the api routes, import route is added only if import service is not nil.
+// expensive routes are rate limited per client and write routes require admin role
+func AddAPIRoutes(
+	router chi.Router, conf *config.APIConfig, auth *Authenticator, limiter *RateLimiter,
+	services map[string]service.Service, readDataServices service.ReadDataService, importService service.ImportService,
+) {
+	rateLimit := RateLimitMiddleware(limiter)
+	requireAdmin := RequireRoleMiddleware(RoleAdmin)
+
+	allowedOrigins := conf.CORSAllowedOrigins
+	if len(allowedOrigins) == 0 {
+		allowedOrigins = []string{"*"}
+	}
+
 	router.Route(conf.APIRoutePrefix, func(router chi.Router) {
 		router.Use(logRequest())
 		router.Use(TraceMiddleware)
 		router.Use(
 			cors.Handler(
 				cors.Options{
-					AllowedOrigins: []string{"*"},
+					AllowedOrigins: allowedOrigins,
 					AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 					AllowedHeaders: []string{"*"},
-					MaxAge:         300, // Maximum value not ignored by any of major browsers
+					// cookie of session is not sent to any origin
+					AllowCredentials: !slices.Contains(allowedOrigins, "*"),
+					MaxAge:           300, // Maximum value not ignored by any of major browsers
 				},
 			),
 		)
 
-		router.Get("/info", GeneralInfoAPIHandler(services, readDataServices))
+		router.With(SessionRateLimitMiddleware(limiter)).Post("/auth/session", SessionCreateAPIHandler(auth))
+		router.Delete("/auth/session", SessionDeleteAPIHandler(auth))
+
+		// middlewares of mux must be defined before its routes, so the
+		// authenticated routes are grouped after the session routes
+		router.Group(func(router chi.Router) {
+			router.Use(AuthMiddleware(auth, limiter), RequireRoleMiddleware(RoleReader))
+
+			router.Get("/info", GeneralInfoAPIHandler(services, readDataServices))
 
-		router.Route("/sites/{siteName}", func(router chi.Router) {
-			router.Use(GetReadDataServiceMiddleware(readDataServices))
-			router.Get("/", SiteInfoAPIHandler)
+			formatServ := formatv1.NewService(conf.EpubConfig, bookURLFunc(services))
 
-			router.Route("/books", func(router chi.Router) {
-				router.With(GetSearchParamsMiddleware).With(GetPageParamsMiddleware).Get("/search", BookSearchAPIHandler)
-				router.With(GetPageParamsMiddleware).Get("/random", BookRandomAPIHandler)
+			router.Route("/sites/{siteName}", func(router chi.Router) {
+				router.Use(GetReadDataServiceMiddleware(readDataServices))
+				router.Use(GetFormatServiceMiddleware(formatServ))
+				router.Get("/", SiteInfoAPIHandler)
 
-				router.Route("/{idHash:\\d+(-[\\w]+)?}", func(router chi.Router) {
-					// idHash format is <id>-<hash>
-					router.Use(GetBookMiddleware)
-					router.With().Get("/", BookInfoAPIHandler)
-					router.Get("/download", BookDownloadAPIHandler)
+				router.Route("/books", func(router chi.Router) {
+					router.With(rateLimit).With(GetSearchParamsMiddleware).With(GetGenreParamsMiddleware).With(GetSortParamsMiddleware).
+						With(GetPageParamsMiddleware).Get("/search", BookSearchAPIHandler)
+					router.With(GetGenreParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).Get("/random", BookRandomAPIHandler)
+					router.With(rateLimit).With(AuditMiddleware).With(GetSearchParamsMiddleware).With(GetDownloadParamsMiddleware).
+						With(GetScriptParamsMiddleware).Get("/bundle", BookBundleAPIHandler)
+
+					router.Route("/{idHash:\\d+(-[\\w]+)?}", func(router chi.Router) {
+						// idHash format is <id>-<hash>
+						router.Use(GetBookMiddleware)
+						router.With().Get("/", BookInfoAPIHandler)
+						router.With(rateLimit).With(AuditMiddleware).With(GetDownloadParamsMiddleware).With(GetScriptParamsMiddleware).
+							Get("/download", BookDownloadAPIHandler)
+						router.With(GetScriptParamsMiddleware).Get("/cover", BookCoverAPIHandler)
+						router.With(GetScriptParamsMiddleware).Get("/chapters", BookChaptersAPIHandler)
+						router.With(GetScriptParamsMiddleware).Get("/chapters/{index:\\d+}", BookChapterAPIHandler)
+						router.Get("/work", BookWorkAPIHandler)
+						router.With(requireAdmin).With(AuditMiddleware).Post("/split", BookSplitWorkAPIHandler)
+					})
 				})
 			})
-		})
 
-		router.Get("/db-stats", DBStatsAPIHandler(readDataServices))
+			router.Route("/works/{workID:\\d+}", func(router chi.Router) {
+				router.Use(GetReadDataServiceMiddleware(readDataServices))
+				router.Use(GetFormatServiceMiddleware(formatServ))
+				router.Use(GetWorkMiddleware)
+				router.Get("/", WorkInfoAPIHandler)
+				router.With(rateLimit).With(AuditMiddleware).With(GetDownloadParamsMiddleware).With(GetScriptParamsMiddleware).
+					Get("/download", WorkDownloadAPIHandler)
+				router.With(requireAdmin).With(AuditMiddleware).Post("/merge", WorkMergeAPIHandler)
+			})
+
+			router.Route("/writers", func(router chi.Router) {
+				router.Use(GetReadDataServiceMiddleware(readDataServices))
+				router.With(rateLimit).With(GetSearchParamsMiddleware).With(GetPageParamsMiddleware).Get("/search", WriterSearchAPIHandler)
+				router.With(GetWriterOrderParamsMiddleware).With(GetPageParamsMiddleware).Get("/top", WriterTopAPIHandler)
+
+				router.Route("/{writerID:\\d+}", func(router chi.Router) {
+					router.Use(GetSortParamsMiddleware)
+					router.Use(GetWriterMiddleware)
+					router.Get("/", WriterInfoAPIHandler)
+				})
+			})
+
+			router.Route("/genres", func(router chi.Router) {
+				router.Use(GetReadDataServiceMiddleware(readDataServices))
+				router.Get("/", GenreListAPIHandler)
+				router.With(GetBrowseParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).Get("/{genre}/books", GenreBooksAPIHandler)
+			})
+
+			router.With(requireAdmin).Get("/db-stats", DBStatsAPIHandler(readDataServices))
+
+			if importService != nil {
+				router.With(requireAdmin).With(AuditMiddleware).Post("/books/import", BookImportAPIHandler(importService))
+			}
+		})
 	})
 
 	router.Get("/docs/swagger/*", httpSwagger.WrapHandler)
@@ -0,0 +1,477 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ForbiddenError       = errors.New("forbidden")
+	TooManyRequestsError = errors.New("too many requests")
+)
+
+const (
+	apiKeyHeader      = "X-API-Key"
+	sessionCookieName = "bookspider_session"
+	defaultSessionTTL = 24 * time.Hour
+	// maxRateLimitBuckets triggers cleanup of idle buckets, so buckets of
+	// anonymous clients do not grow forever
+	maxRateLimitBuckets = 10000
+	rateLimitBucketIdle = 10 * time.Minute
+)
+
+// dummyPasswordHash is compared for unknown user, so the response time does
+// not tell whether the user exists
+var dummyPasswordHash = []byte("$2a$10$OJ1QAUUMsFpJzZAfbxjp3uS1gzD1.RHM6tEihbUPwTxk//rf0dcRG")
+
+// authRateLimit limits the session creation and failed authentication of each
+// remote address, so credentials can't be brute forced
+var authRateLimit = config.ClientRateLimitConfig{Rate: 0.2, Burst: 5}
+
+// Role is the scope of client, admin can do everything reader can do
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleAdmin  Role = "admin"
+)
+
+var roleLevels = map[Role]int{RoleReader: 1, RoleAdmin: 2}
+
+// Allow returns true if the role covers the required role
+func (role Role) Allow(required Role) bool {
+	return roleLevels[role] >= roleLevels[required]
+}
+
+// Client is the caller of api identified by api key, user or session.
+// anonymous clients are named by their remote address
+type Client struct {
+	Name      string
+	Role      Role
+	Anonymous bool
+	RateLimit config.ClientRateLimitConfig
+}
+
+type authSession struct {
+	client    Client
+	expiredAt time.Time
+}
+
+// Authenticator identifies the client of request by api key in `X-API-Key`
+// or bearer token, basic auth of users or the session cookie created by
+// basic auth
+type Authenticator struct {
+	enabled    bool
+	keys       map[string]Client
+	users      map[string]Client
+	passwords  map[string][]byte
+	anonymous  *Client
+	sessionTTL time.Duration
+	rateLimit  config.ClientRateLimitConfig
+
+	lock     sync.Mutex
+	sessions map[string]authSession
+	now      func() time.Time
+}
+
+func NewAuthenticator(conf config.AuthConfig) *Authenticator {
+	auth := &Authenticator{
+		enabled:    conf.Enabled(),
+		keys:       make(map[string]Client, len(conf.APIKeys)),
+		users:      make(map[string]Client, len(conf.Users)),
+		passwords:  make(map[string][]byte, len(conf.Users)),
+		sessionTTL: conf.SessionTTL,
+		rateLimit:  conf.RateLimit,
+		sessions:   make(map[string]authSession),
+		now:        time.Now,
+	}
+	if auth.sessionTTL == 0 {
+		auth.sessionTTL = defaultSessionTTL
+	}
+
+	clientRateLimit := func(rateLimit *config.ClientRateLimitConfig) config.ClientRateLimitConfig {
+		if rateLimit == nil {
+			return conf.RateLimit
+		}
+		return *rateLimit
+	}
+
+	for _, key := range conf.APIKeys {
+		auth.keys[strings.ToLower(key.KeySHA256)] = Client{
+			Name: key.Name, Role: Role(key.Role), RateLimit: clientRateLimit(key.RateLimit),
+		}
+	}
+
+	for _, user := range conf.Users {
+		auth.users[user.Name] = Client{Name: user.Name, Role: Role(user.Role), RateLimit: clientRateLimit(user.RateLimit)}
+		auth.passwords[user.Name] = []byte(user.PasswordBcrypt)
+	}
+
+	if !auth.enabled {
+		// read routes were public before auth was introduced, keep them public
+		// if nothing is configured. admin routes are rejected as no one can
+		// be admin
+		auth.anonymous = &Client{Role: RoleReader}
+	} else if conf.AnonymousRole != "" {
+		auth.anonymous = &Client{Role: Role(conf.AnonymousRole)}
+	}
+
+	return auth
+}
+
+func hashSHA256(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
+
+// Authenticate returns the client of request. error is returned if the
+// request carries invalid credentials, or no credentials while anonymous
+// access is not allowed
+func (auth *Authenticator) Authenticate(req *http.Request) (*Client, error) {
+	if key := req.Header.Get(apiKeyHeader); key != "" {
+		return auth.authenticateKey(key)
+	}
+
+	if authorization := req.Header.Get("Authorization"); authorization != "" {
+		if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+			return auth.authenticateKey(token)
+		}
+
+		if user, password, ok := req.BasicAuth(); ok {
+			return auth.authenticateUser(user, password)
+		}
+
+		return nil, UnauthorizedError
+	}
+
+	if cookie, err := req.Cookie(sessionCookieName); err == nil {
+		return auth.authenticateSession(cookie.Value)
+	}
+
+	if auth.anonymous == nil {
+		return nil, UnauthorizedError
+	}
+
+	client := *auth.anonymous
+	client.Name, client.Anonymous, client.RateLimit = "anonymous:"+remoteHost(req), true, auth.rateLimit
+
+	return &client, nil
+}
+
+func (auth *Authenticator) authenticateKey(key string) (*Client, error) {
+	client, ok := auth.keys[hex.EncodeToString(hashSHA256(key))]
+	if !ok {
+		return nil, UnauthorizedError
+	}
+
+	return &client, nil
+}
+
+func (auth *Authenticator) authenticateUser(user, password string) (*Client, error) {
+	client, ok := auth.users[user]
+	hash := auth.passwords[user]
+	if !ok {
+		hash = dummyPasswordHash
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return nil, UnauthorizedError
+	}
+
+	return &client, nil
+}
+
+func (auth *Authenticator) authenticateSession(token string) (*Client, error) {
+	auth.lock.Lock()
+	defer auth.lock.Unlock()
+
+	session, ok := auth.sessions[token]
+	if !ok {
+		return nil, UnauthorizedError
+	}
+
+	if !auth.now().Before(session.expiredAt) {
+		delete(auth.sessions, token)
+		return nil, UnauthorizedError
+	}
+
+	return &session.client, nil
+}
+
+// CreateSession creates session of the user client, so browser can call api
+// by cookie instead of sending password in every request
+func (auth *Authenticator) CreateSession(client Client) (string, time.Time, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	auth.lock.Lock()
+	defer auth.lock.Unlock()
+
+	now := auth.now()
+	for key, session := range auth.sessions {
+		if !now.Before(session.expiredAt) {
+			delete(auth.sessions, key)
+		}
+	}
+
+	expiredAt := now.Add(auth.sessionTTL)
+	auth.sessions[token] = authSession{client: client, expiredAt: expiredAt}
+
+	return token, expiredAt, nil
+}
+
+// DeleteSession removes the session, it is no-op for unknown token
+func (auth *Authenticator) DeleteSession(token string) {
+	auth.lock.Lock()
+	defer auth.lock.Unlock()
+
+	delete(auth.sessions, token)
+}
+
+func remoteHost(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+
+	return host
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// RateLimiter keeps a token bucket per client, the bucket of client is
+// refilled by the rate in its config
+type RateLimiter struct {
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{buckets: make(map[string]*tokenBucket), now: time.Now}
+}
+
+// Allow takes a token from the bucket of client. the wait time until next
+// token is returned if the bucket is empty
+func (limiter *RateLimiter) Allow(client Client) (bool, time.Duration) {
+	if client.RateLimit.Rate <= 0 {
+		return true, 0
+	}
+
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	bucket := limiter.refill(client)
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / client.RateLimit.Rate * float64(time.Second))
+	}
+
+	bucket.tokens--
+
+	return true, 0
+}
+
+// Wait returns the wait time until the bucket of client has a token without
+// taking it, zero is returned if a token is available
+func (limiter *RateLimiter) Wait(client Client) time.Duration {
+	if client.RateLimit.Rate <= 0 {
+		return 0
+	}
+
+	limiter.lock.Lock()
+	defer limiter.lock.Unlock()
+
+	bucket := limiter.refill(client)
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / client.RateLimit.Rate * float64(time.Second))
+	}
+
+	return 0
+}
+
+// refill returns the bucket of client with tokens refilled until now, lock
+// of limiter must be held
+func (limiter *RateLimiter) refill(client Client) *tokenBucket {
+	rate, burst := client.RateLimit.Rate, float64(max(client.RateLimit.Burst, 1))
+
+	now := limiter.now()
+	bucket, ok := limiter.buckets[client.Name]
+	if !ok && len(limiter.buckets) >= maxRateLimitBuckets {
+		for name, bucket := range limiter.buckets {
+			if now.Sub(bucket.updatedAt) > rateLimitBucketIdle {
+				delete(limiter.buckets, name)
+			}
+		}
+	}
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, updatedAt: now}
+		limiter.buckets[client.Name] = bucket
+	}
+
+	bucket.tokens = min(burst, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+	bucket.updatedAt = now
+
+	return bucket
+}
+
+// clientFromContext returns the authenticated client, nil is returned if
+// the route is not authenticated
+func clientFromContext(ctx context.Context) *Client {
+	client, ok := ctx.Value(ContextKeyClient).(*Client)
+	if !ok {
+		return nil
+	}
+
+	return client
+}
+
+// AuthMiddleware sets the client of request into context, request without
+// valid credentials is rejected with 401. failed authentication takes a token
+// of remote address, remote address without token is rejected with 429
+// before checking credentials
+func AuthMiddleware(auth *Authenticator, limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				remote := authLimitClient(req)
+				if wait := limiter.Wait(remote); wait > 0 {
+					writeTooManyRequests(res, req, remote, wait)
+					return
+				}
+
+				client, err := auth.Authenticate(req)
+				if err != nil {
+					limiter.Allow(remote)
+					zerolog.Ctx(req.Context()).Warn().Err(err).Str("remote", remoteHost(req)).Msg("authenticate failed")
+					writeError(res, http.StatusUnauthorized, UnauthorizedError)
+					return
+				}
+
+				ctx := context.WithValue(req.Context(), ContextKeyClient, client)
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// RequireRoleMiddleware rejects client without the role with 403
+func RequireRoleMiddleware(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				client := clientFromContext(req.Context())
+				if client == nil || !client.Role.Allow(role) {
+					writeError(res, http.StatusForbidden, ForbiddenError)
+					return
+				}
+
+				next.ServeHTTP(res, req)
+			},
+		)
+	}
+}
+
+// RateLimitMiddleware limits the requests of client on expensive routes,
+// 429 is returned with Retry-After once the token bucket of client is empty
+func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				client := clientFromContext(req.Context())
+				if client != nil && !allowRequest(res, req, limiter, *client) {
+					return
+				}
+
+				next.ServeHTTP(res, req)
+			},
+		)
+	}
+}
+
+// SessionRateLimitMiddleware limits the session creation by remote address,
+// as the client is not authenticated yet
+func SessionRateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				if !allowRequest(res, req, limiter, authLimitClient(req)) {
+					return
+				}
+
+				next.ServeHTTP(res, req)
+			},
+		)
+	}
+}
+
+// allowRequest takes a token of client, 429 is written with Retry-After if
+// the bucket of client is empty
+func allowRequest(res http.ResponseWriter, req *http.Request, limiter *RateLimiter, client Client) bool {
+	ok, wait := limiter.Allow(client)
+	if !ok {
+		writeTooManyRequests(res, req, client, wait)
+	}
+
+	return ok
+}
+
+func writeTooManyRequests(res http.ResponseWriter, req *http.Request, client Client, wait time.Duration) {
+	zerolog.Ctx(req.Context()).Warn().Str("client", client.Name).Str("path", req.URL.Path).Msg("rate limited")
+	res.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	writeError(res, http.StatusTooManyRequests, TooManyRequestsError)
+}
+
+// authLimitClient is the client limiting authentication of remote address,
+// session creation and failed authentication share its bucket
+func authLimitClient(req *http.Request) Client {
+	return Client{Name: "auth:" + remoteHost(req), Anonymous: true, RateLimit: authRateLimit}
+}
+
+// AuditMiddleware logs who requested the route and what book or work is
+// requested, it should be added after the book or work middleware
+func AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			wrappedRes := middleware.NewWrapResponseWriter(res, req.ProtoMajor)
+			next.ServeHTTP(wrappedRes, req)
+
+			event := zerolog.Ctx(req.Context()).Info().
+				Str("method", req.Method).
+				Str("path", req.URL.Path).
+				Str("query", req.URL.RawQuery).
+				Int("status", wrappedRes.Status())
+			if client := clientFromContext(req.Context()); client != nil {
+				event = event.Str("client", client.Name).Str("role", string(client.Role))
+			}
+			if bk, ok := req.Context().Value(ContextKeyBook).(*model.Book); ok && bk != nil {
+				event = event.Str("book", bk.String())
+			}
+			if work, ok := req.Context().Value(ContextKeyWork).(*model.Work); ok && work != nil {
+				event = event.Int("work", work.ID)
+			}
+
+			event.Msg("audit")
+		},
+	)
+}
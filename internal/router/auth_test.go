@@ -0,0 +1,598 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func bcryptHash(s string) string {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(s), bcrypt.MinCost)
+	return string(hash)
+}
+
+func testAuthConfig() config.AuthConfig {
+	return config.AuthConfig{
+		APIKeys: []config.APIKeyConfig{
+			{Name: "reader-key", KeySHA256: sha256Hex("reader-secret"), Role: "reader"},
+			{
+				Name: "admin-key", KeySHA256: sha256Hex("admin-secret"), Role: "admin",
+				RateLimit: &config.ClientRateLimitConfig{Rate: 10, Burst: 20},
+			},
+		},
+		Users: []config.UserConfig{
+			{Name: "user", PasswordBcrypt: bcryptHash("password"), Role: "reader"},
+		},
+		RateLimit: config.ClientRateLimitConfig{Rate: 1, Burst: 2},
+	}
+}
+
+func TestRole_Allow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		role     Role
+		required Role
+		want     bool
+	}{
+		{name: "admin allows admin", role: RoleAdmin, required: RoleAdmin, want: true},
+		{name: "admin allows reader", role: RoleAdmin, required: RoleReader, want: true},
+		{name: "reader allows reader", role: RoleReader, required: RoleReader, want: true},
+		{name: "reader not allows admin", role: RoleReader, required: RoleAdmin, want: false},
+		{name: "unknown role not allows reader", role: Role("unknown"), required: RoleReader, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.role.Allow(test.required))
+		})
+	}
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		conf       config.AuthConfig
+		setupReq   func(req *http.Request)
+		wantClient *Client
+		wantErr    error
+	}{
+		{
+			name:     "api key in header",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.Header.Set(apiKeyHeader, "reader-secret") },
+			wantClient: &Client{
+				Name: "reader-key", Role: RoleReader, RateLimit: config.ClientRateLimitConfig{Rate: 1, Burst: 2},
+			},
+		},
+		{
+			name:     "api key in bearer token with own rate limit",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.Header.Set("Authorization", "Bearer admin-secret") },
+			wantClient: &Client{
+				Name: "admin-key", Role: RoleAdmin, RateLimit: config.ClientRateLimitConfig{Rate: 10, Burst: 20},
+			},
+		},
+		{
+			name:     "invalid api key",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.Header.Set(apiKeyHeader, "unknown") },
+			wantErr:  UnauthorizedError,
+		},
+		{
+			name:     "basic auth of user",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.SetBasicAuth("user", "password") },
+			wantClient: &Client{
+				Name: "user", Role: RoleReader, RateLimit: config.ClientRateLimitConfig{Rate: 1, Burst: 2},
+			},
+		},
+		{
+			name:     "basic auth with wrong password",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.SetBasicAuth("user", "wrong") },
+			wantErr:  UnauthorizedError,
+		},
+		{
+			name:     "basic auth of unknown user",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.SetBasicAuth("unknown", "password") },
+			wantErr:  UnauthorizedError,
+		},
+		{
+			name:     "unsupported authorization scheme",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.Header.Set("Authorization", "Digest abc") },
+			wantErr:  UnauthorizedError,
+		},
+		{
+			name:     "unknown session",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) { req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: "unknown"}) },
+			wantErr:  UnauthorizedError,
+		},
+		{
+			name:     "anonymous not allowed",
+			conf:     testAuthConfig(),
+			setupReq: func(req *http.Request) {},
+			wantErr:  UnauthorizedError,
+		},
+		{
+			name: "anonymous with configured role",
+			conf: func() config.AuthConfig {
+				conf := testAuthConfig()
+				conf.AnonymousRole = "reader"
+				return conf
+			}(),
+			setupReq: func(req *http.Request) {},
+			wantClient: &Client{
+				Name: "anonymous:192.0.2.1", Role: RoleReader, Anonymous: true,
+				RateLimit: config.ClientRateLimitConfig{Rate: 1, Burst: 2},
+			},
+		},
+		{
+			name:       "auth disabled allows anonymous as reader",
+			conf:       config.AuthConfig{},
+			setupReq:   func(req *http.Request) {},
+			wantClient: &Client{Name: "anonymous:192.0.2.1", Role: RoleReader, Anonymous: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			auth := NewAuthenticator(test.conf)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			test.setupReq(req)
+
+			client, err := auth.Authenticate(req)
+			assert.Equal(t, test.wantClient, client)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestAuthenticator_Session(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	conf := testAuthConfig()
+	conf.SessionTTL = time.Hour
+
+	tests := []struct {
+		name       string
+		elapsed    time.Duration
+		delete     bool
+		wantClient *Client
+		wantErr    error
+	}{
+		{
+			name:       "valid session",
+			elapsed:    time.Minute,
+			wantClient: &Client{Name: "user", Role: RoleReader},
+		},
+		{
+			name:    "expired session",
+			elapsed: time.Hour,
+			wantErr: UnauthorizedError,
+		},
+		{
+			name:    "deleted session",
+			elapsed: time.Minute,
+			delete:  true,
+			wantErr: UnauthorizedError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			auth := NewAuthenticator(conf)
+			auth.now = func() time.Time { return now }
+
+			token, expiredAt, err := auth.CreateSession(Client{Name: "user", Role: RoleReader})
+			assert.NoError(t, err)
+			assert.Equal(t, now.Add(time.Hour), expiredAt)
+
+			if test.delete {
+				auth.DeleteSession(token)
+			}
+			auth.now = func() time.Time { return now.Add(test.elapsed) }
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: token})
+
+			client, err := auth.Authenticate(req)
+			assert.Equal(t, test.wantClient, client)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		client    Client
+		elapsed   []time.Duration
+		wantAllow []bool
+		wantWait  time.Duration
+	}{
+		{
+			name:      "unlimited client",
+			client:    Client{Name: "unlimited"},
+			elapsed:   []time.Duration{0, 0, 0},
+			wantAllow: []bool{true, true, true},
+		},
+		{
+			name:      "burst is consumed and then rejected",
+			client:    Client{Name: "limited", RateLimit: config.ClientRateLimitConfig{Rate: 1, Burst: 2}},
+			elapsed:   []time.Duration{0, 0, 0},
+			wantAllow: []bool{true, true, false},
+			wantWait:  time.Second,
+		},
+		{
+			name:      "bucket is refilled by rate",
+			client:    Client{Name: "refilled", RateLimit: config.ClientRateLimitConfig{Rate: 2, Burst: 1}},
+			elapsed:   []time.Duration{0, 0, 500 * time.Millisecond},
+			wantAllow: []bool{true, false, true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewRateLimiter()
+			current := now
+
+			var (
+				allows []bool
+				wait   time.Duration
+			)
+			for _, elapsed := range test.elapsed {
+				current = current.Add(elapsed)
+				limiter.now = func() time.Time { return current }
+
+				var ok bool
+				ok, wait = limiter.Allow(test.client)
+				allows = append(allows, ok)
+			}
+
+			assert.Equal(t, test.wantAllow, allows)
+			assert.Equal(t, test.wantWait, wait)
+		})
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		client   Client
+		taken    int
+		wantWait time.Duration
+	}{
+		{
+			name:     "token available",
+			client:   Client{Name: "available", RateLimit: config.ClientRateLimitConfig{Rate: 0.5, Burst: 2}},
+			taken:    1,
+			wantWait: 0,
+		},
+		{
+			name:     "bucket empty",
+			client:   Client{Name: "empty", RateLimit: config.ClientRateLimitConfig{Rate: 0.5, Burst: 2}},
+			taken:    2,
+			wantWait: 2 * time.Second,
+		},
+		{
+			name:     "unlimited client",
+			client:   Client{Name: "unlimited"},
+			taken:    5,
+			wantWait: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewRateLimiter()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			limiter.now = func() time.Time { return now }
+
+			for range test.taken {
+				limiter.Allow(test.client)
+			}
+
+			assert.Equal(t, test.wantWait, limiter.Wait(test.client))
+			// wait does not take token
+			assert.Equal(t, test.wantWait, limiter.Wait(test.client))
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		setupReq   func(req *http.Request)
+		requests   int
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "authenticated client is set into context",
+			setupReq:   func(req *http.Request) { req.Header.Set(apiKeyHeader, "reader-secret") },
+			requests:   1,
+			wantStatus: http.StatusOK,
+			wantBody:   "reader-key",
+		},
+		{
+			name:       "reject request without credentials",
+			setupReq:   func(req *http.Request) {},
+			requests:   1,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"error":"unauthorized"}` + "\n",
+		},
+		{
+			name:       "successful authentication is not limited",
+			setupReq:   func(req *http.Request) { req.SetBasicAuth("user", "password") },
+			requests:   6,
+			wantStatus: http.StatusOK,
+			wantBody:   "user",
+		},
+		{
+			name:       "failed authentication is limited by remote address",
+			setupReq:   func(req *http.Request) { req.SetBasicAuth("user", "wrong") },
+			requests:   6,
+			wantStatus: http.StatusTooManyRequests,
+			wantBody:   `{"error":"too many requests"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewRateLimiter()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			limiter.now = func() time.Time { return now }
+
+			handler := AuthMiddleware(NewAuthenticator(testAuthConfig()), limiter)(http.HandlerFunc(
+				func(res http.ResponseWriter, req *http.Request) {
+					res.Write([]byte(clientFromContext(req.Context()).Name))
+				},
+			))
+
+			var rr *httptest.ResponseRecorder
+			for range test.requests {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				test.setupReq(req)
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+			}
+
+			assert.Equal(t, test.wantStatus, rr.Code)
+			assert.Equal(t, test.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestRequireRoleMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		client     *Client
+		role       Role
+		wantStatus int
+	}{
+		{name: "admin passes admin route", client: &Client{Role: RoleAdmin}, role: RoleAdmin, wantStatus: http.StatusOK},
+		{name: "reader rejected by admin route", client: &Client{Role: RoleReader}, role: RoleAdmin, wantStatus: http.StatusForbidden},
+		{name: "missing client rejected", client: nil, role: RoleReader, wantStatus: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := RequireRoleMiddleware(test.role)(http.HandlerFunc(
+				func(res http.ResponseWriter, req *http.Request) {},
+			))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if test.client != nil {
+				req = req.WithContext(context.WithValue(req.Context(), ContextKeyClient, test.client))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		client         *Client
+		requests       int
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:       "within limit",
+			client:     &Client{Name: "within", RateLimit: config.ClientRateLimitConfig{Rate: 1, Burst: 2}},
+			requests:   2,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:           "exceed limit",
+			client:         &Client{Name: "exceed", RateLimit: config.ClientRateLimitConfig{Rate: 0.5, Burst: 1}},
+			requests:       2,
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "3",
+		},
+		{
+			name:       "no client in context",
+			client:     nil,
+			requests:   3,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewRateLimiter()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			limiter.now = func() time.Time { return now }
+
+			handler := RateLimitMiddleware(limiter)(http.HandlerFunc(
+				func(res http.ResponseWriter, req *http.Request) {},
+			))
+
+			var rr *httptest.ResponseRecorder
+			for i := 0; i < test.requests; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				if test.client != nil {
+					req = req.WithContext(context.WithValue(req.Context(), ContextKeyClient, test.client))
+				}
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+			}
+
+			assert.Equal(t, test.wantStatus, rr.Code)
+			assert.Equal(t, test.wantRetryAfter, rr.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestSessionRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		remoteAddrs []string
+		wantStatus  int
+	}{
+		{
+			name:        "within limit",
+			remoteAddrs: []string{"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.1:1002", "192.0.2.1:1003", "192.0.2.1:1004"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name: "exceed limit of same host",
+			remoteAddrs: []string{
+				"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.1:1002", "192.0.2.1:1003", "192.0.2.1:1004", "192.0.2.1:1005",
+			},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "limit per host",
+			remoteAddrs: []string{
+				"192.0.2.1:1000", "192.0.2.1:1001", "192.0.2.1:1002", "192.0.2.1:1003", "192.0.2.1:1004", "192.0.2.2:1000",
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := NewRateLimiter()
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			limiter.now = func() time.Time { return now }
+
+			handler := SessionRateLimitMiddleware(limiter)(http.HandlerFunc(
+				func(res http.ResponseWriter, req *http.Request) {},
+			))
+
+			var rr *httptest.ResponseRecorder
+			for _, remoteAddr := range test.remoteAddrs {
+				req := httptest.NewRequest(http.MethodPost, "/auth/session", nil)
+				req.RemoteAddr = remoteAddr
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+			}
+
+			assert.Equal(t, test.wantStatus, rr.Code)
+		})
+	}
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		status     int
+		wantStatus int
+	}{
+		{
+			name: "pass through status with book and client",
+			ctx: context.WithValue(
+				context.WithValue(context.Background(), ContextKeyClient, &Client{Name: "admin", Role: RoleAdmin}),
+				ContextKeyBook, &model.Book{Site: "test", ID: 1},
+			),
+			status:     http.StatusOK,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "pass through error status without context values",
+			ctx:        context.Background(),
+			status:     http.StatusBadRequest,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := AuditMiddleware(http.HandlerFunc(
+				func(res http.ResponseWriter, req *http.Request) {
+					res.WriteHeader(test.status)
+				},
+			))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(test.ctx)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, test.wantStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package router
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	"github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const (
+	bundlePageSize = 50
+	// bundleMaxBooks limits the books queried for one bundle, the content of
+	// books is streamed one by one so memory only grows with the manifest
+	bundleMaxBooks   = 200
+	bundleManifest   = "manifest.json"
+	bundleSkipReason = "not downloaded"
+)
+
+// bundleFormats are the formats can be written book by book into bundle
+var bundleFormats = map[string]bool{"txt": true, "epub": true}
+
+type bundleManifestBook struct {
+	Site     string `json:"site"`
+	ID       int    `json:"id"`
+	HashCode string `json:"hash_code"`
+	Title    string `json:"title"`
+	Writer   string `json:"writer"`
+	Status   string `json:"status"`
+	File     string `json:"file,omitempty"`
+	Skipped  string `json:"skipped,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type bundleManifestContent struct {
+	Title     string               `json:"title"`
+	Writer    string               `json:"writer"`
+	Format    string               `json:"format"`
+	Script    string               `json:"script"`
+	Truncated bool                 `json:"truncated"`
+	Books     []bundleManifestBook `json:"books"`
+}
+
+var bundleFileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", "\"", "_")
+
+// bundleFileName prefixes book key to file name, so books with same title
+// and writer in different sites are kept
+func bundleFileName(bk *model.Book, title, writer, extension string) string {
+	return bundleFileNameReplacer.Replace(fmt.Sprintf("%s-%s-%s.%s", bk.String(), title, writer, extension))
+}
+
+// searchBundleBooks pages through search result until the last page or the
+// books reach bundleMaxBooks. truncated is true if there may be more books
+func searchBundleBooks(req *http.Request, serv service.ReadDataService, title, writer string) ([]model.Book, bool, error) {
+	var bks []model.Book
+	params := repo.PageParams{Sort: model.BookSortUpdated, Limit: bundlePageSize}
+	for len(bks) < bundleMaxBooks {
+		page, err := serv.SearchBooks(req.Context(), title, writer, "", params)
+		if err != nil {
+			return nil, false, err
+		}
+
+		bks = append(bks, page.Books...)
+		if page.NextCursor == "" {
+			return bks, false, nil
+		}
+		params.Cursor = page.NextCursor
+	}
+
+	if len(bks) > bundleMaxBooks {
+		bks = bks[:bundleMaxBooks]
+	}
+
+	return bks, true, nil
+}
+
+// writeBundleBook writes the content of downloaded book into zip in the format
+func writeBundleBook(
+	req *http.Request, serv service.ReadDataService, zipWriter *zip.Writer,
+	bk *model.Book, formatStr string, script model.Script,
+) (string, error) {
+	file, err := serv.BookFile(req.Context(), bk)
+	if err != nil {
+		return "", fmt.Errorf("open book file failed: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("stat book file failed: %w", err)
+	}
+
+	content := model.NewScriptReader(file, info.Size(), script)
+	convertedBk := *bk
+	convertedBk.Title, convertedBk.Writer.Name = script.Convert(bk.Title), script.Convert(bk.Writer.Name)
+	fileName := bundleFileName(bk, convertedBk.Title, convertedBk.Writer.Name, downloadFormats[formatStr].extension)
+
+	header := &zip.FileHeader{Name: fileName, Method: zip.Deflate, Modified: info.ModTime()}
+	if formatStr == "epub" {
+		// epub is compressed already
+		header.Method = zip.Store
+	}
+
+	entry, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return "", fmt.Errorf("create zip entry failed: %w", err)
+	}
+
+	formatServ := formatServiceFromContext(req.Context())
+	if formatStr == "epub" {
+		err = formatServ.WriteBookEpubFromTxt(req.Context(), &convertedBk, content, entry)
+	} else {
+		err = formatServ.WriteBookTxtFromTxt(req.Context(), &convertedBk, content, entry)
+	}
+	if err != nil {
+		return fileName, fmt.Errorf("write book failed: %w", err)
+	}
+
+	return fileName, nil
+}
+
+// serveBookBundle streams the downloaded books of search result as a zip in
+// the format and script, a manifest is written at the end of zip listing
+// every book found
+func serveBookBundle(
+	res http.ResponseWriter, req *http.Request, serv service.ReadDataService,
+	title, writer, formatStr string, script model.Script,
+) {
+	logger := zerolog.Ctx(req.Context())
+
+	if (title == "" && writer == "") || !bundleFormats[formatStr] {
+		writeError(res, http.StatusBadRequest, InvalidParamsError)
+		return
+	}
+
+	bks, truncated, err := searchBundleBooks(req, serv, title, writer)
+	if err != nil {
+		logger.Error().Err(err).Str("title", title).Str("writer", writer).Msg("query bundle books failed")
+		writeError(res, http.StatusBadRequest, err)
+		return
+	}
+
+	bundleName := bundleFileNameReplacer.Replace(
+		fmt.Sprintf("bundle-%s.zip", strings.Trim(script.Convert(title)+"-"+script.Convert(writer), "-")),
+	)
+	res.Header().Set("Content-Type", "application/zip")
+	res.Header().Set("Content-Disposition", "attachment; filename=\""+bundleName+"\"")
+
+	manifest := bundleManifestContent{
+		Title: title, Writer: writer, Format: formatStr, Script: string(script),
+		Truncated: truncated, Books: make([]bundleManifestBook, 0, len(bks)),
+	}
+
+	zipWriter := zip.NewWriter(res)
+	for i := range bks {
+		bk := &bks[i]
+		item := bundleManifestBook{
+			Site: bk.Site, ID: bk.ID, HashCode: bk.FormatHashCode(),
+			Title: bk.Title, Writer: bk.Writer.Name, Status: bk.Status.String(),
+		}
+
+		if !bk.IsDownloaded {
+			item.Skipped = bundleSkipReason
+		} else if item.File, err = writeBundleBook(req, serv, zipWriter, bk, formatStr, script); err != nil {
+			logger.Error().Err(err).Str("book", bk.String()).Str("format", formatStr).Msg("write bundle book failed")
+			item.Error = err.Error()
+		}
+
+		manifest.Books = append(manifest.Books, item)
+	}
+
+	entry, err := zipWriter.Create(bundleManifest)
+	if err == nil {
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(manifest)
+	}
+	if err == nil {
+		err = zipWriter.Close()
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("write bundle failed")
+	}
+}
@@ -0,0 +1,244 @@
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/rs/zerolog"
+)
+
+// gzipResponseWriter compresses the body of successful responses only, so
+// error and not modified responses are kept as is
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gzipWriter  *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if statusCode == http.StatusOK {
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.gzipWriter = gzip.NewWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.gzipWriter != nil {
+		return w.gzipWriter.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *gzipResponseWriter) Close() error {
+	if w.gzipWriter == nil {
+		return nil
+	}
+
+	return w.gzipWriter.Close()
+}
+
+func acceptGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		if strings.TrimSpace(strings.Split(encoding, ";")[0]) == "gzip" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bookETag identifies the book content in the script. the hash code changes
+// when the book is renewed and the mod time changes when it is redownloaded
+func bookETag(bk *model.Book, modTime time.Time, script model.Script) string {
+	return fmt.Sprintf(`"%s-%s-%s"`, bk.String(), strconv.FormatInt(modTime.Unix(), 36), script)
+}
+
+// etagWithSuffix appends suffix inside the quotes, so the etag of another
+// representation is still a valid entity tag
+func etagWithSuffix(etag, suffix string) string {
+	return strings.TrimSuffix(etag, `"`) + "-" + suffix + `"`
+}
+
+// notModified checks conditional get headers for responses can't be served by http.ServeContent
+func notModified(req *http.Request, etag string, modTime time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if modTime.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modTime.Truncate(time.Second).After(since)
+}
+
+// downloadFormat is the response of a download format
+type downloadFormat struct {
+	contentType string
+	extension   string
+}
+
+var downloadFormats = map[string]downloadFormat{
+	"txt":      {contentType: "text/txt; charset=utf-8", extension: "txt"},
+	"epub":     {contentType: "application/epub+zip; charset=utf-8", extension: "epub"},
+	"fb2":      {contentType: "application/x-fictionbook+xml; charset=utf-8", extension: "fb2"},
+	"html":     {contentType: "text/html; charset=utf-8", extension: "html"},
+	"markdown": {contentType: "text/markdown; charset=utf-8", extension: "md"},
+}
+
+// serveBookContent streams the book content file in the format and script
+func serveBookContent(res http.ResponseWriter, req *http.Request, bk *model.Book, file *os.File, formatStr string, script model.Script) {
+	logger := zerolog.Ctx(req.Context())
+
+	info, err := file.Stat()
+	if err != nil {
+		logger.Error().Err(err).Str("book", bk.String()).Msg("stat book file failed")
+		writeError(res, http.StatusInternalServerError, err)
+		return
+	}
+
+	content := model.NewScriptReader(file, info.Size(), script)
+	title, writer := script.Convert(bk.Title), script.Convert(bk.Writer.Name)
+	format, ok := downloadFormats[formatStr]
+	if !ok {
+		formatStr, format = "txt", downloadFormats["txt"]
+	}
+	fileName := fmt.Sprintf("%s-%s.%s", title, writer, format.extension)
+	convertedBk := *bk
+	convertedBk.Title, convertedBk.Writer.Name = title, writer
+	formatServ := formatServiceFromContext(req.Context())
+
+	etag := bookETag(bk, info.ModTime(), script)
+	out := io.Writer(res)
+	if formatStr != "txt" {
+		etag = etagWithSuffix(etag, formatStr)
+	} else {
+		// compressed content is another representation, so it can't share the
+		// etag of the plain content
+		res.Header().Set("Vary", "Accept-Encoding")
+		if acceptGzip(req) {
+			etag = etagWithSuffix(etag, "gzip")
+			gzipRes := &gzipResponseWriter{ResponseWriter: res}
+			defer gzipRes.Close()
+			out = gzipRes
+		}
+	}
+
+	// converted content is streamed without rendering the whole book, so it
+	// is not served in range
+	res.Header().Set("Accept-Ranges", "none")
+	res.Header().Set("ETag", etag)
+	res.Header().Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	if notModified(req, etag, info.ModTime()) {
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// txt and epub are written while reading the content, other formats need
+	// every chapter at once
+	writeBook := func(w io.Writer) error {
+		return formatServ.WriteBookEpubFromTxt(req.Context(), &convertedBk, content, w)
+	}
+	if formatStr == "txt" {
+		writeBook = func(w io.Writer) error {
+			return formatServ.WriteBookTxtFromTxt(req.Context(), &convertedBk, content, w)
+		}
+	} else if formatStr != "epub" {
+		chapters, err := formatServ.ChaptersFromTxt(req.Context(), content)
+		if err != nil {
+			logger.Error().Err(err).Str("book", bk.String()).Msg("read chapters failed")
+			writeError(res, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeChapters := map[string]func(context.Context, *model.Book, model.Chapters, io.Writer) error{
+			"fb2":      formatServ.WriteBookFB2,
+			"html":     formatServ.WriteBookHTML,
+			"markdown": formatServ.WriteBookMarkdown,
+		}[formatStr]
+		writeBook = func(w io.Writer) error {
+			return writeChapters(req.Context(), &convertedBk, chapters, w)
+		}
+	}
+
+	res.Header().Set("Content-Type", format.contentType)
+	res.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+"\"")
+	if err := writeBook(out); err != nil {
+		logger.Error().Err(err).Str("book", bk.String()).Str("format", formatStr).Msg("write book failed")
+	}
+}
+
+// coverETag identifies the generated cover, which only changes with the
+// title, writer and script of book
+func coverETag(bk *model.Book, script model.Script) string {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s\n%s", bk.Title, bk.Writer.Name)
+
+	return fmt.Sprintf(`"%s-%x-%s-cover"`, bk.String(), h.Sum32(), script)
+}
+
+// serveBookCover renders the generated png cover of book in the script
+func serveBookCover(res http.ResponseWriter, req *http.Request, bk *model.Book, script model.Script) {
+	logger := zerolog.Ctx(req.Context())
+
+	etag := coverETag(bk, script)
+	res.Header().Set("ETag", etag)
+	res.Header().Set("Cache-Control", "public, max-age=86400")
+	if notModified(req, etag, time.Time{}) {
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	convertedBk := *bk
+	convertedBk.Title, convertedBk.Writer.Name = script.Convert(bk.Title), script.Convert(bk.Writer.Name)
+
+	// render before writing header, so failure can still be reported as error
+	var buf bytes.Buffer
+	err := formatServiceFromContext(req.Context()).WriteBookCover(req.Context(), &convertedBk, &buf)
+	if err != nil {
+		logger.Error().Err(err).Str("book", bk.String()).Msg("write cover failed")
+		res.Header().Del("ETag")
+		res.Header().Del("Cache-Control")
+		writeError(res, http.StatusInternalServerError, err)
+		return
+	}
+
+	res.Header().Set("Content-Type", "image/png")
+	res.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	res.Write(buf.Bytes())
+}
@@ -4,10 +4,8 @@ import (
 	"embed"
 	"fmt"
 	"net/http"
-	"strings"
 	"text/template"
 
-	"github.com/htchan/BookSpider/internal/format/v1"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
@@ -49,7 +47,8 @@ func GeneralLiteHandler(services map[string]service.Service) http.HandlerFunc {
 		execErr := t.ExecuteTemplate(res, "sites.html", struct {
 			Services  map[string]service.Service
 			UriPrefix string
-		}{Services: services, UriPrefix: uriPrefix})
+			Genres    []model.Genre
+		}{Services: services, UriPrefix: uriPrefix, Genres: model.Genres})
 		if execErr != nil {
 			res.WriteHeader(http.StatusInternalServerError)
 			logger.Error().Err(execErr).Msg("compute response failed")
@@ -99,6 +98,7 @@ func SiteLiteHandlerfunc(res http.ResponseWriter, req *http.Request) {
 // @Tags			book-spider-lite
 // @Produce		html
 // @Param			siteName	path		string	true	"site name"
+// @Param			genre		query		string	false	"genre of books"
 // @Success		200			{string}	string
 // @Router			/lite/book-spider/sites/{siteName}/search [get]
 func SearchLiteHandler(res http.ResponseWriter, req *http.Request) {
@@ -122,15 +122,10 @@ func SearchLiteHandler(res http.ResponseWriter, req *http.Request) {
 	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
 	title := req.Context().Value(ContextKeyTitle).(string)
 	writer := req.Context().Value(ContextKeyWriter).(string)
-	page := req.Context().Value(ContextKeyPage).(int)
-	perPage := req.Context().Value(ContextKeyPerPage).(int)
-	limit := req.Context().Value(ContextKeyLimit).(int)
-	offset := req.Context().Value(ContextKeyOffset).(int)
-	if limit == 0 {
-		limit = 10
-	}
+	pageParams := pageParamsFromContext(req.Context())
 
-	bks, err := serv.SearchBooks(req.Context(), title, writer, limit, offset)
+	genre := genreFromContext(req.Context())
+	bkPage, err := serv.SearchBooks(req.Context(), title, writer, genre, pageParams)
 
 	if err != nil {
 		res.WriteHeader(404)
@@ -142,21 +137,27 @@ func SearchLiteHandler(res http.ResponseWriter, req *http.Request) {
 		Name           string
 		UriPrefix      string
 		Books          []model.Book
+		Sources        map[int]model.BookGroup
 		Title          string
 		Writer         string
-		PreviousPage   int
-		NextPage       int
+		Genre          model.Genre
+		Sort           model.BookSort
+		PrevCursor     string
+		NextCursor     string
 		PerPage        int
 		ShowPagination bool
 	}{
 		Name:           "Search Result",
 		UriPrefix:      uriPrefix,
-		Books:          bks,
+		Books:          bkPage.Books,
+		Sources:        bkPage.Sources,
 		Title:          title,
 		Writer:         writer,
-		PreviousPage:   page - 1,
-		NextPage:       page + 1,
-		PerPage:        perPage,
+		Genre:          genre,
+		Sort:           pageParams.Sort,
+		PrevCursor:     bkPage.PrevCursor,
+		NextCursor:     bkPage.NextCursor,
+		PerPage:        pageParams.Limit,
 		ShowPagination: true,
 	})
 	if execErr != nil {
@@ -166,11 +167,31 @@ func SearchLiteHandler(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// @Summary		Search result bundle
+// @description	download all downloaded books of search result as a zip with manifest.json
+// @Tags			book-spider-lite
+// @Produce		application/zip
+// @Param			siteName	path	string	true	"site name"
+// @Param			title		query	string	false	"title of books, either title or writer is required"
+// @Param			writer		query	string	false	"writer of books, either title or writer is required"
+// @Param			format		query	string	false	"format of books"	Enums(txt, epub)
+// @Param			script		query	string	false	"script of books"	Enums(original, s, t)
+// @Success		200			{file}	binary
+// @Router			/lite/book-spider/sites/{siteName}/bundle [get]
+func BundleLiteHandler(res http.ResponseWriter, req *http.Request) {
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	title := req.Context().Value(ContextKeyTitle).(string)
+	writer := req.Context().Value(ContextKeyWriter).(string)
+
+	serveBookBundle(res, req, serv, title, writer, downloadFormatFromContext(req.Context()), scriptFromContext(req.Context()))
+}
+
 // @Summary		Random result page
 // @description	random result page
 // @Tags			book-spider-lite
 // @Produce		html
 // @Param			siteName	path		string	true	"site name"
+// @Param			genre		query		string	false	"genre of books"
 // @Success		200			{string}	string
 // @Router			/lite/book-spider/sites/{siteName}/random [get]
 func RandomLiteHandler(res http.ResponseWriter, req *http.Request) {
@@ -194,11 +215,8 @@ func RandomLiteHandler(res http.ResponseWriter, req *http.Request) {
 
 	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
 	limit := req.Context().Value(ContextKeyLimit).(int)
-	if limit == 0 {
-		limit = 10
-	}
 
-	bks, err := serv.RandomBooks(req.Context(), limit)
+	bks, err := serv.RandomBooks(req.Context(), genreFromContext(req.Context()), bookSortFromContext(req.Context()), limit)
 
 	if err != nil {
 		res.WriteHeader(404)
@@ -210,6 +228,7 @@ func RandomLiteHandler(res http.ResponseWriter, req *http.Request) {
 		Name           string
 		UriPrefix      string
 		Books          []model.Book
+		Sources        map[int]model.BookGroup
 		ShowPagination bool
 	}{
 		Name:           "Random",
@@ -223,6 +242,207 @@ func RandomLiteHandler(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// @Summary		Writers page
+// @description	writers with most books or latest updates, or writers matching the name
+// @Tags			book-spider-lite
+// @Produce		html
+// @Param			writer		query		string	false	"name of writer"
+// @Param			order		query		string	false	"order of writers"	Enums(books, updated)
+// @Param			cursor		query		string	false	"cursor of page"
+// @Param			per_page	query		int		false	"items per page"
+// @Success		200			{string}	string
+// @Router			/lite/book-spider/writers [get]
+func WritersLiteHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	uriPrefix := req.Context().Value(ContextKeyUriPrefix).(string)
+	t, err := new(template.Template).
+		Funcs(customTemplateFunc).
+		ParseFS(
+			files,
+			"templates/writers.html",
+			"templates/styles/pagination.html",
+		)
+	if err != nil {
+		res.WriteHeader(http.StatusNotFound)
+		logger.Error().Err(err).Msg("writers lite handler parse fs fail")
+		return
+	}
+
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	name := req.Context().Value(ContextKeyWriter).(string)
+	pageParams := writerPageParamsFromContext(req.Context())
+
+	var writerPage *repo.WriterPage
+	if name != "" {
+		writerPage, err = serv.SearchWriters(req.Context(), name, pageParams)
+	} else {
+		writerPage, err = serv.TopWriters(req.Context(), pageParams)
+	}
+	if err != nil {
+		res.WriteHeader(404)
+		fmt.Fprint(res, "writers not found")
+		return
+	}
+
+	execErr := t.ExecuteTemplate(res, "writers.html", struct {
+		UriPrefix  string
+		Writers    []model.WriterSummary
+		Name       string
+		Order      model.WriterOrder
+		PrevCursor string
+		NextCursor string
+		PerPage    int
+	}{
+		UriPrefix:  uriPrefix,
+		Writers:    writerPage.Writers,
+		Name:       name,
+		Order:      pageParams.Order,
+		PrevCursor: writerPage.PrevCursor,
+		NextCursor: writerPage.NextCursor,
+		PerPage:    pageParams.Limit,
+	})
+	if execErr != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		logger.Error().Err(execErr).Msg("compute response failed")
+	}
+}
+
+// @Summary		Writer page
+// @description	writer page listing books across sites grouped by work
+// @Tags			book-spider-lite
+// @Produce		html
+// @Param			writerID	path		int	true	"writer id"
+// @Success		200			{string}	string
+// @Router			/lite/book-spider/writers/{writerID} [get]
+func WriterLiteHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	uriPrefix := req.Context().Value(ContextKeyUriPrefix).(string)
+	t, err := new(template.Template).
+		Funcs(customTemplateFunc).
+		ParseFS(
+			files,
+			"templates/writer.html",
+			"templates/components/book-card.html",
+			"templates/styles/book-box.html",
+		)
+	if err != nil {
+		res.WriteHeader(http.StatusNotFound)
+		logger.Error().Err(err).Msg("writer lite handler parse fs fail")
+		return
+	}
+
+	catalog := req.Context().Value(ContextKeyWriterInfo).(*model.WriterCatalog)
+
+	execErr := t.ExecuteTemplate(res, "writer.html", struct {
+		UriPrefix string
+		Writer    *model.WriterCatalog
+	}{
+		UriPrefix: uriPrefix,
+		Writer:    catalog,
+	})
+	if execErr != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		logger.Error().Err(execErr).Msg("compute response failed")
+	}
+}
+
+// @Summary		Genres page
+// @description	genres books can be browsed by
+// @Tags			book-spider-lite
+// @Produce		html
+// @Success		200	{string}	string
+// @Router			/lite/book-spider/genres [get]
+func GenresLiteHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	uriPrefix := req.Context().Value(ContextKeyUriPrefix).(string)
+	t, err := new(template.Template).
+		Funcs(customTemplateFunc).
+		ParseFS(files, "templates/genres.html")
+	if err != nil {
+		res.WriteHeader(http.StatusNotFound)
+		logger.Error().Err(err).Msg("genres lite handler parse fs fail")
+		return
+	}
+
+	execErr := t.ExecuteTemplate(res, "genres.html", struct {
+		UriPrefix string
+		Genres    []model.Genre
+	}{
+		UriPrefix: uriPrefix,
+		Genres:    model.Genres,
+	})
+	if execErr != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		logger.Error().Err(execErr).Msg("compute response failed")
+	}
+}
+
+// @Summary		Genre page
+// @description	books of genre filtered by site and status
+// @Tags			book-spider-lite
+// @Produce		html
+// @Param			genre		path		string	true	"genre of books"
+// @Param			site		query		string	false	"site of books"
+// @Param			status		query		string	false	"status of books"	Enums(INPROGRESS, END)
+// @Param			sort		query		string	false	"order of books"	Enums(updated, newest, title)
+// @Param			cursor		query		string	false	"cursor of page"
+// @Param			per_page	query		int		false	"items per page"
+// @Success		200			{string}	string
+// @Router			/lite/book-spider/genres/{genre} [get]
+func GenreLiteHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	uriPrefix := req.Context().Value(ContextKeyUriPrefix).(string)
+	t, err := new(template.Template).
+		Funcs(customTemplateFunc).
+		ParseFS(
+			files,
+			"templates/genre.html",
+			"templates/components/book-card.html",
+			"templates/styles/book-box.html",
+			"templates/styles/pagination.html",
+		)
+	if err != nil {
+		res.WriteHeader(http.StatusNotFound)
+		logger.Error().Err(err).Msg("genre lite handler parse fs fail")
+		return
+	}
+
+	serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+	params := req.Context().Value(ContextKeyBrowse).(repo.BrowseParams)
+	pageParams := pageParamsFromContext(req.Context())
+
+	bkPage, err := serv.BrowseBooks(req.Context(), params, pageParams)
+	if err != nil {
+		res.WriteHeader(404)
+		fmt.Fprint(res, "books not found")
+		return
+	}
+
+	execErr := t.ExecuteTemplate(res, "genre.html", struct {
+		UriPrefix  string
+		Params     repo.BrowseParams
+		Sort       model.BookSort
+		Books      []model.Book
+		Total      int
+		PrevCursor string
+		NextCursor string
+		PerPage    int
+	}{
+		UriPrefix:  uriPrefix,
+		Params:     params,
+		Sort:       pageParams.Sort,
+		Books:      bkPage.Books,
+		Total:      bkPage.Total,
+		PrevCursor: bkPage.PrevCursor,
+		NextCursor: bkPage.NextCursor,
+		PerPage:    pageParams.Limit,
+	})
+	if execErr != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		logger.Error().Err(execErr).Msg("compute response failed")
+	}
+}
+
 // @Summary		Book info page
 // @description	book info page
 // @Tags			book-spider-lite
@@ -272,7 +492,7 @@ func BookLiteHandler(res http.ResponseWriter, req *http.Request) {
 // @Produce		html
 // @Param			siteName	path		string	true	"site name"
 // @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
-// @Param			format		query		string	true	"txt (default) or epub"
+// @Param			format		query		string	false	"format of content"	Enums(txt, epub, fb2, html, markdown)
 // @Success		200			{string}	string
 // @Router			/lite/book-spider/sites/{siteName}/books/{idHash}/download [get]
 func DownloadLiteHandler(res http.ResponseWriter, req *http.Request) {
@@ -281,30 +501,27 @@ func DownloadLiteHandler(res http.ResponseWriter, req *http.Request) {
 	bk := req.Context().Value(ContextKeyBook).(*model.Book)
 	formatStr := req.Context().Value(ContextKeyFormat).(string)
 
-	content, err := serv.BookContent(req.Context(), bk)
+	file, err := serv.BookFile(req.Context(), bk)
 	if err != nil {
 		res.WriteHeader(500)
 		logger.Error().Err(err).Str("book", bk.String()).Msg("download lite handler failed")
 		return
 	}
+	defer file.Close()
 
-	switch formatStr {
-	case "epub":
-		formatServ := format.NewService()
-		chapters, err := formatServ.ChaptersFromTxt(req.Context(), strings.NewReader(content))
-		if err != nil {
-			res.WriteHeader(500)
-			logger.Error().Err(err).Str("book", bk.String()).Msg("download lite handler failed")
-			return
-		}
-		fileName := fmt.Sprintf("%s-%s.epub", bk.Title, bk.Writer.Name)
-		res.Header().Set("Content-Type", "application/epub+zip; charset=utf-8")
-		res.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+"\"")
-		formatServ.WriteBookEpub(req.Context(), bk, chapters, res)
-	default:
-		fileName := fmt.Sprintf("%s-%s.txt", bk.Title, bk.Writer.Name)
-		res.Header().Set("Content-Type", "text/txt; charset=utf-8")
-		res.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+"\"")
-		fmt.Fprint(res, content)
-	}
+	serveBookContent(res, req, bk, file, formatStr, scriptFromContext(req.Context()))
+}
+
+// @Summary		Book cover
+// @description	generated cover image of book shown in book cards
+// @Tags			book-spider-lite
+// @Produce		png
+// @Param			siteName	path	string	true	"site name"
+// @Param			idHash		path	string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Param			script		query	string	false	"script of title and writer"	Enums(original, s, t)
+// @Success		200			{file}	binary
+// @Router			/lite/book-spider/sites/{siteName}/books/{idHash}/cover [get]
+func CoverLiteHandler(res http.ResponseWriter, req *http.Request) {
+	bk := req.Context().Value(ContextKeyBook).(*model.Book)
+	serveBookCover(res, req, bk, scriptFromContext(req.Context()))
 }
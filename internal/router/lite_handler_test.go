@@ -1,12 +1,19 @@
 package router
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/BookSpider/internal/config/v2"
 	servicemock "github.com/htchan/BookSpider/internal/mock/service/v1"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
@@ -75,11 +82,18 @@ func TestGeneralLiteHandler(t *testing.T) {
 		<input type="text" id="title" name="title"><br>
 		<label for="lname">Writer:</label><br>
 		<input type="text" id="writer" name="writer"><br>
+		<label for="genre">Genre:</label><br>
+		<select id="genre" name="genre">
+		  <option value="">All</option>
+		  <option value="fantasy">玄幻奇幻</option><option value="xianxia">仙侠修真</option><option value="wuxia">武侠</option><option value="urban">都市</option><option value="romance">言情</option><option value="history">历史</option><option value="military">军事</option><option value="game">游戏竞技</option><option value="scifi">科幻</option><option value="horror">灵异悬疑</option><option value="fanfic">同人</option><option value="other">其他</option>
+		</select><br>
 		<input type="hidden" id="page" name="page" value="0"><br>
 		<input type="hidden" id="per_page" name="per_page" value="10"><br>
 		<input type="submit" value="Submit">
 	</form>
 	<button onclick="location.href='/lite/novel/random?per_page=10'">Random</button>
+	<button onclick="location.href='/lite/novel/writers?per_page=10'">Writers</button>
+	<button onclick="location.href='/lite/novel/genres'">Genres</button>
 	</div>
 	</body>
 </html>
@@ -190,15 +204,15 @@ func TestSearchLiteHandler(t *testing.T) {
 				t.Helper()
 
 				serv := servicemock.NewMockReadDataService(ctrl)
-				serv.EXPECT().SearchBooks(gomock.Any(), "title", "writer", 10, 0).Return(
-					[]model.Book{
+				serv.EXPECT().SearchBooks(gomock.Any(), "title", "writer", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: 10}).Return(
+					&repo.BookPage{Books: []model.Book{
 						{
 							Site: "test", ID: 123, HashCode: 100,
 							Title: "title", Writer: model.Writer{Name: "writer"},
 							Type: "type", UpdateDate: "date", UpdateChapter: "chapter",
 							Status: model.StatusEnd, IsDownloaded: true,
 						},
-					}, nil,
+					}, Total: 1}, nil,
 				)
 
 				req, err := http.NewRequest(http.MethodGet, "/", nil)
@@ -207,10 +221,7 @@ func TestSearchLiteHandler(t *testing.T) {
 				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
 				ctx = context.WithValue(ctx, ContextKeyTitle, "title")
 				ctx = context.WithValue(ctx, ContextKeyWriter, "writer")
-				ctx = context.WithValue(ctx, ContextKeyPage, 0)
-				ctx = context.WithValue(ctx, ContextKeyPerPage, 10)
 				ctx = context.WithValue(ctx, ContextKeyLimit, 10)
-				ctx = context.WithValue(ctx, ContextKeyOffset, 0)
 
 				return req.WithContext(ctx)
 			},
@@ -226,6 +237,16 @@ func TestSearchLiteHandler(t *testing.T) {
 			      padding-right: 1em;
 			      margin: 1em;
 				}
+				.cover {
+				  float: left;
+				  width: 6em;
+				  margin: 0.5em 1em 0.5em 0;
+				}
+				.book-box::after {
+				  content: "";
+				  display: block;
+				  clear: both;
+				}
 				.inline {
 				  display: inline-block;
 				}
@@ -251,13 +272,17 @@ func TestSearchLiteHandler(t *testing.T) {
 			
 			<body>
 			  <h1>Search Result</h1>
+			  
+			  
+			  <p>Download all: <a href="/lite/novel/bundle?title=title&writer=writer&format=txt">TXT</a> <a href="/lite/novel/bundle?title=title&writer=writer&format=epub">EPUB</a></p>
+			  
 			  <div>
 			    
-			    
 			      
 			  
 			  
 			  <div class="book-box" onclick="location.href='/lite/novel/sites/test/books/123-2s/'">
+				<img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title" loading="lazy">
 				<p class="inline">title - writer</p>
 				<div class="tag">test</div>
 				<div class="tag" style="background-color: #00ff00;">Downloaded</div>
@@ -290,15 +315,18 @@ func TestSearchLiteHandler(t *testing.T) {
 				t.Helper()
 
 				serv := servicemock.NewMockReadDataService(ctrl)
-				serv.EXPECT().SearchBooks(gomock.Any(), "title", "writer", 1, 5).Return(
-					[]model.Book{
+				serv.EXPECT().SearchBooks(
+					gomock.Any(), "title", "writer", model.GenreUrban,
+					repo.PageParams{Sort: model.BookSortTitle, Limit: 1, Cursor: "abc"},
+				).Return(
+					&repo.BookPage{Books: []model.Book{
 						{
 							Site: "test", ID: 123, HashCode: 100,
 							Title: "title", Writer: model.Writer{Name: "writer"},
 							Type: "type", UpdateDate: "date", UpdateChapter: "chapter",
 							Status: model.StatusEnd, IsDownloaded: true,
 						},
-					}, nil,
+					}, Total: 7, NextCursor: "next", PrevCursor: "prev"}, nil,
 				)
 
 				req, err := http.NewRequest(http.MethodGet, "/", nil)
@@ -307,10 +335,10 @@ func TestSearchLiteHandler(t *testing.T) {
 				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
 				ctx = context.WithValue(ctx, ContextKeyTitle, "title")
 				ctx = context.WithValue(ctx, ContextKeyWriter, "writer")
-				ctx = context.WithValue(ctx, ContextKeyPage, 5)
-				ctx = context.WithValue(ctx, ContextKeyPerPage, 1)
+				ctx = context.WithValue(ctx, ContextKeyGenre, model.GenreUrban)
+				ctx = context.WithValue(ctx, ContextKeySort, model.BookSortTitle)
 				ctx = context.WithValue(ctx, ContextKeyLimit, 1)
-				ctx = context.WithValue(ctx, ContextKeyOffset, 5)
+				ctx = context.WithValue(ctx, ContextKeyCursor, "abc")
 
 				return req.WithContext(ctx)
 			},
@@ -326,6 +354,16 @@ func TestSearchLiteHandler(t *testing.T) {
 			      padding-right: 1em;
 			      margin: 1em;
 				}
+				.cover {
+				  float: left;
+				  width: 6em;
+				  margin: 0.5em 1em 0.5em 0;
+				}
+				.book-box::after {
+				  content: "";
+				  display: block;
+				  clear: both;
+				}
 				.inline {
 				  display: inline-block;
 				}
@@ -351,13 +389,17 @@ func TestSearchLiteHandler(t *testing.T) {
 			
 			<body>
 			  <h1>Search Result</h1>
+			  
+			  
+			  <p>Download all: <a href="/lite/novel/bundle?title=title&writer=writer&format=txt">TXT</a> <a href="/lite/novel/bundle?title=title&writer=writer&format=epub">EPUB</a></p>
+			  
 			  <div>
 			    
-			    
 			      
 			  
 			  
 			  <div class="book-box" onclick="location.href='/lite/novel/sites/test/books/123-2s/'">
+				<img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title" loading="lazy">
 				<p class="inline">title - writer</p>
 				<div class="tag">test</div>
 				<div class="tag" style="background-color: #00ff00;">Downloaded</div>
@@ -375,8 +417,8 @@ func TestSearchLiteHandler(t *testing.T) {
 
 
 			  <div class="pagination">
-				<div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/search?title=title&writer=writer&page=4&per_page=1'">Previous</div>
-				<div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/search?title=title&writer=writer&page=6&per_page=1'">Next</div>
+				<div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/search?title=title&writer=writer&genre=urban&sort=title&cursor=prev&per_page=1'">Previous</div>
+				<div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/search?title=title&writer=writer&genre=urban&sort=title&cursor=next&per_page=1'">Next</div>
 			  </div>
 
 			</body>
@@ -421,7 +463,7 @@ func TestRandomLiteHandler(t *testing.T) {
 				t.Helper()
 
 				serv := servicemock.NewMockReadDataService(ctrl)
-				serv.EXPECT().RandomBooks(gomock.Any(), 10).Return(
+				serv.EXPECT().RandomBooks(gomock.Any(), model.Genre(""), model.BookSortUpdated, 10).Return(
 					[]model.Book{
 						{
 							Site: "test", ID: 123, HashCode: 100,
@@ -437,7 +479,6 @@ func TestRandomLiteHandler(t *testing.T) {
 				ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
 				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
 				ctx = context.WithValue(ctx, ContextKeyLimit, 10)
-				ctx = context.WithValue(ctx, ContextKeyOffset, 0)
 
 				return req.WithContext(ctx)
 			},
@@ -453,6 +494,16 @@ func TestRandomLiteHandler(t *testing.T) {
 			      padding-right: 1em;
 			      margin: 1em;
 				}
+				.cover {
+				  float: left;
+				  width: 6em;
+				  margin: 0.5em 1em 0.5em 0;
+				}
+				.book-box::after {
+				  content: "";
+				  display: block;
+				  clear: both;
+				}
 				.inline {
 				  display: inline-block;
 				}
@@ -478,13 +529,15 @@ func TestRandomLiteHandler(t *testing.T) {
 			
 			<body>
 			  <h1>Random</h1>
+			  
+			  
 			  <div>
 			    
-			    
 			      
 			  
 			  
 			  <div class="book-box" onclick="location.href='/lite/novel/sites/test/books/123-2s/'">
+				<img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title" loading="lazy">
 				<p class="inline">title - writer</p>
 				<div class="tag">test</div>
 				<div class="tag" style="background-color: #00ff00;">Downloaded</div>
@@ -521,6 +574,426 @@ func TestRandomLiteHandler(t *testing.T) {
 	}
 }
 
+func TestWritersLiteHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		prepareRequest   func(*testing.T, *gomock.Controller) *http.Request
+		expectStatusCode int
+		expectRes        string
+	}{
+		{
+			name: "happy flow with top writers",
+			prepareRequest: func(t *testing.T, ctrl *gomock.Controller) *http.Request {
+				t.Helper()
+
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().TopWriters(gomock.Any(), repo.WriterPageParams{Order: model.WriterOrderLatestUpdate, Limit: 1, Cursor: "abc"}).Return(
+					&repo.WriterPage{
+						Writers:    []model.WriterSummary{{ID: 5, Name: "writer", BookCount: 3, LatestUpdate: "date"}},
+						NextCursor: "next", PrevCursor: "prev",
+					}, nil,
+				)
+
+				req, err := http.NewRequest(http.MethodGet, "/", nil)
+				assert.NoError(t, err)
+				ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
+				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
+				ctx = context.WithValue(ctx, ContextKeyWriter, "")
+				ctx = context.WithValue(ctx, ContextKeyWriterOrder, model.WriterOrderLatestUpdate)
+				ctx = context.WithValue(ctx, ContextKeyLimit, 1)
+				ctx = context.WithValue(ctx, ContextKeyCursor, "abc")
+
+				return req.WithContext(ctx)
+			},
+			expectStatusCode: 200,
+			expectRes: `<html>
+
+<head>
+  <title>Novel - Writers</title>
+  <style>
+    .page-button {
+      display: inline-block;
+      margin: 0em 2%;
+      width: 45%;
+      padding: 1% 0em;
+      text-align: center;
+    }
+</style>
+</head>
+
+<body>
+  <h1>Writers</h1>
+  
+  <div class="search_panel">
+    <form action="/lite/novel/writers">
+      <label for="writer">Writer:</label>
+      <input type="text" id="writer" name="writer" value="">
+      <input type="hidden" id="per_page" name="per_page" value="1">
+      <input type="submit" value="Search">
+    </form>
+    <a href="/lite/novel/writers?order=books&per_page=1">Most Books</a>
+    <a href="/lite/novel/writers?order=updated&per_page=1">Recent Updates</a>
+  </div>
+  <ul>
+    
+    <li><a href="/lite/novel/writers/5">writer</a> - 3 books - date</li>
+    
+  </ul>
+  <div class="pagination">
+    <div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/writers?writer=&order=updated&cursor=prev&per_page=1'">Previous</div>
+    <div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/writers?writer=&order=updated&cursor=next&per_page=1'">Next</div>
+  </div>
+</body>
+
+</html>
+`,
+		},
+		{
+			name: "search writers failed",
+			prepareRequest: func(t *testing.T, ctrl *gomock.Controller) *http.Request {
+				t.Helper()
+
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchWriters(gomock.Any(), "writer", repo.WriterPageParams{Order: model.WriterOrderBookCount, Limit: 10}).
+					Return(nil, errors.New("some error"))
+
+				req, err := http.NewRequest(http.MethodGet, "/", nil)
+				assert.NoError(t, err)
+				ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
+				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
+				ctx = context.WithValue(ctx, ContextKeyWriter, "writer")
+				ctx = context.WithValue(ctx, ContextKeyLimit, 10)
+
+				return req.WithContext(ctx)
+			},
+			expectStatusCode: 404,
+			expectRes:        "writers not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			req := test.prepareRequest(t, ctrl)
+			res := httptest.NewRecorder()
+
+			WritersLiteHandler(res, req)
+			assert.Equal(t, test.expectStatusCode, res.Result().StatusCode)
+			assert.Equal(t,
+				strings.ReplaceAll(strings.ReplaceAll(test.expectRes, "\t", ""), "  ", ""),
+				strings.ReplaceAll(strings.ReplaceAll(res.Body.String(), "\t", ""), "  ", ""),
+			)
+		})
+	}
+}
+
+func TestWriterLiteHandler(t *testing.T) {
+	t.Parallel()
+
+	catalog := &model.WriterCatalog{
+		ID: 5, Name: "writer",
+		Groups: []model.BookGroup{{
+			{
+				Site: "test", ID: 123, HashCode: 100, Title: "title", Writer: model.Writer{ID: 5, Name: "writer"},
+				UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusEnd, IsDownloaded: true,
+			},
+			{
+				Site: "test-2", ID: 1, Title: "title", Writer: model.Writer{ID: 5, Name: "writer"},
+				UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
+			},
+		}},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
+	ctx = context.WithValue(ctx, ContextKeyWriterInfo, catalog)
+	res := httptest.NewRecorder()
+
+	WriterLiteHandler(res, req.WithContext(ctx))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t,
+		strings.ReplaceAll(strings.ReplaceAll(`<html>
+
+<head>
+  <title>Novel - Writer - writer</title>
+  <style>
+    .book-box {
+      border-style: solid;
+      padding-left: 1em;
+      padding-right: 1em;
+      margin: 1em;
+    }
+    .cover {
+      float: left;
+      width: 6em;
+      margin: 0.5em 1em 0.5em 0;
+    }
+    .book-box::after {
+      content: "";
+      display: block;
+      clear: both;
+    }
+    .inline {
+      display: inline-block;
+    }
+    .tag {
+      display: inline-block;
+      background-color: #f0f0f0;
+      border-radius: 0.5em;
+      padding: 0.2em 0.5em;
+      margin: 0.5em;
+      border: 0.2em solid #000;
+    }
+</style>
+</head>
+
+<body>
+  <h1>writer</h1>
+  
+  <p>Download all: <a href="/lite/novel/bundle?writer=writer&format=txt">TXT</a> <a href="/lite/novel/bundle?writer=writer&format=epub">EPUB</a></p>
+  
+  <h2>title</h2>
+  <div>
+    
+      
+  
+  
+  <div class="book-box" onclick="location.href='/lite/novel/sites/test/books/123-2s/'">
+    <img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title" loading="lazy">
+    <p class="inline">title - writer</p>
+    <div class="tag">test</div>
+    <div class="tag" style="background-color: #00ff00;">Downloaded</div>
+    <p>date</p>
+    <p>chapter</p>
+  </div>
+
+    
+      
+  
+  
+  <div class="book-box" onclick="location.href='/lite/novel/sites/test-2/books/1-0/'">
+    <img class="cover" src="/lite/novel/sites/test-2/books/1-0/cover" alt="title" loading="lazy">
+    <p class="inline">title - writer</p>
+    <div class="tag">test-2</div>
+    <div class="tag">INPROGRESS</div>
+    <p>date</p>
+    <p>chapter</p>
+  </div>
+
+    
+  </div>
+  
+</body>
+
+</html>
+`, "\t", ""), "  ", ""),
+		strings.ReplaceAll(strings.ReplaceAll(res.Body.String(), "\t", ""), "  ", ""),
+	)
+}
+
+func TestGenresLiteHandler(t *testing.T) {
+	t.Parallel()
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel"))
+	res := httptest.NewRecorder()
+
+	GenresLiteHandler(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), `<li><a href="/lite/novel/genres/fantasy?per_page=10">玄幻奇幻</a></li>`)
+	assert.Contains(t, res.Body.String(), `<li><a href="/lite/novel/genres/other?per_page=10">其他</a></li>`)
+}
+
+func TestGenreLiteHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		prepareRequest   func(*testing.T, *gomock.Controller) *http.Request
+		expectStatusCode int
+		expectRes        string
+	}{
+		{
+			name: "happy flow with filters",
+			prepareRequest: func(t *testing.T, ctrl *gomock.Controller) *http.Request {
+				t.Helper()
+
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().BrowseBooks(
+					gomock.Any(),
+					repo.BrowseParams{Genre: model.GenreUrban, Site: "test", Status: model.StatusEndKey},
+					repo.PageParams{Sort: model.BookSortTitle, Limit: 1, Cursor: "abc"},
+				).Return(
+					&repo.BookPage{Books: []model.Book{
+						{
+							Site: "test", ID: 123, HashCode: 100,
+							Title: "title", Writer: model.Writer{Name: "writer"},
+							Type: "都市小说", UpdateDate: "date", UpdateChapter: "chapter",
+							Status: model.StatusEnd, IsDownloaded: true,
+						},
+					}, Total: 3, NextCursor: "next", PrevCursor: "prev"}, nil,
+				)
+
+				req, err := http.NewRequest(http.MethodGet, "/", nil)
+				assert.NoError(t, err)
+				ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
+				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
+				ctx = context.WithValue(ctx, ContextKeyBrowse, repo.BrowseParams{
+					Genre: model.GenreUrban, Site: "test", Status: model.StatusEndKey,
+				})
+				ctx = context.WithValue(ctx, ContextKeySort, model.BookSortTitle)
+				ctx = context.WithValue(ctx, ContextKeyLimit, 1)
+				ctx = context.WithValue(ctx, ContextKeyCursor, "abc")
+
+				return req.WithContext(ctx)
+			},
+			expectStatusCode: 200,
+			expectRes: `<html>
+
+<head>
+  <title>Novel - 都市</title>
+  <style>
+    .book-box {
+      border-style: solid;
+      padding-left: 1em;
+      padding-right: 1em;
+      margin: 1em;
+    }
+    .cover {
+      float: left;
+      width: 6em;
+      margin: 0.5em 1em 0.5em 0;
+    }
+    .book-box::after {
+      content: "";
+      display: block;
+      clear: both;
+    }
+    .inline {
+      display: inline-block;
+    }
+    .tag {
+      display: inline-block;
+      background-color: #f0f0f0;
+      border-radius: 0.5em;
+      padding: 0.2em 0.5em;
+      margin: 0.5em;
+      border: 0.2em solid #000;
+    }
+</style>
+  <style>
+    .page-button {
+      display: inline-block;
+      margin: 0em 2%;
+      width: 45%;
+      padding: 1% 0em;
+      text-align: center;
+    }
+</style>
+</head>
+
+<body>
+  <h1>都市</h1>
+  <p>3 books</p>
+
+  
+  
+  <div class="search_panel">
+    <form action="/lite/novel/genres/urban">
+      <label for="site">Site:</label>
+      <input type="text" id="site" name="site" value="test">
+      <label for="status">Status:</label>
+      <select id="status" name="status">
+        <option value="" >All</option>
+        <option value="INPROGRESS" >In Progress</option>
+        <option value="END" selected>End</option>
+      </select>
+      <label for="sort">Sort:</label>
+      <select id="sort" name="sort">
+        <option value="updated" >Recent Updates</option>
+                <option value="title" selected>Title</option>
+      <option value="id" >ID</option>
+      <option value="discovered" >Recently Discovered</option>
+      </select>
+      <input type="hidden" id="per_page" name="per_page" value="1">
+      <input type="submit" value="Filter">
+    </form>
+  </div>
+  <div>
+    
+      
+  
+  
+  <div class="book-box" onclick="location.href='/lite/novel/sites/test/books/123-2s/'">
+    <img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title" loading="lazy">
+    <p class="inline">title - writer</p>
+    <div class="tag">test</div>
+    <div class="tag" style="background-color: #00ff00;">Downloaded</div>
+    <p>date</p>
+    <p>chapter</p>
+  </div>
+
+    
+  </div>
+  <div class="pagination">
+    <div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/genres/urban?site=test&status=END&sort=title&cursor=prev&per_page=1'">Previous</div>
+    <div class="page-button" style="border-style: solid;" onclick="location.href='/lite/novel/genres/urban?site=test&status=END&sort=title&cursor=next&per_page=1'">Next</div>
+  </div>
+</body>
+
+</html>
+`,
+		},
+		{
+			name: "browse books failed",
+			prepareRequest: func(t *testing.T, ctrl *gomock.Controller) *http.Request {
+				t.Helper()
+
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().BrowseBooks(
+					gomock.Any(), repo.BrowseParams{Genre: model.GenreUrban}, repo.PageParams{Sort: model.BookSortUpdated, Limit: 10},
+				).Return(nil, errors.New("some error"))
+
+				req, err := http.NewRequest(http.MethodGet, "/", nil)
+				assert.NoError(t, err)
+				ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
+				ctx = context.WithValue(ctx, ContextKeyReadDataServ, serv)
+				ctx = context.WithValue(ctx, ContextKeyBrowse, repo.BrowseParams{Genre: model.GenreUrban})
+				ctx = context.WithValue(ctx, ContextKeyLimit, 10)
+
+				return req.WithContext(ctx)
+			},
+			expectStatusCode: 404,
+			expectRes:        "books not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			req := test.prepareRequest(t, ctrl)
+			res := httptest.NewRecorder()
+
+			GenreLiteHandler(res, req)
+			assert.Equal(t, test.expectStatusCode, res.Result().StatusCode)
+			assert.Equal(t,
+				strings.ReplaceAll(strings.ReplaceAll(test.expectRes, "\t", ""), "  ", ""),
+				strings.ReplaceAll(strings.ReplaceAll(res.Body.String(), "\t", ""), "  ", ""),
+			)
+		})
+	}
+}
+
 func TestBookLiteHandler(t *testing.T) {
 	t.Parallel()
 
@@ -560,6 +1033,16 @@ func TestBookLiteHandler(t *testing.T) {
 			        padding-right: 1em;
 			        margin: 1em;
 				  }
+				  .cover {
+				    float: left;
+				    width: 6em;
+				    margin: 0.5em 1em 0.5em 0;
+				  }
+				  .book-box::after {
+				    content: "";
+				    display: block;
+				    clear: both;
+				  }
 				  .inline {
 				    display: inline-block;
 				  }
@@ -577,6 +1060,7 @@ func TestBookLiteHandler(t *testing.T) {
 			<body>
 				<h1>test</h1>
 				<div>
+						<img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title">
 						<p class="inline">title - writer</p>
 						<div class="tag" style="background-color: #00ff00;">Downloaded</div>
 						<p>date</p>
@@ -584,6 +1068,9 @@ func TestBookLiteHandler(t *testing.T) {
 						
 						<a href="/lite/novel/sites/test/books/123-2s/download?format=txt">Download TXT</a>
 						<a href="/lite/novel/sites/test/books/123-2s/download?format=epub">Download EPUB</a>
+						<a href="/lite/novel/sites/test/books/123-2s/download?format=fb2">Download FB2</a>
+						<a href="/lite/novel/sites/test/books/123-2s/download?format=html">Download HTML</a>
+						<a href="/lite/novel/sites/test/books/123-2s/download?format=markdown">Download Markdown</a>
 						
 				</div>
 				<h2>Book Group</h2>
@@ -604,7 +1091,7 @@ func TestBookLiteHandler(t *testing.T) {
 				ctx := context.WithValue(req.Context(), ContextKeyUriPrefix, "/lite/novel")
 				ctx = context.WithValue(ctx, ContextKeyBook, &model.Book{
 					Site: "test", ID: 123, HashCode: 100,
-					Title: "title", Writer: model.Writer{Name: "writer"},
+					Title: "title", Writer: model.Writer{ID: 5, Name: "writer"},
 					Type: "type", UpdateDate: "date", UpdateChapter: "chapter",
 					Status: model.StatusEnd, IsDownloaded: true,
 				})
@@ -631,6 +1118,16 @@ func TestBookLiteHandler(t *testing.T) {
 			        padding-right: 1em;
 			        margin: 1em;
 				  }
+				  .cover {
+				    float: left;
+				    width: 6em;
+				    margin: 0.5em 1em 0.5em 0;
+				  }
+				  .book-box::after {
+				    content: "";
+				    display: block;
+				    clear: both;
+				  }
 				  .inline {
 				    display: inline-block;
 				  }
@@ -648,13 +1145,17 @@ func TestBookLiteHandler(t *testing.T) {
 			<body>
 				<h1>test</h1>
 				<div>
-						<p class="inline">title - writer</p>
+						<img class="cover" src="/lite/novel/sites/test/books/123-2s/cover" alt="title">
+						<p class="inline">title - <a href="/lite/novel/writers/5">writer</a></p>
 						<div class="tag" style="background-color: #00ff00;">Downloaded</div>
 						<p>date</p>
 						<p>chapter</p>
 						
 						<a href="/lite/novel/sites/test/books/123-2s/download?format=txt">Download TXT</a>
 						<a href="/lite/novel/sites/test/books/123-2s/download?format=epub">Download EPUB</a>
+						<a href="/lite/novel/sites/test/books/123-2s/download?format=fb2">Download FB2</a>
+						<a href="/lite/novel/sites/test/books/123-2s/download?format=html">Download HTML</a>
+						<a href="/lite/novel/sites/test/books/123-2s/download?format=markdown">Download Markdown</a>
 						
 				</div>
 				<h2>Book Group</h2>
@@ -664,6 +1165,7 @@ func TestBookLiteHandler(t *testing.T) {
 
 
 				<div class="book-box" onclick="location.href='/lite/novel/sites/test-2/books/123-2s/'">
+				<img class="cover" src="/lite/novel/sites/test-2/books/123-2s/cover" alt="title" loading="lazy">
 				<p class="inline">title - writer</p>
 				<div class="tag">test-2</div>
 				<div class="tag" style="background-color: #00ff00;">Downloaded</div>
@@ -697,3 +1199,310 @@ func TestBookLiteHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloadLiteHandler(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	location := filepath.Join(dir, "1.txt")
+	os.WriteFile(location, []byte("鬥破蒼穹\n天蠶土豆\n--------------------\n\n第一章\n--------------------\n蕭炎\n--------------------\n"), os.ModePerm)
+	info, err := os.Stat(location)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	bk := &model.Book{Site: "test", ID: 1, Title: "鬥破蒼穹", Writer: model.Writer{Name: "天蠶土豆"}, IsDownloaded: true}
+
+	tests := []struct {
+		name             string
+		format           string
+		script           model.Script
+		reqHeader        map[string]string
+		expectStatusCode int
+		expectHeader     map[string]string
+		expectFiles      []string
+		expectContains   string
+	}{
+		{
+			name:             "epub in simplified script",
+			format:           "epub",
+			script:           model.ScriptSimplified,
+			expectStatusCode: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":        "application/epub+zip; charset=utf-8",
+				"Content-Disposition": `attachment; filename="斗破苍穹-天蚕土豆.epub"`,
+				"ETag":                etagWithSuffix(bookETag(bk, info.ModTime(), model.ScriptSimplified), "epub"),
+			},
+			expectFiles: []string{
+				"mimetype",
+				"META-INF/container.xml",
+				"OEBPS/styles/book.css",
+				"OEBPS/images/cover.png",
+				"OEBPS/cover.xhtml",
+				"OEBPS/chapters/chapter-1.xhtml",
+				"OEBPS/nav.xhtml",
+				"OEBPS/toc.ncx",
+				"OEBPS/content.opf",
+			},
+		},
+		{
+			name:             "epub not modified",
+			format:           "epub",
+			script:           model.ScriptOriginal,
+			reqHeader:        map[string]string{"If-None-Match": etagWithSuffix(bookETag(bk, info.ModTime(), model.ScriptOriginal), "epub")},
+			expectStatusCode: http.StatusNotModified,
+		},
+		{
+			name:             "txt",
+			format:           "txt",
+			script:           model.ScriptOriginal,
+			expectStatusCode: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type": "text/txt; charset=utf-8",
+				"ETag":         bookETag(bk, info.ModTime(), model.ScriptOriginal),
+			},
+		},
+		{
+			name:             "fb2",
+			format:           "fb2",
+			script:           model.ScriptOriginal,
+			expectStatusCode: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":        "application/x-fictionbook+xml; charset=utf-8",
+				"Content-Disposition": `attachment; filename="鬥破蒼穹-天蠶土豆.fb2"`,
+				"ETag":                etagWithSuffix(bookETag(bk, info.ModTime(), model.ScriptOriginal), "fb2"),
+			},
+			expectContains: `<section id="chapter-1">`,
+		},
+		{
+			name:             "html in simplified script",
+			format:           "html",
+			script:           model.ScriptSimplified,
+			expectStatusCode: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":        "text/html; charset=utf-8",
+				"Content-Disposition": `attachment; filename="斗破苍穹-天蚕土豆.html"`,
+			},
+			expectContains: `<p>萧炎</p>`,
+		},
+		{
+			name:             "markdown",
+			format:           "markdown",
+			script:           model.ScriptOriginal,
+			expectStatusCode: http.StatusOK,
+			expectHeader: map[string]string{
+				"Content-Type":        "text/markdown; charset=utf-8",
+				"Content-Disposition": `attachment; filename="鬥破蒼穹-天蠶土豆.md"`,
+			},
+			expectContains: "## 第一章\n\n蕭炎\n",
+		},
+		{
+			name:             "markdown not modified",
+			format:           "markdown",
+			script:           model.ScriptOriginal,
+			reqHeader:        map[string]string{"If-None-Match": etagWithSuffix(bookETag(bk, info.ModTime(), model.ScriptOriginal), "markdown")},
+			expectStatusCode: http.StatusNotModified,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			file, err := os.Open(location)
+			if !assert.NoError(t, err) {
+				return
+			}
+			serv := servicemock.NewMockReadDataService(ctrl)
+			serv.EXPECT().BookFile(gomock.Any(), bk).Return(file, nil)
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			assert.NoError(t, err)
+			for key, value := range test.reqHeader {
+				req.Header.Set(key, value)
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, service.ReadDataService(serv))
+			ctx = context.WithValue(ctx, ContextKeyBook, bk)
+			ctx = context.WithValue(ctx, ContextKeyFormat, test.format)
+			ctx = context.WithValue(ctx, ContextKeyScript, test.script)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			DownloadLiteHandler(res, req)
+
+			assert.Equal(t, test.expectStatusCode, res.Code)
+			for key, value := range test.expectHeader {
+				assert.Equal(t, value, res.Header().Get(key), key)
+			}
+
+			if test.expectFiles != nil {
+				zipReader, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				files := make([]string, len(zipReader.File))
+				for i, file := range zipReader.File {
+					files[i] = file.Name
+				}
+				assert.Equal(t, test.expectFiles, files)
+			}
+			if test.expectContains != "" {
+				assert.Contains(t, res.Body.String(), test.expectContains)
+			}
+		})
+	}
+}
+
+func TestBundleLiteHandler(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	location := filepath.Join(dir, "1.txt")
+	os.WriteFile(location, []byte("鬥破蒼穹"), os.ModePerm)
+
+	bk := model.Book{Site: "test", ID: 1, Title: "鬥破蒼穹", Writer: model.Writer{Name: "天蠶土豆"}, IsDownloaded: true}
+
+	tests := []struct {
+		name             string
+		setupServ        func(*gomock.Controller) service.ReadDataService
+		title            string
+		expectStatusCode int
+		expectFiles      []string
+	}{
+		{
+			name: "happy flow",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "鬥破", "", model.Genre(""), repo.PageParams{Sort: model.BookSortUpdated, Limit: bundlePageSize}).
+					Return(&repo.BookPage{Books: []model.Book{bk}}, nil)
+				serv.EXPECT().BookFile(gomock.Any(), &bk).DoAndReturn(
+					func(context.Context, *model.Book) (*os.File, error) { return os.Open(location) },
+				)
+
+				return serv
+			},
+			title:            "鬥破",
+			expectStatusCode: http.StatusOK,
+			expectFiles:      []string{"test-1-鬥破蒼穹-天蠶土豆.txt", bundleManifest},
+		},
+		{
+			name: "missing search params",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				return servicemock.NewMockReadDataService(ctrl)
+			},
+			expectStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			req, err := http.NewRequest(http.MethodGet, "/", nil)
+			assert.NoError(t, err)
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, ContextKeyTitle, test.title)
+			ctx = context.WithValue(ctx, ContextKeyWriter, "")
+			req = req.WithContext(ctx)
+			res := httptest.NewRecorder()
+
+			BundleLiteHandler(res, req)
+			assert.Equal(t, test.expectStatusCode, res.Code)
+
+			if test.expectFiles != nil {
+				zipReader, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				var files []string
+				for _, file := range zipReader.File {
+					files = append(files, file.Name)
+				}
+				assert.Equal(t, test.expectFiles, files)
+			}
+		})
+	}
+}
+
+func TestAddLiteRoutes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		setupServ        func(ctrl *gomock.Controller) service.ReadDataService
+		url              string
+		setupReq         func(req *http.Request)
+		expectStatusCode int
+	}{
+		{
+			name: "bundle without credentials",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				return servicemock.NewMockReadDataService(ctrl)
+			},
+			url:              "/lite/bundle?title=title",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "book download without credentials",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().BookGroup(gomock.Any(), "test", "1", "").Return(&model.Book{Site: "test", ID: 1}, &model.BookGroup{}, nil)
+
+				return serv
+			},
+			url:              "/lite/sites/test/books/1/download",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "bundle with api key",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title", "", model.Genre(""), gomock.Any()).Return(&repo.BookPage{}, nil)
+
+				return serv
+			},
+			url:              "/lite/bundle?title=title",
+			setupReq:         func(req *http.Request) { req.Header.Set(apiKeyHeader, "reader-secret") },
+			expectStatusCode: http.StatusOK,
+		},
+		{
+			name: "search stays public",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := servicemock.NewMockReadDataService(ctrl)
+				serv.EXPECT().SearchBooks(gomock.Any(), "title", "", model.Genre(""), gomock.Any()).Return(&repo.BookPage{}, nil)
+
+				return serv
+			},
+			url:              "/lite/search?title=title",
+			setupReq:         func(req *http.Request) {},
+			expectStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			conf := &config.APIConfig{LiteRoutePrefix: "/lite", AuthConfig: testAuthConfig()}
+			router := chi.NewRouter()
+			AddLiteRoutes(router, conf, NewAuthenticator(conf.AuthConfig), NewRateLimiter(), nil, test.setupServ(ctrl))
+
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			test.setupReq(req)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectStatusCode, res.Code)
+		})
+	}
+}
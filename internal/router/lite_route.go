@@ -3,36 +3,67 @@ package router
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/htchan/BookSpider/internal/config/v2"
+	formatv1 "github.com/htchan/BookSpider/internal/format/v1"
 	"github.com/htchan/BookSpider/internal/service"
 )
 
-func AddLiteRoutes(router chi.Router, conf *config.APIConfig, services map[string]service.Service, readDataServices service.ReadDataService) {
+// AddLiteRoutes adds the html routes, download routes share the auth, rate
+// limit and audit of api download routes
+func AddLiteRoutes(
+	router chi.Router, conf *config.APIConfig, auth *Authenticator, limiter *RateLimiter,
+	services map[string]service.Service, readDataServices service.ReadDataService,
+) {
+	download := chi.Chain(AuthMiddleware(auth, limiter), RequireRoleMiddleware(RoleReader), RateLimitMiddleware(limiter), AuditMiddleware)
+
 	router.Route(conf.LiteRoutePrefix, func(router chi.Router) {
 		router.Use(logRequest())
 		router.Use(TraceMiddleware)
 		router.Use(SetUriPrefixMiddleware(conf.LiteRoutePrefix))
 		router.Use(GetReadDataServiceMiddleware(readDataServices))
+		router.Use(GetFormatServiceMiddleware(formatv1.NewService(conf.EpubConfig, bookURLFunc(services))))
 
 		router.Route("/sites/{siteName}", func(router chi.Router) {
 			router.Use(GetSiteMiddleware)
 			router.Get("/", SiteLiteHandlerfunc)
 
-			router.With(GetSearchParamsMiddleware).With(GetPageParamsMiddleware).Get("/search", SearchLiteHandler)
-			router.With(GetPageParamsMiddleware).Get("/random", RandomLiteHandler)
+			router.With(GetSearchParamsMiddleware).With(GetGenreParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).
+				Get("/search", SearchLiteHandler)
+			router.With(GetGenreParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).Get("/random", RandomLiteHandler)
+			router.With(download...).With(GetSearchParamsMiddleware).With(GetDownloadParamsMiddleware).With(GetScriptParamsMiddleware).
+				Get("/bundle", BundleLiteHandler)
 
 			router.Route("/books", func(router chi.Router) {
 				router.Route("/{idHash:\\d+(-[\\w]+)?}", func(router chi.Router) {
 					// idHash format is <id>-<hash>
 					router.Use(GetBookMiddleware)
 					router.Get("/", BookLiteHandler)
-					router.With(GetDownloadParamsMiddleware).Get("/download", DownloadLiteHandler)
+					router.With(download...).With(GetDownloadParamsMiddleware).With(GetScriptParamsMiddleware).Get("/download", DownloadLiteHandler)
+					router.With(GetScriptParamsMiddleware).Get("/cover", CoverLiteHandler)
 				})
 			})
 		})
 
-		router.Get("/", GeneralLiteHandler(services))
-		router.With(GetSearchParamsMiddleware).With(GetPageParamsMiddleware).Get("/search", SearchLiteHandler)
-		router.With(GetPageParamsMiddleware).Get("/random", RandomLiteHandler)
+		router.Route("/writers", func(router chi.Router) {
+			router.With(GetSearchParamsMiddleware).With(GetWriterOrderParamsMiddleware).With(GetPageParamsMiddleware).
+				Get("/", WritersLiteHandler)
+
+			router.Route("/{writerID:\\d+}", func(router chi.Router) {
+				router.Use(GetSortParamsMiddleware)
+				router.Use(GetWriterMiddleware)
+				router.Get("/", WriterLiteHandler)
+			})
+		})
+
+		router.Route("/genres", func(router chi.Router) {
+			router.Get("/", GenresLiteHandler)
+			router.With(GetBrowseParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).Get("/{genre}", GenreLiteHandler)
+		})
 
+		router.Get("/", GeneralLiteHandler(services))
+		router.With(GetSearchParamsMiddleware).With(GetGenreParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).
+			Get("/search", SearchLiteHandler)
+		router.With(GetGenreParamsMiddleware).With(GetSortParamsMiddleware).With(GetPageParamsMiddleware).Get("/random", RandomLiteHandler)
+		router.With(download...).With(GetSearchParamsMiddleware).With(GetDownloadParamsMiddleware).With(GetScriptParamsMiddleware).
+			Get("/bundle", BundleLiteHandler)
 	})
 }
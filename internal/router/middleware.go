@@ -11,7 +11,11 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/format"
+	formatv1 "github.com/htchan/BookSpider/internal/format/v1"
 	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -37,6 +41,16 @@ const (
 	ContextKeyOffset       ContextKey = "offset"
 	ContextKeyUriPrefix    ContextKey = "uri_prefix"
 	ContextKeyFormat       ContextKey = "format"
+	ContextKeyWork         ContextKey = "work"
+	ContextKeyScript       ContextKey = "script"
+	ContextKeyFormatServ   ContextKey = "format_serv"
+	ContextKeyWriterInfo   ContextKey = "writer_info"
+	ContextKeyWriterOrder  ContextKey = "writer_order"
+	ContextKeyGenre        ContextKey = "genre"
+	ContextKeyBrowse       ContextKey = "browse"
+	ContextKeySort         ContextKey = "sort"
+	ContextKeyCursor       ContextKey = "cursor"
+	ContextKeyClient       ContextKey = "client"
 )
 
 func getTracer() trace.Tracer {
@@ -78,6 +92,40 @@ func GetReadDataServiceMiddleware(readDataServ service.ReadDataService) func(htt
 		)
 	}
 }
+
+func GetFormatServiceMiddleware(formatServ format.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				ctx := context.WithValue(req.Context(), ContextKeyFormatServ, formatServ)
+				next.ServeHTTP(res, req.WithContext(ctx))
+			},
+		)
+	}
+}
+
+// formatServiceFromContext returns the format service, default to service with default config
+func formatServiceFromContext(ctx context.Context) format.Service {
+	formatServ, ok := ctx.Value(ContextKeyFormatServ).(format.Service)
+	if !ok {
+		return formatv1.NewService(config.EpubConfig{}, nil)
+	}
+
+	return formatServ
+}
+
+// bookURLFunc returns the book url from the service of the book site
+func bookURLFunc(services map[string]service.Service) func(*model.Book) string {
+	return func(bk *model.Book) string {
+		serv, ok := services[bk.Site]
+		if !ok {
+			return ""
+		}
+
+		return serv.BookURL(bk)
+	}
+}
+
 func GetSiteMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
@@ -138,6 +186,73 @@ func GetBookMiddleware(next http.Handler) http.Handler {
 		},
 	)
 }
+func GetWorkMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			logger := zerolog.Ctx(req.Context())
+			workID := chi.URLParam(req, "workID")
+			serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+
+			_, span := getTracer().Start(req.Context(), "get work middleware")
+			defer span.End()
+
+			span.SetAttributes(attribute.String("work_id", workID))
+
+			work, err := serv.Work(req.Context(), workID)
+			if err != nil {
+				span.SetStatus(codes.Error, "get work failed")
+				span.RecordError(err)
+
+				logger.
+					Error().
+					Err(err).
+					Str("work-id", workID).
+					Msg("get work middleware failed")
+				writeError(res, http.StatusNotFound, errors.New("work not found"))
+				return
+			}
+
+			span.End()
+
+			ctx := context.WithValue(req.Context(), ContextKeyWork, work)
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+func GetWriterMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			logger := zerolog.Ctx(req.Context())
+			writerID := chi.URLParam(req, "writerID")
+			serv := req.Context().Value(ContextKeyReadDataServ).(service.ReadDataService)
+
+			_, span := getTracer().Start(req.Context(), "get writer middleware")
+			defer span.End()
+
+			span.SetAttributes(attribute.String("writer_id", writerID))
+
+			catalog, err := serv.Writer(req.Context(), writerID, bookSortFromContext(req.Context()))
+			if err != nil {
+				span.SetStatus(codes.Error, "get writer failed")
+				span.RecordError(err)
+
+				logger.
+					Error().
+					Err(err).
+					Str("writer-id", writerID).
+					Msg("get writer middleware failed")
+				writeError(res, http.StatusNotFound, errors.New("writer not found"))
+				return
+			}
+
+			span.End()
+
+			ctx := context.WithValue(req.Context(), ContextKeyWriterInfo, catalog)
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
 func GetSearchParamsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
@@ -151,22 +266,49 @@ func GetSearchParamsMiddleware(next http.Handler) http.Handler {
 		},
 	)
 }
+
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+)
+
+// GetPageParamsMiddleware reads page, per_page and cursor in query. per_page
+// defaults to 10 and is limited to 100, invalid page or per_page is rejected
 func GetPageParamsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
-			page, _ := strconv.Atoi(req.URL.Query().Get("page"))
-			perPage, _ := strconv.Atoi(req.URL.Query().Get("per_page"))
+			page, perPage := 0, defaultPerPage
+
+			var err error
+			if pageStr := req.URL.Query().Get("page"); pageStr != "" {
+				page, err = strconv.Atoi(pageStr)
+				if err != nil || page < 0 {
+					writeError(res, http.StatusBadRequest, InvalidParamsError)
+					return
+				}
+			}
+
+			if perPageStr := req.URL.Query().Get("per_page"); perPageStr != "" {
+				perPage, err = strconv.Atoi(perPageStr)
+				if err != nil || perPage <= 0 || perPage > maxPerPage {
+					writeError(res, http.StatusBadRequest, InvalidParamsError)
+					return
+				}
+			}
+
 			offset := page * perPage
 
 			ctx := context.WithValue(req.Context(), ContextKeyLimit, perPage)
 			ctx = context.WithValue(ctx, ContextKeyOffset, offset)
 			ctx = context.WithValue(ctx, ContextKeyPage, page)
 			ctx = context.WithValue(ctx, ContextKeyPerPage, perPage)
+			ctx = context.WithValue(ctx, ContextKeyCursor, req.URL.Query().Get("cursor"))
 
 			next.ServeHTTP(res, req.WithContext(ctx))
 		},
 	)
 }
+
 func GetDownloadParamsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
@@ -174,12 +316,180 @@ func GetDownloadParamsMiddleware(next http.Handler) http.Handler {
 			if format == "" {
 				format = "txt"
 			}
+			if _, ok := downloadFormats[format]; !ok {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
 			ctx := context.WithValue(req.Context(), ContextKeyFormat, format)
 
 			next.ServeHTTP(res, req.WithContext(ctx))
 		},
 	)
 }
+
+func GetScriptParamsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			script, err := model.ParseScript(req.URL.Query().Get("script"))
+			if err != nil {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyScript, script)
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+func GetWriterOrderParamsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			order, err := model.ParseWriterOrder(req.URL.Query().Get("order"))
+			if err != nil {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyWriterOrder, order)
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+func GetGenreParamsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			genre, err := model.ParseGenre(req.URL.Query().Get("genre"))
+			if err != nil {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyGenre, genre)
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+func GetSortParamsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			sort, err := model.ParseBookSort(req.URL.Query().Get("sort"))
+			if err != nil {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+			ctx := context.WithValue(req.Context(), ContextKeySort, sort)
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+// GetBrowseParamsMiddleware reads the genre in path and the site, status and
+// sort in query. limit and offset are left for the page params
+func GetBrowseParamsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			genre, err := model.ParseGenre(chi.URLParam(req, "genre"))
+			if err != nil || genre == "" {
+				writeError(res, http.StatusNotFound, errors.New("genre not found"))
+				return
+			}
+
+			status := strings.ToUpper(req.URL.Query().Get("status"))
+			if _, ok := model.StatusCodeMap[status]; status != "" && !ok {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+
+			params := repo.BrowseParams{
+				Genre:  genre,
+				Site:   req.URL.Query().Get("site"),
+				Status: status,
+			}
+			ctx := context.WithValue(req.Context(), ContextKeyBrowse, params)
+
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+// downloadFormatFromContext returns the requested download format, default to txt
+func downloadFormatFromContext(ctx context.Context) string {
+	format, ok := ctx.Value(ContextKeyFormat).(string)
+	if !ok {
+		return "txt"
+	}
+
+	return format
+}
+
+// scriptFromContext returns the requested script, default to original script
+func scriptFromContext(ctx context.Context) model.Script {
+	script, ok := ctx.Value(ContextKeyScript).(model.Script)
+	if !ok {
+		return model.ScriptOriginal
+	}
+
+	return script
+}
+
+// writerOrderFromContext returns the requested writer order, default to order by book count
+func writerOrderFromContext(ctx context.Context) model.WriterOrder {
+	order, ok := ctx.Value(ContextKeyWriterOrder).(model.WriterOrder)
+	if !ok {
+		return model.WriterOrderBookCount
+	}
+
+	return order
+}
+
+// genreFromContext returns the requested genre, empty genre is no filter
+func genreFromContext(ctx context.Context) model.Genre {
+	genre, ok := ctx.Value(ContextKeyGenre).(model.Genre)
+	if !ok {
+		return ""
+	}
+
+	return genre
+}
+
+// bookSortFromContext returns the requested book sort, default to order by update date
+func bookSortFromContext(ctx context.Context) model.BookSort {
+	sort, ok := ctx.Value(ContextKeySort).(model.BookSort)
+	if !ok {
+		return model.BookSortUpdated
+	}
+
+	return sort
+}
+
+// pageParamsFromContext returns the sort, per page and cursor requested as
+// params of keyset pagination
+func pageParamsFromContext(ctx context.Context) repo.PageParams {
+	limit, ok := ctx.Value(ContextKeyLimit).(int)
+	if !ok {
+		limit = defaultPerPage
+	}
+	cursor, _ := ctx.Value(ContextKeyCursor).(string)
+
+	return repo.PageParams{Sort: bookSortFromContext(ctx), Limit: limit, Cursor: cursor}
+}
+
+// writerPageParamsFromContext returns the order, per page and cursor
+// requested as params of keyset pagination of writers
+func writerPageParamsFromContext(ctx context.Context) repo.WriterPageParams {
+	limit, ok := ctx.Value(ContextKeyLimit).(int)
+	if !ok {
+		limit = defaultPerPage
+	}
+	cursor, _ := ctx.Value(ContextKeyCursor).(string)
+
+	return repo.WriterPageParams{Order: writerOrderFromContext(ctx), Limit: limit, Cursor: cursor}
+}
+
 func logRequest() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
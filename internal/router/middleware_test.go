@@ -13,6 +13,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	mockservice "github.com/htchan/BookSpider/internal/mock/service/v1"
 	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -178,6 +179,74 @@ func Test_GetBookMiddleware(t *testing.T) {
 	}
 }
 
+func Test_GetWriterMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		setupServ    func(ctrl *gomock.Controller) service.ReadDataService
+		writerID     string
+		expectWriter *model.WriterCatalog
+		wantRes      string
+	}{
+		{
+			name: "set request context writer for existing id",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().Writer(gomock.Any(), "5", model.BookSortUpdated).Return(&model.WriterCatalog{ID: 5, Name: "writer"}, nil)
+
+				return serv
+			},
+			writerID:     "5",
+			expectWriter: &model.WriterCatalog{ID: 5, Name: "writer"},
+			wantRes:      "ok",
+		},
+		{
+			name: "return error for not exist id",
+			setupServ: func(ctrl *gomock.Controller) service.ReadDataService {
+				serv := mockservice.NewMockReadDataService(ctrl)
+				serv.EXPECT().Writer(gomock.Any(), "5", model.BookSortUpdated).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			writerID: "5",
+			wantRes:  `{"error":"writer not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := GetWriterMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.expectWriter, r.Context().Value(ContextKeyWriterInfo).(*model.WriterCatalog))
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+
+			req, err := http.NewRequest("GET", "", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			ctx := context.WithValue(req.Context(), ContextKeyReadDataServ, test.setupServ(ctrl))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("writerID", test.writerID)
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
 func Test_GetSearchParamsMiddleware(t *testing.T) {
 
 	t.Parallel()
@@ -261,6 +330,351 @@ func Test_GetSearchParamsMiddleware(t *testing.T) {
 	}
 }
 
+func Test_GetDownloadParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		url        string
+		wantFormat string
+		wantRes    string
+	}{
+		{
+			name:       "default to txt",
+			url:        "http://host/test",
+			wantFormat: "txt",
+			wantRes:    "ok",
+		},
+		{
+			name:       "epub",
+			url:        "http://host/test?format=epub",
+			wantFormat: "epub",
+			wantRes:    "ok",
+		},
+		{
+			name:       "fb2",
+			url:        "http://host/test?format=fb2",
+			wantFormat: "fb2",
+			wantRes:    "ok",
+		},
+		{
+			name:       "html",
+			url:        "http://host/test?format=html",
+			wantFormat: "html",
+			wantRes:    "ok",
+		},
+		{
+			name:       "markdown",
+			url:        "http://host/test?format=markdown",
+			wantFormat: "markdown",
+			wantRes:    "ok",
+		},
+		{
+			name:    "invalid format",
+			url:     "http://host/test?format=pdf",
+			wantRes: `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			handler := GetDownloadParamsMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.wantFormat, r.Context().Value(ContextKeyFormat).(string))
+
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GetScriptParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		url        string
+		wantScript model.Script
+		wantRes    string
+	}{
+		{
+			name:       "default to original",
+			url:        "http://host/test",
+			wantScript: model.ScriptOriginal,
+			wantRes:    "ok",
+		},
+		{
+			name:       "simplified",
+			url:        "http://host/test?script=s",
+			wantScript: model.ScriptSimplified,
+			wantRes:    "ok",
+		},
+		{
+			name:       "traditional",
+			url:        "http://host/test?script=t",
+			wantScript: model.ScriptTraditional,
+			wantRes:    "ok",
+		},
+		{
+			name:    "invalid script",
+			url:     "http://host/test?script=x",
+			wantRes: `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			handler := GetScriptParamsMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.wantScript, r.Context().Value(ContextKeyScript).(model.Script))
+
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GetWriterOrderParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		url       string
+		wantOrder model.WriterOrder
+		wantRes   string
+	}{
+		{
+			name:      "default to book count",
+			url:       "http://host/test",
+			wantOrder: model.WriterOrderBookCount,
+			wantRes:   "ok",
+		},
+		{
+			name:      "latest update",
+			url:       "http://host/test?order=updated",
+			wantOrder: model.WriterOrderLatestUpdate,
+			wantRes:   "ok",
+		},
+		{
+			name:    "invalid order",
+			url:     "http://host/test?order=name",
+			wantRes: `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			handler := GetWriterOrderParamsMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.wantOrder, writerOrderFromContext(r.Context()))
+
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GetGenreParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		url       string
+		wantGenre model.Genre
+		wantRes   string
+	}{
+		{
+			name:    "default to no filter",
+			url:     "http://host/test",
+			wantRes: "ok",
+		},
+		{
+			name:      "valid genre",
+			url:       "http://host/test?genre=wuxia",
+			wantGenre: model.GenreWuxia,
+			wantRes:   "ok",
+		},
+		{
+			name:    "invalid genre",
+			url:     "http://host/test?genre=unknown",
+			wantRes: `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			handler := GetGenreParamsMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.wantGenre, genreFromContext(r.Context()))
+
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GetBrowseParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		url        string
+		genre      string
+		wantParams repo.BrowseParams
+		wantRes    string
+	}{
+		{
+			name:       "default params",
+			url:        "http://host/test",
+			genre:      "urban",
+			wantParams: repo.BrowseParams{Genre: model.GenreUrban},
+			wantRes:    "ok",
+		},
+		{
+			name:  "all params",
+			url:   "http://host/test?site=xbiquge&status=end",
+			genre: "fantasy",
+			wantParams: repo.BrowseParams{
+				Genre: model.GenreFantasy, Site: "xbiquge", Status: model.StatusEndKey,
+			},
+			wantRes: "ok",
+		},
+		{
+			name:    "invalid genre",
+			url:     "http://host/test",
+			genre:   "unknown",
+			wantRes: `{"error":"genre not found"}`,
+		},
+		{
+			name:    "invalid status",
+			url:     "http://host/test?status=done",
+			genre:   "urban",
+			wantRes: `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			handler := GetBrowseParamsMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.wantParams, r.Context().Value(ContextKeyBrowse).(repo.BrowseParams))
+
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("genre", test.genre)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GetSortParamsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		url      string
+		wantSort model.BookSort
+		wantRes  string
+	}{
+		{
+			name:     "default to order by update date",
+			url:      "http://host/test",
+			wantSort: model.BookSortUpdated,
+			wantRes:  "ok",
+		},
+		{
+			name:     "valid sort",
+			url:      "http://host/test?sort=discovered",
+			wantSort: model.BookSortDiscovered,
+			wantRes:  "ok",
+		},
+		{
+			name:    "invalid sort",
+			url:     "http://host/test?sort=random",
+			wantRes: `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			handler := GetSortParamsMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, test.wantSort, bookSortFromContext(r.Context()))
+
+					fmt.Fprintln(w, test.wantRes)
+				},
+			))
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
 func Test_GetPageParamsMiddleware(t *testing.T) {
 
 	t.Parallel()
@@ -270,26 +684,27 @@ func Test_GetPageParamsMiddleware(t *testing.T) {
 		url        string
 		wantLimit  int
 		wantOffset int
+		wantCursor string
 		wantRes    string
 	}{
 		{
 			name:       "empty page and empty per page",
 			url:        "http://host/test",
-			wantLimit:  0,
+			wantLimit:  10,
 			wantOffset: 0,
 			wantRes:    "ok",
 		},
 		{
 			name:       "page and empty per page",
 			url:        "http://host/test?page=2",
-			wantLimit:  0,
-			wantOffset: 0,
+			wantLimit:  10,
+			wantOffset: 20,
 			wantRes:    "ok",
 		},
 		{
 			name:       "empty page and per page",
-			url:        "http://host/test?per_page=10",
-			wantLimit:  10,
+			url:        "http://host/test?per_page=5",
+			wantLimit:  5,
 			wantOffset: 0,
 			wantRes:    "ok",
 		},
@@ -301,12 +716,33 @@ func Test_GetPageParamsMiddleware(t *testing.T) {
 			wantRes:    "ok",
 		},
 		{
-			name:       "page and per page of unknown value",
-			url:        "http://host/test?page=limit&per_page=offset",
-			wantLimit:  0,
+			name:       "cursor",
+			url:        "http://host/test?cursor=abc&per_page=10",
+			wantLimit:  10,
 			wantOffset: 0,
+			wantCursor: "abc",
 			wantRes:    "ok",
 		},
+		{
+			name:    "page and per page of unknown value",
+			url:     "http://host/test?page=limit&per_page=offset",
+			wantRes: `{"error":"invalid params"}`,
+		},
+		{
+			name:    "negative page",
+			url:     "http://host/test?page=-1",
+			wantRes: `{"error":"invalid params"}`,
+		},
+		{
+			name:    "zero per page",
+			url:     "http://host/test?per_page=0",
+			wantRes: `{"error":"invalid params"}`,
+		},
+		{
+			name:    "per page over limit",
+			url:     "http://host/test?per_page=101",
+			wantRes: `{"error":"invalid params"}`,
+		},
 		{
 			name:       "some unrelated params",
 			url:        "http://host/test?page=2&per_page=10&unknown=1",
@@ -331,6 +767,11 @@ func Test_GetPageParamsMiddleware(t *testing.T) {
 						t.Errorf("offset diff: %v", cmp.Diff(offset, test.wantOffset))
 					}
 
+					cursor := r.Context().Value(ContextKeyCursor).(string)
+					if cursor != test.wantCursor {
+						t.Errorf("cursor diff: %v", cmp.Diff(cursor, test.wantCursor))
+					}
+
 					fmt.Fprintln(w, test.wantRes)
 				},
 			))
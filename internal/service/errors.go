@@ -11,5 +11,15 @@ var (
 	ErrBookFileNotFound      = errors.New("book file not found")
 	ErrInvalidBookID         = errors.New("invalid book id")
 	ErrInvalidHashCode       = errors.New("invalid hash code")
+	ErrInvalidWorkID         = errors.New("invalid work id")
+	ErrInvalidWriterID       = errors.New("invalid writer id")
+	ErrWorkNoSource          = errors.New("work has no available source")
 	ErrTooManyFailedChapters = errors.New("too many failed chapters")
+	ErrImportFormat          = errors.New("unsupported import format")
+	ErrImportTitleMissing    = errors.New("import book title missing")
+	ErrSelectorBroken        = errors.New("selectors broken")
+	ErrSnapshotDisabled      = errors.New("snapshot disabled")
+	ErrNoProxyAvailable      = errors.New("no proxy available")
+	ErrChapterMojibake       = errors.New("chapter mojibake")
+	ErrBookNotExist          = errors.New("book not exist")
 )
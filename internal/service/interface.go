@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"database/sql"
+	"io"
+	"os"
 	"sync/atomic"
 
 	"github.com/htchan/BookSpider/internal/model"
@@ -31,6 +33,32 @@ type DownloadStats struct {
 	NoChapter           atomic.Int64
 	TooManyFailChapters atomic.Int64
 	RequestFail         atomic.Int64
+	AlternateChapters   atomic.Int64
+}
+
+type LinkWorkStats struct {
+	Total   atomic.Int64
+	Linked  atomic.Int64
+	NewWork atomic.Int64
+	Skipped atomic.Int64
+	Fail    atomic.Int64
+}
+
+type ReparseStats struct {
+	Total      atomic.Int64
+	Updated    atomic.Int64
+	Unchanged  atomic.Int64
+	NoSnapshot atomic.Int64
+	Fail       atomic.Int64
+	Redownload atomic.Int64
+}
+
+type ChecksumStats struct {
+	Books   atomic.Int64
+	Writers atomic.Int64
+	Updated atomic.Int64
+	Skipped atomic.Int64 // title or name too long to have checksum
+	Fail    atomic.Int64
 }
 
 type PatchStorageStats struct {
@@ -41,10 +69,12 @@ type PatchStorageStats struct {
 //go:generate go tool mockgen -destination=../mock/service/v1/service.go -package=mockservice . Service
 type Service interface {
 	Name() string
-	// Backup() error
+	Backup(context.Context) error
 	PatchDownloadStatus(context.Context, *PatchStorageStats) error
+	MissingBookIDs(context.Context) ([]int, error)
 	PatchMissingRecords(context.Context, *UpdateStats) error
 	CheckAvailability(context.Context) error
+	CheckSelectors(context.Context) error
 
 	UpdateBook(context.Context, *model.Book, *UpdateStats) error
 	Update(context.Context, *UpdateStats) error
@@ -55,24 +85,69 @@ type Service interface {
 	DownloadBook(context.Context, *model.Book, *DownloadStats) error
 	Download(context.Context, *DownloadStats) error
 
+	// multi source download
+	RegisterSources(map[string]Service)
+	ChapterList(context.Context, *model.Book) (model.Chapters, error)
+	DownloadChapter(context.Context, *model.Chapter) error
+
 	ValidateBookEnd(context.Context, *model.Book) error
 	ValidateEnd(context.Context) error
 
+	ReparseBook(context.Context, *model.Book, *ReparseStats) error
+	Reparse(context.Context, *ReparseStats) error
+
+	LinkBookWork(context.Context, *model.Book, *LinkWorkStats) error
+	LinkWorks(context.Context, *LinkWorkStats) error
+
+	SyncGenres(context.Context) (int, error)
+
 	ProcessBook(context.Context, *model.Book) error
 	Process(context.Context) error
 
 	BookInfo(context.Context, *model.Book) string
+	BookURL(*model.Book) string
 }
 
 //go:generate go tool mockgen -destination=../mock/service/v1/read_data_service.go -package=mockservice . ReadDataService
 type ReadDataService interface {
 	Book(ctx context.Context, site, id, hash string) (*model.Book, error)
-	BookContent(context.Context, *model.Book) (string, error)
+	BookFile(context.Context, *model.Book) (*os.File, error)
 	BookChapters(context.Context, *model.Book) (model.Chapters, error)
 	BookGroup(ctx context.Context, site, id, hash string) (*model.Book, *model.BookGroup, error)
-	SearchBooks(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error)
-	RandomBooks(ctx context.Context, limit int) ([]model.Book, error)
+	SearchBooks(ctx context.Context, title, writer string, genre model.Genre, page repo.PageParams) (*repo.BookPage, error)
+	RandomBooks(ctx context.Context, genre model.Genre, sort model.BookSort, limit int) ([]model.Book, error)
+	BrowseBooks(context.Context, repo.BrowseParams, repo.PageParams) (*repo.BookPage, error)
+
+	Work(ctx context.Context, workID string) (*model.Work, error)
+	WorkSource(context.Context, *model.Work) (*model.Book, error)
+	MergeWorks(ctx context.Context, targetID, sourceID string) error
+	SplitBookWork(context.Context, *model.Book) (*model.Work, error)
+
+	Writer(ctx context.Context, writerID string, sort model.BookSort) (*model.WriterCatalog, error)
+	SearchWriters(ctx context.Context, name string, page repo.WriterPageParams) (*repo.WriterPage, error)
+	TopWriters(ctx context.Context, page repo.WriterPageParams) (*repo.WriterPage, error)
 
 	Stats(context.Context, string) repo.Summary
 	DBStats(context.Context) sql.DBStats
 }
+
+// ImportParams is the external book file to import. title, writer and type
+// replace the ones found in file if they are not empty
+type ImportParams struct {
+	Format string // txt or epub
+	File   io.ReaderAt
+	Size   int64
+	Title  string
+	Writer string
+	Type   string
+}
+
+//go:generate go tool mockgen -destination=../mock/service/v1/import_service.go -package=mockservice . ImportService
+type ImportService interface {
+	ImportBook(context.Context, ImportParams) (*model.Book, error)
+}
+
+//go:generate go tool mockgen -destination=../mock/service/v1/checksum_service.go -package=mockservice . ChecksumService
+type ChecksumService interface {
+	RebuildChecksums(ctx context.Context, dryRun bool, stats *ChecksumStats) error
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// adaptiveLimiter paces the requests of vendor by a rate adjusted in AIMD
+// style, it starts from the min rate and keeps the rate within bounds
+type adaptiveLimiter struct {
+	vendor           string
+	conf             config.AdaptiveRateLimitConfig
+	minRate, maxRate float64 // requests per second
+
+	lock      sync.Mutex
+	rate      float64
+	nextSlot  time.Time
+	latencies []time.Duration
+	failures  int
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// newAdaptiveLimiter returns nil if adaptive rate limit is disabled, nil
+// limiter does not pace any request
+func newAdaptiveLimiter(vendor string, conf config.AdaptiveRateLimitConfig) *adaptiveLimiter {
+	if !conf.Enabled {
+		return nil
+	}
+
+	limiter := &adaptiveLimiter{
+		vendor:  vendor,
+		conf:    conf,
+		minRate: 1 / conf.MaxInterval.Seconds(),
+		maxRate: 1 / conf.MinInterval.Seconds(),
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+	limiter.rate = limiter.minRate
+
+	requestRates().register(limiter)
+
+	return limiter
+}
+
+// requestRateGauge reports the rate of every vendor in one gauge, limiters
+// are replaced by vendor so a reloaded service does not report twice
+type requestRateGauge struct {
+	lock     sync.Mutex
+	limiters map[string]*adaptiveLimiter
+}
+
+func newRequestRateGauge(meter metric.Meter) (*requestRateGauge, error) {
+	gauge := &requestRateGauge{limiters: make(map[string]*adaptiveLimiter)}
+
+	_, err := meter.Float64ObservableGauge(
+		"bookspider.client.request_rate",
+		metric.WithUnit("{request}/s"),
+		metric.WithDescription("current request rate of adaptive rate limit"),
+		metric.WithFloat64Callback(gauge.observe),
+	)
+
+	return gauge, err
+}
+
+// requestRates is the gauge registered to the global meter provider
+var requestRates = sync.OnceValue(func() *requestRateGauge {
+	gauge, err := newRequestRateGauge(otel.Meter("htchan/BookSpider/client"))
+	if err != nil {
+		log.Error().Err(err).Msg("register request rate metric failed")
+	}
+
+	return gauge
+})
+
+func (g *requestRateGauge) register(limiter *adaptiveLimiter) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.limiters[limiter.vendor] = limiter
+}
+
+func (g *requestRateGauge) observe(ctx context.Context, observer metric.Float64Observer) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for vendor, limiter := range g.limiters {
+		observer.Observe(limiter.Rate(), metric.WithAttributes(attribute.String("vendor", vendor)))
+	}
+
+	return nil
+}
+
+// Rate returns the current requests per second
+func (l *adaptiveLimiter) Rate() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.rate
+}
+
+// reserve returns the wait time of the next request slot
+func (l *adaptiveLimiter) reserve() time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	slot := l.nextSlot
+	if slot.Before(now) {
+		slot = now
+	}
+	l.nextSlot = slot.Add(time.Duration(float64(time.Second) / l.rate))
+
+	return slot.Sub(now)
+}
+
+func (l *adaptiveLimiter) setRate(rate float64, reason string) {
+	rate = math.Max(l.minRate, math.Min(l.maxRate, rate))
+	if rate == l.rate {
+		return
+	}
+
+	event := log.Debug()
+	if rate < l.rate {
+		event = log.Info()
+	}
+	event.
+		Str("vendor", l.vendor).
+		Float64("from", l.rate).
+		Float64("to", rate).
+		Str("reason", reason).
+		Msg("adaptive request rate changed")
+
+	l.rate = rate
+}
+
+func (l *adaptiveLimiter) resetWindow() {
+	l.latencies, l.failures = l.latencies[:0], 0
+}
+
+// observe adjusts the rate by response of a request
+func (l *adaptiveLimiter) observe(latency time.Duration, resp *http.Response, err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if resp != nil {
+		retryAfter, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), l.now())
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable || hasRetryAfter {
+			l.setRate(l.rate*l.conf.BackoffRatio, "throttled: status "+strconv.Itoa(resp.StatusCode))
+			if until := l.now().Add(retryAfter); until.After(l.nextSlot) {
+				l.nextSlot = until
+			}
+			l.resetWindow()
+
+			return
+		}
+	}
+
+	l.latencies = append(l.latencies, latency)
+	if err != nil || resp == nil || resp.StatusCode >= 500 {
+		l.failures++
+	}
+
+	if len(l.latencies) < l.conf.Window {
+		return
+	}
+
+	p95 := percentile(l.latencies, 0.95)
+	errorRate := float64(l.failures) / float64(len(l.latencies))
+	if p95 <= l.conf.TargetLatency && errorRate <= l.conf.MaxErrorRate {
+		l.setRate(l.rate+l.conf.IncreaseStep, "healthy window")
+	} else {
+		l.setRate(l.rate*l.conf.BackoffRatio, "unhealthy window: p95 "+p95.String()+", error rate "+strconv.FormatFloat(errorRate, 'f', 2, 64))
+	}
+	l.resetWindow()
+}
+
+// wrap paces the requests of next by the limiter and observes their responses
+func (l *adaptiveLimiter) wrap(next func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	if l == nil {
+		return next
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		if err := l.sleep(req.Context(), l.reserve()); err != nil {
+			return nil, err
+		}
+
+		start := l.now()
+		resp, err := next(req)
+		l.observe(l.now().Sub(start), resp, err)
+
+		return resp, err
+	}
+}
+
+// percentile returns the nearest rank percentile of durations
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+	return sorted[max(rank, 0)]
+}
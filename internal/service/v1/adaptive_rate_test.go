@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	metricsdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+)
+
+var testAdaptiveConf = config.AdaptiveRateLimitConfig{
+	Enabled:       true,
+	MinInterval:   100 * time.Millisecond,
+	MaxInterval:   time.Second,
+	Window:        4,
+	TargetLatency: 200 * time.Millisecond,
+	MaxErrorRate:  0.25,
+	IncreaseStep:  1,
+	BackoffRatio:  0.5,
+}
+
+type observation struct {
+	latency time.Duration
+	resp    *http.Response
+	err     error
+}
+
+func okObservations(n int, latency time.Duration) []observation {
+	result := make([]observation, n)
+	for i := range result {
+		result[i] = observation{latency: latency, resp: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}}
+	}
+
+	return result
+}
+
+func Test_newAdaptiveLimiter(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, newAdaptiveLimiter("test", config.AdaptiveRateLimitConfig{}))
+
+	limiter := newAdaptiveLimiter("test", testAdaptiveConf)
+	assert.InDelta(t, 1, limiter.Rate(), 0.0001)
+	assert.InDelta(t, 10, limiter.maxRate, 0.0001)
+}
+
+func Test_requestRateGauge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		limiters func() []*adaptiveLimiter
+		want     []metricdata.DataPoint[float64]
+	}{
+		{
+			name: "observe rate of every vendor",
+			limiters: func() []*adaptiveLimiter {
+				slow := &adaptiveLimiter{vendor: "slow", rate: 1}
+				fast := &adaptiveLimiter{vendor: "fast", rate: 5}
+
+				return []*adaptiveLimiter{slow, fast}
+			},
+			want: []metricdata.DataPoint[float64]{
+				{Attributes: attribute.NewSet(attribute.String("vendor", "slow")), Value: 1},
+				{Attributes: attribute.NewSet(attribute.String("vendor", "fast")), Value: 5},
+			},
+		},
+		{
+			name: "replace limiter of same vendor",
+			limiters: func() []*adaptiveLimiter {
+				return []*adaptiveLimiter{{vendor: "test", rate: 1}, {vendor: "test", rate: 3}}
+			},
+			want: []metricdata.DataPoint[float64]{
+				{Attributes: attribute.NewSet(attribute.String("vendor", "test")), Value: 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			reader := metricsdk.NewManualReader()
+			provider := metricsdk.NewMeterProvider(metricsdk.WithReader(reader))
+
+			gauge, err := newRequestRateGauge(provider.Meter("test"))
+			assert.NoError(t, err)
+			for _, limiter := range test.limiters() {
+				gauge.register(limiter)
+			}
+
+			var rm metricdata.ResourceMetrics
+			assert.NoError(t, reader.Collect(context.Background(), &rm))
+			if !assert.Len(t, rm.ScopeMetrics, 1) || !assert.Len(t, rm.ScopeMetrics[0].Metrics, 1) {
+				return
+			}
+
+			metricdatatest.AssertEqual(t, metricdata.Metrics{
+				Name:        "bookspider.client.request_rate",
+				Description: "current request rate of adaptive rate limit",
+				Unit:        "{request}/s",
+				Data:        metricdata.Gauge[float64]{DataPoints: test.want},
+			}, rm.ScopeMetrics[0].Metrics[0], metricdatatest.IgnoreTimestamp())
+		})
+	}
+}
+
+func Test_adaptiveLimiter_observe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		initRate     float64
+		observations []observation
+		wantRate     float64
+	}{
+		{
+			name:         "keep rate before window is full",
+			initRate:     2,
+			observations: okObservations(3, 10*time.Millisecond),
+			wantRate:     2,
+		},
+		{
+			name:         "increase rate for healthy windows",
+			initRate:     2,
+			observations: okObservations(8, 10*time.Millisecond),
+			wantRate:     4,
+		},
+		{
+			name:         "increase rate up to max rate",
+			initRate:     9.5,
+			observations: okObservations(8, 10*time.Millisecond),
+			wantRate:     10,
+		},
+		{
+			name:     "decrease rate for slow window",
+			initRate: 4,
+			observations: append(
+				okObservations(3, 10*time.Millisecond),
+				okObservations(1, time.Second)...,
+			),
+			wantRate: 2,
+		},
+		{
+			name:     "decrease rate for error rate over max",
+			initRate: 4,
+			observations: append(
+				okObservations(2, 10*time.Millisecond),
+				observation{latency: 10 * time.Millisecond, err: errors.New("connection reset")},
+				observation{latency: 10 * time.Millisecond, resp: &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}},
+			),
+			wantRate: 2,
+		},
+		{
+			name:     "decrease rate immediately for too many requests",
+			initRate: 4,
+			observations: []observation{
+				{latency: 10 * time.Millisecond, resp: &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}},
+			},
+			wantRate: 2,
+		},
+		{
+			name:     "decrease rate immediately for retry after",
+			initRate: 4,
+			observations: []observation{
+				{latency: 10 * time.Millisecond, resp: &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Retry-After": {"1"}}}},
+			},
+			wantRate: 2,
+		},
+		{
+			name:     "decrease rate down to min rate",
+			initRate: 1.5,
+			observations: []observation{
+				{latency: 10 * time.Millisecond, resp: &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}},
+			},
+			wantRate: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := newAdaptiveLimiter("test", testAdaptiveConf)
+			limiter.rate = test.initRate
+			for _, o := range test.observations {
+				limiter.observe(o.latency, o.resp, o.err)
+			}
+
+			assert.InDelta(t, test.wantRate, limiter.Rate(), 0.0001)
+		})
+	}
+}
+
+func Test_adaptiveLimiter_wrap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pace requests by rate and retry after", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := newAdaptiveLimiter("test", testAdaptiveConf)
+		limiter.rate = 4
+
+		now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+		var waits []time.Duration
+		limiter.now = func() time.Time { return now }
+		limiter.sleep = func(ctx context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		}
+
+		responses := []*http.Response{
+			{StatusCode: http.StatusOK, Header: http.Header{}},
+			{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"3"}}},
+			{StatusCode: http.StatusOK, Header: http.Header{}},
+		}
+		requester := limiter.wrap(func(req *http.Request) (*http.Response, error) {
+			resp := responses[0]
+			responses = responses[1:]
+			return resp, nil
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "https://test.com", nil)
+		assert.NoError(t, err)
+		for range 3 {
+			_, err := requester(req)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, []time.Duration{0, 250 * time.Millisecond, 3 * time.Second}, waits)
+		assert.InDelta(t, 2, limiter.Rate(), 0.0001)
+	})
+
+	t.Run("stop waiting if context cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := newAdaptiveLimiter("test", testAdaptiveConf)
+		requester := limiter.wrap(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://test.com", nil)
+		assert.NoError(t, err)
+
+		_, err = requester(req)
+		assert.NoError(t, err)
+
+		cancel()
+		_, err = requester(req)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("nil limiter", func(t *testing.T) {
+		t.Parallel()
+
+		var limiter *adaptiveLimiter
+		requester := limiter.wrap(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusTeapot}, nil
+		})
+
+		resp, err := requester(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	})
+}
+
+func Test_percentile(t *testing.T) {
+	t.Parallel()
+
+	durations := make([]time.Duration, 20)
+	for i := range durations {
+		durations[i] = time.Duration(20-i) * time.Millisecond
+	}
+
+	assert.Equal(t, 19*time.Millisecond, percentile(durations, 0.95))
+	assert.Equal(t, 10*time.Millisecond, percentile(durations, 0.5))
+	assert.Equal(t, time.Duration(0), percentile(nil, 0.95))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/rs/zerolog"
@@ -19,3 +20,8 @@ func (s *ServiceImpl) BookInfo(ctx context.Context, bk *model.Book) string {
 
 	return string(bytes)
 }
+
+// BookURL returns the url of the book on the vendor site
+func (s *ServiceImpl) BookURL(bk *model.Book) string {
+	return s.vendorService.BookURL(strconv.Itoa(bk.ID))
+}
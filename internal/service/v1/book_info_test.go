@@ -4,8 +4,11 @@ import (
 	"context"
 	"testing"
 
+	vendormock "github.com/htchan/BookSpider/internal/mock/vendorservice"
 	"github.com/htchan/BookSpider/internal/model"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestServiceImpl_BookInfo(t *testing.T) {
@@ -31,3 +34,38 @@ func TestServiceImpl_BookInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceImpl_BookURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		vendorService func(*gomock.Controller) vendor.VendorService
+		bk            *model.Book
+		want          string
+	}{
+		{
+			name: "happy flow",
+			vendorService: func(ctrl *gomock.Controller) vendor.VendorService {
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				vendorService.EXPECT().BookURL("123").Return("https://test.com/book/123")
+
+				return vendorService
+			},
+			bk:   &model.Book{Site: "test", ID: 123},
+			want: "https://test.com/book/123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			got := (&ServiceImpl{vendorService: test.vendorService(ctrl)}).BookURL(test.bk)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
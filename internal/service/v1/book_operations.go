@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/model"
 	serv "github.com/htchan/BookSpider/internal/service"
 	vendor "github.com/htchan/BookSpider/internal/vendorservice"
@@ -32,14 +34,28 @@ func (s *ServiceImpl) UpdateBook(ctx context.Context, bk *model.Book, stats *ser
 		stats = new(serv.UpdateStats)
 	}
 
-	body, err := s.cli.Get(ctx, s.vendorService.BookURL(strconv.FormatInt(int64(bk.ID), 10)))
-	if err != nil {
+	// error book is not compared with page, so it always fetches full page
+	getCtx := ctx
+	if bk.Status != model.StatusError {
+		getCtx = client.WithConditionalRequest(ctx)
+	}
+
+	url := s.vendorService.BookURL(strconv.FormatInt(int64(bk.ID), 10))
+	body, err := s.cli.Get(getCtx, url)
+	if errors.Is(err, client.ErrNotModified) {
+		zerolog.Ctx(ctx).Debug().Msg("book page not modified")
+		stats.Unchanged.Add(1)
+		return nil
+	} else if err != nil {
 		stats.Fail.Add(1)
 		return fmt.Errorf("get book page failed: %w", err)
 	}
+	s.saveSnapshot(ctx, url, body)
 
 	bkInfo, err := s.vendorService.ParseBook(body)
+	parseMonitorFromContext(ctx).record(err)
 	if err != nil {
+		s.forgetValidators(url)
 		stats.Fail.Add(1)
 		return fmt.Errorf("parse book page failed: %w", err)
 	}
@@ -77,6 +93,7 @@ func (s *ServiceImpl) UpdateBook(ctx context.Context, bk *model.Book, stats *ser
 		saveBkErr := s.rpo.CreateBook(ctx, bk)
 		saveErrErr := s.rpo.SaveError(ctx, bk, bk.Error)
 		if saveWriterErr != nil || saveBkErr != nil || saveErrErr != nil {
+			s.forgetValidators(url)
 			return errors.Join(saveWriterErr, saveBkErr)
 		}
 	} else if isBookUpdated(bk, bkInfo) {
@@ -116,6 +133,7 @@ func (s *ServiceImpl) UpdateBook(ctx context.Context, bk *model.Book, stats *ser
 		saveBkErr := s.rpo.UpdateBook(ctx, bk)
 		saveErrErr := s.rpo.SaveError(ctx, bk, bk.Error)
 		if saveWriterErr != nil || saveBkErr != nil || saveErrErr != nil {
+			s.forgetValidators(url)
 			return errors.Join(saveWriterErr, saveBkErr)
 		}
 	} else {
@@ -137,7 +155,15 @@ func (s *ServiceImpl) Update(ctx context.Context, stats *serv.UpdateStats) error
 		return fmt.Errorf("fail to load books from DB: %w", err)
 	}
 
+	monitor := newParseMonitor(s.conf.HealthConfig)
+	ctx = withParseMonitor(ctx, monitor)
+
 	for bk := range bkChan {
+		// drain the remaining books without update once selectors are broken
+		if monitor.isTripped() {
+			continue
+		}
+
 		s.vendorSema.Acquire(ctx, 1)
 		s.sema.Acquire(ctx, 1)
 		wg.Add(1)
@@ -166,7 +192,7 @@ func (s *ServiceImpl) Update(ctx context.Context, stats *serv.UpdateStats) error
 
 	wg.Wait()
 
-	return nil
+	return s.finishParseMonitor(ctx, monitor, "update")
 }
 
 func (s *ServiceImpl) ExploreBook(ctx context.Context, bk *model.Book, stats *serv.UpdateStats) error {
@@ -188,12 +214,23 @@ func (s *ServiceImpl) ExploreBook(ctx context.Context, bk *model.Book, stats *se
 			return fmt.Errorf("explore book fail: %w; save error fail: %w", err, saveErr)
 		}
 
+		var statusErr client.StatusCodeError
+		if errors.As(err, &statusErr) && statusErr.NotFound() {
+			return fmt.Errorf("explore book fail: %w: %w", serv.ErrBookNotExist, err)
+		}
+
 		return fmt.Errorf("explore book fail: %w", err)
 	}
 
 	return err
 }
 
+// isExploreEnd reports if err of explore book counts toward MaxExploreError.
+// temporary failure of vendor tells nothing about whether the book exists
+func isExploreEnd(err error) bool {
+	return !client.IsTemporary(err) && !errors.Is(err, serv.ErrNoProxyAvailable)
+}
+
 func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) error {
 	summary := s.rpo.Stats(ctx, s.name)
 	var errorCount atomic.Int64
@@ -204,11 +241,22 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 
 	var wg sync.WaitGroup
 
-	for i := summary.LatestSuccessID + 1; i <= summary.MaxBookID && int(errorCount.Load()) < s.conf.MaxExploreError; i++ {
+	// books after the max id are expected to be missing and their pages may not
+	// parse, so only books before it are monitored
+	monitor := newParseMonitor(s.conf.HealthConfig)
+	monitorCtx := withParseMonitor(ctx, monitor)
+
+	for i := summary.LatestSuccessID + 1; i <= summary.MaxBookID && int(errorCount.Load()) < s.conf.MaxExploreError && !monitor.isTripped(); i++ {
 		i := i
 
 		s.vendorSema.Acquire(ctx, 1)
 		s.sema.Acquire(ctx, 1)
+		// books explored while waiting may have reached the limit
+		if int(errorCount.Load()) >= s.conf.MaxExploreError {
+			s.vendorSema.Release(1)
+			s.sema.Release(1)
+			break
+		}
 		wg.Add(1)
 
 		go func(id int) {
@@ -227,11 +275,13 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 				Int("bk_id", bk.ID).
 				Str("bk_hash_code", bk.FormatHashCode()).
 				Logger()
-			err = s.ExploreBook(logger.WithContext(ctx), bk, stats)
+			err = s.ExploreBook(logger.WithContext(monitorCtx), bk, stats)
 			if err != nil {
 				logger.Error().Err(err).
 					Msg("explore book failed")
-				errorCount.Add(1)
+				if isExploreEnd(err) {
+					errorCount.Add(1)
+				}
 			} else {
 				errorCount.Store(0)
 			}
@@ -243,11 +293,17 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 
 	wg.Wait()
 
-	for i := summary.MaxBookID + 1; int(errorCount.Load()) < s.conf.MaxExploreError; i++ {
+	for i := summary.MaxBookID + 1; int(errorCount.Load()) < s.conf.MaxExploreError && !monitor.isTripped(); i++ {
 		i := i
 
 		s.vendorSema.Acquire(ctx, 1)
 		s.sema.Acquire(ctx, 1)
+		// books explored while waiting may have reached the limit
+		if int(errorCount.Load()) >= s.conf.MaxExploreError {
+			s.vendorSema.Release(1)
+			s.sema.Release(1)
+			break
+		}
 		wg.Add(1)
 
 		go func(id int) {
@@ -266,7 +322,9 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 			if err != nil {
 				logger.Error().Err(err).
 					Msg("explore book failed")
-				errorCount.Add(1)
+				if isExploreEnd(err) {
+					errorCount.Add(1)
+				}
 			} else {
 				errorCount.Store(0)
 			}
@@ -278,11 +336,27 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 
 	wg.Wait()
 
-	return nil
+	return s.finishParseMonitor(ctx, monitor, "explore")
+}
+
+// forgetValidators makes next update fetch the full book page, as the page
+// fetched this time is not saved
+func (s *ServiceImpl) forgetValidators(url string) {
+	if forgetter, ok := s.cli.(client.ValidatorForgetter); ok {
+		forgetter.ForgetValidators(url)
+	}
+}
+
+// forgetCachedResponse makes next download fetch the chapter page again, as
+// the page cached this time could not be processed
+func (s *ServiceImpl) forgetCachedResponse(url string) {
+	if forgetter, ok := s.cli.(client.CachedResponseForgetter); ok {
+		forgetter.ForgetCachedResponse(url)
+	}
 }
 
 func (s *ServiceImpl) downloadChapter(ctx context.Context, ch *model.Chapter) error {
-	body, err := s.cli.Get(ctx, ch.URL)
+	body, err := s.cli.Get(client.WithCacheableResponse(ctx), ch.URL)
 	if err != nil {
 		ch.Error = err
 
@@ -292,10 +366,19 @@ func (s *ServiceImpl) downloadChapter(ctx context.Context, ch *model.Chapter) er
 	chapter, err := s.vendorService.ParseChapter(body)
 	if err != nil {
 		ch.Error = err
+		s.forgetCachedResponse(ch.URL)
 
 		return fmt.Errorf("parse chapter page failed: %w", err)
 	}
 
+	// decoded by wrong encoding, failed chapter can be fetched from other sources
+	if text := chapter.Title + chapter.Body; client.IsMojibake(text) {
+		ch.Error = serv.ErrChapterMojibake
+		s.forgetCachedResponse(ch.URL)
+
+		return fmt.Errorf("parse chapter page failed: %w (replacement ratio %.2f)", ch.Error, client.MojibakeRatio(text))
+	}
+
 	ch.Title, ch.Content = chapter.Title, chapter.Body
 
 	ch.OptimizeContent()
@@ -318,14 +401,17 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 
 	logger.Info().Msg("get chapter list")
 
-	body, err := s.cli.Get(ctx, s.vendorService.ChapterListURL(strconv.FormatInt(int64(bk.ID), 10)))
+	url := s.vendorService.ChapterListURL(strconv.FormatInt(int64(bk.ID), 10))
+	body, err := s.cli.Get(ctx, url)
 	if err != nil {
 		stats.RequestFail.Add(1)
 
 		return fmt.Errorf("get chapter list failed: %w", err)
 	}
+	s.saveSnapshot(ctx, url, body)
 
 	chapterList, err := s.vendorService.ParseChapterList(strconv.Itoa(bk.ID), body)
+	parseMonitorFromContext(ctx).record(err)
 	if err != nil {
 		if errors.Is(err, vendor.ErrChapterListEmpty) {
 			stats.NoChapter.Add(1)
@@ -339,7 +425,6 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 	logger.Info().Msg("download chapters")
 	chapters := make(model.Chapters, len(chapterList))
 	var wg sync.WaitGroup
-	failedChapterCount := 0
 
 	for i := range chapters {
 		chapters[i] = model.NewChapter(i, (chapterList)[i].URL, (chapterList)[i].Title)
@@ -358,7 +443,6 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 				Logger()
 			err := s.downloadChapter(chapterLogger.WithContext(ctx), ch)
 			if err != nil {
-				failedChapterCount += 1
 				chapterLogger.Error().Err(err).
 					Str("chapter_title", ch.Title).
 					Msg("download chapter failed")
@@ -368,6 +452,17 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 
 	wg.Wait()
 
+	if s.conf.MultiSourceDownload {
+		s.downloadFromAlternateSources(ctx, bk, chapters, stats)
+	}
+
+	failedChapterCount := 0
+	for _, chapter := range chapters {
+		if chapter.Error != nil {
+			failedChapterCount++
+		}
+	}
+
 	if failedChapterCount > 50 || failedChapterCount*10 > len(chapters) {
 		stats.TooManyFailChapters.Add(1)
 
@@ -381,15 +476,15 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 	}
 	defer file.Close()
 
-	_, err = file.WriteString(bk.HeaderInfo())
+	err = model.WriteBookFile(file, bk, chapters)
 	if err != nil {
-		return fmt.Errorf("write book header in save chapter fail: %w", err)
+		return fmt.Errorf("write book file in save chapters fail: %w", err)
 	}
 
-	for _, chapter := range chapters {
-		_, err := file.WriteString(chapter.ContentString())
+	if s.conf.MultiSourceDownload {
+		err = s.saveChapterSources(bk, chapters)
 		if err != nil {
-			return fmt.Errorf("write chapter %s in save chapters fail: %w", chapter.URL, err)
+			return fmt.Errorf("save chapter sources fail: %w", err)
 		}
 	}
 
@@ -405,6 +500,176 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 	return nil
 }
 
+func (s *ServiceImpl) RegisterSources(services map[string]serv.Service) {
+	s.sources = make(map[string]serv.Service)
+	for name, service := range services {
+		if name != s.name {
+			s.sources[name] = service
+		}
+	}
+}
+
+func (s *ServiceImpl) ChapterList(ctx context.Context, bk *model.Book) (model.Chapters, error) {
+	url := s.vendorService.ChapterListURL(strconv.Itoa(bk.ID))
+	body, err := s.cli.Get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("get chapter list failed: %w", err)
+	}
+	s.saveSnapshot(ctx, url, body)
+
+	chapterList, err := s.vendorService.ParseChapterList(strconv.Itoa(bk.ID), body)
+	if err != nil {
+		return nil, fmt.Errorf("parse chapter list failed: %w", err)
+	}
+
+	chapters := make(model.Chapters, len(chapterList))
+	for i := range chapterList {
+		chapters[i] = model.NewChapter(i, chapterList[i].URL, chapterList[i].Title)
+	}
+
+	return chapters, nil
+}
+
+func (s *ServiceImpl) DownloadChapter(ctx context.Context, ch *model.Chapter) error {
+	s.vendorSema.Acquire(ctx, 1)
+	defer s.vendorSema.Release(1)
+	s.sema.Acquire(ctx, 1)
+	defer s.sema.Release(1)
+
+	return s.downloadChapter(ctx, ch)
+}
+
+func isChapterMissing(ch *model.Chapter) bool {
+	return ch.Error != nil || strings.TrimSpace(ch.Content) == ""
+}
+
+// alignChapters maps the index of every chapter to the index of the alternate
+// chapter of the same normalized title, the nth occurrence of a repeated title
+// is mapped to the nth occurrence in the alternate chapters
+func alignChapters(chapters, altChapters model.Chapters) map[int]int {
+	altIndexes := make(map[string][]int)
+	for i := range altChapters {
+		key := model.NormalizeChapterTitle(altChapters[i].Title)
+		if key != "" {
+			altIndexes[key] = append(altIndexes[key], i)
+		}
+	}
+
+	aligned := make(map[int]int)
+	occurrences := make(map[string]int)
+	for i := range chapters {
+		key := model.NormalizeChapterTitle(chapters[i].Title)
+		if key == "" {
+			continue
+		}
+
+		n := occurrences[key]
+		occurrences[key]++
+		if n < len(altIndexes[key]) {
+			aligned[i] = altIndexes[key][n]
+		}
+	}
+
+	return aligned
+}
+
+// downloadFromAlternateSources fills failed or empty chapters with the aligned
+// chapter from other sites in the book group
+func (s *ServiceImpl) downloadFromAlternateSources(ctx context.Context, bk *model.Book, chapters model.Chapters, stats *serv.DownloadStats) {
+	logger := zerolog.Ctx(ctx)
+
+	missingCount := 0
+	for i := range chapters {
+		if isChapterMissing(&chapters[i]) {
+			missingCount++
+		}
+	}
+
+	if missingCount == 0 {
+		return
+	}
+
+	group, err := s.rpo.FindBookGroupByIDHash(ctx, bk.Site, bk.ID, bk.HashCode)
+	if err != nil {
+		logger.Error().Err(err).Msg("find book group for alternate sources failed")
+		return
+	}
+
+	// requests are bounded by the semaphores of the source in DownloadChapter,
+	// this only bounds the goroutines waiting for them
+	se := semaphore.NewWeighted(int64(max(s.conf.ClientConfig.RateLimit.QueueSize, 1)))
+
+	for _, alt := range group {
+		source, ok := s.sources[alt.Site]
+		if !ok || alt.Status == model.StatusError {
+			continue
+		}
+
+		altChapters, err := source.ChapterList(ctx, &alt)
+		if err != nil {
+			logger.Error().Err(err).Str("source", alt.String()).Msg("get alternate chapter list failed")
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for i, altIndex := range alignChapters(chapters, altChapters) {
+			if !isChapterMissing(&chapters[i]) {
+				continue
+			}
+
+			se.Acquire(ctx, 1)
+			wg.Add(1)
+
+			go func(ch, altChapter *model.Chapter) {
+				defer wg.Done()
+				defer se.Release(1)
+
+				err := source.DownloadChapter(ctx, altChapter)
+				if err != nil || isChapterMissing(altChapter) {
+					logger.Error().Err(err).Str("source", alt.String()).
+						Str("chapter_title", altChapter.Title).
+						Msg("download alternate chapter failed")
+					return
+				}
+
+				ch.Content, ch.Error, ch.Source = altChapter.Content, nil, alt.String()
+				stats.AlternateChapters.Add(1)
+			}(&chapters[i], &altChapters[altIndex])
+		}
+
+		wg.Wait()
+	}
+}
+
+func (s *ServiceImpl) bookSourcesLocation(bk *model.Book) string {
+	return strings.TrimSuffix(s.bookFileLocation(bk), ".txt") + ".sources.json"
+}
+
+// saveChapterSources records which book every chapter is downloaded from
+func (s *ServiceImpl) saveChapterSources(bk *model.Book, chapters model.Chapters) error {
+	type chapterSource struct {
+		Index  int    `json:"index"`
+		Title  string `json:"title"`
+		Source string `json:"source"`
+	}
+
+	sources := make([]chapterSource, len(chapters))
+	for i, chapter := range chapters {
+		source := chapter.Source
+		if source == "" {
+			source = bk.String()
+		}
+		sources[i] = chapterSource{Index: chapter.Index, Title: chapter.Title, Source: source}
+	}
+
+	data, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.bookSourcesLocation(bk), data, 0644)
+}
+
 func (s *ServiceImpl) Download(ctx context.Context, stats *serv.DownloadStats) error {
 	se := semaphore.NewWeighted(int64(s.conf.MaxDownloadConcurrency))
 	var wg sync.WaitGroup
@@ -418,7 +683,15 @@ func (s *ServiceImpl) Download(ctx context.Context, stats *serv.DownloadStats) e
 		return fmt.Errorf("fail to fetch books: %w", err)
 	}
 
+	monitor := newParseMonitor(s.conf.HealthConfig)
+	ctx = withParseMonitor(ctx, monitor)
+
 	for bk := range bkChan {
+		// drain the remaining books without download once selectors are broken
+		if monitor.isTripped() {
+			continue
+		}
+
 		s.vendorSema.Acquire(ctx, 1)
 		s.sema.Acquire(ctx, 1)
 		se.Acquire(ctx, 1)
@@ -450,7 +723,7 @@ func (s *ServiceImpl) Download(ctx context.Context, stats *serv.DownloadStats) e
 
 	wg.Wait()
 
-	return nil
+	return s.finishParseMonitor(ctx, monitor, "download")
 }
 
 func isEnd(bk *model.Book) bool {
@@ -495,3 +768,88 @@ func (s *ServiceImpl) ValidateBookEnd(ctx context.Context, bk *model.Book) error
 func (s *ServiceImpl) ValidateEnd(ctx context.Context) error {
 	return s.rpo.UpdateBooksStatus(ctx)
 }
+
+func (s *ServiceImpl) LinkBookWork(ctx context.Context, bk *model.Book, stats *serv.LinkWorkStats) error {
+	if stats == nil {
+		stats = new(serv.LinkWorkStats)
+	}
+
+	if bk.WorkID > 0 || bk.Status == model.StatusError || bk.Title == "" {
+		stats.Skipped.Add(1)
+		return nil
+	}
+
+	candidates, err := s.rpo.FindWorkCandidates(ctx, bk)
+	if err != nil {
+		stats.Fail.Add(1)
+		return fmt.Errorf("find work candidates fail: %w", err)
+	}
+
+	var (
+		bestWork  *model.Work
+		bestScore float64
+	)
+	for i := range candidates {
+		score := candidates[i].MatchScore(*bk)
+		if score >= model.WorkMatchThreshold && score > bestScore {
+			bestWork, bestScore = &candidates[i], score
+		}
+	}
+
+	if bestWork == nil {
+		bestWork = &model.Work{Title: bk.Title, Writer: bk.Writer.Name}
+		err = s.rpo.CreateWork(ctx, bestWork)
+		if err != nil {
+			stats.Fail.Add(1)
+			return fmt.Errorf("create work fail: %w", err)
+		}
+		stats.NewWork.Add(1)
+	}
+
+	err = s.rpo.LinkBookToWork(ctx, bk, bestWork.ID)
+	if err != nil {
+		stats.Fail.Add(1)
+		return fmt.Errorf("link book to work fail: %w", err)
+	}
+	stats.Linked.Add(1)
+
+	return nil
+}
+
+// LinkWorks links all books without work to a new or existing work.
+// it runs sequentially so that books created in the same run can find each other's work
+func (s *ServiceImpl) LinkWorks(ctx context.Context, stats *serv.LinkWorkStats) error {
+	if stats == nil {
+		stats = new(serv.LinkWorkStats)
+	}
+
+	bkChan, err := s.rpo.FindBooksWithoutWork(ctx, s.name)
+	if err != nil {
+		return fmt.Errorf("fail to load books from DB: %w", err)
+	}
+
+	for bk := range bkChan {
+		stats.Total.Add(1)
+
+		err := s.LinkBookWork(ctx, &bk, stats)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).
+				Int("bk_id", bk.ID).
+				Str("bk_hash_code", bk.FormatHashCode()).
+				Msg("link book work failed")
+		}
+	}
+
+	return nil
+}
+
+// SyncGenres maps the book types of site not yet in genres table, the mapped
+// types are kept so that the manual corrections are not overwritten
+func (s *ServiceImpl) SyncGenres(ctx context.Context) (int, error) {
+	count, err := s.rpo.SyncGenres(ctx, s.name)
+	if err != nil {
+		return 0, fmt.Errorf("sync genres fail: %w", err)
+	}
+
+	return count, nil
+}
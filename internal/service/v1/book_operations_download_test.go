@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
 	"os"
 	"testing"
 
+	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/config/v2"
 	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
 	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	servicemock "github.com/htchan/BookSpider/internal/mock/service/v1"
 	vendormock "github.com/htchan/BookSpider/internal/mock/vendorservice"
 	"github.com/htchan/BookSpider/internal/model"
 	serv "github.com/htchan/BookSpider/internal/service"
@@ -16,23 +19,34 @@ import (
 	"golang.org/x/sync/semaphore"
 )
 
+// forgettingBookClient records the urls whose cached response is forgotten
+type forgettingBookClient struct {
+	*clientmock.MockBookClient
+	forgotten []string
+}
+
+func (c *forgettingBookClient) ForgetCachedResponse(url string) {
+	c.forgotten = append(c.forgotten, url)
+}
+
 func TestServiceImpl_downloadChapter(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		name        string
-		getService  func(ctrl *gomock.Controller) *ServiceImpl
-		chapter     *model.Chapter
-		wantChapter *model.Chapter
-		wantError   error
+		name          string
+		getService    func(ctrl *gomock.Controller) *ServiceImpl
+		chapter       *model.Chapter
+		wantChapter   *model.Chapter
+		wantError     error
+		wantForgotten []string
 	}{
 		{
 			name: "happy flow",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
-				cli := clientmock.NewMockBookClient(ctrl)
+				cli := &forgettingBookClient{MockBookClient: clientmock.NewMockBookClient(ctrl)}
 				vendorService := vendormock.NewMockVendorService(ctrl)
 
-				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("chapter response", nil)
+				cli.EXPECT().Get(gomock.Cond(client.IsCacheableResponse), "https://test.com").Return("chapter response", nil)
 				vendorService.EXPECT().ParseChapter("chapter response").Return(&vendor.ChapterInfo{
 					Title: "title", Body: "content content content",
 				}, nil)
@@ -51,7 +65,7 @@ func TestServiceImpl_downloadChapter(t *testing.T) {
 		{
 			name: "fail to send request",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
-				cli := clientmock.NewMockBookClient(ctrl)
+				cli := &forgettingBookClient{MockBookClient: clientmock.NewMockBookClient(ctrl)}
 
 				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("", serv.ErrUnavailable)
 
@@ -68,7 +82,7 @@ func TestServiceImpl_downloadChapter(t *testing.T) {
 		{
 			name: "fail to parse chapter",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
-				cli := clientmock.NewMockBookClient(ctrl)
+				cli := &forgettingBookClient{MockBookClient: clientmock.NewMockBookClient(ctrl)}
 				vendorService := vendormock.NewMockVendorService(ctrl)
 
 				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("chapter response", nil)
@@ -82,7 +96,30 @@ func TestServiceImpl_downloadChapter(t *testing.T) {
 			wantChapter: &model.Chapter{
 				Index: 1, URL: "https://test.com", Error: serv.ErrUnavailable,
 			},
-			wantError: serv.ErrUnavailable,
+			wantError:     serv.ErrUnavailable,
+			wantForgotten: []string{"https://test.com"},
+		},
+		{
+			name: "chapter decoded by wrong encoding",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := &forgettingBookClient{MockBookClient: clientmock.NewMockBookClient(ctrl)}
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("chapter response", nil)
+				vendorService.EXPECT().ParseChapter("chapter response").Return(&vendor.ChapterInfo{
+					Title: "绗�竴绔�", Body: "浠栬�杩欎釜鏃跺€欐垜浠�殑涔﹀凡缁忔潵浜嗭紝杩欐槸绗�竴绔犵殑鍐呭�銆�",
+				}, nil)
+
+				return &ServiceImpl{cli: cli, vendorService: vendorService}
+			},
+			chapter: &model.Chapter{
+				Index: 1, URL: "https://test.com",
+			},
+			wantChapter: &model.Chapter{
+				Index: 1, URL: "https://test.com", Error: serv.ErrChapterMojibake,
+			},
+			wantError:     serv.ErrChapterMojibake,
+			wantForgotten: []string{"https://test.com"},
 		},
 	}
 
@@ -93,9 +130,61 @@ func TestServiceImpl_downloadChapter(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			err := test.getService(ctrl).downloadChapter(t.Context(), test.chapter)
+			s := test.getService(ctrl)
+			err := s.downloadChapter(t.Context(), test.chapter)
 			assert.ErrorIs(t, err, test.wantError)
 			assert.Equal(t, test.wantChapter, test.chapter)
+			assert.Equal(t, test.wantForgotten, s.cli.(*forgettingBookClient).forgotten)
+		})
+	}
+}
+
+func Test_alignChapters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		chapters    model.Chapters
+		altChapters model.Chapters
+		want        map[int]int
+	}{
+		{
+			name: "align chapters of offset alternate list",
+			chapters: model.Chapters{
+				{Title: "第一章 开始"}, {Title: "第二章 结束"},
+			},
+			altChapters: model.Chapters{
+				{Title: "序章"}, {Title: "公告"}, {Title: "第1章 開始"}, {Title: "第2章 結束"},
+			},
+			want: map[int]int{0: 2, 1: 3},
+		},
+		{
+			name: "align repeated titles by occurrence",
+			chapters: model.Chapters{
+				{Title: "番外"}, {Title: "第一章"}, {Title: "番外"},
+			},
+			altChapters: model.Chapters{
+				{Title: "第一章"}, {Title: "番外"}, {Title: "番外"},
+			},
+			want: map[int]int{0: 1, 1: 0, 2: 2},
+		},
+		{
+			name: "skip chapters without alternate",
+			chapters: model.Chapters{
+				{Title: "第一章"}, {Title: "第二章"}, {Title: ""},
+			},
+			altChapters: model.Chapters{
+				{Title: "第二章"}, {Title: ""},
+			},
+			want: map[int]int{1: 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, alignChapters(test.chapters, test.altChapters))
 		})
 	}
 }
@@ -115,6 +204,8 @@ func TestServiceImpl_DownloadBook(t *testing.T) {
 		wantError            error
 		wantBookFileLocation string
 		wantBookContent      string
+		wantSourcesLocation  string
+		wantSources          string
 		wantDownloadStats    func() *serv.DownloadStats
 	}{
 		{
@@ -158,16 +249,20 @@ func TestServiceImpl_DownloadBook(t *testing.T) {
 			},
 			wantError:            nil,
 			wantBookFileLocation: "./download-book/1.txt",
-			wantBookContent: `title 1
-writer 1
+			wantBookContent: `BOOKSPIDER/2
+title: "title 1"
+writer: "writer 1"
+site: ""
+hash: "0"
+chapters: 2
 --------------------
 
+[chapter 0 title:15 content:29]
 chapter title 1
---------------------
 content 1 content 1 content 1
 --------------------
+[chapter 1 title:15 content:29]
 chapter title 2
---------------------
 content 2 content 2 content 2
 --------------------
 `,
@@ -178,6 +273,93 @@ content 2 content 2 content 2
 				return stats
 			},
 		},
+		{
+			name: "happy flow with failed chapter downloaded from alternate source",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				altService := servicemock.NewMockService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("2").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("2", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "第一章 开始"},
+					{URL: "https://test.com/chapter/2", Title: "第二章 结束"},
+				}, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(&vendor.ChapterInfo{
+					Title: "第一章 开始", Body: "content 1 content 1 content 1",
+				}, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/2").Return("", serv.ErrUnavailable)
+
+				rpo.EXPECT().FindBookGroupByIDHash(gomock.Any(), "test", 2, 0).Return(model.BookGroup{
+					{Site: "test", ID: 2, Status: model.StatusEnd},
+					{Site: "alt", ID: 9, Status: model.StatusEnd},
+				}, nil)
+				altService.EXPECT().ChapterList(gomock.Any(), &model.Book{Site: "alt", ID: 9, Status: model.StatusEnd}).
+					Return(model.Chapters{
+						{Index: 0, URL: "https://alt.com/chapter/0", Title: "序章"},
+						{Index: 1, URL: "https://alt.com/chapter/1", Title: "第1章 開始"},
+						{Index: 2, URL: "https://alt.com/chapter/2", Title: "第2章 結束"},
+					}, nil)
+				altService.EXPECT().DownloadChapter(gomock.Any(), &model.Chapter{
+					Index: 2, URL: "https://alt.com/chapter/2", Title: "第2章 結束",
+				}).DoAndReturn(func(_ context.Context, ch *model.Chapter) error {
+					ch.Content = "alt content 2"
+					return nil
+				})
+
+				rpo.EXPECT().UpdateBook(gomock.Any(), &model.Book{
+					Site: "test", ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
+					Status: model.StatusEnd, IsDownloaded: true,
+				}).Return(nil)
+
+				return &ServiceImpl{
+					name: "test",
+					conf: config.SiteConfig{Storage: "./download-book", MultiSourceDownload: true},
+					sema: semaphore.NewWeighted(1), vendorSema: semaphore.NewWeighted(1),
+					rpo: rpo, cli: cli, vendorService: vendorService,
+					sources: map[string]serv.Service{"alt": altService},
+				}
+			},
+			book: &model.Book{
+				Site: "test", ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantBook: &model.Book{
+				Site: "test", ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
+				Status: model.StatusEnd, IsDownloaded: true,
+			},
+			wantError:            nil,
+			wantBookFileLocation: "./download-book/2.txt",
+			wantBookContent: `BOOKSPIDER/2
+title: "title 2"
+writer: "writer 2"
+site: "test"
+hash: "0"
+chapters: 2
+--------------------
+
+[chapter 0 title:16 content:29]
+第一章 开始
+content 1 content 1 content 1
+--------------------
+[chapter 1 title:16 content:13]
+第二章 结束
+alt content 2
+--------------------
+`,
+			wantSourcesLocation: "./download-book/2.sources.json",
+			wantSources:         `[{"index":0,"title":"第一章 开始","source":"test-2"},{"index":1,"title":"第二章 结束","source":"alt-9"}]`,
+			wantDownloadStats: func() *serv.DownloadStats {
+				stats := new(serv.DownloadStats)
+				stats.Success.Add(1)
+				stats.AlternateChapters.Add(1)
+
+				return stats
+			},
+		},
 		{
 			name: "book status is not end",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
@@ -365,6 +547,12 @@ content 2 content 2 content 2
 				assert.NoError(t, err)
 				assert.Equal(t, test.wantBookContent, string(content))
 			}
+
+			if test.wantSourcesLocation != "" {
+				content, err := os.ReadFile(test.wantSourcesLocation)
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantSources, string(content))
+			}
 		})
 	}
 }
@@ -425,6 +613,43 @@ func TestServiceImpl_Download(t *testing.T) {
 			},
 			wantError: nil,
 		},
+		{
+			name: "abort download once selectors are broken",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				ch := make(chan model.Book)
+				go func() {
+					for id := 1; id <= 10; id++ {
+						ch <- model.Book{Site: "test", ID: id, Title: "title", Status: model.StatusEnd}
+					}
+					close(ch)
+				}()
+
+				// the book in progress when monitor trips is still downloaded
+				rpo.EXPECT().FindBooksForDownload(gomock.Any(), "test").Return(ch, nil)
+				vendorService.EXPECT().ChapterListURL(gomock.Any()).Return("https://test.com/chapter-list").MinTimes(2).MaxTimes(3)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil).MinTimes(2).MaxTimes(3)
+				vendorService.EXPECT().ParseChapterList(gomock.Any(), "chapter list response").
+					Return(nil, vendor.ErrChapterListEmpty).MinTimes(2).MaxTimes(3)
+				rpo.EXPECT().SaveSiteHealth(gomock.Any(), gomock.Cond(func(health *model.SiteHealth) bool {
+					return health.Status == model.HealthBroken && health.Phase == "download" && health.Failed == health.Checked
+				})).Return(nil)
+
+				return &ServiceImpl{
+					name: "test",
+					conf: config.SiteConfig{
+						Storage: "./download-book", MaxDownloadConcurrency: 1,
+						HealthConfig: config.HealthConfig{MaxParseFailureRate: 0.5, MinParseSamples: 2},
+					},
+					sema: semaphore.NewWeighted(1), vendorSema: semaphore.NewWeighted(1),
+					rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			wantError: serv.ErrSelectorBroken,
+		},
 		{
 			name: "fail to find books for download",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
@@ -449,3 +674,62 @@ func TestServiceImpl_Download(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceImpl_ChapterList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(ctrl *gomock.Controller) *ServiceImpl
+		book       *model.Book
+		want       model.Chapters
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+
+				return &ServiceImpl{cli: cli, vendorService: vendorService}
+			},
+			book:      &model.Book{ID: 1},
+			want:      model.Chapters{{Index: 0, URL: "https://test.com/chapter/1", Title: "title 1"}},
+			wantError: nil,
+		},
+		{
+			name: "fail to send request",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("", serv.ErrUnavailable)
+
+				return &ServiceImpl{cli: cli, vendorService: vendorService}
+			},
+			book:      &model.Book{ID: 1},
+			want:      nil,
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			got, err := test.getService(ctrl).ChapterList(t.Context(), test.book)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/config/v2"
 	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
 	repomock "github.com/htchan/BookSpider/internal/mock/repo"
@@ -11,6 +13,7 @@ import (
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
 	serv "github.com/htchan/BookSpider/internal/service"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/sync/semaphore"
@@ -72,6 +75,24 @@ func TestServiceImpl_ExploreBook(t *testing.T) {
 			wantBk:    &model.Book{ID: 1, Status: model.StatusError, Error: fmt.Errorf("get book page failed: %w", serv.ErrUnavailable)},
 			wantError: serv.ErrUnavailable,
 		},
+		{
+			name: "book page not exist",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("", client.StatusCodeError{StatusCode: 404})
+				expectErr := fmt.Errorf("get book page failed: %w", client.StatusCodeError{StatusCode: 404})
+				rpo.EXPECT().SaveError(gomock.Any(), &model.Book{ID: 1, Status: model.StatusError, Error: expectErr}, expectErr).Return(nil)
+
+				return &ServiceImpl{rpo: rpo, vendorService: vendorService, cli: cli}
+			},
+			bk:        &model.Book{ID: 1, Status: model.StatusError, Error: serv.ErrUnavailable},
+			wantBk:    &model.Book{ID: 1, Status: model.StatusError, Error: fmt.Errorf("get book page failed: %w", client.StatusCodeError{StatusCode: 404})},
+			wantError: serv.ErrBookNotExist,
+		},
 	}
 
 	for _, test := range tests {
@@ -139,6 +160,65 @@ func TestServiceImpl_Explore(t *testing.T) {
 			},
 			wantError: nil,
 		},
+		{
+			name: "temporary failure not count toward limit",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+
+				rpo.EXPECT().Stats(gomock.Any(), "test").Return(repo.Summary{LatestSuccessID: 5, MaxBookID: 5})
+
+				rpo.EXPECT().CreateBook(gomock.Any(), &model.Book{Site: "test", ID: 6, HashCode: model.GenerateHash(), Status: model.StatusError}).Return(nil)
+				vendorService.EXPECT().BookURL("6").Return("https://test.com/6")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/6").Return("", client.StatusCodeError{StatusCode: 503})
+				temporaryErr := fmt.Errorf("get book page failed: %w", client.StatusCodeError{StatusCode: 503})
+				rpo.EXPECT().SaveError(gomock.Any(), &model.Book{Site: "test", ID: 6, HashCode: model.GenerateHash(), Status: model.StatusError, Error: temporaryErr}, temporaryErr).Return(nil)
+
+				rpo.EXPECT().CreateBook(gomock.Any(), &model.Book{Site: "test", ID: 7, HashCode: model.GenerateHash(), Status: model.StatusError}).Return(nil)
+				vendorService.EXPECT().BookURL("7").Return("https://test.com/7")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/7").Return("", client.StatusCodeError{StatusCode: 404})
+				notExistErr := fmt.Errorf("get book page failed: %w", client.StatusCodeError{StatusCode: 404})
+				rpo.EXPECT().SaveError(gomock.Any(), &model.Book{Site: "test", ID: 7, HashCode: model.GenerateHash(), Status: model.StatusError, Error: notExistErr}, notExistErr).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, vendorService: vendorService, cli: cli, sema: semaphore.NewWeighted(1), vendorSema: semaphore.NewWeighted(1),
+					conf: config.SiteConfig{MaxExploreError: 1},
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "stop exploring once selectors are broken",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+
+				// the book in progress when monitor trips is still explored, and
+				// books after the max id are not explored
+				rpo.EXPECT().Stats(gomock.Any(), "test").Return(repo.Summary{LatestSuccessID: 0, MaxBookID: 3})
+				rpo.EXPECT().FindBookById(gomock.Any(), "test", gomock.Any()).DoAndReturn(func(_ context.Context, _ string, id int) (*model.Book, error) {
+					return &model.Book{Site: "test", ID: id, Status: model.StatusError, Error: serv.ErrUnavailable}, nil
+				}).MinTimes(2).MaxTimes(3)
+				vendorService.EXPECT().BookURL(gomock.Any()).Return("https://test.com").MinTimes(2).MaxTimes(3)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("response", nil).MinTimes(2).MaxTimes(3)
+				vendorService.EXPECT().ParseBook("response").Return(nil, vendor.ErrFieldsNotFound).MinTimes(2).MaxTimes(3)
+				rpo.EXPECT().SaveError(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).MinTimes(2).MaxTimes(3)
+				rpo.EXPECT().SaveSiteHealth(gomock.Any(), gomock.Cond(func(health *model.SiteHealth) bool {
+					return health.Status == model.HealthBroken && health.Phase == "explore" && health.Failed == health.Checked
+				})).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, vendorService: vendorService, cli: cli, sema: semaphore.NewWeighted(1), vendorSema: semaphore.NewWeighted(1),
+					conf: config.SiteConfig{
+						MaxExploreError: 10,
+						HealthConfig:    config.HealthConfig{MaxParseFailureRate: 0.5, MinParseSamples: 2},
+					},
+				}
+			},
+			wantError: serv.ErrSelectorBroken,
+		},
 	}
 
 	for _, test := range tests {
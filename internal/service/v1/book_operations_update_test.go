@@ -3,6 +3,8 @@ package service
 import (
 	"testing"
 
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/config/v2"
 	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
 	repomock "github.com/htchan/BookSpider/internal/mock/repo"
 	vendormock "github.com/htchan/BookSpider/internal/mock/vendorservice"
@@ -128,7 +130,7 @@ func TestServiceImpl_UpdateBook(t *testing.T) {
 				rpo, cli := repomock.NewMockRepository(ctrl), clientmock.NewMockBookClient(ctrl)
 				vendorService := vendormock.NewMockVendorService(ctrl)
 				vendorService.EXPECT().BookURL("1").Return("https://test.com")
-				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("response", nil)
+				cli.EXPECT().Get(gomock.Not(gomock.Cond(client.IsConditionalRequest)), "https://test.com").Return("response", nil)
 				vendorService.EXPECT().ParseBook("response").Return(nil, serv.ErrUnavailable)
 
 				return &ServiceImpl{rpo: rpo, vendorService: vendorService, cli: cli}
@@ -170,6 +172,30 @@ func TestServiceImpl_UpdateBook(t *testing.T) {
 				return result
 			},
 		},
+		{
+			name: "no update for book page not modified",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo, cli := repomock.NewMockRepository(ctrl), clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				vendorService.EXPECT().BookURL("1").Return("https://test.com")
+				cli.EXPECT().Get(gomock.Cond(client.IsConditionalRequest), "https://test.com").Return("", client.ErrNotModified)
+
+				return &ServiceImpl{rpo: rpo, vendorService: vendorService, cli: cli}
+			},
+			bk: &model.Book{ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
+			},
+			wantBk: &model.Book{ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
+			},
+			wantError: nil,
+			wantUpdateStats: func() *serv.UpdateStats {
+				result := new(serv.UpdateStats)
+				result.Unchanged.Add(1)
+
+				return result
+			},
+		},
 		{
 			name: "update book with status error",
 			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
@@ -441,6 +467,37 @@ func TestServiceImpl_Update(t *testing.T) {
 			},
 			wantError: nil,
 		},
+		{
+			name: "abort update once selectors are broken",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo, cli := repomock.NewMockRepository(ctrl), clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				ch := make(chan model.Book)
+
+				go func() {
+					for id := 1; id <= 10; id++ {
+						ch <- model.Book{Site: "test", ID: id, Title: "title", Status: model.StatusInProgress}
+					}
+					close(ch)
+				}()
+
+				// the book in progress when monitor trips is still updated
+				rpo.EXPECT().FindBooksForUpdate(gomock.Any(), "test").Return(ch, nil)
+				vendorService.EXPECT().BookURL(gomock.Any()).Return("https://test.com").MinTimes(2).MaxTimes(3)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("response", nil).MinTimes(2).MaxTimes(3)
+				vendorService.EXPECT().ParseBook("response").Return(nil, vendor.ErrFieldsNotFound).MinTimes(2).MaxTimes(3)
+				rpo.EXPECT().SaveSiteHealth(gomock.Any(), gomock.Cond(func(health *model.SiteHealth) bool {
+					return health.Status == model.HealthBroken && health.Phase == "update" && health.Failed == health.Checked
+				})).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", sema: semaphore.NewWeighted(1), vendorSema: semaphore.NewWeighted(1),
+					conf: config.SiteConfig{HealthConfig: config.HealthConfig{MaxParseFailureRate: 0.5, MinParseSamples: 2}},
+					rpo:  rpo, vendorService: vendorService, cli: cli,
+				}
+			},
+			wantError: serv.ErrSelectorBroken,
+		},
 		{
 			name: "return error if find book for update failed",
 			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
@@ -169,3 +169,49 @@ func TestServiceImpl_ValidateEnd(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceImpl_SyncGenres(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getServ   func(ctrl *gomock.Controller) *ServiceImpl
+		want      int
+		wantError error
+	}{
+		{
+			name: "happy flow",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().SyncGenres(gomock.Any(), "test").Return(2, nil)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			want: 2,
+		},
+		{
+			name: "repo return error",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().SyncGenres(gomock.Any(), "test").Return(0, serv.ErrUnavailable)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			want:      0,
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			count, err := test.getServ(ctrl).SyncGenres(t.Context())
+			assert.Equal(t, test.want, count)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
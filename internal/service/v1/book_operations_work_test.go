@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServiceImpl_LinkBookWork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		getServ     func(ctrl *gomock.Controller) *ServiceImpl
+		bk          *model.Book
+		wantBk      *model.Book
+		wantLinked  int64
+		wantNewWork int64
+		wantSkipped int64
+		wantError   error
+	}{
+		{
+			name: "link book to best matched work",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkCandidates(gomock.Any(), gomock.Any()).Return([]model.Work{
+					{ID: 1, Title: "斗破苍穹", Writer: "土豆"},
+					{ID: 2, Title: "斗破苍穹", Writer: "天蚕土豆"},
+				}, nil)
+				rpo.EXPECT().LinkBookToWork(gomock.Any(), gomock.Any(), 2).
+					DoAndReturn(func(_ context.Context, bk *model.Book, workID int) error {
+						bk.WorkID = workID
+						return nil
+					})
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			bk:         &model.Book{Title: "鬥破蒼穹(精校版)", Writer: model.Writer{Name: "天蠶土豆"}, Status: model.StatusInProgress},
+			wantBk:     &model.Book{Title: "鬥破蒼穹(精校版)", Writer: model.Writer{Name: "天蠶土豆"}, Status: model.StatusInProgress, WorkID: 2},
+			wantLinked: 1,
+			wantError:  nil,
+		},
+		{
+			name: "create work if no candidate matched",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkCandidates(gomock.Any(), gomock.Any()).Return([]model.Work{
+					{ID: 1, Title: "武动乾坤", Writer: "天蚕土豆"},
+				}, nil)
+				rpo.EXPECT().CreateWork(gomock.Any(), &model.Work{Title: "斗破苍穹", Writer: "天蚕土豆"}).
+					DoAndReturn(func(_ context.Context, work *model.Work) error {
+						work.ID = 3
+						return nil
+					})
+				rpo.EXPECT().LinkBookToWork(gomock.Any(), gomock.Any(), 3).
+					DoAndReturn(func(_ context.Context, bk *model.Book, workID int) error {
+						bk.WorkID = workID
+						return nil
+					})
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			bk:          &model.Book{Title: "斗破苍穹", Writer: model.Writer{Name: "天蚕土豆"}, Status: model.StatusInProgress},
+			wantBk:      &model.Book{Title: "斗破苍穹", Writer: model.Writer{Name: "天蚕土豆"}, Status: model.StatusInProgress, WorkID: 3},
+			wantLinked:  1,
+			wantNewWork: 1,
+			wantError:   nil,
+		},
+		{
+			name: "skip error book",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{}
+			},
+			bk:          &model.Book{Status: model.StatusError},
+			wantBk:      &model.Book{Status: model.StatusError},
+			wantSkipped: 1,
+			wantError:   nil,
+		},
+		{
+			name: "find candidates fail",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkCandidates(gomock.Any(), gomock.Any()).Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			bk:        &model.Book{Title: "title", Status: model.StatusInProgress},
+			wantBk:    &model.Book{Title: "title", Status: model.StatusInProgress},
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			stats := new(serv.LinkWorkStats)
+			err := test.getServ(ctrl).LinkBookWork(t.Context(), test.bk, stats)
+			assert.Equal(t, test.wantBk, test.bk)
+			assert.Equal(t, test.wantLinked, stats.Linked.Load())
+			assert.Equal(t, test.wantNewWork, stats.NewWork.Load())
+			assert.Equal(t, test.wantSkipped, stats.Skipped.Load())
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
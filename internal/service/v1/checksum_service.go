@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+// ChecksumServiceImpl fills the missing checksums of books and writers, so
+// they are grouped with the same title and writer of other sites
+type ChecksumServiceImpl struct {
+	rpo repo.Repository
+}
+
+var _ serv.ChecksumService = (*ChecksumServiceImpl)(nil)
+
+func NewChecksumService(rpo repo.Repository) *ChecksumServiceImpl {
+	return &ChecksumServiceImpl{rpo: rpo}
+}
+
+// RebuildChecksums saves the checksums of books and writers without one,
+// nothing is saved in dry run but stats are counted as if it is saved
+func (s *ChecksumServiceImpl) RebuildChecksums(ctx context.Context, dryRun bool, stats *serv.ChecksumStats) error {
+	if stats == nil {
+		stats = new(serv.ChecksumStats)
+	}
+
+	bkChan, err := s.rpo.FindBooksWithoutChecksum(ctx)
+	if err != nil {
+		return fmt.Errorf("fail to load books from DB: %w", err)
+	}
+
+	// drain the channel even if context is done, as repo is blocked on it
+	for bk := range bkChan {
+		stats.Books.Add(1)
+		if ctx.Err() != nil {
+			continue
+		}
+
+		logger := zerolog.Ctx(ctx).With().Str("book", bk.String()).Logger()
+		checksum := bk.Checksum()
+		if checksum == "" {
+			stats.Skipped.Add(1)
+			continue
+		}
+
+		if !dryRun {
+			if err := s.rpo.UpdateBookChecksum(ctx, &bk); err != nil {
+				logger.Error().Err(err).Msg("save book checksum failed")
+				stats.Fail.Add(1)
+				continue
+			}
+		}
+
+		logger.Debug().Str("checksum", checksum).Bool("dry_run", dryRun).Msg("book checksum saved")
+		stats.Updated.Add(1)
+	}
+
+	writerChan, err := s.rpo.FindWritersWithoutChecksum(ctx)
+	if err != nil {
+		return fmt.Errorf("fail to load writers from DB: %w", err)
+	}
+
+	for writer := range writerChan {
+		stats.Writers.Add(1)
+		if ctx.Err() != nil {
+			continue
+		}
+
+		logger := zerolog.Ctx(ctx).With().Int("writer_id", writer.ID).Logger()
+		checksum := writer.Checksum()
+		if checksum == "" {
+			stats.Skipped.Add(1)
+			continue
+		}
+
+		if !dryRun {
+			if err := s.rpo.UpdateWriterChecksum(ctx, &writer); err != nil {
+				logger.Error().Err(err).Msg("save writer checksum failed")
+				stats.Fail.Add(1)
+				continue
+			}
+		}
+
+		logger.Debug().Str("checksum", checksum).Bool("dry_run", dryRun).Msg("writer checksum saved")
+		stats.Updated.Add(1)
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	mockrepo "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func bookChan(bks ...model.Book) <-chan model.Book {
+	bkChan := make(chan model.Book, len(bks))
+	for _, bk := range bks {
+		bkChan <- bk
+	}
+	close(bkChan)
+
+	return bkChan
+}
+
+func writerChan(writers ...model.Writer) <-chan model.Writer {
+	writerChan := make(chan model.Writer, len(writers))
+	for _, writer := range writers {
+		writerChan <- writer
+	}
+	close(writerChan)
+
+	return writerChan
+}
+
+func TestChecksumServiceImpl_RebuildChecksums(t *testing.T) {
+	t.Parallel()
+
+	longTitle := strings.Repeat("title", 30)
+
+	tests := []struct {
+		name      string
+		getRepo   func(*gomock.Controller) *mockrepo.MockRepository
+		dryRun    bool
+		wantStats map[string]int64
+		wantError error
+	}{
+		{
+			name: "happy flow",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksWithoutChecksum(gomock.Any()).Return(bookChan(
+					model.Book{Site: "test", ID: 1, Title: "title"},
+					model.Book{Site: "test", ID: 2, Title: longTitle},
+					model.Book{Site: "test", ID: 3, Title: "fail"},
+				), nil)
+				rpo.EXPECT().UpdateBookChecksum(gomock.Any(), &model.Book{Site: "test", ID: 1, Title: "title"}).Return(nil)
+				rpo.EXPECT().UpdateBookChecksum(gomock.Any(), &model.Book{Site: "test", ID: 3, Title: "fail"}).Return(serv.ErrUnavailable)
+				rpo.EXPECT().FindWritersWithoutChecksum(gomock.Any()).Return(writerChan(model.Writer{ID: 1, Name: "writer"}), nil)
+				rpo.EXPECT().UpdateWriterChecksum(gomock.Any(), &model.Writer{ID: 1, Name: "writer"}).Return(nil)
+
+				return rpo
+			},
+			wantStats: map[string]int64{"books": 3, "writers": 1, "updated": 2, "skipped": 1, "fail": 1},
+		},
+		{
+			name: "dry run saves nothing",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksWithoutChecksum(gomock.Any()).Return(bookChan(model.Book{Site: "test", ID: 1, Title: "title"}), nil)
+				rpo.EXPECT().FindWritersWithoutChecksum(gomock.Any()).Return(writerChan(model.Writer{ID: 1, Name: "writer"}), nil)
+
+				return rpo
+			},
+			dryRun:    true,
+			wantStats: map[string]int64{"books": 1, "writers": 1, "updated": 2, "skipped": 0, "fail": 0},
+		},
+		{
+			name: "FindBooksWithoutChecksum returns error",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksWithoutChecksum(gomock.Any()).Return(nil, serv.ErrUnavailable)
+
+				return rpo
+			},
+			wantStats: map[string]int64{"books": 0, "writers": 0, "updated": 0, "skipped": 0, "fail": 0},
+			wantError: serv.ErrUnavailable,
+		},
+		{
+			name: "FindWritersWithoutChecksum returns error",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksWithoutChecksum(gomock.Any()).Return(bookChan(), nil)
+				rpo.EXPECT().FindWritersWithoutChecksum(gomock.Any()).Return(nil, serv.ErrUnavailable)
+
+				return rpo
+			},
+			wantStats: map[string]int64{"books": 0, "writers": 0, "updated": 0, "skipped": 0, "fail": 0},
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			stats := new(serv.ChecksumStats)
+			err := NewChecksumService(test.getRepo(ctrl)).RebuildChecksums(t.Context(), test.dryRun, stats)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantStats, map[string]int64{
+				"books":   stats.Books.Load(),
+				"writers": stats.Writers.Load(),
+				"updated": stats.Updated.Load(),
+				"skipped": stats.Skipped.Load(),
+				"fail":    stats.Fail.Load(),
+			})
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const canaryPhase = "canary"
+
+type contextKey string
+
+const contextKeyParseMonitor contextKey = "parse_monitor"
+
+// parseMonitor counts the parse results of vendor pages in a phase. it trips
+// once the failure rate of at least min samples exceeds the max rate, so the
+// phase stops before a broken selector fails every book
+type parseMonitor struct {
+	maxFailureRate float64
+	minSamples     int64
+
+	checked atomic.Int64
+	failed  atomic.Int64
+	tripped atomic.Bool
+
+	lock    sync.Mutex
+	lastErr error
+}
+
+// newParseMonitor returns nil if max failure rate is not set, methods of
+// nil monitor do nothing
+func newParseMonitor(conf config.HealthConfig) *parseMonitor {
+	if conf.MaxParseFailureRate <= 0 {
+		return nil
+	}
+
+	return &parseMonitor{
+		maxFailureRate: conf.MaxParseFailureRate,
+		minSamples:     int64(max(conf.MinParseSamples, 1)),
+	}
+}
+
+func withParseMonitor(ctx context.Context, monitor *parseMonitor) context.Context {
+	return context.WithValue(ctx, contextKeyParseMonitor, monitor)
+}
+
+func parseMonitorFromContext(ctx context.Context) *parseMonitor {
+	monitor, _ := ctx.Value(contextKeyParseMonitor).(*parseMonitor)
+	return monitor
+}
+
+func (monitor *parseMonitor) record(err error) {
+	if monitor == nil {
+		return
+	}
+
+	checked := monitor.checked.Add(1)
+	failed := monitor.failed.Load()
+	if err != nil {
+		failed = monitor.failed.Add(1)
+
+		monitor.lock.Lock()
+		monitor.lastErr = err
+		monitor.lock.Unlock()
+	}
+
+	if checked >= monitor.minSamples && float64(failed)/float64(checked) > monitor.maxFailureRate {
+		monitor.tripped.Store(true)
+	}
+}
+
+func (monitor *parseMonitor) isTripped() bool {
+	return monitor != nil && monitor.tripped.Load()
+}
+
+func (monitor *parseMonitor) health(site, phase string) model.SiteHealth {
+	health := model.SiteHealth{
+		Site:      site,
+		Status:    model.HealthHealthy,
+		Phase:     phase,
+		Checked:   int(monitor.checked.Load()),
+		Failed:    int(monitor.failed.Load()),
+		CheckedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	if monitor.isTripped() {
+		health.Status = model.HealthBroken
+	}
+
+	monitor.lock.Lock()
+	if monitor.lastErr != nil {
+		health.Error = monitor.lastErr.Error()
+	}
+	monitor.lock.Unlock()
+
+	return health
+}
+
+func (s *ServiceImpl) saveHealth(ctx context.Context, health *model.SiteHealth) {
+	zerolog.Ctx(ctx).Info().
+		Str("status", string(health.Status)).
+		Str("phase", health.Phase).
+		Int("checked", health.Checked).
+		Int("failed", health.Failed).
+		Msg("selector health checked")
+
+	if err := s.rpo.SaveSiteHealth(ctx, health); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("save site health fail")
+	}
+}
+
+// finishParseMonitor saves the health of phase and returns ErrSelectorBroken
+// if the monitor tripped in phase
+func (s *ServiceImpl) finishParseMonitor(ctx context.Context, monitor *parseMonitor, phase string) error {
+	if monitor == nil || monitor.checked.Load() == 0 {
+		return nil
+	}
+
+	health := monitor.health(s.name, phase)
+	s.saveHealth(ctx, &health)
+
+	if health.Status == model.HealthBroken {
+		return fmt.Errorf("%w: %d/%d pages fail to parse in %s", serv.ErrSelectorBroken, health.Failed, health.Checked, phase)
+	}
+
+	return nil
+}
+
+// CheckSelectors parses the book page and chapter list of canary books.
+// canary books are known good, so any parse failure means the vendor html
+// changed. pages fail to fetch are not counted, and selectors are not
+// checked if no page is fetched
+func (s *ServiceImpl) CheckSelectors(ctx context.Context) error {
+	if len(s.conf.HealthConfig.CanaryBookIDs) == 0 {
+		return nil
+	}
+
+	// every canary page must parse
+	monitor := &parseMonitor{minSamples: 1}
+	var fetchErr error
+
+	for _, id := range s.conf.HealthConfig.CanaryBookIDs {
+		bookID := strconv.Itoa(id)
+
+		body, err := s.cli.Get(ctx, s.vendorService.BookURL(bookID))
+		if err != nil {
+			fetchErr = errors.Join(fetchErr, fmt.Errorf("get canary book %d failed: %w", id, err))
+		} else {
+			_, err = s.vendorService.ParseBook(body)
+			monitor.record(err)
+		}
+
+		body, err = s.cli.Get(ctx, s.vendorService.ChapterListURL(bookID))
+		if err != nil {
+			fetchErr = errors.Join(fetchErr, fmt.Errorf("get canary chapter list %d failed: %w", id, err))
+		} else {
+			_, err = s.vendorService.ParseChapterList(bookID, body)
+			monitor.record(err)
+		}
+	}
+
+	if fetchErr != nil {
+		zerolog.Ctx(ctx).Warn().Err(fetchErr).Msg("some canary pages fail to fetch")
+	}
+
+	// unreachable vendor tells nothing about the selectors, the phase handles
+	// the fetch failures itself
+	if monitor.checked.Load() == 0 {
+		return nil
+	}
+
+	health := monitor.health(s.name, canaryPhase)
+	s.saveHealth(ctx, &health)
+
+	if health.Status == model.HealthBroken {
+		return fmt.Errorf("%w: %d/%d canary pages fail to parse: %s", serv.ErrSelectorBroken, health.Failed, health.Checked, health.Error)
+	}
+
+	return nil
+}
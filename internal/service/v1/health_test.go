@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	vendormock "github.com/htchan/BookSpider/internal/mock/vendorservice"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_parseMonitor_record(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		conf        config.HealthConfig
+		results     []error
+		wantNil     bool
+		wantTripped bool
+		wantHealth  model.SiteHealth
+	}{
+		{
+			name:    "disabled without max failure rate",
+			conf:    config.HealthConfig{MinParseSamples: 1},
+			results: []error{vendor.ErrFieldsNotFound},
+			wantNil: true,
+		},
+		{
+			name:        "not tripped before min samples",
+			conf:        config.HealthConfig{MaxParseFailureRate: 0.5, MinParseSamples: 3},
+			results:     []error{vendor.ErrFieldsNotFound, vendor.ErrFieldsNotFound},
+			wantTripped: false,
+			wantHealth: model.SiteHealth{
+				Site: "test", Status: model.HealthHealthy, Phase: "update",
+				Checked: 2, Failed: 2, Error: vendor.ErrFieldsNotFound.Error(),
+			},
+		},
+		{
+			name:        "tripped once failure rate exceeds max rate",
+			conf:        config.HealthConfig{MaxParseFailureRate: 0.5, MinParseSamples: 3},
+			results:     []error{nil, vendor.ErrFieldsNotFound, vendor.ErrFieldsNotFound},
+			wantTripped: true,
+			wantHealth: model.SiteHealth{
+				Site: "test", Status: model.HealthBroken, Phase: "update",
+				Checked: 3, Failed: 2, Error: vendor.ErrFieldsNotFound.Error(),
+			},
+		},
+		{
+			name:        "not tripped at max rate",
+			conf:        config.HealthConfig{MaxParseFailureRate: 0.5, MinParseSamples: 2},
+			results:     []error{nil, vendor.ErrFieldsNotFound},
+			wantTripped: false,
+			wantHealth: model.SiteHealth{
+				Site: "test", Status: model.HealthHealthy, Phase: "update",
+				Checked: 2, Failed: 1, Error: vendor.ErrFieldsNotFound.Error(),
+			},
+		},
+		{
+			name:        "tripped state is kept after success",
+			conf:        config.HealthConfig{MaxParseFailureRate: 0.1, MinParseSamples: 1},
+			results:     []error{vendor.ErrFieldsNotFound, nil, nil},
+			wantTripped: true,
+			wantHealth: model.SiteHealth{
+				Site: "test", Status: model.HealthBroken, Phase: "update",
+				Checked: 3, Failed: 1, Error: vendor.ErrFieldsNotFound.Error(),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			monitor := newParseMonitor(test.conf)
+			ctx := withParseMonitor(t.Context(), monitor)
+			for _, err := range test.results {
+				parseMonitorFromContext(ctx).record(err)
+			}
+
+			assert.Equal(t, test.wantTripped, monitor.isTripped())
+			if test.wantNil {
+				assert.Nil(t, monitor)
+				return
+			}
+
+			health := monitor.health("test", "update")
+			assert.NotZero(t, health.CheckedAt)
+			health.CheckedAt = test.wantHealth.CheckedAt
+			assert.Equal(t, test.wantHealth, health)
+		})
+	}
+}
+
+func TestServiceImpl_CheckSelectors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		wantError  error
+	}{
+		{
+			name: "skip without canary books",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{name: "test"}
+			},
+			wantError: nil,
+		},
+		{
+			name: "canary books parsed",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/book/1").Return("book response", nil)
+				vendorService.EXPECT().ParseBook("book response").Return(&vendor.BookInfo{Title: "title"}, nil)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapters/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapters/1").Return("", serv.ErrUnavailable)
+				rpo.EXPECT().SaveSiteHealth(gomock.Any(), gomock.Cond(func(health *model.SiteHealth) bool {
+					return health.Site == "test" && health.Status == model.HealthHealthy &&
+						health.Phase == canaryPhase && health.Checked == 1 && health.Failed == 0
+				})).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, cli: cli, vendorService: vendorService,
+					conf: config.SiteConfig{HealthConfig: config.HealthConfig{CanaryBookIDs: []int{1}}},
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "canary book fail to parse",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/book/1").Return("book response", nil)
+				vendorService.EXPECT().ParseBook("book response").Return(&vendor.BookInfo{Title: "title"}, nil)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapters/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapters/1").Return("chapters response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapters response").Return(nil, vendor.ErrChapterListEmpty)
+				vendorService.EXPECT().BookURL("2").Return("https://test.com/book/2")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/book/2").Return("book response 2", nil)
+				vendorService.EXPECT().ParseBook("book response 2").Return(nil, vendor.ErrFieldsNotFound)
+				vendorService.EXPECT().ChapterListURL("2").Return("https://test.com/chapters/2")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapters/2").Return("chapters response 2", nil)
+				vendorService.EXPECT().ParseChapterList("2", "chapters response 2").Return(vendor.ChapterList{{URL: "url"}}, nil)
+				rpo.EXPECT().SaveSiteHealth(gomock.Any(), gomock.Cond(func(health *model.SiteHealth) bool {
+					return health.Status == model.HealthBroken && health.Checked == 4 && health.Failed == 2 &&
+						health.Error == vendor.ErrFieldsNotFound.Error()
+				})).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, cli: cli, vendorService: vendorService,
+					conf: config.SiteConfig{HealthConfig: config.HealthConfig{CanaryBookIDs: []int{1, 2}}},
+				}
+			},
+			wantError: serv.ErrSelectorBroken,
+		},
+		{
+			name: "skip if canary pages fail to fetch",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/book/1").Return("", serv.ErrUnavailable)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapters/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapters/1").Return("", serv.ErrUnavailable)
+
+				return &ServiceImpl{
+					name: "test", cli: cli, vendorService: vendorService,
+					conf: config.SiteConfig{HealthConfig: config.HealthConfig{CanaryBookIDs: []int{1}}},
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "save health failure is not returned",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/book/1").Return("book response", nil)
+				vendorService.EXPECT().ParseBook("book response").Return(&vendor.BookInfo{Title: "title"}, nil)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapters/1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapters/1").Return("chapters response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapters response").Return(vendor.ChapterList{{URL: "url"}}, nil)
+				rpo.EXPECT().SaveSiteHealth(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, cli: cli, vendorService: vendorService,
+					conf: config.SiteConfig{HealthConfig: config.HealthConfig{CanaryBookIDs: []int{1}}},
+				}
+			},
+			wantError: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			err := test.getService(ctrl).CheckSelectors(t.Context())
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
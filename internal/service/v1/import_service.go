@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/format"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const importUpdateDateLayout = "2006-01-02"
+
+// ImportServiceImpl saves external books under the local site. imported
+// books have checksum like crawled books, so they are grouped together
+type ImportServiceImpl struct {
+	rpo        repo.Repository
+	formatServ format.Service
+	storage    string
+	now        func() time.Time
+}
+
+var _ serv.ImportService = (*ImportServiceImpl)(nil)
+
+func NewImportService(rpo repo.Repository, formatServ format.Service, storage string) *ImportServiceImpl {
+	return &ImportServiceImpl{
+		rpo:        rpo,
+		formatServ: formatServ,
+		storage:    storage,
+		now:        time.Now,
+	}
+}
+
+func (s *ImportServiceImpl) bookFileLocation(bk *model.Book) string {
+	filename := fmt.Sprintf("%d.txt", bk.ID)
+	if bk.HashCode > 0 {
+		filename = fmt.Sprintf("%d-v%s.txt", bk.ID, bk.FormatHashCode())
+	}
+
+	return filepath.Join(s.storage, filename)
+}
+
+func (s *ImportServiceImpl) parse(ctx context.Context, params serv.ImportParams) (*model.Book, model.Chapters, error) {
+	switch params.Format {
+	case "txt":
+		return s.formatServ.ImportTxt(ctx, io.NewSectionReader(params.File, 0, params.Size))
+	case "epub":
+		return s.formatServ.ImportEpub(ctx, params.File, params.Size)
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", serv.ErrImportFormat, params.Format)
+	}
+}
+
+func (s *ImportServiceImpl) ImportBook(ctx context.Context, params serv.ImportParams) (*model.Book, error) {
+	parsedBk, chapters, err := s.parse(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("parse import file fail: %w", err)
+	}
+
+	for _, override := range []struct {
+		field *string
+		value string
+	}{
+		{field: &parsedBk.Title, value: params.Title},
+		{field: &parsedBk.Writer.Name, value: params.Writer},
+		{field: &parsedBk.Type, value: params.Type},
+	} {
+		if override.value != "" {
+			*override.field = override.value
+		}
+	}
+
+	if parsedBk.Title == "" {
+		return nil, serv.ErrImportTitleMissing
+	}
+
+	// id is taken by repository, so concurrent imports never share the same id
+	bk := model.NewBook(model.LocalSite, 0)
+	bk.Title, bk.Writer.Name, bk.Type = parsedBk.Title, parsedBk.Writer.Name, parsedBk.Type
+	bk.UpdateDate = s.now().UTC().Format(importUpdateDateLayout)
+	bk.UpdateChapter = chapters[len(chapters)-1].Title
+	bk.Status = model.StatusEnd
+
+	if err := s.rpo.SaveWriter(ctx, &bk.Writer); err != nil {
+		return nil, fmt.Errorf("save writer fail: %w", err)
+	}
+	if err := s.rpo.CreateBookWithNextID(ctx, &bk); err != nil {
+		return nil, fmt.Errorf("create book fail: %w", err)
+	}
+
+	logger := zerolog.Ctx(ctx).With().Str("book", bk.String()).Logger()
+
+	logger.Info().Int("chapters", len(chapters)).Msg("save imported chapters")
+	location := s.bookFileLocation(&bk)
+	file, err := os.Create(location)
+	if err != nil {
+		return nil, fmt.Errorf("create file to save chapters fail: %w", err)
+	}
+
+	err = model.WriteBookFile(file, &bk, chapters)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("write book file fail: %w", err), os.Remove(location))
+	}
+
+	bk.IsDownloaded = true
+	if err := s.rpo.UpdateBook(ctx, &bk); err != nil {
+		return nil, fmt.Errorf("update book is_downloaded fail: %w", err)
+	}
+
+	return &bk, nil
+}
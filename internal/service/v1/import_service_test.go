@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/format"
+	formatv1 "github.com/htchan/BookSpider/internal/format/v1"
+	mockrepo "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewImportService(t *testing.T) {
+	t.Parallel()
+
+	got := NewImportService(nil, nil, "/data")
+	assert.Equal(t, "/data", got.storage)
+	assert.NotNil(t, got.now)
+}
+
+func TestImportServiceImpl_ImportBook(t *testing.T) {
+	t.Parallel()
+
+	importTime := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	txtContent := "《斗破苍穹》\n作者：天蚕土豆\n第一章 开始\n内容 1\n第二章 结束\n内容 2\n"
+	formatServ := formatv1.NewService(config.EpubConfig{}, nil)
+	createErr := errors.New("create failed")
+
+	tests := []struct {
+		name        string
+		getRepo     func(*gomock.Controller) *mockrepo.MockRepository
+		params      serv.ImportParams
+		want        *model.Book
+		wantFile    string
+		wantContent string
+		wantError   error
+	}{
+		{
+			name: "happy flow/txt",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SaveWriter(gomock.Any(), &model.Writer{Name: "天蚕土豆"}).DoAndReturn(
+					func(_ context.Context, writer *model.Writer) error {
+						writer.ID = 5
+						return nil
+					},
+				)
+				rpo.EXPECT().CreateBookWithNextID(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, bk *model.Book) error {
+						bk.ID, bk.HashCode = 4, 0
+						return nil
+					},
+				)
+				rpo.EXPECT().UpdateBook(gomock.Any(), gomock.Any()).Return(nil)
+
+				return rpo
+			},
+			params: serv.ImportParams{Format: "txt", File: strings.NewReader(txtContent), Size: int64(len(txtContent))},
+			want: &model.Book{
+				Site: model.LocalSite, ID: 4, Title: "斗破苍穹", Writer: model.Writer{ID: 5, Name: "天蚕土豆"},
+				UpdateDate: "2026-10-19", UpdateChapter: "第二章 结束", Status: model.StatusEnd, IsDownloaded: true,
+			},
+			wantFile: "4.txt",
+			wantContent: `BOOKSPIDER/2
+title: "斗破苍穹"
+writer: "天蚕土豆"
+site: "local"
+hash: "0"
+chapters: 2
+--------------------
+
+[chapter 0 title:16 content:8]
+第一章 开始
+内容 1
+--------------------
+[chapter 1 title:16 content:8]
+第二章 结束
+内容 2
+--------------------
+`,
+		},
+		{
+			name: "happy flow/first book with overridden info",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SaveWriter(gomock.Any(), &model.Writer{Name: "writer"}).Return(nil)
+				rpo.EXPECT().CreateBookWithNextID(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, bk *model.Book) error {
+						bk.ID, bk.HashCode = 1, 0
+						return nil
+					},
+				)
+				rpo.EXPECT().UpdateBook(gomock.Any(), gomock.Any()).Return(nil)
+
+				return rpo
+			},
+			params: serv.ImportParams{
+				Format: "txt", File: strings.NewReader(txtContent), Size: int64(len(txtContent)),
+				Title: "title", Writer: "writer", Type: "type",
+			},
+			want: &model.Book{
+				Site: model.LocalSite, ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Type: "type",
+				UpdateDate: "2026-10-19", UpdateChapter: "第二章 结束", Status: model.StatusEnd, IsDownloaded: true,
+			},
+			wantFile: "1.txt",
+		},
+		{
+			name: "unsupported format",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				return mockrepo.NewMockRepository(ctrl)
+			},
+			params:    serv.ImportParams{Format: "pdf", File: strings.NewReader(""), Size: 0},
+			wantError: serv.ErrImportFormat,
+		},
+		{
+			name: "file without chapter",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				return mockrepo.NewMockRepository(ctrl)
+			},
+			params:    serv.ImportParams{Format: "txt", File: strings.NewReader("content"), Size: 7},
+			wantError: format.ErrNoChapter,
+		},
+		{
+			name: "title missing",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				return mockrepo.NewMockRepository(ctrl)
+			},
+			params:    serv.ImportParams{Format: "txt", File: strings.NewReader("第一章\n内容\n"), Size: 17},
+			wantError: serv.ErrImportTitleMissing,
+		},
+		{
+			name: "create book fail",
+			getRepo: func(ctrl *gomock.Controller) *mockrepo.MockRepository {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SaveWriter(gomock.Any(), gomock.Any()).Return(nil)
+				rpo.EXPECT().CreateBookWithNextID(gomock.Any(), gomock.Any()).Return(createErr)
+
+				return rpo
+			},
+			params:    serv.ImportParams{Format: "txt", File: strings.NewReader(txtContent), Size: int64(len(txtContent))},
+			wantError: createErr,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			storage := t.TempDir()
+			s := &ImportServiceImpl{
+				rpo:        test.getRepo(ctrl),
+				formatServ: formatServ,
+				storage:    storage,
+				now:        func() time.Time { return importTime },
+			}
+
+			got, err := s.ImportBook(context.Background(), test.params)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.want, got)
+
+			if test.wantFile != "" {
+				content, err := os.ReadFile(filepath.Join(storage, test.wantFile))
+				assert.NoError(t, err)
+				if test.wantContent != "" {
+					assert.Equal(t, test.wantContent, string(content))
+				}
+			}
+		})
+	}
+}
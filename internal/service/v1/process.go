@@ -50,6 +50,14 @@ func (s *ServiceImpl) Process(ctx context.Context) error {
 	// 	return fmt.Errorf("Backup fail: %w", backupErr)
 	// }
 
+	checkProxiesCtx := zerolog.Ctx(ctx).With().Str("operation", "check-proxies").Logger().WithContext(ctx)
+	zerolog.Ctx(checkProxiesCtx).Trace().Msg("start")
+	checkProxiesErr := s.proxies.checkHealth(checkProxiesCtx)
+	zerolog.Ctx(checkProxiesCtx).Trace().Msg("complete")
+	if checkProxiesErr != nil {
+		return fmt.Errorf("check proxies fail: %w", checkProxiesErr)
+	}
+
 	checkAvailabilityCtx := zerolog.Ctx(ctx).With().Str("operation", "check-availability").Logger().WithContext(ctx)
 	zerolog.Ctx(checkAvailabilityCtx).Trace().Msg("start")
 	checkAvailabilityErr := s.CheckAvailability(checkAvailabilityCtx)
@@ -60,6 +68,9 @@ func (s *ServiceImpl) Process(ctx context.Context) error {
 
 	updateCtx := zerolog.Ctx(ctx).With().Str("operation", "update").Logger().WithContext(ctx)
 	zerolog.Ctx(updateCtx).Trace().Msg("start")
+	if canaryErr := s.CheckSelectors(updateCtx); canaryErr != nil {
+		return fmt.Errorf("Update fail: %w", canaryErr)
+	}
 	updateStats := new(serv.UpdateStats)
 	updateErr := s.Update(updateCtx, updateStats)
 	zerolog.Ctx(updateCtx).Trace().
@@ -78,6 +89,9 @@ func (s *ServiceImpl) Process(ctx context.Context) error {
 	}
 	exploreCtx := zerolog.Ctx(ctx).With().Str("operation", "explore").Logger().WithContext(ctx)
 	zerolog.Ctx(exploreCtx).Trace().Msg("start")
+	if canaryErr := s.CheckSelectors(exploreCtx); canaryErr != nil {
+		return fmt.Errorf("Explore fail: %w", canaryErr)
+	}
 	exploreStats := new(serv.UpdateStats)
 	exploreErr := s.Explore(exploreCtx, exploreStats)
 	zerolog.Ctx(exploreCtx).Trace().
@@ -103,8 +117,34 @@ func (s *ServiceImpl) Process(ctx context.Context) error {
 		return fmt.Errorf("Update Status fail: %w", checkErr)
 	}
 
+	linkWorksCtx := zerolog.Ctx(ctx).With().Str("operation", "link-works").Logger().WithContext(ctx)
+	zerolog.Ctx(linkWorksCtx).Trace().Msg("start")
+	linkWorkStats := new(serv.LinkWorkStats)
+	linkWorksErr := s.LinkWorks(linkWorksCtx, linkWorkStats)
+	zerolog.Ctx(linkWorksCtx).Trace().
+		Int64("total", linkWorkStats.Total.Load()).
+		Int64("linked", linkWorkStats.Linked.Load()).
+		Int64("new_work", linkWorkStats.NewWork.Load()).
+		Int64("skipped", linkWorkStats.Skipped.Load()).
+		Int64("fail", linkWorkStats.Fail.Load()).
+		Msg("complete")
+	if linkWorksErr != nil {
+		return fmt.Errorf("link works fail: %w", linkWorksErr)
+	}
+
+	syncGenresCtx := zerolog.Ctx(ctx).With().Str("operation", "sync-genres").Logger().WithContext(ctx)
+	zerolog.Ctx(syncGenresCtx).Trace().Msg("start")
+	syncedGenres, syncGenresErr := s.SyncGenres(syncGenresCtx)
+	zerolog.Ctx(syncGenresCtx).Trace().Int("new_types", syncedGenres).Msg("complete")
+	if syncGenresErr != nil {
+		return fmt.Errorf("sync genres fail: %w", syncGenresErr)
+	}
+
 	downloadCtx := zerolog.Ctx(ctx).With().Str("operation", "download").Logger().WithContext(ctx)
 	zerolog.Ctx(downloadCtx).Trace().Msg("start")
+	if canaryErr := s.CheckSelectors(downloadCtx); canaryErr != nil {
+		return fmt.Errorf("Download fail: %w", canaryErr)
+	}
 	downloadStats := new(serv.DownloadStats)
 	downloadErr := s.Download(downloadCtx, downloadStats)
 	zerolog.Ctx(downloadCtx).Trace().
@@ -113,6 +153,7 @@ func (s *ServiceImpl) Process(ctx context.Context) error {
 		Int64("no_chapter_error", downloadStats.NoChapter.Load()).
 		Int64("too_many_failed_chapters", downloadStats.TooManyFailChapters.Load()).
 		Int64("request_fail", downloadStats.RequestFail.Load()).
+		Int64("alternate_chapters", downloadStats.AlternateChapters.Load()).
 		Msg("complete")
 	if downloadErr != nil {
 		return fmt.Errorf("Download fail: %w", downloadErr)
@@ -148,5 +189,26 @@ func (s *ServiceImpl) Process(ctx context.Context) error {
 		return fmt.Errorf("patch status fail: %w", patchMissingRecordsErr)
 	}
 
+	pruneSnapshotsCtx := zerolog.Ctx(ctx).With().Str("operation", "prune-snapshots").Logger().WithContext(ctx)
+	zerolog.Ctx(pruneSnapshotsCtx).Trace().Msg("start")
+	removedSnapshots, pruneSnapshotsErr := s.snapshots.Prune()
+	zerolog.Ctx(pruneSnapshotsCtx).Trace().Int("removed_objects", removedSnapshots).Msg("complete")
+	if pruneSnapshotsErr != nil {
+		return fmt.Errorf("prune snapshots fail: %w", pruneSnapshotsErr)
+	}
+
+	pruneCacheCtx := zerolog.Ctx(ctx).With().Str("operation", "prune-response-cache").Logger().WithContext(ctx)
+	zerolog.Ctx(pruneCacheCtx).Trace().Msg("start")
+	removedCache, pruneCacheErr := s.responseCache.Prune()
+	zerolog.Ctx(pruneCacheCtx).Trace().Int("removed_entries", removedCache).Msg("complete")
+	if pruneCacheErr != nil {
+		return fmt.Errorf("prune response cache fail: %w", pruneCacheErr)
+	}
+
 	return nil
 }
+
+// Backup copies the books, writers and errors of site to backup directory
+func (s *ServiceImpl) Backup(ctx context.Context) error {
+	return s.rpo.Backup(ctx, s.name, s.conf.BackupDirectory)
+}
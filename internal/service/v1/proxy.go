@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/htchan/goclient"
+	circuitbreaker "github.com/htchan/goclient/middlewares/circuit_breaker"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	rotationRoundRobin = "round_robin"
+	rotationRandom     = "random"
+)
+
+// proxy is a route of requests to vendor, nil url means direct connection
+type proxy struct {
+	url     *url.URL
+	cli     *goclient.Client // request through circuit breaker of proxy
+	httpCli *http.Client     // request without circuit breaker, for health check
+
+	open    atomic.Bool
+	healthy atomic.Bool
+}
+
+func (p *proxy) String() string {
+	if p.url == nil {
+		return "direct"
+	}
+
+	return p.url.Redacted()
+}
+
+func (p *proxy) isAvailable() bool {
+	return p.healthy.Load() && !p.open.Load()
+}
+
+// proxyPool is the requester of goclient, it sends every request through a
+// proxy picked by strategy with an identity profile picked by same strategy
+type proxyPool struct {
+	proxies        []*proxy
+	strategy       string
+	profiles       []config.IdentityProfileConfig
+	healthCheckURL string
+
+	nextProxy   atomic.Uint64
+	nextProfile atomic.Uint64
+}
+
+func newHTTPClient(proxyURL *url.URL, conf config.IdentityConfig, timeout time.Duration) *http.Client {
+	httpCli := &http.Client{Timeout: timeout}
+	if proxyURL != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		httpCli.Transport = transport
+	}
+
+	if conf.CookieJar {
+		// cookiejar.New never returns error without options
+		httpCli.Jar, _ = cookiejar.New(nil)
+	}
+
+	return httpCli
+}
+
+// newProxyPool builds a direct route if no proxy is configured. proxy with
+// invalid url is skipped
+func newProxyPool(name string, conf config.ClientConfig, timeout time.Duration) *proxyPool {
+	pool := &proxyPool{
+		strategy:       conf.Proxy.Strategy,
+		profiles:       conf.Identity.Profiles,
+		healthCheckURL: conf.Proxy.HealthCheckURL,
+	}
+
+	for _, rawURL := range conf.Proxy.URLs {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil {
+			log.Error().Err(err).Str("vendor", name).Msg("parse proxy url failed")
+			continue
+		}
+
+		p := &proxy{url: proxyURL, httpCli: newHTTPClient(proxyURL, conf.Identity, timeout)}
+		p.healthy.Store(true)
+
+		breaker := circuitbreaker.NewCircuitBreaker(
+			conf.CircuitBreaker.FailureThreshold,
+			conf.CircuitBreaker.SuccessThreshold,
+			conf.CircuitBreaker.RecoverDuration,
+			isServerError,
+			circuitbreaker.WithOnStateChange(func(from, to circuitbreaker.State) {
+				p.open.Store(to == circuitbreaker.StateOpen)
+
+				log.Info().
+					Str("vendor", name).
+					Str("proxy", p.String()).
+					Str("from", from.String()).
+					Str("to", to.String()).
+					Msg("proxy circuit breaker state changed")
+			}),
+		)
+		p.cli = goclient.NewClient(
+			goclient.WithRequester(p.httpCli.Do),
+			goclient.WithMiddlewares(circuitbreaker.NewCircuitBreakerMiddleware(breaker)),
+		)
+
+		pool.proxies = append(pool.proxies, p)
+	}
+
+	if len(pool.proxies) == 0 {
+		p := &proxy{httpCli: newHTTPClient(nil, conf.Identity, timeout)}
+		p.healthy.Store(true)
+		p.cli = goclient.NewClient(goclient.WithRequester(p.httpCli.Do))
+
+		pool.proxies = append(pool.proxies, p)
+	}
+
+	return pool
+}
+
+// hasProxy reports if requests are sent through proxies instead of directly
+func (pool *proxyPool) hasProxy() bool {
+	return pool.proxies[0].url != nil
+}
+
+// pickIndex returns index in [0, n) by strategy of pool
+func (pool *proxyPool) pickIndex(counter *atomic.Uint64, n int) int {
+	if pool.strategy == rotationRandom {
+		return rand.IntN(n)
+	}
+
+	return int((counter.Add(1) - 1) % uint64(n))
+}
+
+func (pool *proxyPool) pick() *proxy {
+	available := make([]*proxy, 0, len(pool.proxies))
+	for _, p := range pool.proxies {
+		if p.isAvailable() {
+			available = append(available, p)
+		}
+	}
+
+	if len(available) == 0 {
+		return nil
+	}
+
+	return available[pool.pickIndex(&pool.nextProxy, len(available))]
+}
+
+func (pool *proxyPool) applyIdentity(req *http.Request) {
+	if len(pool.profiles) == 0 {
+		return
+	}
+
+	profile := pool.profiles[pool.pickIndex(&pool.nextProfile, len(pool.profiles))]
+	req.Header.Set("User-Agent", profile.UserAgent)
+	for key, value := range profile.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+func (pool *proxyPool) do(req *http.Request) (*http.Response, error) {
+	p := pool.pick()
+	if p == nil {
+		return nil, serv.ErrNoProxyAvailable
+	}
+
+	pool.applyIdentity(req)
+
+	return p.cli.Do(req)
+}
+
+// checkHealth requests the health check url through every proxy, proxies
+// failed the check are skipped until next check
+func (pool *proxyPool) checkHealth(ctx context.Context) error {
+	if pool == nil || pool.healthCheckURL == "" {
+		return nil
+	}
+
+	healthyCount := 0
+	for _, p := range pool.proxies {
+		err := checkProxy(ctx, p, pool.healthCheckURL)
+		p.healthy.Store(err == nil)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Str("proxy", p.String()).Msg("proxy health check failed")
+			continue
+		}
+
+		healthyCount++
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int("healthy", healthyCount).
+		Int("total", len(pool.proxies)).
+		Msg("proxy health checked")
+
+	if healthyCount == 0 {
+		return serv.ErrNoProxyAvailable
+	}
+
+	return nil
+}
+
+func checkProxy(ctx context.Context, p *proxy, healthCheckURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthCheckURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.httpCli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("health check status code is %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -57,17 +58,30 @@ func (s *ReadDataServiceImpl) Book(ctx context.Context, site, id, hash string) (
 	return s.rpo.FindBookByIdHash(ctx, site, int(bkID), int(hashcode))
 }
 
-func (s *ReadDataServiceImpl) BookContent(ctx context.Context, bk *model.Book) (string, error) {
+// BookFile opens the content file of the book, caller should close it
+func (s *ReadDataServiceImpl) BookFile(ctx context.Context, bk *model.Book) (*os.File, error) {
 	if !bk.IsDownloaded {
-		return "", serv.ErrBookNotDownload
+		return nil, serv.ErrBookNotDownload
+	}
+
+	file, err := os.Open(s.bookFileLocation(bk))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, serv.ErrBookFileNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("open file fail: %w", err)
 	}
 
-	location := s.bookFileLocation(bk)
-	if _, err := os.Stat(location); err != nil {
-		return "", serv.ErrBookFileNotFound
+	return file, nil
+}
+
+func (s *ReadDataServiceImpl) BookContent(ctx context.Context, bk *model.Book) (string, error) {
+	file, err := s.BookFile(ctx, bk)
+	if err != nil {
+		return "", err
 	}
+	defer file.Close()
 
-	content, err := os.ReadFile(location)
+	content, err := io.ReadAll(file)
 	if err != nil {
 		return "", fmt.Errorf("read file fail: %w", err)
 	}
@@ -76,12 +90,13 @@ func (s *ReadDataServiceImpl) BookContent(ctx context.Context, bk *model.Book) (
 }
 
 func (s *ReadDataServiceImpl) BookChapters(ctx context.Context, bk *model.Book) (model.Chapters, error) {
-	content, err := s.BookContent(ctx, bk)
+	file, err := s.BookFile(ctx, bk)
 	if err != nil {
 		return nil, fmt.Errorf("load content failed: %w", err)
 	}
+	defer file.Close()
 
-	chapters, err := model.StringToChapters(content)
+	_, chapters, err := model.ReadBookFile(file)
 	if err != nil {
 		return nil, fmt.Errorf("parse chapter failed: %w", err)
 	}
@@ -154,12 +169,123 @@ func (s *ReadDataServiceImpl) BookGroup(ctx context.Context, site, id, hash stri
 	return &bk, &group, nil
 }
 
-func (s *ReadDataServiceImpl) SearchBooks(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error) {
-	return s.rpo.FindBooksByTitleWriter(ctx, title, writer, limit, offset)
+// SearchBooks returns a page of books matching the title or writer. books of
+// the same work are listed once, with every book of the work in sources
+func (s *ReadDataServiceImpl) SearchBooks(ctx context.Context, title, writer string, genre model.Genre, page repo.PageParams) (*repo.BookPage, error) {
+	return s.rpo.FindBooksByTitleWriter(ctx, title, writer, genre, page)
+}
+
+func (s *ReadDataServiceImpl) RandomBooks(ctx context.Context, genre model.Genre, sort model.BookSort, limit int) ([]model.Book, error) {
+	return s.rpo.FindBooksByRandom(ctx, genre, sort, limit)
+}
+
+// BrowseBooks returns a page of books of the genre filtered by site and status
+func (s *ReadDataServiceImpl) BrowseBooks(ctx context.Context, params repo.BrowseParams, page repo.PageParams) (*repo.BookPage, error) {
+	if params.Genre == "" {
+		return nil, model.ErrInvalidGenre
+	}
+
+	return s.rpo.FindBooksByGenre(ctx, params, page)
+}
+
+func parseWorkID(workID string) (int, error) {
+	id, err := strconv.Atoi(workID)
+	if err != nil || id <= 0 {
+		return 0, serv.ErrInvalidWorkID
+	}
+
+	return id, nil
+}
+
+func (s *ReadDataServiceImpl) Work(ctx context.Context, workID string) (*model.Work, error) {
+	id, err := parseWorkID(workID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rpo.FindWorkByID(ctx, id)
+}
+
+// WorkSource returns the downloaded best source of the work
+func (s *ReadDataServiceImpl) WorkSource(ctx context.Context, work *model.Work) (*model.Book, error) {
+	bk := work.BestSource()
+	if bk == nil || !bk.IsDownloaded {
+		return nil, serv.ErrWorkNoSource
+	}
+
+	return bk, nil
+}
+
+// MergeWorks moves all books of source work into target work
+func (s *ReadDataServiceImpl) MergeWorks(ctx context.Context, targetID, sourceID string) error {
+	target, err := parseWorkID(targetID)
+	if err != nil {
+		return err
+	}
+
+	source, err := parseWorkID(sourceID)
+	if err != nil {
+		return err
+	}
+
+	if target == source {
+		return serv.ErrInvalidWorkID
+	}
+
+	return s.rpo.MergeWorks(ctx, target, source)
+}
+
+// SplitBookWork moves the book out of its work into a new work of its own.
+// the new work keeps the book from being linked back by the next link works run
+func (s *ReadDataServiceImpl) SplitBookWork(ctx context.Context, bk *model.Book) (*model.Work, error) {
+	work := &model.Work{Title: bk.Title, Writer: bk.Writer.Name}
+	err := s.rpo.CreateWork(ctx, work)
+	if err != nil {
+		return nil, fmt.Errorf("create work fail: %w", err)
+	}
+
+	err = s.rpo.LinkBookToWork(ctx, bk, work.ID)
+	if err != nil {
+		return nil, fmt.Errorf("link book to work fail: %w", err)
+	}
+
+	work.Books = model.BookGroup{*bk}
+
+	return work, nil
+}
+
+// Writer returns every book of the writer grouped by work in the sort order
+func (s *ReadDataServiceImpl) Writer(ctx context.Context, writerID string, sort model.BookSort) (*model.WriterCatalog, error) {
+	id, err := strconv.Atoi(writerID)
+	if err != nil || id <= 0 {
+		return nil, serv.ErrInvalidWriterID
+	}
+
+	writer, err := s.rpo.FindWriterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bks, err := s.rpo.FindBooksByWriterID(ctx, id, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewWriterCatalog(*writer, bks), nil
+}
+
+// SearchWriters returns writers matching the name, writers with more books come first
+func (s *ReadDataServiceImpl) SearchWriters(ctx context.Context, name string, page repo.WriterPageParams) (*repo.WriterPage, error) {
+	if name == "" {
+		return &repo.WriterPage{Writers: []model.WriterSummary{}}, nil
+	}
+
+	page.Order = model.WriterOrderBookCount
+	return s.rpo.FindWriters(ctx, name, page)
 }
 
-func (s *ReadDataServiceImpl) RandomBooks(ctx context.Context, limit int) ([]model.Book, error) {
-	return s.rpo.FindBooksByRandom(ctx, limit)
+func (s *ReadDataServiceImpl) TopWriters(ctx context.Context, page repo.WriterPageParams) (*repo.WriterPage, error) {
+	return s.rpo.FindWriters(ctx, "", page)
 }
 
 func (s *ReadDataServiceImpl) Stats(ctx context.Context, site string) repo.Summary {
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"database/sql"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/htchan/BookSpider/internal/config/v2"
@@ -170,6 +172,65 @@ func TestReadDataReadDataServiceImpl_Book(t *testing.T) {
 	}
 }
 
+func TestReadDataReadDataServiceImpl_BookFile(t *testing.T) {
+	t.Parallel()
+
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll("./book-file-read"))
+	})
+
+	if !assert.NoError(t, os.Mkdir("./book-file-read", os.ModePerm)) ||
+		!assert.NoError(t, os.WriteFile("./book-file-read/123-va.txt", []byte("test v2"), 0644)) {
+		return
+	}
+
+	tests := []struct {
+		name      string
+		serv      *ReadDataServiceImpl
+		bk        *model.Book
+		want      string
+		wantError error
+	}{
+		{
+			name:      "book content exist",
+			serv:      &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-file-read"}}},
+			bk:        &model.Book{Site: "test", ID: 123, HashCode: 10, IsDownloaded: true},
+			want:      "test v2",
+			wantError: nil,
+		},
+		{
+			name:      "book not downloaded",
+			serv:      &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-file-read"}}},
+			bk:        &model.Book{Site: "test", ID: 123, HashCode: 10, IsDownloaded: false},
+			wantError: serv.ErrBookNotDownload,
+		},
+		{
+			name:      "book content not exist",
+			serv:      &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-file-read"}}},
+			bk:        &model.Book{Site: "test", ID: 456, IsDownloaded: true},
+			wantError: serv.ErrBookFileNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, err := test.serv.BookFile(context.Background(), test.bk)
+			assert.ErrorIs(t, err, test.wantError)
+			if err != nil {
+				assert.Nil(t, file)
+				return
+			}
+			defer file.Close()
+
+			content, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(content))
+		})
+	}
+}
+
 func TestReadDataReadDataServiceImpl_BookContent(t *testing.T) {
 	t.Parallel()
 
@@ -239,13 +300,20 @@ func TestReadDataReadDataServiceImpl_BookChapters(t *testing.T) {
 		assert.NoError(t, os.RemoveAll("./book-chapters-read"))
 	})
 
+	chapters := model.Chapters{
+		{Index: 0, Title: "title 1", Content: "content 1"},
+		{Index: 1, Title: "title 2", Content: "content 2"},
+	}
+	var content strings.Builder
+	if !assert.NoError(t, model.WriteBookFile(&content, &model.Book{Site: "test", ID: 123, Title: "test"}, chapters)) {
+		return
+	}
+	legacyContent := (&model.Book{Title: "test"}).HeaderInfo() + chapters[0].ContentString() + chapters[1].ContentString()
+
 	if !assert.NoError(t, os.Mkdir("./book-chapters-read", os.ModePerm)) ||
-		!assert.NoError(t, os.WriteFile("./book-chapters-read/123.txt", []byte(
-			"test"+model.CONTENT_SEP+
-				"title 1"+model.CONTENT_SEP+"content 1"+model.CONTENT_SEP+
-				"title 2"+model.CONTENT_SEP+"content 2"+model.CONTENT_SEP,
-		), 0644)) ||
-		!assert.NoError(t, os.WriteFile("./book-chapters-read/123-va.txt", []byte("test"), 0644)) {
+		!assert.NoError(t, os.WriteFile("./book-chapters-read/123.txt", []byte(content.String()), 0644)) ||
+		!assert.NoError(t, os.WriteFile("./book-chapters-read/123-v1.txt", []byte(legacyContent), 0644)) ||
+		!assert.NoError(t, os.WriteFile("./book-chapters-read/123-va.txt", []byte(content.String()[:40]), 0644)) {
 		return
 	}
 
@@ -257,13 +325,17 @@ func TestReadDataReadDataServiceImpl_BookChapters(t *testing.T) {
 		wantError error
 	}{
 		{
-			name: "successfully read and parse content to chapters",
-			serv: &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-chapters-read"}}},
-			bk:   &model.Book{Site: "test", ID: 123, IsDownloaded: true},
-			want: model.Chapters{
-				{Index: 0, Title: "title 1", Content: "content 1"},
-				{Index: 1, Title: "title 2", Content: "content 2"},
-			},
+			name:      "successfully read and parse content to chapters",
+			serv:      &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-chapters-read"}}},
+			bk:        &model.Book{Site: "test", ID: 123, IsDownloaded: true},
+			want:      chapters,
+			wantError: nil,
+		},
+		{
+			name:      "successfully read and parse legacy content to chapters",
+			serv:      &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-chapters-read"}}},
+			bk:        &model.Book{Site: "test", ID: 123, HashCode: 1, IsDownloaded: true},
+			want:      chapters,
 			wantError: nil,
 		},
 		{
@@ -271,7 +343,7 @@ func TestReadDataReadDataServiceImpl_BookChapters(t *testing.T) {
 			serv:      &ReadDataServiceImpl{confs: map[string]config.SiteConfig{"test": {Storage: "./book-chapters-read"}}},
 			bk:        &model.Book{Site: "test", ID: 123, HashCode: 10, IsDownloaded: true},
 			want:      nil,
-			wantError: model.ErrCannotParseContent,
+			wantError: model.ErrBookFileCorrupt,
 		},
 		{
 			name:      "fail to read content ",
@@ -412,27 +484,69 @@ func TestReadDataReadDataServiceImpl_SearchBook(t *testing.T) {
 		getService func(*gomock.Controller) *ReadDataServiceImpl
 		title      string
 		writer     string
-		limit      int
-		offset     int
-		want       []model.Book
+		genre      model.Genre
+		page       repo.PageParams
+		want       *repo.BookPage
 		wantError  error
 	}{
 		{
 			name: "happy flow with books",
 			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindBooksByTitleWriter(gomock.Any(), "title", "writer", 10, 0).
-					Return([]model.Book{{ID: 123, HashCode: 0}}, nil)
+				rpo.EXPECT().FindBooksByTitleWriter(
+					gomock.Any(), "title", "writer", model.GenreUrban,
+					repo.PageParams{Sort: model.BookSortTitle, Limit: 10, Cursor: "cursor"},
+				).Return(&repo.BookPage{Books: []model.Book{{ID: 123, HashCode: 0}}, Total: 11, NextCursor: "next"}, nil)
 
 				return &ReadDataServiceImpl{rpo: rpo}
 			},
 			title:     "title",
 			writer:    "writer",
-			limit:     10,
-			offset:    0,
-			want:      []model.Book{{ID: 123, HashCode: 0}},
+			genre:     model.GenreUrban,
+			page:      repo.PageParams{Sort: model.BookSortTitle, Limit: 10, Cursor: "cursor"},
+			want:      &repo.BookPage{Books: []model.Book{{ID: 123, HashCode: 0}}, Total: 11, NextCursor: "next"},
 			wantError: nil,
 		},
+		{
+			name: "happy flow with sources of works",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksByTitleWriter(gomock.Any(), "title", "", model.Genre(""), repo.PageParams{Limit: 10}).
+					Return(&repo.BookPage{
+						Books: []model.Book{{Site: "a", ID: 1, WorkID: 5}, {Site: "b", ID: 2}},
+						Total: 2,
+						Sources: map[int]model.BookGroup{
+							5: {{Site: "a", ID: 1, WorkID: 5}, {Site: "b", ID: 3, WorkID: 5}},
+						},
+					}, nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			title: "title",
+			page:  repo.PageParams{Limit: 10},
+			want: &repo.BookPage{
+				Books: []model.Book{{Site: "a", ID: 1, WorkID: 5}, {Site: "b", ID: 2}},
+				Total: 2,
+				Sources: map[int]model.BookGroup{
+					5: {{Site: "a", ID: 1, WorkID: 5}, {Site: "b", ID: 3, WorkID: 5}},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name: "repo return error",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksByTitleWriter(gomock.Any(), "title", "", model.Genre(""), repo.PageParams{Limit: 10}).
+					Return(nil, serv.ErrUnavailable)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			title:     "title",
+			page:      repo.PageParams{Limit: 10},
+			want:      nil,
+			wantError: serv.ErrUnavailable,
+		},
 	}
 
 	for _, test := range tests {
@@ -444,9 +558,7 @@ func TestReadDataReadDataServiceImpl_SearchBook(t *testing.T) {
 
 			svc := test.getService(ctrl)
 
-			got, err := svc.SearchBooks(
-				context.Background(), test.title, test.writer, test.limit, test.offset,
-			)
+			got, err := svc.SearchBooks(context.Background(), test.title, test.writer, test.genre, test.page)
 			assert.Equal(t, test.want, got)
 			assert.ErrorIs(t, err, test.wantError)
 		})
@@ -459,6 +571,8 @@ func TestReadDataReadDataServiceImpl_RandomBook(t *testing.T) {
 	tests := []struct {
 		name       string
 		getService func(*gomock.Controller) *ReadDataServiceImpl
+		genre      model.Genre
+		sort       model.BookSort
 		limit      int
 		want       []model.Book
 		wantError  error
@@ -467,10 +581,26 @@ func TestReadDataReadDataServiceImpl_RandomBook(t *testing.T) {
 			name: "happy flow",
 			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindBooksByRandom(gomock.Any(), 10).Return([]model.Book{{ID: 123, HashCode: 0}}, nil)
+				rpo.EXPECT().FindBooksByRandom(gomock.Any(), model.Genre(""), model.BookSortUpdated, 10).Return([]model.Book{{ID: 123, HashCode: 0}}, nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			sort:      model.BookSortUpdated,
+			limit:     10,
+			want:      []model.Book{{ID: 123, HashCode: 0}},
+			wantError: nil,
+		},
+		{
+			name: "happy flow with genre",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksByRandom(gomock.Any(), model.GenreWuxia, model.BookSortTitle, 10).
+					Return([]model.Book{{ID: 123, HashCode: 0}}, nil)
 
 				return &ReadDataServiceImpl{rpo: rpo}
 			},
+			genre:     model.GenreWuxia,
+			sort:      model.BookSortTitle,
 			limit:     10,
 			want:      []model.Book{{ID: 123, HashCode: 0}},
 			wantError: nil,
@@ -486,13 +616,434 @@ func TestReadDataReadDataServiceImpl_RandomBook(t *testing.T) {
 
 			svc := test.getService(ctrl)
 
-			got, err := svc.RandomBooks(context.Background(), test.limit)
+			got, err := svc.RandomBooks(context.Background(), test.genre, test.sort, test.limit)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_BrowseBooks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ReadDataServiceImpl
+		params     repo.BrowseParams
+		page       repo.PageParams
+		want       *repo.BookPage
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksByGenre(
+					gomock.Any(),
+					repo.BrowseParams{Genre: model.GenreXianxia, Site: "test", Status: "END"},
+					repo.PageParams{Sort: model.BookSortDiscovered, Limit: 10, Cursor: "cursor"},
+				).Return(&repo.BookPage{Books: []model.Book{{ID: 123, HashCode: 0}}, Total: 1}, nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			params:    repo.BrowseParams{Genre: model.GenreXianxia, Site: "test", Status: "END"},
+			page:      repo.PageParams{Sort: model.BookSortDiscovered, Limit: 10, Cursor: "cursor"},
+			want:      &repo.BookPage{Books: []model.Book{{ID: 123, HashCode: 0}}, Total: 1},
+			wantError: nil,
+		},
+		{
+			name: "empty genre",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				return &ReadDataServiceImpl{rpo: mockrepo.NewMockRepository(ctrl)}
+			},
+			params:    repo.BrowseParams{},
+			page:      repo.PageParams{Limit: 10},
+			want:      nil,
+			wantError: model.ErrInvalidGenre,
+		},
+		{
+			name: "repo return error",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindBooksByGenre(gomock.Any(), repo.BrowseParams{Genre: model.GenreOther}, repo.PageParams{Limit: 10}).
+					Return(nil, repo.ErrInvalidCursor)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			params:    repo.BrowseParams{Genre: model.GenreOther},
+			page:      repo.PageParams{Limit: 10},
+			want:      nil,
+			wantError: repo.ErrInvalidCursor,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			got, err := svc.BrowseBooks(context.Background(), test.params, test.page)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_Work(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ReadDataServiceImpl
+		workID     string
+		want       *model.Work
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkByID(gomock.Any(), 5).Return(&model.Work{ID: 5}, nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			workID:    "5",
+			want:      &model.Work{ID: 5},
+			wantError: nil,
+		},
+		{
+			name: "invalid work id",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				return &ReadDataServiceImpl{}
+			},
+			workID:    "abc",
+			want:      nil,
+			wantError: serv.ErrInvalidWorkID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			got, err := svc.Work(context.Background(), test.workID)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_WorkSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		serv      *ReadDataServiceImpl
+		work      *model.Work
+		wantBk    *model.Book
+		wantError error
+	}{
+		{
+			name: "return downloaded source",
+			serv: &ReadDataServiceImpl{},
+			work: &model.Work{Books: model.BookGroup{
+				{Site: "a", ID: 1, Status: model.StatusEnd},
+				{Site: "b", ID: 2, Status: model.StatusEnd, IsDownloaded: true},
+			}},
+			wantBk:    &model.Book{Site: "b", ID: 2, Status: model.StatusEnd, IsDownloaded: true},
+			wantError: nil,
+		},
+		{
+			name: "no downloaded source",
+			serv: &ReadDataServiceImpl{},
+			work: &model.Work{Books: model.BookGroup{
+				{Site: "a", ID: 1, Status: model.StatusEnd},
+			}},
+			wantBk:    nil,
+			wantError: serv.ErrWorkNoSource,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			bk, err := test.serv.WorkSource(context.Background(), test.work)
+			assert.Equal(t, test.wantBk, bk)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_MergeWorks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ReadDataServiceImpl
+		targetID   string
+		sourceID   string
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().MergeWorks(gomock.Any(), 1, 2).Return(nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			targetID:  "1",
+			sourceID:  "2",
+			wantError: nil,
+		},
+		{
+			name: "invalid source id",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				return &ReadDataServiceImpl{}
+			},
+			targetID:  "1",
+			sourceID:  "",
+			wantError: serv.ErrInvalidWorkID,
+		},
+		{
+			name: "merge work into itself",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				return &ReadDataServiceImpl{}
+			},
+			targetID:  "1",
+			sourceID:  "1",
+			wantError: serv.ErrInvalidWorkID,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			err := svc.MergeWorks(context.Background(), test.targetID, test.sourceID)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_SplitBookWork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ReadDataServiceImpl
+		bk         *model.Book
+		want       *model.Work
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateWork(gomock.Any(), &model.Work{Title: "title", Writer: "writer"}).
+					DoAndReturn(func(_ context.Context, work *model.Work) error {
+						work.ID = 10
+						return nil
+					})
+				rpo.EXPECT().LinkBookToWork(gomock.Any(), gomock.Any(), 10).
+					DoAndReturn(func(_ context.Context, bk *model.Book, workID int) error {
+						bk.WorkID = workID
+						return nil
+					})
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			bk: &model.Book{Site: "a", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, WorkID: 5},
+			want: &model.Work{
+				ID: 10, Title: "title", Writer: "writer",
+				Books: model.BookGroup{
+					{Site: "a", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, WorkID: 10},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name: "create work fail",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateWork(gomock.Any(), gomock.Any()).Return(serv.ErrUnavailable)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			bk:        &model.Book{Site: "a", ID: 1, Title: "title", WorkID: 5},
+			want:      nil,
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			got, err := svc.SplitBookWork(context.Background(), test.bk)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_Writer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ReadDataServiceImpl
+		writerID   string
+		want       *model.WriterCatalog
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWriterByID(gomock.Any(), 5).Return(&model.Writer{ID: 5, Name: "writer"}, nil)
+				rpo.EXPECT().FindBooksByWriterID(gomock.Any(), 5, model.BookSortTitle).Return([]model.Book{
+					{Site: "a", ID: 1, WorkID: 2}, {Site: "b", ID: 1, WorkID: 2}, {Site: "b", ID: 3},
+				}, nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			writerID: "5",
+			want: &model.WriterCatalog{
+				ID: 5, Name: "writer",
+				Groups: []model.BookGroup{
+					{{Site: "a", ID: 1, WorkID: 2}, {Site: "b", ID: 1, WorkID: 2}},
+					{{Site: "b", ID: 3}},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name: "invalid writer id",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				return &ReadDataServiceImpl{}
+			},
+			writerID:  "abc",
+			want:      nil,
+			wantError: serv.ErrInvalidWriterID,
+		},
+		{
+			name: "writer not found",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWriterByID(gomock.Any(), 5).Return(nil, sql.ErrNoRows)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			writerID:  "5",
+			want:      nil,
+			wantError: sql.ErrNoRows,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			got, err := svc.Writer(context.Background(), test.writerID, model.BookSortTitle)
 			assert.Equal(t, test.want, got)
 			assert.ErrorIs(t, err, test.wantError)
 		})
 	}
 }
 
+func TestReadDataReadDataServiceImpl_SearchWriters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ReadDataServiceImpl
+		writerName string
+		want       *repo.WriterPage
+		wantError  error
+	}{
+		{
+			name: "happy flow searches by book count",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWriters(gomock.Any(), "writer", repo.WriterPageParams{
+					Order: model.WriterOrderBookCount, Limit: 10, Cursor: "cursor",
+				}).Return(&repo.WriterPage{Writers: []model.WriterSummary{{ID: 1, Name: "writer", BookCount: 3}}, NextCursor: "next"}, nil)
+
+				return &ReadDataServiceImpl{rpo: rpo}
+			},
+			writerName: "writer",
+			want:       &repo.WriterPage{Writers: []model.WriterSummary{{ID: 1, Name: "writer", BookCount: 3}}, NextCursor: "next"},
+			wantError:  nil,
+		},
+		{
+			name: "empty name",
+			getService: func(ctrl *gomock.Controller) *ReadDataServiceImpl {
+				return &ReadDataServiceImpl{}
+			},
+			writerName: "",
+			want:       &repo.WriterPage{Writers: []model.WriterSummary{}},
+			wantError:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			got, err := svc.SearchWriters(context.Background(), test.writerName, repo.WriterPageParams{
+				Order: model.WriterOrderLatestUpdate, Limit: 10, Cursor: "cursor",
+			})
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestReadDataReadDataServiceImpl_TopWriters(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	rpo := mockrepo.NewMockRepository(ctrl)
+	params := repo.WriterPageParams{Order: model.WriterOrderLatestUpdate, Limit: 10}
+	rpo.EXPECT().FindWriters(gomock.Any(), "", params).
+		Return(&repo.WriterPage{Writers: []model.WriterSummary{{ID: 1, Name: "writer", LatestUpdate: "date"}}}, nil)
+
+	got, err := (&ReadDataServiceImpl{rpo: rpo}).TopWriters(context.Background(), params)
+	assert.Equal(t, &repo.WriterPage{Writers: []model.WriterSummary{{ID: 1, Name: "writer", LatestUpdate: "date"}}}, got)
+	assert.NoError(t, err)
+}
+
 func TestReadDataReadDataServiceImpl_Stats(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/htchan/BookSpider/internal/snapshot"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/rs/zerolog"
+)
+
+func (s *ServiceImpl) saveSnapshot(ctx context.Context, url, body string) {
+	if err := s.snapshots.Save(url, body); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("url", url).Msg("save snapshot failed")
+	}
+}
+
+func isBookInfoChanged(bk *model.Book, bkInfo *vendor.BookInfo) bool {
+	return bk.Status == model.StatusError ||
+		bk.Title != bkInfo.Title || bk.Writer.Name != bkInfo.Writer || bk.Type != bkInfo.Type ||
+		isBookUpdated(bk, bkInfo)
+}
+
+// ReparseBook parses the latest snapshot of book page again and updates the
+// book in place. unlike UpdateBook, a different title or writer does not
+// create new book, as the snapshot is the same page parsed by the old parser
+func (s *ServiceImpl) ReparseBook(ctx context.Context, bk *model.Book, stats *serv.ReparseStats) error {
+	if stats == nil {
+		stats = new(serv.ReparseStats)
+	}
+
+	if s.snapshots == nil {
+		return serv.ErrSnapshotDisabled
+	}
+
+	bookID := strconv.Itoa(bk.ID)
+	body, _, err := s.snapshots.Latest(s.vendorService.BookURL(bookID))
+	if errors.Is(err, snapshot.ErrSnapshotNotFound) {
+		stats.NoSnapshot.Add(1)
+		return fmt.Errorf("load book snapshot failed: %w", err)
+	} else if err != nil {
+		stats.Fail.Add(1)
+		return fmt.Errorf("load book snapshot failed: %w", err)
+	}
+
+	bkInfo, err := s.vendorService.ParseBook(body)
+	if err != nil {
+		stats.Fail.Add(1)
+		return fmt.Errorf("parse book snapshot failed: %w", err)
+	}
+
+	if isBookInfoChanged(bk, bkInfo) {
+		zerolog.Ctx(ctx).Debug().
+			Interface("existing_book", bk).
+			Interface("reparsed_book", bkInfo).
+			Msg("reparsed book changed")
+
+		bk.Title, bk.Writer.Name, bk.Type = bkInfo.Title, bkInfo.Writer, bkInfo.Type
+		bk.UpdateDate, bk.UpdateChapter = bkInfo.UpdateDate, bkInfo.UpdateChapter
+		if bk.Status == model.StatusError {
+			bk.Status = model.StatusInProgress
+		}
+		bk.Error = nil
+
+		saveWriterErr := s.rpo.SaveWriter(ctx, &bk.Writer)
+		saveBkErr := s.rpo.UpdateBook(ctx, bk)
+		saveErrErr := s.rpo.SaveError(ctx, bk, bk.Error)
+		if saveWriterErr != nil || saveBkErr != nil || saveErrErr != nil {
+			stats.Fail.Add(1)
+			return errors.Join(saveWriterErr, saveBkErr, saveErrErr)
+		}
+
+		stats.Updated.Add(1)
+	} else {
+		stats.Unchanged.Add(1)
+	}
+
+	if bk.IsDownloaded {
+		if err := s.reparseChapterList(ctx, bk, stats); err != nil {
+			return fmt.Errorf("reparse chapter list failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reparseChapterList marks the downloaded book to download again if the
+// chapter list snapshot has more chapters than the book file, e.g. after a
+// fix of chapter list parser. legacy book files without chapter count are
+// skipped
+func (s *ServiceImpl) reparseChapterList(ctx context.Context, bk *model.Book, stats *serv.ReparseStats) error {
+	bookID := strconv.Itoa(bk.ID)
+	body, _, err := s.snapshots.Latest(s.vendorService.ChapterListURL(bookID))
+	if errors.Is(err, snapshot.ErrSnapshotNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	chapterList, err := s.vendorService.ParseChapterList(bookID, body)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(s.bookFileLocation(bk))
+	if err != nil {
+		// missing file is handled by patch download status
+		return nil
+	}
+	defer file.Close()
+
+	reader, err := model.NewBookFileReader(file)
+	if err != nil {
+		return err
+	}
+
+	chapterCount := reader.Header().ChapterCount
+	if chapterCount < 0 || chapterCount >= len(chapterList) {
+		return nil
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int("file_chapters", chapterCount).
+		Int("reparsed_chapters", len(chapterList)).
+		Msg("reparsed chapter list has more chapters, download again")
+
+	bk.IsDownloaded = false
+	if err := s.rpo.UpdateBook(ctx, bk); err != nil {
+		return err
+	}
+	stats.Redownload.Add(1)
+
+	return nil
+}
+
+func (s *ServiceImpl) Reparse(ctx context.Context, stats *serv.ReparseStats) error {
+	if stats == nil {
+		stats = new(serv.ReparseStats)
+	}
+
+	if s.snapshots == nil {
+		return serv.ErrSnapshotDisabled
+	}
+
+	ids, err := s.rpo.FindAllBookIDs(ctx, s.name)
+	if err != nil {
+		return fmt.Errorf("find all book ids fail: %w", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, id := range ids {
+		s.sema.Acquire(ctx, 1)
+		wg.Add(1)
+		stats.Total.Add(1)
+
+		go func(id int) {
+			defer wg.Done()
+			defer s.sema.Release(1)
+
+			logger := zerolog.Ctx(ctx).With().
+				Str("worker_id", uuid.New().String()).
+				Int("bk_id", id).
+				Logger()
+
+			bk, err := s.rpo.FindBookById(ctx, s.name, id)
+			if err != nil {
+				stats.Fail.Add(1)
+				logger.Error().Err(err).Msg("find book for reparse failed")
+				return
+			}
+
+			err = s.ReparseBook(logger.WithContext(ctx), bk, stats)
+			if err != nil && !errors.Is(err, snapshot.ErrSnapshotNotFound) {
+				logger.Error().Err(err).Msg("reparse book failed")
+			}
+		}(id)
+	}
+
+	wg.Wait()
+
+	return nil
+}
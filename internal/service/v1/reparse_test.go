@@ -0,0 +1,361 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	vendormock "github.com/htchan/BookSpider/internal/mock/vendorservice"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/htchan/BookSpider/internal/snapshot"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/semaphore"
+)
+
+func newTestSnapshotStore(t *testing.T, pages map[string]string) *snapshot.Store {
+	t.Helper()
+
+	store := snapshot.NewStore(config.SnapshotConfig{Directory: t.TempDir()})
+	for url, body := range pages {
+		if err := store.Save(url, body); err != nil {
+			t.Fatalf("save snapshot fail: %v", err)
+		}
+	}
+
+	return store
+}
+
+func writeTestBookFile(t *testing.T, storage string, bk *model.Book, chapterCount int) {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(storage, "1-v"+bk.FormatHashCode()+".txt"))
+	if err != nil {
+		t.Fatalf("create book file fail: %v", err)
+	}
+	defer file.Close()
+
+	chapters := make(model.Chapters, chapterCount)
+	for i := range chapters {
+		chapters[i] = model.Chapter{Index: i, Title: "title", Content: "content"}
+	}
+
+	if err := model.WriteBookFile(file, bk, chapters); err != nil {
+		t.Fatalf("write book file fail: %v", err)
+	}
+}
+
+func TestServiceImpl_ReparseBook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		bk         model.Book
+		getService func(*testing.T, *gomock.Controller) *ServiceImpl
+		wantBook   model.Book
+		wantStats  map[string]int64
+		wantError  error
+	}{
+		{
+			name: "snapshot disabled",
+			bk:   model.Book{Site: "test", ID: 1},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{name: "test"}
+			},
+			wantBook:  model.Book{Site: "test", ID: 1},
+			wantStats: map[string]int64{},
+			wantError: serv.ErrSnapshotDisabled,
+		},
+		{
+			name: "no snapshot of book",
+			bk:   model.Book{Site: "test", ID: 1},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+
+				return &ServiceImpl{name: "test", vendorService: vendorService, snapshots: newTestSnapshotStore(t, nil)}
+			},
+			wantBook:  model.Book{Site: "test", ID: 1},
+			wantStats: map[string]int64{"no_snapshot": 1},
+			wantError: snapshot.ErrSnapshotNotFound,
+		},
+		{
+			name: "update book parsed differently",
+			bk: model.Book{
+				Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{ID: 1, Name: "wrong writer"},
+				Type: "type", UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
+			},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				vendorService.EXPECT().ParseBook("book page").Return(&vendor.BookInfo{
+					Title: "title", Writer: "writer", Type: "type", UpdateDate: "date", UpdateChapter: "chapter",
+				}, nil)
+
+				bkUpdated := model.Book{
+					Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{ID: 1, Name: "writer"},
+					Type: "type", UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
+				}
+				rpo.EXPECT().SaveWriter(gomock.Any(), &bkUpdated.Writer).Return(nil)
+				rpo.EXPECT().UpdateBook(gomock.Any(), &bkUpdated).Return(nil)
+				rpo.EXPECT().SaveError(gomock.Any(), &bkUpdated, nil).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, vendorService: vendorService,
+					snapshots: newTestSnapshotStore(t, map[string]string{"https://test.com/book/1": "book page"}),
+				}
+			},
+			wantBook: model.Book{
+				Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{ID: 1, Name: "writer"},
+				Type: "type", UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
+			},
+			wantStats: map[string]int64{"updated": 1},
+		},
+		{
+			name: "recover error book",
+			bk:   model.Book{Site: "test", ID: 1, Status: model.StatusError, Error: vendor.ErrFieldsNotFound},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				vendorService.EXPECT().ParseBook("book page").Return(&vendor.BookInfo{Title: "title", Writer: "writer"}, nil)
+
+				bkUpdated := model.Book{
+					Site: "test", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Status: model.StatusInProgress,
+				}
+				rpo.EXPECT().SaveWriter(gomock.Any(), &bkUpdated.Writer).Return(nil)
+				rpo.EXPECT().UpdateBook(gomock.Any(), &bkUpdated).Return(nil)
+				rpo.EXPECT().SaveError(gomock.Any(), &bkUpdated, nil).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, vendorService: vendorService,
+					snapshots: newTestSnapshotStore(t, map[string]string{"https://test.com/book/1": "book page"}),
+				}
+			},
+			wantBook:  model.Book{Site: "test", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Status: model.StatusInProgress},
+			wantStats: map[string]int64{"updated": 1},
+		},
+		{
+			name: "parse snapshot fail",
+			bk:   model.Book{Site: "test", ID: 1, Title: "title", Status: model.StatusInProgress},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				vendorService.EXPECT().ParseBook("book page").Return(nil, vendor.ErrFieldsNotFound)
+
+				return &ServiceImpl{
+					name: "test", vendorService: vendorService,
+					snapshots: newTestSnapshotStore(t, map[string]string{"https://test.com/book/1": "book page"}),
+				}
+			},
+			wantBook:  model.Book{Site: "test", ID: 1, Title: "title", Status: model.StatusInProgress},
+			wantStats: map[string]int64{"fail": 1},
+			wantError: vendor.ErrFieldsNotFound,
+		},
+		{
+			name: "download again if chapter list has more chapters",
+			bk: model.Book{
+				Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{Name: "writer"},
+				Status: model.StatusEnd, IsDownloaded: true,
+			},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				storage := t.TempDir()
+				writeTestBookFile(t, storage, &model.Book{Site: "test", ID: 1, HashCode: 1}, 1)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				vendorService.EXPECT().ParseBook("book page").Return(&vendor.BookInfo{Title: "title", Writer: "writer"}, nil)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapters/1")
+				vendorService.EXPECT().ParseChapterList("1", "chapter list").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "chapter 1"},
+					{URL: "https://test.com/chapter/2", Title: "chapter 2"},
+				}, nil)
+				rpo.EXPECT().UpdateBook(gomock.Any(), &model.Book{
+					Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{Name: "writer"},
+					Status: model.StatusEnd, IsDownloaded: false,
+				}).Return(nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, vendorService: vendorService, conf: config.SiteConfig{Storage: storage},
+					snapshots: newTestSnapshotStore(t, map[string]string{
+						"https://test.com/book/1":     "book page",
+						"https://test.com/chapters/1": "chapter list",
+					}),
+				}
+			},
+			wantBook: model.Book{
+				Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{Name: "writer"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantStats: map[string]int64{"unchanged": 1, "redownload": 1},
+		},
+		{
+			name: "keep downloaded book with all chapters",
+			bk: model.Book{
+				Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{Name: "writer"},
+				Status: model.StatusEnd, IsDownloaded: true,
+			},
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				storage := t.TempDir()
+				writeTestBookFile(t, storage, &model.Book{Site: "test", ID: 1, HashCode: 1}, 2)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				vendorService.EXPECT().ParseBook("book page").Return(&vendor.BookInfo{Title: "title", Writer: "writer"}, nil)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapters/1")
+				vendorService.EXPECT().ParseChapterList("1", "chapter list").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "chapter 1"},
+					{URL: "https://test.com/chapter/2", Title: "chapter 2"},
+				}, nil)
+
+				return &ServiceImpl{
+					name: "test", vendorService: vendorService, conf: config.SiteConfig{Storage: storage},
+					snapshots: newTestSnapshotStore(t, map[string]string{
+						"https://test.com/book/1":     "book page",
+						"https://test.com/chapters/1": "chapter list",
+					}),
+				}
+			},
+			wantBook: model.Book{
+				Site: "test", ID: 1, HashCode: 1, Title: "title", Writer: model.Writer{Name: "writer"},
+				Status: model.StatusEnd, IsDownloaded: true,
+			},
+			wantStats: map[string]int64{"unchanged": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bk := test.bk
+			var stats serv.ReparseStats
+			err := test.getService(t, ctrl).ReparseBook(t.Context(), &bk, &stats)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantBook, bk)
+			assert.Equal(t, test.wantStats, reparseStatsMap(&stats))
+		})
+	}
+}
+
+func reparseStatsMap(stats *serv.ReparseStats) map[string]int64 {
+	result := make(map[string]int64)
+	for key, value := range map[string]int64{
+		"total":       stats.Total.Load(),
+		"updated":     stats.Updated.Load(),
+		"unchanged":   stats.Unchanged.Load(),
+		"no_snapshot": stats.NoSnapshot.Load(),
+		"fail":        stats.Fail.Load(),
+		"redownload":  stats.Redownload.Load(),
+	} {
+		if value > 0 {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+func TestServiceImpl_Reparse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*testing.T, *gomock.Controller) *ServiceImpl
+		wantStats  map[string]int64
+		wantError  error
+	}{
+		{
+			name: "reparse every book",
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				rpo.EXPECT().FindAllBookIDs(gomock.Any(), "test").Return([]int{1, 2, 3}, nil)
+				rpo.EXPECT().FindBookById(gomock.Any(), "test", 1).Return(&model.Book{
+					Site: "test", ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Status: model.StatusInProgress,
+				}, nil)
+				rpo.EXPECT().FindBookById(gomock.Any(), "test", 2).Return(&model.Book{Site: "test", ID: 2}, nil)
+				rpo.EXPECT().FindBookById(gomock.Any(), "test", 3).Return(nil, serv.ErrUnavailable)
+				vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+				vendorService.EXPECT().BookURL("2").Return("https://test.com/book/2")
+				vendorService.EXPECT().ParseBook("book page").Return(&vendor.BookInfo{Title: "title", Writer: "writer"}, nil)
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, vendorService: vendorService, sema: semaphore.NewWeighted(2),
+					snapshots: newTestSnapshotStore(t, map[string]string{"https://test.com/book/1": "book page"}),
+				}
+			},
+			wantStats: map[string]int64{"total": 3, "unchanged": 1, "no_snapshot": 1, "fail": 1},
+		},
+		{
+			name: "find book ids fail",
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().FindAllBookIDs(gomock.Any(), "test").Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{name: "test", rpo: rpo, snapshots: newTestSnapshotStore(t, nil)}
+			},
+			wantStats: map[string]int64{},
+			wantError: serv.ErrUnavailable,
+		},
+		{
+			name: "snapshot disabled",
+			getService: func(t *testing.T, ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{name: "test"}
+			},
+			wantStats: map[string]int64{},
+			wantError: serv.ErrSnapshotDisabled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var stats serv.ReparseStats
+			err := test.getService(t, ctrl).Reparse(t.Context(), &stats)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantStats, reparseStatsMap(&stats))
+		})
+	}
+}
+
+func TestServiceImpl_UpdateBook_SaveSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cli := clientmock.NewMockBookClient(ctrl)
+	vendorService := vendormock.NewMockVendorService(ctrl)
+	vendorService.EXPECT().BookURL("1").Return("https://test.com/book/1")
+	cli.EXPECT().Get(gomock.Any(), "https://test.com/book/1").Return("book page", nil)
+	vendorService.EXPECT().ParseBook("book page").Return(&vendor.BookInfo{Title: "title"}, nil)
+
+	store := newTestSnapshotStore(t, nil)
+	s := &ServiceImpl{name: "test", cli: cli, vendorService: vendorService, snapshots: store}
+
+	bk := model.Book{Site: "test", ID: 1, Title: "title", Status: model.StatusInProgress}
+	assert.NoError(t, s.UpdateBook(t.Context(), &bk, nil))
+
+	body, _, err := store.Latest("https://test.com/book/1")
+	assert.NoError(t, err)
+	assert.Equal(t, "book page", body)
+}
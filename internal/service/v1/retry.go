@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/htchan/goclient/middlewares/retry"
+)
+
+func newRetryIntervalCalculator(intervalType string, baseInterval time.Duration) retry.RetryIntervalCalculator {
+	switch intervalType {
+	case "linear":
+		return retry.LinearRetryInterval(baseInterval)
+	case "exponential":
+		return retry.ExponentialRetryInterval(baseInterval)
+	default:
+		return retry.StaticRetryInterval(baseInterval)
+	}
+}
+
+// retryPolicy sends the request again on temporary failure. the retry
+// middleware of goclient only knows the attempt number, so the policy wraps
+// the requester to wait for Retry-After and track the budget of a request
+type retryPolicy struct {
+	maxRetries int
+	interval   retry.RetryIntervalCalculator
+	budget     time.Duration
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func newRetryPolicy(conf config.RetryConfig) *retryPolicy {
+	return &retryPolicy{
+		maxRetries: conf.MaxRetries,
+		interval:   newRetryIntervalCalculator(conf.IntervalType, conf.BaseInterval),
+		budget:     conf.Budget,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// shouldRetry never retries missing page (404, 410) or cancelled request,
+// timeout of a single attempt is retried
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, serv.ErrNoProxyAvailable)
+	}
+
+	return client.IsRetryableStatus(resp.StatusCode)
+}
+
+func (p *retryPolicy) wrap(next func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		var waited time.Duration
+
+		for attempt := 0; ; attempt++ {
+			resp, err := next(req)
+			if attempt >= p.maxRetries || !shouldRetry(req.Context(), resp, err) {
+				return resp, err
+			}
+
+			wait := p.interval(attempt)
+			if resp != nil {
+				if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), p.now()); ok && retryAfter > wait {
+					wait = retryAfter
+				}
+			}
+
+			// give up with the last response if waiting exceeds budget of request
+			if p.budget > 0 && waited+wait > p.budget {
+				return resp, err
+			}
+
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+
+			if err := p.sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+			waited += wait
+		}
+	}
+}
+
+// parseRetryAfter returns the duration of Retry-After header in seconds or
+// http date, and if the header is valid
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_shouldRetry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cancelled bool
+		resp      *http.Response
+		err       error
+		want      bool
+	}{
+		{name: "success", resp: &http.Response{StatusCode: http.StatusOK}, want: false},
+		{name: "not modified", resp: &http.Response{StatusCode: http.StatusNotModified}, want: false},
+		{name: "not found", resp: &http.Response{StatusCode: http.StatusNotFound}, want: false},
+		{name: "gone", resp: &http.Response{StatusCode: http.StatusGone}, want: false},
+		{name: "forbidden", resp: &http.Response{StatusCode: http.StatusForbidden}, want: false},
+		{name: "too many requests", resp: &http.Response{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "server error", resp: &http.Response{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "network error", err: errors.New("connection reset"), want: true},
+		{name: "attempt timeout", err: context.DeadlineExceeded, want: true},
+		{name: "cancelled request", cancelled: true, err: context.Canceled, want: false},
+		{name: "no proxy available", err: serv.ErrNoProxyAvailable, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			if test.cancelled {
+				cancel()
+			}
+
+			assert.Equal(t, test.want, shouldRetry(ctx, test.resp, test.err))
+		})
+	}
+}
+
+func Test_retryPolicy_wrap(t *testing.T) {
+	t.Parallel()
+
+	response := func(statusCode int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name           string
+		conf           config.RetryConfig
+		responses      []*http.Response
+		wantStatusCode int
+		wantAttempts   int
+		wantWaits      []time.Duration
+	}{
+		{
+			name:           "no retry for success",
+			conf:           config.RetryConfig{MaxRetries: 3, BaseInterval: time.Second},
+			responses:      []*http.Response{response(200, "")},
+			wantStatusCode: 200,
+			wantAttempts:   1,
+		},
+		{
+			name:           "no retry for not found",
+			conf:           config.RetryConfig{MaxRetries: 3, BaseInterval: time.Second},
+			responses:      []*http.Response{response(404, "")},
+			wantStatusCode: 404,
+			wantAttempts:   1,
+		},
+		{
+			name:           "retry server error until success",
+			conf:           config.RetryConfig{MaxRetries: 3, BaseInterval: time.Second, IntervalType: "linear"},
+			responses:      []*http.Response{response(500, ""), response(503, ""), response(200, "")},
+			wantStatusCode: 200,
+			wantAttempts:   3,
+			wantWaits:      []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:           "stop at max retries",
+			conf:           config.RetryConfig{MaxRetries: 1, BaseInterval: time.Second},
+			responses:      []*http.Response{response(500, ""), response(502, "")},
+			wantStatusCode: 502,
+			wantAttempts:   2,
+			wantWaits:      []time.Duration{time.Second},
+		},
+		{
+			name:           "wait for retry after longer than interval",
+			conf:           config.RetryConfig{MaxRetries: 3, BaseInterval: time.Second},
+			responses:      []*http.Response{response(429, "5"), response(429, ""), response(200, "")},
+			wantStatusCode: 200,
+			wantAttempts:   3,
+			wantWaits:      []time.Duration{5 * time.Second, time.Second},
+		},
+		{
+			name:           "give up if retry after exceeds budget",
+			conf:           config.RetryConfig{MaxRetries: 3, BaseInterval: time.Second, Budget: 10 * time.Second},
+			responses:      []*http.Response{response(503, "3600")},
+			wantStatusCode: 503,
+			wantAttempts:   1,
+		},
+		{
+			name:           "give up once total wait exceeds budget",
+			conf:           config.RetryConfig{MaxRetries: 5, BaseInterval: time.Second, IntervalType: "exponential", Budget: 5 * time.Second},
+			responses:      []*http.Response{response(500, ""), response(500, ""), response(500, ""), response(200, "")},
+			wantStatusCode: 500,
+			wantAttempts:   3,
+			wantWaits:      []time.Duration{time.Second, 2 * time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var waits []time.Duration
+			policy := newRetryPolicy(test.conf)
+			policy.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+
+			attempts := 0
+			requester := policy.wrap(func(req *http.Request) (*http.Response, error) {
+				resp := test.responses[attempts]
+				attempts++
+				return resp, nil
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "https://test.com", nil)
+			assert.NoError(t, err)
+
+			resp, err := requester(req)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantStatusCode, resp.StatusCode)
+			assert.Equal(t, test.wantAttempts, attempts)
+			assert.Equal(t, test.wantWaits, waits)
+		})
+	}
+
+	t.Run("stop retry if context cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		policy := newRetryPolicy(config.RetryConfig{MaxRetries: 3, BaseInterval: time.Hour})
+
+		attempts := 0
+		requester := policy.wrap(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			cancel()
+			return response(500, ""), nil
+		})
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://test.com", nil)
+		assert.NoError(t, err)
+
+		resp, err := requester(req)
+		assert.NoError(t, err)
+		assert.Equal(t, 500, resp.StatusCode)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "empty", value: "", want: 0, wantOK: false},
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "http date", value: "Mon, 19 Oct 2026 00:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{name: "past http date", value: "Sun, 18 Oct 2026 00:00:00 GMT", want: 0, wantOK: true},
+		{name: "invalid", value: "soon", want: 0, wantOK: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseRetryAfter(test.value, now)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+}
@@ -8,18 +8,17 @@ import (
 	"path/filepath"
 	"sync"
 	"sync/atomic"
-	"time"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/config/v2"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
 	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/htchan/BookSpider/internal/snapshot"
 	vendor "github.com/htchan/BookSpider/internal/vendorservice"
 	"github.com/htchan/goclient"
 	circuitbreaker "github.com/htchan/goclient/middlewares/circuit_breaker"
 	ratelimit "github.com/htchan/goclient/middlewares/rate_limit"
-	"github.com/htchan/goclient/middlewares/retry"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/semaphore"
@@ -30,6 +29,10 @@ type ServiceImpl struct {
 	cli           client.BookClient
 	rpo           repo.Repository
 	vendorService vendor.VendorService
+	sources       map[string]serv.Service // other sites to fetch failed chapters from
+	snapshots     *snapshot.Store         // nil if snapshot is disabled
+	responseCache *client.ResponseCache   // nil if response cache is disabled
+	proxies       *proxyPool
 
 	conf       config.SiteConfig
 	sema       *semaphore.Weighted // shared across all vendors
@@ -45,27 +48,6 @@ func isServerError(req *http.Request, resp *http.Response, err error) bool {
 	return resp != nil && resp.StatusCode >= 500
 }
 
-func retryOnError(req *http.Request, resp *http.Response, err error) bool {
-	if err != nil {
-		return true
-	}
-	if resp != nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
-		return true
-	}
-	return false
-}
-
-func newRetryIntervalCalculator(intervalType string, baseInterval time.Duration) retry.RetryIntervalCalculator {
-	switch intervalType {
-	case "linear":
-		return retry.LinearRetryInterval(baseInterval)
-	case "exponential":
-		return retry.ExponentialRetryInterval(baseInterval)
-	default:
-		return retry.StaticRetryInterval(baseInterval)
-	}
-}
-
 func NewService(
 	name string, rpo repo.Repository,
 	vendorService vendor.VendorService,
@@ -144,20 +126,36 @@ func NewService(
 		}),
 	)
 
-	cli := goclient.NewClient(
-		goclient.WithRequester((&http.Client{Timeout: conf.RequestTimeout}).Do),
-		goclient.WithMiddlewares(
-			retry.NewRetryMiddleware(
-				conf.ClientConfig.Retry.MaxRetries,
-				retryOnError,
-				newRetryIntervalCalculator(
-					conf.ClientConfig.Retry.IntervalType,
-					conf.ClientConfig.Retry.BaseInterval,
-				),
+	// retry wraps the whole client, so every attempt waits in the rate limit
+	// queue, is counted by circuit breaker and may be sent through other proxy
+	proxies := newProxyPool(name, conf.ClientConfig, conf.RequestTimeout)
+	requester := newAdaptiveLimiter(name, conf.ClientConfig.RateLimit.Adaptive).wrap(proxies.do)
+	rateLimitMiddleware := ratelimit.NewRateLimitMiddleware(queue, conf.ClientConfig.RateLimit.Interval)
+
+	var attemptCli *goclient.Client
+	if proxies.hasProxy() {
+		// every proxy has its own circuit breaker, so a blocked proxy does not
+		// stop the requests through other proxies
+		attemptCli = goclient.NewClient(
+			goclient.WithRequester(requester),
+			goclient.WithMiddlewares(rateLimitMiddleware),
+		)
+	} else {
+		attemptCli = goclient.NewClient(
+			goclient.WithRequester(requester),
+			goclient.WithMiddlewares(
+				circuitbreaker.NewCircuitBreakerMiddleware(breaker),
+				rateLimitMiddleware,
 			),
-			circuitbreaker.NewCircuitBreakerMiddleware(breaker),
-			ratelimit.NewRateLimitMiddleware(queue, conf.ClientConfig.RateLimit.Interval),
-		),
+		)
+	}
+	cli := goclient.NewClient(
+		goclient.WithRequester(newRetryPolicy(conf.ClientConfig.Retry).wrap(attemptCli.Do)),
+	)
+
+	responseCache := client.NewResponseCache(
+		conf.ClientConfig.ResponseCache.Directory,
+		conf.ClientConfig.ResponseCache.TTL,
 	)
 
 	return &ServiceImpl{
@@ -165,9 +163,18 @@ func NewService(
 		cli: client.NewClient(
 			cli,
 			conf.DecodeMethod,
+			client.WithResponseCache(responseCache),
+			client.WithRobots(
+				conf.ClientConfig.Robots.UserAgent,
+				conf.ClientConfig.Robots.TTL,
+				conf.ClientConfig.Robots.IgnoreDisallow,
+			),
 		),
 		rpo:           rpo,
 		vendorService: vendorService,
+		snapshots:     snapshot.NewStore(conf.SnapshotConfig),
+		responseCache: responseCache,
+		proxies:       proxies,
 
 		sema:       sema,
 		vendorSema: vendorSema,
@@ -175,6 +182,12 @@ func NewService(
 	}
 }
 
+// WrapClient replaces the client of vendor by the wrapped one, e.g. record
+// or replay client of fixture tests. it must be called before service is used
+func (s *ServiceImpl) WrapClient(wrap func(client.BookClient) client.BookClient) {
+	s.cli = wrap(s.cli)
+}
+
 func (s *ServiceImpl) Name() string {
 	return s.name
 }
@@ -253,6 +266,16 @@ func (s *ServiceImpl) PatchDownloadStatus(ctx context.Context, stats *serv.Patch
 	return nil
 }
 
+// MissingBookIDs returns the ids of books in vendor but not in database
+func (s *ServiceImpl) MissingBookIDs(ctx context.Context) ([]int, error) {
+	allBkIDs, err := s.rpo.FindAllBookIDs(ctx, s.name)
+	if err != nil {
+		return nil, fmt.Errorf("find all book ids fail: %w", err)
+	}
+
+	return s.vendorService.FindMissingIds(allBkIDs), nil
+}
+
 func (s *ServiceImpl) PatchMissingRecords(ctx context.Context, stats *serv.UpdateStats) error {
 	zerolog.Ctx(ctx).Info().Msg("patch missing records")
 
@@ -260,13 +283,12 @@ func (s *ServiceImpl) PatchMissingRecords(ctx context.Context, stats *serv.Updat
 		stats = new(serv.UpdateStats)
 	}
 
-	var wg sync.WaitGroup
-	allBkIDs, err := s.rpo.FindAllBookIDs(ctx, s.name)
+	missingIDs, err := s.MissingBookIDs(ctx)
 	if err != nil {
-		return fmt.Errorf("find all book ids fail: %w", err)
+		return err
 	}
 
-	missingIDs := s.vendorService.FindMissingIds(allBkIDs)
+	var wg sync.WaitGroup
 	for _, bookID := range missingIDs {
 		s.sema.Acquire(ctx, 1)
 		wg.Add(1)
@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"flag"
 	"os"
 	"testing"
 	"time"
 
+	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/config/v2"
 	mockclient "github.com/htchan/BookSpider/internal/mock/client/v2"
 	mockrepo "github.com/htchan/BookSpider/internal/mock/repo"
@@ -103,6 +105,29 @@ func TestServiceImpl_Name(t *testing.T) {
 	}
 }
 
+func TestServiceImpl_WrapClient(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	original := mockclient.NewMockBookClient(ctrl)
+	archive := client.NewFixtureArchive()
+	archive.Add(&client.Response{URL: "https://example.com", StatusCode: 200, Body: "replayed"})
+
+	serv := &ServiceImpl{cli: original}
+
+	var wrapped client.BookClient
+	serv.WrapClient(func(cli client.BookClient) client.BookClient {
+		wrapped = cli
+		return client.NewReplayClient(archive)
+	})
+
+	assert.Equal(t, original, wrapped)
+
+	body, err := serv.cli.Get(context.Background(), "https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "replayed", body)
+}
+
 func TestServiceImpl_bookFileLocation(t *testing.T) {
 	t.Parallel()
 
@@ -290,6 +315,100 @@ func TestServiceImpl_PatchDownloadStatus(t *testing.T) {
 	}
 }
 
+func TestServiceImpl_Backup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().Backup(gomock.Any(), "test", "/backup").Return(nil)
+
+				return &ServiceImpl{name: "test", conf: config.SiteConfig{BackupDirectory: "/backup"}, rpo: rpo}
+			},
+			wantError: nil,
+		},
+		{
+			name: "backup returns error",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().Backup(gomock.Any(), "test", "/backup").Return(service.ErrUnavailable)
+
+				return &ServiceImpl{name: "test", conf: config.SiteConfig{BackupDirectory: "/backup"}, rpo: rpo}
+			},
+			wantError: service.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			err := test.getService(ctrl).Backup(t.Context())
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestServiceImpl_MissingBookIDs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		want       []int
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				vendorService := mockvendor.NewMockVendorService(ctrl)
+
+				rpo.EXPECT().FindAllBookIDs(gomock.Any(), "test").Return([]int{1, 2, 5}, nil)
+				vendorService.EXPECT().FindMissingIds([]int{1, 2, 5}).Return([]int{3, 4})
+
+				return &ServiceImpl{name: "test", rpo: rpo, vendorService: vendorService}
+			},
+			want:      []int{3, 4},
+			wantError: nil,
+		},
+		{
+			name: "FindAllBookIDs returns error",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+
+				rpo.EXPECT().FindAllBookIDs(gomock.Any(), "test").Return(nil, service.ErrUnavailable)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			want:      nil,
+			wantError: service.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ids, err := test.getService(ctrl).MissingBookIDs(t.Context())
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.want, ids)
+		})
+	}
+}
+
 func TestServiceImpl_PatchMissingRecords(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,314 @@
+// Package snapshot keeps the raw pages fetched from vendor sites, so books can
+// be parsed again after a parser fix without crawling the site again
+package snapshot
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+)
+
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// Version is a stored page of url, body is saved in object of its sha256 so
+// unchanged pages fetched again take no extra space
+type Version struct {
+	SHA256    string    `json:"sha256"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+type index struct {
+	URL      string    `json:"url"`
+	Versions []Version `json:"versions"` // oldest first
+}
+
+// Store saves pages in directory as
+//   - index/{sha256 of url}.json: versions of url
+//   - objects/{first 2 chars of sha256}/{sha256}.gz: gzipped page
+type Store struct {
+	dir         string
+	maxVersions int
+	maxAge      time.Duration
+
+	lock sync.Mutex
+	now  func() time.Time
+}
+
+// NewStore returns nil if directory is not set, nil store saves nothing and
+// finds nothing
+func NewStore(conf config.SnapshotConfig) *Store {
+	if conf.Directory == "" {
+		return nil
+	}
+
+	return &Store{
+		dir:         conf.Directory,
+		maxVersions: conf.MaxVersions,
+		maxAge:      conf.MaxAge,
+		now:         time.Now,
+	}
+}
+
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) indexPath(url string) string {
+	return filepath.Join(s.dir, "index", hash(url)+".json")
+}
+
+func (s *Store) objectPath(sha string) string {
+	return filepath.Join(s.dir, "objects", sha[:2], sha+".gz")
+}
+
+// writeFile replaces the file by rename, so readers never see partial file
+func writeFile(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (s *Store) readIndex(url string) (*index, error) {
+	data, err := os.ReadFile(s.indexPath(url))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &index{URL: url}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, err
+	}
+
+	return &idx, nil
+}
+
+func (s *Store) writeIndex(idx *index) error {
+	return writeFile(s.indexPath(idx.URL), func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(idx)
+	})
+}
+
+// retain drops the versions out of retention policy, latest version is kept
+func (s *Store) retain(idx *index) {
+	keep := idx.Versions
+	if s.maxVersions > 0 && len(keep) > s.maxVersions {
+		keep = keep[len(keep)-s.maxVersions:]
+	}
+
+	if s.maxAge > 0 {
+		deadline := s.now().Add(-s.maxAge)
+		for len(keep) > 1 && keep[0].FetchedAt.Before(deadline) {
+			keep = keep[1:]
+		}
+	}
+
+	idx.Versions = keep
+}
+
+// Save stores the page of url as its latest version
+func (s *Store) Save(url, body string) error {
+	if s == nil {
+		return nil
+	}
+
+	sha := hash(body)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := os.Stat(s.objectPath(sha)); errors.Is(err, fs.ErrNotExist) {
+		err := writeFile(s.objectPath(sha), func(w io.Writer) error {
+			gz := gzip.NewWriter(w)
+			if _, err := io.WriteString(gz, body); err != nil {
+				return err
+			}
+			return gz.Close()
+		})
+		if err != nil {
+			return fmt.Errorf("save snapshot object fail: %w", err)
+		}
+	}
+
+	idx, err := s.readIndex(url)
+	if err != nil {
+		return fmt.Errorf("read snapshot index fail: %w", err)
+	}
+
+	version := Version{SHA256: sha, FetchedAt: s.now().UTC()}
+	if last := len(idx.Versions) - 1; last >= 0 && idx.Versions[last].SHA256 == sha {
+		idx.Versions[last] = version
+	} else {
+		idx.Versions = append(idx.Versions, version)
+	}
+	s.retain(idx)
+
+	if err := s.writeIndex(idx); err != nil {
+		return fmt.Errorf("save snapshot index fail: %w", err)
+	}
+
+	return nil
+}
+
+// Versions returns the stored versions of url, oldest first
+func (s *Store) Versions(url string) ([]Version, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	idx, err := s.readIndex(url)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot index fail: %w", err)
+	}
+
+	return idx.Versions, nil
+}
+
+// Latest returns the body of latest version of url
+func (s *Store) Latest(url string) (string, Version, error) {
+	versions, err := s.Versions(url)
+	if err != nil {
+		return "", Version{}, err
+	}
+
+	if len(versions) == 0 {
+		return "", Version{}, fmt.Errorf("%w: %s", ErrSnapshotNotFound, url)
+	}
+
+	version := versions[len(versions)-1]
+	body, err := s.Body(version.SHA256)
+	if err != nil {
+		return "", Version{}, err
+	}
+
+	return body, version, nil
+}
+
+// Body returns the page stored by sha256
+func (s *Store) Body(sha string) (string, error) {
+	if s == nil || len(sha) < 2 {
+		return "", fmt.Errorf("%w: %s", ErrSnapshotNotFound, sha)
+	}
+
+	file, err := os.Open(s.objectPath(sha))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSnapshotNotFound, sha)
+	} else if err != nil {
+		return "", fmt.Errorf("open snapshot object fail: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return "", fmt.Errorf("read snapshot object fail: %w", err)
+	}
+	defer gz.Close()
+
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		return "", fmt.Errorf("read snapshot object fail: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// Prune applies retention policy to every url and removes the objects no
+// longer referenced, it returns the number of removed objects
+func (s *Store) Prune() (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	referenced := make(map[string]bool)
+	indexes, err := filepath.Glob(filepath.Join(s.dir, "index", "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("list snapshot index fail: %w", err)
+	}
+
+	for _, path := range indexes {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return 0, fmt.Errorf("read snapshot index fail: %w", err)
+		}
+
+		var idx index
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return 0, fmt.Errorf("decode snapshot index %s fail: %w", path, err)
+		}
+
+		count := len(idx.Versions)
+		s.retain(&idx)
+		if len(idx.Versions) != count {
+			if err := s.writeIndex(&idx); err != nil {
+				return 0, fmt.Errorf("save snapshot index fail: %w", err)
+			}
+		}
+
+		for _, version := range idx.Versions {
+			referenced[version.SHA256] = true
+		}
+	}
+
+	removed := 0
+	err = filepath.WalkDir(filepath.Join(s.dir, "objects"), func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil || entry.IsDir() {
+			return err
+		}
+
+		sha := strings.TrimSuffix(entry.Name(), ".gz")
+		if referenced[sha] {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("remove snapshot objects fail: %w", err)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,236 @@
+package snapshot
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	leak := flag.Bool("leak", false, "check for memory leaks")
+	flag.Parse()
+
+	if *leak {
+		goleak.VerifyTestMain(m)
+	} else {
+		os.Exit(m.Run())
+	}
+}
+
+// newTestStore returns store with clock moved forward by every call of tick
+func newTestStore(t *testing.T, conf config.SnapshotConfig) (*Store, func(time.Duration)) {
+	t.Helper()
+
+	conf.Directory = t.TempDir()
+	store := NewStore(conf)
+
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	return store, func(d time.Duration) { now = now.Add(d) }
+}
+
+func countObjects(t *testing.T, store *Store) int {
+	t.Helper()
+
+	objects, err := filepath.Glob(filepath.Join(store.dir, "objects", "*", "*.gz"))
+	assert.NoError(t, err)
+
+	return len(objects)
+}
+
+func TestNewStore(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NewStore(config.SnapshotConfig{}))
+	assert.NotNil(t, NewStore(config.SnapshotConfig{Directory: t.TempDir()}))
+}
+
+func TestStore_Save(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		conf         config.SnapshotConfig
+		bodies       []string
+		interval     time.Duration
+		wantLatest   string
+		wantVersions int
+		wantObjects  int
+	}{
+		{
+			name:         "save page",
+			bodies:       []string{"<html>page 1</html>"},
+			wantLatest:   "<html>page 1</html>",
+			wantVersions: 1,
+			wantObjects:  1,
+		},
+		{
+			name:         "unchanged page is saved once",
+			bodies:       []string{"<html>page 1</html>", "<html>page 1</html>"},
+			wantLatest:   "<html>page 1</html>",
+			wantVersions: 1,
+			wantObjects:  1,
+		},
+		{
+			name:         "keep every version without limit",
+			bodies:       []string{"v1", "v2", "v3"},
+			wantLatest:   "v3",
+			wantVersions: 3,
+			wantObjects:  3,
+		},
+		{
+			name:         "drop versions beyond max versions",
+			conf:         config.SnapshotConfig{MaxVersions: 2},
+			bodies:       []string{"v1", "v2", "v3"},
+			wantLatest:   "v3",
+			wantVersions: 2,
+			wantObjects:  3,
+		},
+		{
+			name:         "drop versions older than max age",
+			conf:         config.SnapshotConfig{MaxAge: 36 * time.Hour},
+			bodies:       []string{"v1", "v2", "v3"},
+			interval:     24 * time.Hour,
+			wantLatest:   "v3",
+			wantVersions: 2,
+			wantObjects:  3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			store, tick := newTestStore(t, test.conf)
+			for _, body := range test.bodies {
+				assert.NoError(t, store.Save("https://test.com/book/1", body))
+				tick(test.interval)
+			}
+
+			body, version, err := store.Latest("https://test.com/book/1")
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantLatest, body)
+			assert.Equal(t, hash(test.wantLatest), version.SHA256)
+
+			versions, err := store.Versions("https://test.com/book/1")
+			assert.NoError(t, err)
+			assert.Len(t, versions, test.wantVersions)
+			assert.Equal(t, test.wantObjects, countObjects(t, store))
+		})
+	}
+}
+
+func TestStore_Save_Compressed(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t, config.SnapshotConfig{})
+	body := strings.Repeat("<p>重複的內容</p>", 1000)
+	assert.NoError(t, store.Save("https://test.com/book/1", body))
+
+	info, err := os.Stat(store.objectPath(hash(body)))
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(len(body)/10))
+}
+
+func TestStore_Latest(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newTestStore(t, config.SnapshotConfig{})
+	assert.NoError(t, store.Save("https://test.com/book/1", "book 1"))
+
+	tests := []struct {
+		name      string
+		store     *Store
+		url       string
+		wantBody  string
+		wantError error
+	}{
+		{
+			name:     "stored url",
+			store:    store,
+			url:      "https://test.com/book/1",
+			wantBody: "book 1",
+		},
+		{
+			name:      "url not stored",
+			store:     store,
+			url:       "https://test.com/book/2",
+			wantError: ErrSnapshotNotFound,
+		},
+		{
+			name:      "nil store",
+			store:     nil,
+			url:       "https://test.com/book/1",
+			wantError: ErrSnapshotNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, _, err := test.store.Latest(test.url)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantBody, body)
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	t.Parallel()
+
+	store, tick := newTestStore(t, config.SnapshotConfig{MaxVersions: 2, MaxAge: 48 * time.Hour})
+
+	// v1 is dropped by max versions, shared is referenced by both urls
+	for _, body := range []string{"v1", "v2", "shared"} {
+		assert.NoError(t, store.Save("https://test.com/book/1", body))
+	}
+	assert.NoError(t, store.Save("https://test.com/book/2", "old"))
+	tick(24 * time.Hour)
+	assert.NoError(t, store.Save("https://test.com/book/2", "shared"))
+	assert.Equal(t, 4, countObjects(t, store))
+
+	removed, err := store.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	// old versions expire as time passes, latest versions are kept
+	tick(36 * time.Hour)
+	removed, err = store.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	_, err = store.Body(hash("shared"))
+	assert.NoError(t, err)
+	for _, body := range []string{"v1", "v2", "old"} {
+		_, err := store.Body(hash(body))
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+	}
+
+	body, _, err := store.Latest("https://test.com/book/2")
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", body)
+}
+
+func TestStore_Nil(t *testing.T) {
+	t.Parallel()
+
+	var store *Store
+	assert.NoError(t, store.Save("https://test.com/book/1", "book 1"))
+
+	versions, err := store.Versions("https://test.com/book/1")
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	removed, err := store.Prune()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
@@ -6,6 +6,7 @@ package sqlc
 
 import (
 	"database/sql"
+	"time"
 )
 
 type Book struct {
@@ -21,6 +22,8 @@ type Book struct {
 	IsDownloaded   bool
 	Checksum       sql.NullString
 	WriterChecksum sql.NullString
+	WorkID         sql.NullInt32
+	DiscoveredAt   time.Time
 }
 
 type Error struct {
@@ -29,6 +32,30 @@ type Error struct {
 	Data sql.NullString
 }
 
+type Genre struct {
+	Site  string
+	Type  string
+	Genre string
+}
+
+type SiteHealth struct {
+	Site      string
+	Status    string
+	Phase     string
+	Checked   int32
+	Failed    int32
+	Error     string
+	CheckedAt time.Time
+}
+
+type Work struct {
+	ID        int32
+	Title     string
+	Writer    string
+	TitleKey  string
+	WriterKey string
+}
+
 type Writer struct {
 	ID       int32
 	Name     sql.NullString
@@ -8,6 +8,9 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 const booksStat = `-- name: BooksStat :one
@@ -75,13 +78,71 @@ func (q *Queries) BooksStatusStat(ctx context.Context, site string) ([]BooksStat
 	return items, nil
 }
 
+const countBooksByGenre = `-- name: CountBooksByGenre :one
+select count(*)
+from books left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = $1::text and
+  ($2::text = '' or books.site = $2::text) and
+  (
+    ($3::text = '' and books.status != 'ERROR') or
+    books.status = $3::text
+  )
+`
+
+type CountBooksByGenreParams struct {
+	Genre  string
+	Site   string
+	Status string
+}
+
+func (q *Queries) CountBooksByGenre(ctx context.Context, arg CountBooksByGenreParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBooksByGenre, arg.Genre, arg.Site, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countBooksByTitleWriter = `-- name: CountBooksByTitleWriter :one
+select count(*)
+from books left join writers on books.writer_id=writers.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any($1::text[]) or
+  writers.name like any($2::text[])) and
+  ($3::text = '' or coalesce(genres.genre, 'other') = $3::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any($1::text[]) or
+      other_writers.name like any($2::text[])) and
+      ($3::text = '' or coalesce(other_genres.genre, 'other') = $3::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  ))
+`
+
+type CountBooksByTitleWriterParams struct {
+	Titles  []string
+	Writers []string
+	Genre   string
+}
+
+func (q *Queries) CountBooksByTitleWriter(ctx context.Context, arg CountBooksByTitleWriterParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBooksByTitleWriter, pq.Array(arg.Titles), pq.Array(arg.Writers), arg.Genre)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createBookWithHash = `-- name: CreateBookWithHash :one
 INSERT INTO books
 (site, id, hash_code, title, writer_id, writer_checksum, type, 
 update_date, update_chapter, status, is_downloaded, checksum)
 VALUES
 ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, work_id, discovered_at
 `
 
 type CreateBookWithHashParams struct {
@@ -128,6 +189,66 @@ func (q *Queries) CreateBookWithHash(ctx context.Context, arg CreateBookWithHash
 		&i.IsDownloaded,
 		&i.Checksum,
 		&i.WriterChecksum,
+		&i.WorkID,
+		&i.DiscoveredAt,
+	)
+	return i, err
+}
+
+const createBookWithNextID = `-- name: CreateBookWithNextID :one
+INSERT INTO books
+(site, id, hash_code, title, writer_id, writer_checksum, type,
+update_date, update_chapter, status, is_downloaded, checksum)
+VALUES
+($1, (select coalesce(max(id), 0) + 1 from books where site=$1), 0, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (site, id, hash_code) DO NOTHING
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, work_id, discovered_at
+`
+
+type CreateBookWithNextIDParams struct {
+	Site           string
+	Title          sql.NullString
+	WriterID       sql.NullInt32
+	WriterChecksum sql.NullString
+	Type           sql.NullString
+	UpdateDate     sql.NullString
+	UpdateChapter  sql.NullString
+	Status         string
+	IsDownloaded   bool
+	Checksum       sql.NullString
+}
+
+// takes the id next to the largest id of site, no row is returned when a
+// concurrent insert takes the same id first
+func (q *Queries) CreateBookWithNextID(ctx context.Context, arg CreateBookWithNextIDParams) (Book, error) {
+	row := q.db.QueryRowContext(ctx, createBookWithNextID,
+		arg.Site,
+		arg.Title,
+		arg.WriterID,
+		arg.WriterChecksum,
+		arg.Type,
+		arg.UpdateDate,
+		arg.UpdateChapter,
+		arg.Status,
+		arg.IsDownloaded,
+		arg.Checksum,
+	)
+	var i Book
+	err := row.Scan(
+		&i.Site,
+		&i.ID,
+		&i.HashCode,
+		&i.Title,
+		&i.WriterID,
+		&i.Type,
+		&i.UpdateDate,
+		&i.UpdateChapter,
+		&i.Status,
+		&i.IsDownloaded,
+		&i.Checksum,
+		&i.WriterChecksum,
+		&i.WorkID,
+		&i.DiscoveredAt,
 	)
 	return i, err
 }
@@ -138,7 +259,7 @@ INSERT INTO books
 update_date, update_chapter, status, is_downloaded, checksum)
 VALUES
 ($1, $2, 0, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, work_id, discovered_at
 `
 
 type CreateBookWithZeroHashParams struct {
@@ -183,6 +304,8 @@ func (q *Queries) CreateBookWithZeroHash(ctx context.Context, arg CreateBookWith
 		&i.IsDownloaded,
 		&i.Checksum,
 		&i.WriterChecksum,
+		&i.WorkID,
+		&i.DiscoveredAt,
 	)
 	return i, err
 }
@@ -207,6 +330,53 @@ func (q *Queries) CreateError(ctx context.Context, arg CreateErrorParams) (Error
 	return i, err
 }
 
+const createGenre = `-- name: CreateGenre :exec
+insert into genres (site, type, genre) values ($1, $2, $3)
+on conflict (site, type) do nothing
+`
+
+type CreateGenreParams struct {
+	Site  string
+	Type  string
+	Genre string
+}
+
+func (q *Queries) CreateGenre(ctx context.Context, arg CreateGenreParams) error {
+	_, err := q.db.ExecContext(ctx, createGenre, arg.Site, arg.Type, arg.Genre)
+	return err
+}
+
+const createWork = `-- name: CreateWork :one
+insert into works (title, writer, title_key, writer_key)
+values ($1, $2, $3, $4)
+returning id, title, writer, title_key, writer_key
+`
+
+type CreateWorkParams struct {
+	Title     string
+	Writer    string
+	TitleKey  string
+	WriterKey string
+}
+
+func (q *Queries) CreateWork(ctx context.Context, arg CreateWorkParams) (Work, error) {
+	row := q.db.QueryRowContext(ctx, createWork,
+		arg.Title,
+		arg.Writer,
+		arg.TitleKey,
+		arg.WriterKey,
+	)
+	var i Work
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Writer,
+		&i.TitleKey,
+		&i.WriterKey,
+	)
+	return i, err
+}
+
 const createWriter = `-- name: CreateWriter :one
 insert into writers (name, checksum) values ($1, $2) 
 on conflict (name) do update set name=$1 
@@ -241,6 +411,15 @@ func (q *Queries) DeleteError(ctx context.Context, arg DeleteErrorParams) (Error
 	return i, err
 }
 
+const deleteWork = `-- name: DeleteWork :exec
+delete from works where id=$1
+`
+
+func (q *Queries) DeleteWork(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteWork, id)
+	return err
+}
+
 const downloadedBooksStat = `-- name: DownloadedBooksStat :one
 select count(*) as downloaded_count from books where site=$1 and is_downloaded=true
 `
@@ -396,7 +575,8 @@ const getBookGroupByID = `-- name: GetBookGroupByID :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
 from books
   left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
@@ -405,6 +585,10 @@ where (books.checksum, books.writer_checksum) = (
   where bks.site=$1 and bks.id=$2 
   and bks.checksum != '' and bks.writer_checksum != ''
   order by bks.hash_code desc limit 1
+) or books.work_id = (
+  select bks.work_id from books as bks
+  where bks.site=$1 and bks.id=$2 and bks.work_id is not null
+  order by bks.hash_code desc limit 1
 ) or books.site=$1 and books.id=$2
 `
 
@@ -426,6 +610,7 @@ type GetBookGroupByIDRow struct {
 	Status        string
 	IsDownloaded  bool
 	Data          string
+	WorkID        int32
 }
 
 func (q *Queries) GetBookGroupByID(ctx context.Context, arg GetBookGroupByIDParams) ([]GetBookGroupByIDRow, error) {
@@ -450,6 +635,7 @@ func (q *Queries) GetBookGroupByID(ctx context.Context, arg GetBookGroupByIDPara
 			&i.Status,
 			&i.IsDownloaded,
 			&i.Data,
+			&i.WorkID,
 		); err != nil {
 			return nil, err
 		}
@@ -468,7 +654,8 @@ const getBookGroupByIDHash = `-- name: GetBookGroupByIDHash :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
 from books
   left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
@@ -477,6 +664,9 @@ where (books.checksum, books.writer_checksum) = (
   where bks.site=$1 and bks.id=$2 and bks.hash_code=$3 
   and bks.checksum != '' and bks.writer_checksum != ''
   order by bks.hash_code desc limit 1
+) or books.work_id = (
+  select bks.work_id from books as bks
+  where bks.site=$1 and bks.id=$2 and bks.hash_code=$3 and bks.work_id is not null
 ) or books.site=$1 and books.id=$2
 `
 
@@ -499,6 +689,7 @@ type GetBookGroupByIDHashRow struct {
 	Status        string
 	IsDownloaded  bool
 	Data          string
+	WorkID        int32
 }
 
 func (q *Queries) GetBookGroupByIDHash(ctx context.Context, arg GetBookGroupByIDHashParams) ([]GetBookGroupByIDHashRow, error) {
@@ -523,6 +714,7 @@ func (q *Queries) GetBookGroupByIDHash(ctx context.Context, arg GetBookGroupByID
 			&i.Status,
 			&i.IsDownloaded,
 			&i.Data,
+			&i.WorkID,
 		); err != nil {
 			return nil, err
 		}
@@ -537,6 +729,58 @@ func (q *Queries) GetBookGroupByIDHash(ctx context.Context, arg GetBookGroupByID
 	return items, nil
 }
 
+const getSiteHealth = `-- name: GetSiteHealth :one
+select site, status, phase, checked, failed, error, checked_at from site_health where site=$1
+`
+
+func (q *Queries) GetSiteHealth(ctx context.Context, site string) (SiteHealth, error) {
+	row := q.db.QueryRowContext(ctx, getSiteHealth, site)
+	var i SiteHealth
+	err := row.Scan(
+		&i.Site,
+		&i.Status,
+		&i.Phase,
+		&i.Checked,
+		&i.Failed,
+		&i.Error,
+		&i.CheckedAt,
+	)
+	return i, err
+}
+
+const getWork = `-- name: GetWork :one
+select id, title, writer, title_key, writer_key from works where id=$1
+`
+
+func (q *Queries) GetWork(ctx context.Context, id int32) (Work, error) {
+	row := q.db.QueryRowContext(ctx, getWork, id)
+	var i Work
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Writer,
+		&i.TitleKey,
+		&i.WriterKey,
+	)
+	return i, err
+}
+
+const getWriter = `-- name: GetWriter :one
+select id, coalesce(name, '') as name from writers where id=$1
+`
+
+type GetWriterRow struct {
+	ID   int32
+	Name string
+}
+
+func (q *Queries) GetWriter(ctx context.Context, id int32) (GetWriterRow, error) {
+	row := q.db.QueryRowContext(ctx, getWriter, id)
+	var i GetWriterRow
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
 const listBooks = `-- name: ListBooks :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
@@ -599,53 +843,112 @@ func (q *Queries) ListBooks(ctx context.Context, site string) ([]ListBooksRow, e
 	return items, nil
 }
 
-const listBooksByStatus = `-- name: ListBooksByStatus :many
+const listBooksByGenreDiscovered = `-- name: ListBooksByGenreDiscovered :many
 select books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id 
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  to_char(books.discovered_at, 'YYYY-MM-DD"T"HH24:MI:SS.US')::text as sort_key
+from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.status=$1 order by hash_code desc
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = $1::text and
+  ($2::text = '' or books.site = $2::text) and
+  (
+    ($3::text = '' and books.status != 'ERROR') or
+    books.status = $3::text
+  ) and (
+  not $4::bool or (
+    $5::bool and (books.discovered_at, books.site, books.id, books.hash_code) > (
+    $6::text::timestamp,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  ) or (
+    not $5::bool and (books.discovered_at, books.site, books.id, books.hash_code) < (
+    $6::text::timestamp,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  )
+)
+order by
+  case when $5::bool then books.discovered_at end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.hash_code end asc,
+  books.discovered_at desc, books.site desc, books.id desc, books.hash_code desc
+limit $10
 `
 
-type ListBooksByStatusRow struct {
+type ListBooksByGenreDiscoveredParams struct {
+	Genre          string
+	Site           string
+	Status         string
+	HasCursor      bool
+	Ascending      bool
+	CursorKey      string
+	CursorSite     string
+	CursorID       int32
+	CursorHashCode int32
+	LimitCount     int32
+}
+
+type ListBooksByGenreDiscoveredRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
 	Title         sql.NullString
 	WriterID      sql.NullInt32
-	Name          string
+	WriterName    string
 	Type          sql.NullString
 	UpdateDate    sql.NullString
 	UpdateChapter sql.NullString
 	Status        string
 	IsDownloaded  bool
-	Data          string
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
 }
 
-func (q *Queries) ListBooksByStatus(ctx context.Context, status string) ([]ListBooksByStatusRow, error) {
-	rows, err := q.db.QueryContext(ctx, listBooksByStatus, status)
+func (q *Queries) ListBooksByGenreDiscovered(ctx context.Context, arg ListBooksByGenreDiscoveredParams) ([]ListBooksByGenreDiscoveredRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByGenreDiscovered,
+		arg.Genre,
+		arg.Site,
+		arg.Status,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorKey,
+		arg.CursorSite,
+		arg.CursorID,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBooksByStatusRow
+	var items []ListBooksByGenreDiscoveredRow
 	for rows.Next() {
-		var i ListBooksByStatusRow
+		var i ListBooksByGenreDiscoveredRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
 			&i.HashCode,
 			&i.Title,
 			&i.WriterID,
-			&i.Name,
+			&i.WriterName,
 			&i.Type,
 			&i.UpdateDate,
 			&i.UpdateChapter,
 			&i.Status,
 			&i.IsDownloaded,
-			&i.Data,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
 		); err != nil {
 			return nil, err
 		}
@@ -660,68 +963,107 @@ func (q *Queries) ListBooksByStatus(ctx context.Context, status string) ([]ListB
 	return items, nil
 }
 
-const listBooksByTitleWriter = `-- name: ListBooksByTitleWriter :many
+const listBooksByGenreID = `-- name: ListBooksByGenreID :many
 select books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  ''::text as sort_key
 from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.status != 'ERROR' and 
-  (($1 != '%%' and books.title like $1) or
-  ($2 != '%%' and writers.name like $2))
-order by books.update_date desc, books.id desc, books.site desc limit $3 offset $4
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = $1::text and
+  ($2::text = '' or books.site = $2::text) and
+  (
+    ($3::text = '' and books.status != 'ERROR') or
+    books.status = $3::text
+  ) and (
+  not $4::bool or (
+    $5::bool and (books.id, books.site, books.hash_code) > (
+    $6::integer,
+    $7::text,
+    $8::integer
+    )
+  ) or (
+    not $5::bool and (books.id, books.site, books.hash_code) < (
+    $6::integer,
+    $7::text,
+    $8::integer
+    )
+  )
+)
+order by
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.hash_code end asc,
+  books.id desc, books.site desc, books.hash_code desc
+limit $9
 `
 
-type ListBooksByTitleWriterParams struct {
-	Column1 interface{}
-	Column2 interface{}
-	Limit   int32
-	Offset  int32
+type ListBooksByGenreIDParams struct {
+	Genre          string
+	Site           string
+	Status         string
+	HasCursor      bool
+	Ascending      bool
+	CursorID       int32
+	CursorSite     string
+	CursorHashCode int32
+	LimitCount     int32
 }
 
-type ListBooksByTitleWriterRow struct {
+type ListBooksByGenreIDRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
 	Title         sql.NullString
 	WriterID      sql.NullInt32
-	Name          string
+	WriterName    string
 	Type          sql.NullString
 	UpdateDate    sql.NullString
 	UpdateChapter sql.NullString
 	Status        string
 	IsDownloaded  bool
-	Data          string
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
 }
 
-func (q *Queries) ListBooksByTitleWriter(ctx context.Context, arg ListBooksByTitleWriterParams) ([]ListBooksByTitleWriterRow, error) {
-	rows, err := q.db.QueryContext(ctx, listBooksByTitleWriter,
-		arg.Column1,
-		arg.Column2,
-		arg.Limit,
-		arg.Offset,
+func (q *Queries) ListBooksByGenreID(ctx context.Context, arg ListBooksByGenreIDParams) ([]ListBooksByGenreIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByGenreID,
+		arg.Genre,
+		arg.Site,
+		arg.Status,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorID,
+		arg.CursorSite,
+		arg.CursorHashCode,
+		arg.LimitCount,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBooksByTitleWriterRow
+	var items []ListBooksByGenreIDRow
 	for rows.Next() {
-		var i ListBooksByTitleWriterRow
+		var i ListBooksByGenreIDRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
 			&i.HashCode,
 			&i.Title,
 			&i.WriterID,
-			&i.Name,
+			&i.WriterName,
 			&i.Type,
 			&i.UpdateDate,
 			&i.UpdateChapter,
 			&i.Status,
 			&i.IsDownloaded,
-			&i.Data,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
 		); err != nil {
 			return nil, err
 		}
@@ -736,55 +1078,112 @@ func (q *Queries) ListBooksByTitleWriter(ctx context.Context, arg ListBooksByTit
 	return items, nil
 }
 
-const listBooksForDownload = `-- name: ListBooksForDownload :many
-select distinct on (books.site, books.id) 
-  books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id 
+const listBooksByGenreTitle = `-- name: ListBooksByGenreTitle :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.title, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.site=$1 and books.status='END' and books.is_downloaded=false
-order by books.site, books.id desc, books.hash_code desc
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = $1::text and
+  ($2::text = '' or books.site = $2::text) and
+  (
+    ($3::text = '' and books.status != 'ERROR') or
+    books.status = $3::text
+  ) and (
+  not $4::bool or (
+    $5::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) > (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  ) or (
+    not $5::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) < (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  )
+)
+order by
+  case when $5::bool then coalesce(books.title, '') end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.hash_code end asc,
+  coalesce(books.title, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit $10
 `
 
-type ListBooksForDownloadRow struct {
+type ListBooksByGenreTitleParams struct {
+	Genre          string
+	Site           string
+	Status         string
+	HasCursor      bool
+	Ascending      bool
+	CursorKey      string
+	CursorSite     string
+	CursorID       int32
+	CursorHashCode int32
+	LimitCount     int32
+}
+
+type ListBooksByGenreTitleRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
 	Title         sql.NullString
 	WriterID      sql.NullInt32
-	Name          string
+	WriterName    string
 	Type          sql.NullString
 	UpdateDate    sql.NullString
 	UpdateChapter sql.NullString
 	Status        string
 	IsDownloaded  bool
-	Data          string
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
 }
 
-func (q *Queries) ListBooksForDownload(ctx context.Context, site string) ([]ListBooksForDownloadRow, error) {
-	rows, err := q.db.QueryContext(ctx, listBooksForDownload, site)
+func (q *Queries) ListBooksByGenreTitle(ctx context.Context, arg ListBooksByGenreTitleParams) ([]ListBooksByGenreTitleRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByGenreTitle,
+		arg.Genre,
+		arg.Site,
+		arg.Status,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorKey,
+		arg.CursorSite,
+		arg.CursorID,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBooksForDownloadRow
+	var items []ListBooksByGenreTitleRow
 	for rows.Next() {
-		var i ListBooksForDownloadRow
+		var i ListBooksByGenreTitleRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
 			&i.HashCode,
 			&i.Title,
 			&i.WriterID,
-			&i.Name,
+			&i.WriterName,
 			&i.Type,
 			&i.UpdateDate,
 			&i.UpdateChapter,
 			&i.Status,
 			&i.IsDownloaded,
-			&i.Data,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
 		); err != nil {
 			return nil, err
 		}
@@ -799,55 +1198,112 @@ func (q *Queries) ListBooksForDownload(ctx context.Context, site string) ([]List
 	return items, nil
 }
 
-const listBooksForUpdate = `-- name: ListBooksForUpdate :many
-select distinct on (books.site, books.id) 
-  books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id 
+const listBooksByGenreUpdated = `-- name: ListBooksByGenreUpdated :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.update_date, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.site=$1
-order by books.site, books.id desc, books.hash_code desc
+  left join genres on books.site=genres.site and books.type=genres.type
+where coalesce(genres.genre, 'other') = $1::text and
+  ($2::text = '' or books.site = $2::text) and
+  (
+    ($3::text = '' and books.status != 'ERROR') or
+    books.status = $3::text
+  ) and (
+  not $4::bool or (
+    $5::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) > (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  ) or (
+    not $5::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) < (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  )
+)
+order by
+  case when $5::bool then coalesce(books.update_date, '') end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.hash_code end asc,
+  coalesce(books.update_date, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit $10
 `
 
-type ListBooksForUpdateRow struct {
+type ListBooksByGenreUpdatedParams struct {
+	Genre          string
+	Site           string
+	Status         string
+	HasCursor      bool
+	Ascending      bool
+	CursorKey      string
+	CursorSite     string
+	CursorID       int32
+	CursorHashCode int32
+	LimitCount     int32
+}
+
+type ListBooksByGenreUpdatedRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
 	Title         sql.NullString
 	WriterID      sql.NullInt32
-	Name          string
+	WriterName    string
 	Type          sql.NullString
 	UpdateDate    sql.NullString
 	UpdateChapter sql.NullString
 	Status        string
 	IsDownloaded  bool
-	Data          string
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
 }
 
-func (q *Queries) ListBooksForUpdate(ctx context.Context, site string) ([]ListBooksForUpdateRow, error) {
-	rows, err := q.db.QueryContext(ctx, listBooksForUpdate, site)
+func (q *Queries) ListBooksByGenreUpdated(ctx context.Context, arg ListBooksByGenreUpdatedParams) ([]ListBooksByGenreUpdatedRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByGenreUpdated,
+		arg.Genre,
+		arg.Site,
+		arg.Status,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorKey,
+		arg.CursorSite,
+		arg.CursorID,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBooksForUpdateRow
+	var items []ListBooksByGenreUpdatedRow
 	for rows.Next() {
-		var i ListBooksForUpdateRow
+		var i ListBooksByGenreUpdatedRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
 			&i.HashCode,
 			&i.Title,
 			&i.WriterID,
-			&i.Name,
+			&i.WriterName,
 			&i.Type,
 			&i.UpdateDate,
 			&i.UpdateChapter,
 			&i.Status,
 			&i.IsDownloaded,
-			&i.Data,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
 		); err != nil {
 			return nil, err
 		}
@@ -862,23 +1318,17 @@ func (q *Queries) ListBooksForUpdate(ctx context.Context, site string) ([]ListBo
 	return items, nil
 }
 
-const listRandomBooks = `-- name: ListRandomBooks :many
+const listBooksByStatus = `-- name: ListBooksByStatus :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
   books.status, books.is_downloaded, coalesce(errors.data, '')
 from books left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
-where books.is_downloaded=true
-order by books.site, books.id desc, books.hash_code desc 
-limit $1 offset RANDOM() * 
-greatest(
-  (select count(*) - $1
-  from books as bks where bks.is_downloaded=true), 0
-)
+where books.status=$1 order by hash_code desc
 `
 
-type ListRandomBooksRow struct {
+type ListBooksByStatusRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
@@ -893,15 +1343,15 @@ type ListRandomBooksRow struct {
 	Data          string
 }
 
-func (q *Queries) ListRandomBooks(ctx context.Context, dollar_1 interface{}) ([]ListRandomBooksRow, error) {
-	rows, err := q.db.QueryContext(ctx, listRandomBooks, dollar_1)
+func (q *Queries) ListBooksByStatus(ctx context.Context, status string) ([]ListBooksByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByStatus, status)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListRandomBooksRow
+	var items []ListBooksByStatusRow
 	for rows.Next() {
-		var i ListRandomBooksRow
+		var i ListBooksByStatusRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
@@ -929,34 +1379,1407 @@ func (q *Queries) ListRandomBooks(ctx context.Context, dollar_1 interface{}) ([]
 	return items, nil
 }
 
-const nonErrorBooksStat = `-- name: NonErrorBooksStat :one
-select max(id) as latest_success_id from books where status<>'ERROR' and site=$1
+const listBooksByTitleWriterDiscovered = `-- name: ListBooksByTitleWriterDiscovered :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  to_char(books.discovered_at, 'YYYY-MM-DD"T"HH24:MI:SS.US')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any($1::text[]) or
+  writers.name like any($2::text[])) and
+  ($3::text = '' or coalesce(genres.genre, 'other') = $3::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any($1::text[]) or
+      other_writers.name like any($2::text[])) and
+      ($3::text = '' or coalesce(other_genres.genre, 'other') = $3::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not $4::bool or (
+    $5::bool and (books.discovered_at, books.site, books.id, books.hash_code) > (
+    $6::text::timestamp,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  ) or (
+    not $5::bool and (books.discovered_at, books.site, books.id, books.hash_code) < (
+    $6::text::timestamp,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  )
+)
+order by
+  case when $5::bool then books.discovered_at end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.hash_code end asc,
+  books.discovered_at desc, books.site desc, books.id desc, books.hash_code desc
+limit $10
 `
 
-func (q *Queries) NonErrorBooksStat(ctx context.Context, site string) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, nonErrorBooksStat, site)
-	var latest_success_id interface{}
-	err := row.Scan(&latest_success_id)
-	return latest_success_id, err
+type ListBooksByTitleWriterDiscoveredParams struct {
+	Titles         []string
+	Writers        []string
+	Genre          string
+	HasCursor      bool
+	Ascending      bool
+	CursorKey      string
+	CursorSite     string
+	CursorID       int32
+	CursorHashCode int32
+	LimitCount     int32
 }
 
-const updateBook = `-- name: UpdateBook :one
-Update books SET 
-title=$4, writer_id=$5, writer_checksum=$12, type=$6, update_date=$7, update_chapter=$8,
-status=$9, is_downloaded=$10, checksum=$11
-WHERE site=$1 and id=$2 and hash_code=$3
-RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum
-`
+type ListBooksByTitleWriterDiscoveredRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
+}
 
-type UpdateBookParams struct {
-	Site           string
-	ID             int32
-	HashCode       int32
-	Title          sql.NullString
-	WriterID       sql.NullInt32
-	Type           sql.NullString
-	UpdateDate     sql.NullString
-	UpdateChapter  sql.NullString
+func (q *Queries) ListBooksByTitleWriterDiscovered(ctx context.Context, arg ListBooksByTitleWriterDiscoveredParams) ([]ListBooksByTitleWriterDiscoveredRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByTitleWriterDiscovered,
+		pq.Array(arg.Titles),
+		pq.Array(arg.Writers),
+		arg.Genre,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorKey,
+		arg.CursorSite,
+		arg.CursorID,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByTitleWriterDiscoveredRow
+	for rows.Next() {
+		var i ListBooksByTitleWriterDiscoveredRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByTitleWriterID = `-- name: ListBooksByTitleWriterID :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  ''::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any($1::text[]) or
+  writers.name like any($2::text[])) and
+  ($3::text = '' or coalesce(genres.genre, 'other') = $3::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any($1::text[]) or
+      other_writers.name like any($2::text[])) and
+      ($3::text = '' or coalesce(other_genres.genre, 'other') = $3::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not $4::bool or (
+    $5::bool and (books.id, books.site, books.hash_code) > (
+    $6::integer,
+    $7::text,
+    $8::integer
+    )
+  ) or (
+    not $5::bool and (books.id, books.site, books.hash_code) < (
+    $6::integer,
+    $7::text,
+    $8::integer
+    )
+  )
+)
+order by
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.hash_code end asc,
+  books.id desc, books.site desc, books.hash_code desc
+limit $9
+`
+
+type ListBooksByTitleWriterIDParams struct {
+	Titles         []string
+	Writers        []string
+	Genre          string
+	HasCursor      bool
+	Ascending      bool
+	CursorID       int32
+	CursorSite     string
+	CursorHashCode int32
+	LimitCount     int32
+}
+
+type ListBooksByTitleWriterIDRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
+}
+
+func (q *Queries) ListBooksByTitleWriterID(ctx context.Context, arg ListBooksByTitleWriterIDParams) ([]ListBooksByTitleWriterIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByTitleWriterID,
+		pq.Array(arg.Titles),
+		pq.Array(arg.Writers),
+		arg.Genre,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorID,
+		arg.CursorSite,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByTitleWriterIDRow
+	for rows.Next() {
+		var i ListBooksByTitleWriterIDRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByTitleWriterTitle = `-- name: ListBooksByTitleWriterTitle :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.title, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any($1::text[]) or
+  writers.name like any($2::text[])) and
+  ($3::text = '' or coalesce(genres.genre, 'other') = $3::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any($1::text[]) or
+      other_writers.name like any($2::text[])) and
+      ($3::text = '' or coalesce(other_genres.genre, 'other') = $3::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not $4::bool or (
+    $5::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) > (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  ) or (
+    not $5::bool and (coalesce(books.title, ''), books.site, books.id, books.hash_code) < (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  )
+)
+order by
+  case when $5::bool then coalesce(books.title, '') end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.hash_code end asc,
+  coalesce(books.title, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit $10
+`
+
+type ListBooksByTitleWriterTitleParams struct {
+	Titles         []string
+	Writers        []string
+	Genre          string
+	HasCursor      bool
+	Ascending      bool
+	CursorKey      string
+	CursorSite     string
+	CursorID       int32
+	CursorHashCode int32
+	LimitCount     int32
+}
+
+type ListBooksByTitleWriterTitleRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
+}
+
+func (q *Queries) ListBooksByTitleWriterTitle(ctx context.Context, arg ListBooksByTitleWriterTitleParams) ([]ListBooksByTitleWriterTitleRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByTitleWriterTitle,
+		pq.Array(arg.Titles),
+		pq.Array(arg.Writers),
+		arg.Genre,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorKey,
+		arg.CursorSite,
+		arg.CursorID,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByTitleWriterTitleRow
+	for rows.Next() {
+		var i ListBooksByTitleWriterTitleRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByTitleWriterUpdated = `-- name: ListBooksByTitleWriterUpdated :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+  books.update_date, books.update_chapter,
+  books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+  coalesce(books.work_id, 0) as work_id,
+  coalesce(books.update_date, '')::text as sort_key
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+  left join genres on books.site=genres.site and books.type=genres.type
+where books.status != 'ERROR' and
+  (books.title like any($1::text[]) or
+  writers.name like any($2::text[])) and
+  ($3::text = '' or coalesce(genres.genre, 'other') = $3::text) and
+  (books.work_id is null or not exists (
+    select 1 from books as others
+      left join writers as other_writers on others.writer_id=other_writers.id
+      left join genres as other_genres on others.site=other_genres.site and others.type=other_genres.type
+    where others.work_id=books.work_id and others.status != 'ERROR' and
+      (others.title like any($1::text[]) or
+      other_writers.name like any($2::text[])) and
+      ($3::text = '' or coalesce(other_genres.genre, 'other') = $3::text) and
+      (coalesce(others.update_date, ''), others.site, others.id, others.hash_code) >
+      (coalesce(books.update_date, ''), books.site, books.id, books.hash_code)
+  )) and (
+  not $4::bool or (
+    $5::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) > (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  ) or (
+    not $5::bool and (coalesce(books.update_date, ''), books.site, books.id, books.hash_code) < (
+    $6::text,
+    $7::text,
+    $8::integer,
+    $9::integer
+    )
+  )
+)
+order by
+  case when $5::bool then coalesce(books.update_date, '') end asc,
+  case when $5::bool then books.site end asc,
+  case when $5::bool then books.id end asc,
+  case when $5::bool then books.hash_code end asc,
+  coalesce(books.update_date, '') desc, books.site desc, books.id desc, books.hash_code desc
+limit $10
+`
+
+type ListBooksByTitleWriterUpdatedParams struct {
+	Titles         []string
+	Writers        []string
+	Genre          string
+	HasCursor      bool
+	Ascending      bool
+	CursorKey      string
+	CursorSite     string
+	CursorID       int32
+	CursorHashCode int32
+	LimitCount     int32
+}
+
+type ListBooksByTitleWriterUpdatedRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ErrorData     string
+	WorkID        int32
+	SortKey       string
+}
+
+// listing queries are one per sort, so the keyset compares the typed columns
+// of the sort. direction is chosen by ascending and has_cursor parameters, which
+// a generic plan of prepared statement can't fold, so the keyset may not use
+// the sort index.
+// books of a work are listed once by the latest updated book matching search
+func (q *Queries) ListBooksByTitleWriterUpdated(ctx context.Context, arg ListBooksByTitleWriterUpdatedParams) ([]ListBooksByTitleWriterUpdatedRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByTitleWriterUpdated,
+		pq.Array(arg.Titles),
+		pq.Array(arg.Writers),
+		arg.Genre,
+		arg.HasCursor,
+		arg.Ascending,
+		arg.CursorKey,
+		arg.CursorSite,
+		arg.CursorID,
+		arg.CursorHashCode,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByTitleWriterUpdatedRow
+	for rows.Next() {
+		var i ListBooksByTitleWriterUpdatedRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ErrorData,
+			&i.WorkID,
+			&i.SortKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByWorkID = `-- name: ListBooksByWorkID :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.work_id=$1
+order by books.site, books.id, books.hash_code
+`
+
+type ListBooksByWorkIDRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+	WorkID        int32
+}
+
+func (q *Queries) ListBooksByWorkID(ctx context.Context, workID sql.NullInt32) ([]ListBooksByWorkIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByWorkID, workID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByWorkIDRow
+	for rows.Next() {
+		var i ListBooksByWorkIDRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+			&i.WorkID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByWorkIDs = `-- name: ListBooksByWorkIDs :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.work_id = any($1::integer[]) and books.status != 'ERROR'
+order by books.work_id, books.site, books.id, books.hash_code
+`
+
+type ListBooksByWorkIDsRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+	WorkID        int32
+}
+
+func (q *Queries) ListBooksByWorkIDs(ctx context.Context, workIds []int32) ([]ListBooksByWorkIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByWorkIDs, pq.Array(workIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByWorkIDsRow
+	for rows.Next() {
+		var i ListBooksByWorkIDsRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+			&i.WorkID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByWriter = `-- name: ListBooksByWriter :many
+with latest as (
+  select distinct on (books.site, books.id)
+    books.site, books.id, books.hash_code, books.title,
+    books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+    books.update_date, books.update_chapter, 
+    books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+    coalesce(books.work_id, 0) as work_id,
+    books.discovered_at
+  from books left join writers on books.writer_id=writers.id 
+    left join errors on books.site=errors.site and books.id=errors.id
+  where books.writer_id=$2 or books.writer_checksum = (
+    select wts.checksum from writers as wts 
+    where wts.id=$2 and wts.checksum != ''
+  )
+  order by books.site, books.id, books.hash_code desc
+)
+select latest.site, latest.id, latest.hash_code, latest.title,
+  latest.writer_id, latest.writer_name, latest.type,
+  latest.update_date, latest.update_chapter,
+  latest.status, latest.is_downloaded, latest.error_data,
+  latest.work_id
+from latest
+order by
+  case when $1::text = 'title' then coalesce(latest.title, '') end asc,
+  case when $1::text = 'id' then latest.id end desc,
+  case when $1::text = 'discovered' then latest.discovered_at end desc,
+  case when $1::text not in ('title', 'id', 'discovered') then coalesce(latest.update_date, '') end desc,
+  latest.site desc, latest.id desc
+`
+
+type ListBooksByWriterParams struct {
+	SortBy   string
+	WriterID sql.NullInt32
+}
+
+type ListBooksByWriterRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ErrorData     string
+	WorkID        int32
+}
+
+func (q *Queries) ListBooksByWriter(ctx context.Context, arg ListBooksByWriterParams) ([]ListBooksByWriterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByWriter, arg.SortBy, arg.WriterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksByWriterRow
+	for rows.Next() {
+		var i ListBooksByWriterRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ErrorData,
+			&i.WorkID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksForDownload = `-- name: ListBooksForDownload :many
+select distinct on (books.site, books.id) 
+  books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, '')
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1 and books.status='END' and books.is_downloaded=false
+order by books.site, books.id desc, books.hash_code desc
+`
+
+type ListBooksForDownloadRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+}
+
+func (q *Queries) ListBooksForDownload(ctx context.Context, site string) ([]ListBooksForDownloadRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksForDownload, site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksForDownloadRow
+	for rows.Next() {
+		var i ListBooksForDownloadRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksForUpdate = `-- name: ListBooksForUpdate :many
+select distinct on (books.site, books.id) 
+  books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, '')
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1
+order by books.site, books.id desc, books.hash_code desc
+`
+
+type ListBooksForUpdateRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+}
+
+func (q *Queries) ListBooksForUpdate(ctx context.Context, site string) ([]ListBooksForUpdateRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksForUpdate, site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksForUpdateRow
+	for rows.Next() {
+		var i ListBooksForUpdateRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksWithoutChecksum = `-- name: ListBooksWithoutChecksum :many
+select books.site, books.id, books.hash_code, coalesce(books.title, '') as title
+from books
+where books.status != 'ERROR' and (books.checksum='' or books.checksum is null)
+order by books.site, books.id, books.hash_code
+`
+
+type ListBooksWithoutChecksumRow struct {
+	Site     string
+	ID       int32
+	HashCode int32
+	Title    string
+}
+
+func (q *Queries) ListBooksWithoutChecksum(ctx context.Context) ([]ListBooksWithoutChecksumRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksWithoutChecksum)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksWithoutChecksumRow
+	for rows.Next() {
+		var i ListBooksWithoutChecksumRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksWithoutWork = `-- name: ListBooksWithoutWork :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  coalesce(books.work_id, 0) as work_id
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1 and books.work_id is null and books.status != 'ERROR'
+order by books.site, books.id, books.hash_code
+`
+
+type ListBooksWithoutWorkRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+	WorkID        int32
+}
+
+func (q *Queries) ListBooksWithoutWork(ctx context.Context, site string) ([]ListBooksWithoutWorkRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksWithoutWork, site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBooksWithoutWorkRow
+	for rows.Next() {
+		var i ListBooksWithoutWorkRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+			&i.WorkID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRandomBooks = `-- name: ListRandomBooks :many
+with sampled as (
+  select books.site, books.id, books.hash_code, books.title,
+    books.writer_id, coalesce(writers.name, '') as writer_name, books.type,
+    books.update_date, books.update_chapter, 
+    books.status, books.is_downloaded, coalesce(errors.data, '') as error_data,
+    case $1::text
+      when 'title' then coalesce(books.title, '')
+      when 'id' then lpad(books.id::text, 10, '0')
+      when 'discovered' then to_char(books.discovered_at, 'YYYYMMDDHH24MISSUS')
+      else coalesce(books.update_date, '')
+    end::text as sort_key
+  from books left join writers on books.writer_id=writers.id 
+    left join errors on books.site=errors.site and books.id=errors.id
+    left join genres on books.site=genres.site and books.type=genres.type
+  where books.is_downloaded=true and
+    ($2::text = '' or coalesce(genres.genre, 'other') = $2::text)
+  order by books.site, books.id desc, books.hash_code desc 
+  limit $3 offset RANDOM() * 
+  greatest(
+    (select count(*) - $3
+    from books as bks left join genres as gns on bks.site=gns.site and bks.type=gns.type
+    where bks.is_downloaded=true and
+    ($2::text = '' or coalesce(gns.genre, 'other') = $2::text)), 0
+  )
+)
+select sampled.site, sampled.id, sampled.hash_code, sampled.title,
+  sampled.writer_id, sampled.writer_name, sampled.type,
+  sampled.update_date, sampled.update_chapter,
+  sampled.status, sampled.is_downloaded, sampled.error_data
+from sampled
+order by
+  case when $1::text = 'title' then sampled.sort_key end asc,
+  sampled.sort_key desc, sampled.site desc, sampled.id desc, sampled.hash_code desc
+`
+
+type ListRandomBooksParams struct {
+	SortBy     string
+	Genre      string
+	LimitCount interface{}
+}
+
+type ListRandomBooksRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ErrorData     string
+}
+
+func (q *Queries) ListRandomBooks(ctx context.Context, arg ListRandomBooksParams) ([]ListRandomBooksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRandomBooks, arg.SortBy, arg.Genre, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRandomBooksRow
+	for rows.Next() {
+		var i ListRandomBooksRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ErrorData,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnmappedBookTypes = `-- name: ListUnmappedBookTypes :many
+select distinct books.type::text as type
+from books left join genres on books.site=genres.site and books.type=genres.type
+where books.site=$1 and books.type is not null and books.type != '' and
+  genres.genre is null
+`
+
+func (q *Queries) ListUnmappedBookTypes(ctx context.Context, site string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUnmappedBookTypes, site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var type_ string
+		if err := rows.Scan(&type_); err != nil {
+			return nil, err
+		}
+		items = append(items, type_)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkCandidates = `-- name: ListWorkCandidates :many
+select id, title, writer, title_key, writer_key from works where title_key=$1 or writer_key=$2
+order by id
+`
+
+type ListWorkCandidatesParams struct {
+	TitleKey  string
+	WriterKey string
+}
+
+func (q *Queries) ListWorkCandidates(ctx context.Context, arg ListWorkCandidatesParams) ([]Work, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkCandidates, arg.TitleKey, arg.WriterKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Work
+	for rows.Next() {
+		var i Work
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Writer,
+			&i.TitleKey,
+			&i.WriterKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWritersByBookCount = `-- name: ListWritersByBookCount :many
+select writers.id, coalesce(writers.name, '') as name,
+  count(distinct (books.site, books.id)) as book_count,
+  coalesce(max(books.update_date), '')::text as latest_update
+from writers join books on books.writer_id=writers.id
+where books.status != 'ERROR' and (
+  cardinality($1::text[]) = 0 or
+  writers.name like any($1::text[])
+)
+group by writers.id
+having not $2::bool or (
+  not $3::bool and (count(distinct (books.site, books.id)), -writers.id) < (
+    $4::bigint,
+    -$5::integer
+  )
+) or (
+  $3::bool and (count(distinct (books.site, books.id)), -writers.id) > (
+    $4::bigint,
+    -$5::integer
+  )
+)
+order by
+  case when $3::bool then count(distinct (books.site, books.id)) end asc,
+  case when $3::bool then writers.id end desc,
+  count(distinct (books.site, books.id)) desc, writers.id asc
+limit $6
+`
+
+type ListWritersByBookCountParams struct {
+	Names           []string
+	HasCursor       bool
+	Backward        bool
+	CursorBookCount int64
+	CursorID        int32
+	LimitCount      int32
+}
+
+type ListWritersByBookCountRow struct {
+	ID           int32
+	Name         string
+	BookCount    int64
+	LatestUpdate string
+}
+
+// writer listings are one per order. writers are listed from the cursor in
+// descending order of the keys, or ascending if the cursor is backward. id is
+// negated so writers of the same keys are listed by id
+func (q *Queries) ListWritersByBookCount(ctx context.Context, arg ListWritersByBookCountParams) ([]ListWritersByBookCountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWritersByBookCount,
+		pq.Array(arg.Names),
+		arg.HasCursor,
+		arg.Backward,
+		arg.CursorBookCount,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWritersByBookCountRow
+	for rows.Next() {
+		var i ListWritersByBookCountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.BookCount,
+			&i.LatestUpdate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWritersByLatestUpdate = `-- name: ListWritersByLatestUpdate :many
+select writers.id, coalesce(writers.name, '') as name,
+  count(distinct (books.site, books.id)) as book_count,
+  coalesce(max(books.update_date), '')::text as latest_update
+from writers join books on books.writer_id=writers.id
+where books.status != 'ERROR' and (
+  cardinality($1::text[]) = 0 or
+  writers.name like any($1::text[])
+)
+group by writers.id
+having not $2::bool or (
+  not $3::bool and (coalesce(max(books.update_date), ''), count(distinct (books.site, books.id)), -writers.id) < (
+    $4::text,
+    $5::bigint,
+    -$6::integer
+  )
+) or (
+  $3::bool and (coalesce(max(books.update_date), ''), count(distinct (books.site, books.id)), -writers.id) > (
+    $4::text,
+    $5::bigint,
+    -$6::integer
+  )
+)
+order by
+  case when $3::bool then coalesce(max(books.update_date), '') end asc,
+  case when $3::bool then count(distinct (books.site, books.id)) end asc,
+  case when $3::bool then writers.id end desc,
+  coalesce(max(books.update_date), '') desc, count(distinct (books.site, books.id)) desc, writers.id asc
+limit $7
+`
+
+type ListWritersByLatestUpdateParams struct {
+	Names              []string
+	HasCursor          bool
+	Backward           bool
+	CursorLatestUpdate string
+	CursorBookCount    int64
+	CursorID           int32
+	LimitCount         int32
+}
+
+type ListWritersByLatestUpdateRow struct {
+	ID           int32
+	Name         string
+	BookCount    int64
+	LatestUpdate string
+}
+
+func (q *Queries) ListWritersByLatestUpdate(ctx context.Context, arg ListWritersByLatestUpdateParams) ([]ListWritersByLatestUpdateRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWritersByLatestUpdate,
+		pq.Array(arg.Names),
+		arg.HasCursor,
+		arg.Backward,
+		arg.CursorLatestUpdate,
+		arg.CursorBookCount,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWritersByLatestUpdateRow
+	for rows.Next() {
+		var i ListWritersByLatestUpdateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.BookCount,
+			&i.LatestUpdate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWritersWithoutChecksum = `-- name: ListWritersWithoutChecksum :many
+select id, coalesce(name, '') as name from writers
+where checksum='' or checksum is null
+order by id
+`
+
+type ListWritersWithoutChecksumRow struct {
+	ID   int32
+	Name string
+}
+
+func (q *Queries) ListWritersWithoutChecksum(ctx context.Context) ([]ListWritersWithoutChecksumRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWritersWithoutChecksum)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWritersWithoutChecksumRow
+	for rows.Next() {
+		var i ListWritersWithoutChecksumRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveWorkBooks = `-- name: MoveWorkBooks :exec
+update books set work_id=$1 where work_id=$2
+`
+
+type MoveWorkBooksParams struct {
+	WorkID   sql.NullInt32
+	WorkID_2 sql.NullInt32
+}
+
+func (q *Queries) MoveWorkBooks(ctx context.Context, arg MoveWorkBooksParams) error {
+	_, err := q.db.ExecContext(ctx, moveWorkBooks, arg.WorkID, arg.WorkID_2)
+	return err
+}
+
+const nonErrorBooksStat = `-- name: NonErrorBooksStat :one
+select max(id) as latest_success_id from books where status<>'ERROR' and site=$1
+`
+
+func (q *Queries) NonErrorBooksStat(ctx context.Context, site string) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, nonErrorBooksStat, site)
+	var latest_success_id interface{}
+	err := row.Scan(&latest_success_id)
+	return latest_success_id, err
+}
+
+const saveSiteHealth = `-- name: SaveSiteHealth :exec
+insert into site_health (site, status, phase, checked, failed, error, checked_at)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (site) do update set status=$2, phase=$3, checked=$4, failed=$5, error=$6, checked_at=$7
+`
+
+type SaveSiteHealthParams struct {
+	Site      string
+	Status    string
+	Phase     string
+	Checked   int32
+	Failed    int32
+	Error     string
+	CheckedAt time.Time
+}
+
+func (q *Queries) SaveSiteHealth(ctx context.Context, arg SaveSiteHealthParams) error {
+	_, err := q.db.ExecContext(ctx, saveSiteHealth,
+		arg.Site,
+		arg.Status,
+		arg.Phase,
+		arg.Checked,
+		arg.Failed,
+		arg.Error,
+		arg.CheckedAt,
+	)
+	return err
+}
+
+const updateBook = `-- name: UpdateBook :one
+Update books SET 
+title=$4, writer_id=$5, writer_checksum=$12, type=$6, update_date=$7, update_chapter=$8,
+status=$9, is_downloaded=$10, checksum=$11
+WHERE site=$1 and id=$2 and hash_code=$3
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, work_id, discovered_at
+`
+
+type UpdateBookParams struct {
+	Site           string
+	ID             int32
+	HashCode       int32
+	Title          sql.NullString
+	WriterID       sql.NullInt32
+	Type           sql.NullString
+	UpdateDate     sql.NullString
+	UpdateChapter  sql.NullString
 	Status         string
 	IsDownloaded   bool
 	Checksum       sql.NullString
@@ -992,10 +2815,54 @@ func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, e
 		&i.IsDownloaded,
 		&i.Checksum,
 		&i.WriterChecksum,
+		&i.WorkID,
+		&i.DiscoveredAt,
 	)
 	return i, err
 }
 
+const updateBookChecksum = `-- name: UpdateBookChecksum :exec
+update books set checksum=$4 where site=$1 and id=$2 and hash_code=$3
+`
+
+type UpdateBookChecksumParams struct {
+	Site     string
+	ID       int32
+	HashCode int32
+	Checksum sql.NullString
+}
+
+func (q *Queries) UpdateBookChecksum(ctx context.Context, arg UpdateBookChecksumParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookChecksum,
+		arg.Site,
+		arg.ID,
+		arg.HashCode,
+		arg.Checksum,
+	)
+	return err
+}
+
+const updateBookWork = `-- name: UpdateBookWork :exec
+update books set work_id=$4 where site=$1 and id=$2 and hash_code=$3
+`
+
+type UpdateBookWorkParams struct {
+	Site     string
+	ID       int32
+	HashCode int32
+	WorkID   sql.NullInt32
+}
+
+func (q *Queries) UpdateBookWork(ctx context.Context, arg UpdateBookWorkParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookWork,
+		arg.Site,
+		arg.ID,
+		arg.HashCode,
+		arg.WorkID,
+	)
+	return err
+}
+
 const updateBooksStatus = `-- name: UpdateBooksStatus :exec
 update books set is_downloaded=false, status='END' 
 where (update_date < $1 or 
@@ -1024,6 +2891,20 @@ func (q *Queries) UpdateBooksStatus(ctx context.Context, updateDate sql.NullStri
 	return err
 }
 
+const updateWriterChecksum = `-- name: UpdateWriterChecksum :exec
+update writers set checksum=$2 where id=$1
+`
+
+type UpdateWriterChecksumParams struct {
+	ID       int32
+	Checksum sql.NullString
+}
+
+func (q *Queries) UpdateWriterChecksum(ctx context.Context, arg UpdateWriterChecksumParams) error {
+	_, err := q.db.ExecContext(ctx, updateWriterChecksum, arg.ID, arg.Checksum)
+	return err
+}
+
 const writersStat = `-- name: WritersStat :one
 select count(distinct writer_id) as writer_count 
 from books where site=$1
@@ -0,0 +1,205 @@
+package configured
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+)
+
+func (p *VendorService) ParseDoc(body string) (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(strings.NewReader(body))
+}
+
+// selectionContent returns the attr or text of selection with unwanted
+// content removed
+func selectionContent(s *goquery.Selection, conf config.GoquerySelectorConfig) string {
+	var content string
+	if conf.Attr != "" {
+		content = s.AttrOr(conf.Attr, "")
+	} else {
+		content = vendor.GetGoqueryContentWithChildren(s)
+	}
+
+	for _, unwanted := range conf.UnwantedContent {
+		content = strings.ReplaceAll(content, unwanted, "")
+	}
+
+	return strings.TrimSpace(content)
+}
+
+// findContent joins the content of all elements matching the selector
+func findContent(doc *goquery.Document, conf config.GoquerySelectorConfig) string {
+	if conf.Selector == "" {
+		return ""
+	}
+
+	var contents []string
+	doc.Find(conf.Selector).Each(func(_ int, s *goquery.Selection) {
+		if content := selectionContent(s, conf); content != "" {
+			contents = append(contents, content)
+		}
+	})
+
+	return strings.Join(contents, "\n")
+}
+
+func (p *VendorService) ParseBook(body string) (*vendor.BookInfo, error) {
+	doc, docErr := p.ParseDoc(body)
+	if docErr != nil {
+		return nil, fmt.Errorf("parse body fail: %w", docErr)
+	}
+
+	var parseErr error
+
+	// parse title
+	title := findContent(doc, p.selectors.Title)
+	if title == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookTitleNotFound)
+	}
+
+	// parse writer
+	writer := findContent(doc, p.selectors.Writer)
+	if writer == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookWriterNotFound)
+	}
+
+	// parse type
+	bookType := findContent(doc, p.selectors.BookType)
+	if bookType == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookTypeNotFound)
+	}
+
+	// parse date
+	date := findContent(doc, p.selectors.LastUpdate)
+	if date == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookDateNotFound)
+	}
+
+	// parse chapter
+	chapter := findContent(doc, p.selectors.LastChapter)
+	if chapter == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookChapterNotFound)
+	}
+
+	if parseErr != nil {
+		parseErr = errors.Join(parseErr, vendor.ErrFieldsNotFound)
+	}
+
+	return &vendor.BookInfo{
+		Title:         title,
+		Writer:        writer,
+		Type:          bookType,
+		UpdateDate:    date,
+		UpdateChapter: chapter,
+	}, parseErr
+}
+
+func (p *VendorService) ParseChapterList(_, body string) (vendor.ChapterList, error) {
+	doc, docErr := p.ParseDoc(body)
+	if docErr != nil {
+		return nil, fmt.Errorf("parse body fail: %w", docErr)
+	}
+
+	titleSelector := p.selectors.BookChapterTitle
+	if titleSelector.Selector == "" {
+		titleSelector = config.GoquerySelectorConfig{Selector: p.selectors.BookChapterURL.Selector}
+	}
+
+	urls := doc.Find(p.selectors.BookChapterURL.Selector)
+	titles := doc.Find(titleSelector.Selector)
+
+	var chapterList vendor.ChapterList
+	var parseErr error
+	urls.Each(func(i int, s *goquery.Selection) {
+		url := selectionContent(s, p.selectors.BookChapterURL)
+		if url == "" {
+			parseErr = errors.Join(
+				parseErr,
+				fmt.Errorf("parse chapter url fail: %d, %w", i, vendor.ErrChapterListUrlNotFound),
+			)
+		}
+
+		title := selectionContent(titles.Eq(i), titleSelector)
+		if title == "" {
+			parseErr = errors.Join(
+				parseErr,
+				fmt.Errorf("parse chapter url fail: %d, %w", i, vendor.ErrChapterListTitleNotFound),
+			)
+		}
+
+		chapterList = append(chapterList, vendor.ChapterListInfo{
+			URL:   p.ChapterURL(url),
+			Title: title,
+		})
+	})
+
+	if len(chapterList) == 0 {
+		return nil, vendor.ErrChapterListEmpty
+	}
+
+	if parseErr != nil {
+		parseErr = errors.Join(parseErr, vendor.ErrFieldsNotFound)
+	}
+
+	return chapterList, parseErr
+}
+
+func (p *VendorService) ParseChapter(body string) (*vendor.ChapterInfo, error) {
+	doc, docErr := p.ParseDoc(body)
+	if docErr != nil {
+		return nil, fmt.Errorf("parse body fail: %w", docErr)
+	}
+
+	var parseErr error
+
+	// parse title
+	title := findContent(doc, p.selectors.ChapterTitle)
+	if title == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrChapterTitleNotFound)
+	}
+
+	// parse content
+	content := findContent(doc, p.selectors.ChapterContent)
+	if content == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrChapterContentNotFound)
+	}
+
+	if parseErr != nil {
+		parseErr = errors.Join(parseErr, vendor.ErrFieldsNotFound)
+	}
+
+	return &vendor.ChapterInfo{
+		Title: title,
+		Body:  content,
+	}, parseErr
+}
+
+func (p *VendorService) IsAvailable(body string) bool {
+	return strings.Contains(body, p.available.CheckString)
+}
+
+func (p *VendorService) FindMissingIds(ids []int) []int {
+	var missingIDs []int
+
+	sort.Ints(ids)
+
+	idPointer, i := 0, 1
+	for idPointer < len(ids) && ids[len(ids)-1] > i {
+		if i == ids[idPointer] {
+			i++
+			idPointer++
+		} else if i > ids[idPointer] {
+			idPointer++
+		} else if i < ids[idPointer] {
+			missingIDs = append(missingIDs, i)
+			i++
+		}
+	}
+
+	return missingIDs
+}
@@ -0,0 +1,227 @@
+package configured
+
+import (
+	"testing"
+
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ParseBook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		want      *vendor.BookInfo
+		wantError error
+	}{
+		{
+			name: "happy flow",
+			body: `<data>
+				<meta property="title" content="book name" />
+				<span class="writer">作者：author</span>
+				<span class="type">type</span>
+				<span class="date">date</span>
+				<span class="chapter">chapter name</span>
+			</data>`,
+			want: &vendor.BookInfo{
+				Title: "book name", Writer: "author", Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter name",
+			},
+			wantError: nil,
+		},
+		{
+			name: "title not found",
+			body: `<data>
+				<span class="writer">author</span>
+				<span class="type">type</span>
+				<span class="date">date</span>
+				<span class="chapter">chapter name</span>
+			</data>`,
+			want: &vendor.BookInfo{
+				Writer: "author", Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter name",
+			},
+			wantError: vendor.ErrBookTitleNotFound,
+		},
+		{
+			name:      "all fields not found",
+			body:      "<data></data>",
+			want:      &vendor.BookInfo{},
+			wantError: vendor.ErrFieldsNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewVendorService("test", testSiteConfig())
+			got, err := p.ParseBook(test.body)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestParser_ParseChapterList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		want      vendor.ChapterList
+		wantError error
+	}{
+		{
+			name: "happy flow",
+			body: `<ul>
+				<li><a href="/book/1/1.html">chapter 1</a></li>
+				<li><a href="https://other.com/book/1/2.html">chapter 2</a></li>
+			</ul>`,
+			want: vendor.ChapterList{
+				{URL: "https://example.com/book/1/1.html", Title: "chapter 1"},
+				{URL: "https://other.com/book/1/2.html", Title: "chapter 2"},
+			},
+			wantError: nil,
+		},
+		{
+			name: "title not found",
+			body: `<ul>
+				<li><a href="/book/1/1.html"></a></li>
+			</ul>`,
+			want: vendor.ChapterList{
+				{URL: "https://example.com/book/1/1.html", Title: ""},
+			},
+			wantError: vendor.ErrChapterListTitleNotFound,
+		},
+		{
+			name:      "empty chapter list",
+			body:      "<ul></ul>",
+			want:      nil,
+			wantError: vendor.ErrChapterListEmpty,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewVendorService("test", testSiteConfig())
+			got, err := p.ParseChapterList("1", test.body)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestParser_ParseChapter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		body      string
+		want      *vendor.ChapterInfo
+		wantError error
+	}{
+		{
+			name: "happy flow with multiple paragraphs",
+			body: `<data>
+				<h1>chapter name</h1>
+				<div id="content"><p>line 1</p><p>廣告</p><p>line 2</p></div>
+			</data>`,
+			want: &vendor.ChapterInfo{
+				Title: "chapter name", Body: "line 1\nline 2",
+			},
+			wantError: nil,
+		},
+		{
+			name: "body not found",
+			body: `<data><h1>chapter name</h1></data>`,
+			want: &vendor.ChapterInfo{
+				Title: "chapter name", Body: "",
+			},
+			wantError: vendor.ErrChapterContentNotFound,
+		},
+		{
+			name:      "all fields not found",
+			body:      "<data></data>",
+			want:      &vendor.ChapterInfo{},
+			wantError: vendor.ErrFieldsNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewVendorService("test", testSiteConfig())
+			got, err := p.ParseChapter(test.body)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestParser_IsAvailable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{
+			name: "return true",
+			body: "<h1>example</h1>",
+			want: true,
+		},
+		{
+			name: "return false",
+			body: "",
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewVendorService("test", testSiteConfig())
+			got := p.IsAvailable(test.body)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParser_FindMissingIds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ids  []int
+		want []int
+	}{
+		{
+			name: "no missing ids",
+			ids:  []int{4, 2, 3, 1, 5},
+			want: nil,
+		},
+		{
+			name: "some id is missing",
+			ids:  []int{3, 5, 1},
+			want: []int{2, 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := NewVendorService("test", testSiteConfig())
+			got := p.FindMissingIds(test.ids)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
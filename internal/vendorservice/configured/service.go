@@ -0,0 +1,43 @@
+package configured
+
+import (
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/repo"
+	"github.com/htchan/BookSpider/internal/service"
+	serviceV1 "github.com/htchan/BookSpider/internal/service/v1"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"golang.org/x/sync/semaphore"
+)
+
+// VendorService builds urls and parses pages by the urls and goquery
+// selectors in site config, so a site can be supported without code
+type VendorService struct {
+	name      string
+	urls      config.URLConfig
+	selectors config.GoquerySelectorsConfig
+	available config.AvailabilityConfig
+}
+
+var _ vendor.VendorService = (*VendorService)(nil)
+
+func NewVendorService(name string, conf config.SiteConfig) *VendorService {
+	return &VendorService{
+		name:      name,
+		urls:      conf.URL,
+		selectors: conf.GoquerySelectorsConfig,
+		available: conf.AvailabilityConfig,
+	}
+}
+
+// IsConfigured returns true if site config has enough urls and selectors
+// to build a configured vendor
+func IsConfigured(conf config.SiteConfig) bool {
+	return conf.URL.Base != "" && conf.URL.Download != "" &&
+		conf.GoquerySelectorsConfig.Title.Selector != "" &&
+		conf.GoquerySelectorsConfig.BookChapterURL.Selector != "" &&
+		conf.GoquerySelectorsConfig.ChapterContent.Selector != ""
+}
+
+func NewService(name string, rpo repo.Repository, sema *semaphore.Weighted, conf config.SiteConfig) service.Service {
+	return serviceV1.NewService(name, rpo, NewVendorService(name, conf), sema, conf)
+}
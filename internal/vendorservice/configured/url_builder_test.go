@@ -0,0 +1,172 @@
+package configured
+
+import (
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSiteConfig() config.SiteConfig {
+	return config.SiteConfig{
+		URL: config.URLConfig{
+			Base:          "https://example.com/book/%v/",
+			Download:      "https://example.com/book/%v/chapters/",
+			ChapterPrefix: "https://example.com/",
+		},
+		GoquerySelectorsConfig: config.GoquerySelectorsConfig{
+			Title:            config.GoquerySelectorConfig{Selector: `meta[property="title"]`, Attr: "content"},
+			Writer:           config.GoquerySelectorConfig{Selector: "span.writer", UnwantedContent: []string{"作者："}},
+			BookType:         config.GoquerySelectorConfig{Selector: "span.type"},
+			LastUpdate:       config.GoquerySelectorConfig{Selector: "span.date"},
+			LastChapter:      config.GoquerySelectorConfig{Selector: "span.chapter"},
+			BookChapterURL:   config.GoquerySelectorConfig{Selector: "ul>li>a", Attr: "href"},
+			BookChapterTitle: config.GoquerySelectorConfig{Selector: "ul>li>a"},
+			ChapterTitle:     config.GoquerySelectorConfig{Selector: "h1"},
+			ChapterContent:   config.GoquerySelectorConfig{Selector: "div#content>p", UnwantedContent: []string{"廣告"}},
+		},
+		AvailabilityConfig: config.AvailabilityConfig{URL: "https://example.com", CheckString: "example"},
+	}
+}
+
+func TestVendorService_BookURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		bkID string
+		want string
+	}{
+		{
+			name: "int book id",
+			bkID: "1234",
+			want: "https://example.com/book/1234/",
+		},
+		{
+			name: "non int book id",
+			bkID: "abcd",
+			want: "https://example.com/book/abcd/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			serv := NewVendorService("test", testSiteConfig())
+			got := serv.BookURL(test.bkID)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVendorService_ChapterListURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		bkID string
+		want string
+	}{
+		{
+			name: "int book id",
+			bkID: "1234",
+			want: "https://example.com/book/1234/chapters/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			serv := NewVendorService("test", testSiteConfig())
+			got := serv.ChapterListURL(test.bkID)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVendorService_ChapterURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		resources []string
+		want      string
+	}{
+		{
+			name:      "single full http resource input",
+			resources: []string{"http://testing.com"},
+			want:      "http://testing.com",
+		},
+		{
+			name:      "single uri resource input with slash",
+			resources: []string{"/testing"},
+			want:      "https://example.com/testing",
+		},
+		{
+			name:      "single url resource input without slash",
+			resources: []string{"testing"},
+			want:      "testing",
+		},
+		{
+			name:      "zero resources input",
+			resources: []string{},
+			want:      "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			serv := NewVendorService("test", testSiteConfig())
+			got := serv.ChapterURL(test.resources...)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVendorService_AvailabilityURL(t *testing.T) {
+	t.Parallel()
+
+	serv := NewVendorService("test", testSiteConfig())
+	assert.Equal(t, "https://example.com", serv.AvailabilityURL())
+}
+
+func TestIsConfigured(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		conf config.SiteConfig
+		want bool
+	}{
+		{
+			name: "urls and selectors configured",
+			conf: testSiteConfig(),
+			want: true,
+		},
+		{
+			name: "selectors not configured",
+			conf: config.SiteConfig{URL: testSiteConfig().URL},
+			want: false,
+		},
+		{
+			name: "empty config",
+			conf: config.SiteConfig{},
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, IsConfigured(test.conf))
+		})
+	}
+}
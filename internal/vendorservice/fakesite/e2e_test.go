@@ -0,0 +1,430 @@
+package fakesite_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/htchan/BookSpider/internal/vendorservice/configured"
+	"github.com/htchan/BookSpider/internal/vendorservice/fakesite"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/semaphore"
+)
+
+// memoryRepo keeps books of a site in memory with the behaviour Process
+// relies on. methods not used by Process panic by the nil embedded interface
+type memoryRepo struct {
+	repo.Repository
+
+	lock   sync.Mutex
+	books  map[int]model.Book
+	health *model.SiteHealth
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{books: make(map[int]model.Book)}
+}
+
+func (r *memoryRepo) book(id int) (model.Book, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	bk, ok := r.books[id]
+	return bk, ok
+}
+
+func (r *memoryRepo) save(bk *model.Book) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.books[bk.ID] = *bk
+	return nil
+}
+
+func (r *memoryRepo) filter(match func(model.Book) bool) <-chan model.Book {
+	r.lock.Lock()
+	ids := make([]int, 0, len(r.books))
+	for id, bk := range r.books {
+		if match(bk) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	bks := make([]model.Book, len(ids))
+	for i, id := range ids {
+		bks[i] = r.books[id]
+	}
+	r.lock.Unlock()
+
+	ch := make(chan model.Book, len(bks))
+	for _, bk := range bks {
+		ch <- bk
+	}
+	close(ch)
+
+	return ch
+}
+
+func (r *memoryRepo) CreateBook(_ context.Context, bk *model.Book) error { return r.save(bk) }
+func (r *memoryRepo) UpdateBook(_ context.Context, bk *model.Book) error { return r.save(bk) }
+
+func (r *memoryRepo) SaveWriter(context.Context, *model.Writer) error { return nil }
+
+func (r *memoryRepo) SaveError(_ context.Context, bk *model.Book, err error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if stored, ok := r.books[bk.ID]; ok {
+		stored.Error = err
+		r.books[bk.ID] = stored
+	}
+	return nil
+}
+
+func (r *memoryRepo) FindBookById(_ context.Context, _ string, id int) (*model.Book, error) {
+	bk, ok := r.book(id)
+	if !ok {
+		return nil, repo.ErrBookNotExist
+	}
+	return &bk, nil
+}
+
+func (r *memoryRepo) FindBooksForUpdate(context.Context, string) (<-chan model.Book, error) {
+	return r.filter(func(bk model.Book) bool { return bk.Status != model.StatusError }), nil
+}
+
+func (r *memoryRepo) FindBooksForDownload(context.Context, string) (<-chan model.Book, error) {
+	return r.filter(func(bk model.Book) bool { return bk.Status == model.StatusEnd && !bk.IsDownloaded }), nil
+}
+
+func (r *memoryRepo) FindAllBooks(context.Context, string) (<-chan model.Book, error) {
+	return r.filter(func(model.Book) bool { return true }), nil
+}
+
+func (r *memoryRepo) FindBooksWithoutWork(context.Context, string) (<-chan model.Book, error) {
+	return r.filter(func(model.Book) bool { return false }), nil
+}
+
+func (r *memoryRepo) FindAllBookIDs(context.Context, string) ([]int, error) {
+	var ids []int
+	for bk := range r.filter(func(model.Book) bool { return true }) {
+		ids = append(ids, bk.ID)
+	}
+	return ids, nil
+}
+
+// UpdateBooksStatus ends the books not updated since last year like the db does
+func (r *memoryRepo) UpdateBooksStatus(context.Context) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	lastYear := strconv.Itoa(time.Now().Year() - 1)
+	for id, bk := range r.books {
+		if bk.Status == model.StatusInProgress && bk.UpdateDate < lastYear {
+			bk.Status = model.StatusEnd
+			r.books[id] = bk
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepo) SyncGenres(context.Context, string) (int, error) { return 0, nil }
+
+func (r *memoryRepo) SaveSiteHealth(_ context.Context, health *model.SiteHealth) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.health = health
+	return nil
+}
+
+func (r *memoryRepo) Stats(context.Context, string) repo.Summary {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	summary := repo.Summary{Health: r.health}
+	for id, bk := range r.books {
+		summary.BookCount++
+		summary.MaxBookID = max(summary.MaxBookID, id)
+		if bk.Status != model.StatusError {
+			summary.LatestSuccessID = max(summary.LatestSuccessID, id)
+		}
+	}
+	return summary
+}
+
+func testDataset(bookCount int) fakesite.Dataset {
+	var dataset fakesite.Dataset
+	for id := 1; id <= bookCount; id++ {
+		dataset.Books = append(dataset.Books, fakesite.Book{
+			ID: id, Title: fmt.Sprintf("書名%d", id), Writer: fmt.Sprintf("作者%d", id),
+			Type: "玄幻", UpdateDate: "2020-01-01",
+			Chapters: []fakesite.Chapter{
+				{Title: "第一章 開始", Content: fmt.Sprintf("第%d本書的第一行\n第二行", id)},
+				{Title: "第二章 中段", Content: "中段內容"},
+				{Title: "第三章 完結", Content: "全書完"},
+			},
+		})
+	}
+
+	return dataset
+}
+
+type injectedFault struct {
+	pathPrefix string
+	count      int
+	fault      fakesite.Fault
+}
+
+// runProcess runs Process of a configured vendor pointing to server, it
+// fails the test instead of hanging if breaker and semaphores deadlock
+func runProcess(t *testing.T, server *fakesite.Server, rpo *memoryRepo, storage string, configure func(*config.SiteConfig)) error {
+	t.Helper()
+
+	conf := server.SiteConfig(storage)
+	if configure != nil {
+		configure(&conf)
+	}
+	serv := configured.NewService("fakesite", rpo, semaphore.NewWeighted(10), conf)
+
+	result := make(chan error, 1)
+	go func() { result <- serv.Process(context.Background()) }()
+
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(30 * time.Second):
+		t.Fatal("process does not complete in 30s")
+		return nil
+	}
+}
+
+func assertBooksDownloaded(t *testing.T, rpo *memoryRepo, storage string, dataset fakesite.Dataset) {
+	t.Helper()
+
+	for _, want := range dataset.Books {
+		bk, ok := rpo.book(want.ID)
+		if !assert.True(t, ok, "book %d not found", want.ID) {
+			continue
+		}
+
+		assert.Equal(t, want.Title, bk.Title)
+		assert.Equal(t, want.Writer, bk.Writer.Name)
+		assert.Equal(t, want.UpdateChapter(), bk.UpdateChapter)
+		assert.EqualValues(t, model.StatusEnd, bk.Status)
+		assert.True(t, bk.IsDownloaded, "book %d not downloaded", want.ID)
+
+		content, err := os.ReadFile(filepath.Join(storage, fmt.Sprintf("%d-v%s.txt", bk.ID, bk.FormatHashCode())))
+		if assert.NoError(t, err) {
+			for _, chapter := range want.Chapters {
+				assert.Contains(t, string(content), chapter.Title)
+				for _, line := range strings.Split(chapter.Content, "\n") {
+					assert.Contains(t, string(content), line)
+				}
+			}
+		}
+	}
+}
+
+func TestProcess_FakeSite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		decodeMethod   client.DecodeMethod
+		faults         []injectedFault
+		requestTimeout time.Duration
+	}{
+		{
+			name:         "utf8 site",
+			decodeMethod: client.DecodeMethodUTF8,
+		},
+		{
+			name:         "gbk site",
+			decodeMethod: client.DecodeMethodGBK,
+		},
+		{
+			name:         "big5 site",
+			decodeMethod: client.DecodeMethodBig5,
+		},
+		{
+			name:         "retry server error of book page",
+			decodeMethod: client.DecodeMethodUTF8,
+			faults:       []injectedFault{{pathPrefix: "/book/1/", count: 2, fault: fakesite.FaultServerError}},
+		},
+		{
+			name:         "retry rate limited chapters",
+			decodeMethod: client.DecodeMethodGBK,
+			faults:       []injectedFault{{pathPrefix: "/book/2/chapter/", count: 2, fault: fakesite.FaultRateLimit(0)}},
+		},
+		{
+			name:           "retry timeout of chapter list",
+			decodeMethod:   client.DecodeMethodBig5,
+			faults:         []injectedFault{{pathPrefix: "/book/3/chapters/", count: 1, fault: fakesite.FaultTimeout(time.Second)}},
+			requestTimeout: 200 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dataset := testDataset(3)
+			server := fakesite.NewServer(dataset, test.decodeMethod)
+			defer server.Close()
+			for _, fault := range test.faults {
+				server.InjectFault(fault.pathPrefix, fault.count, fault.fault)
+			}
+
+			rpo, storage := newMemoryRepo(), t.TempDir()
+			err := runProcess(t, server, rpo, storage, func(conf *config.SiteConfig) {
+				if test.requestTimeout > 0 {
+					conf.RequestTimeout = test.requestTimeout
+				}
+			})
+			assert.NoError(t, err)
+
+			assertBooksDownloaded(t, rpo, storage, dataset)
+		})
+	}
+}
+
+func TestProcess_FakeSite_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	server := fakesite.NewServer(testDataset(3), client.DecodeMethodUTF8)
+	defer server.Close()
+	server.InjectFault("/", 0, fakesite.FaultServerError)
+
+	rpo := newMemoryRepo()
+	err := runProcess(t, server, rpo, t.TempDir(), nil)
+	assert.Error(t, err)
+
+	// nothing is explored if the site is unavailable
+	assert.Equal(t, 0, server.Requests("/book/1/"))
+}
+
+// TestProcess_FakeSite_CircuitBreaker covers the breaker holding and then
+// releasing the vendor semaphore. a run with persistent server errors must
+// complete instead of deadlock, and the next run must recover all books
+func TestProcess_FakeSite_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	dataset := testDataset(5)
+	server := fakesite.NewServer(dataset, client.DecodeMethodUTF8)
+	defer server.Close()
+
+	rpo, storage := newMemoryRepo(), t.TempDir()
+	err := runProcess(t, server, rpo, storage, nil)
+	assert.NoError(t, err)
+	assertBooksDownloaded(t, rpo, storage, dataset)
+
+	// every book is updated again in next run, fail them all
+	bookRequests := server.Requests("/book/1/")
+	server.InjectFault("/book/", 30, fakesite.FaultServerError)
+
+	err = runProcess(t, server, rpo, storage, nil)
+	assert.NoError(t, err)
+	assert.Greater(t, server.Requests("/book/1/"), bookRequests)
+
+	for _, want := range dataset.Books {
+		bk, ok := rpo.book(want.ID)
+		assert.True(t, ok)
+		assert.Equal(t, want.Title, bk.Title)
+	}
+
+	// the site works again
+	server.ClearFaults()
+	err = runProcess(t, server, rpo, storage, nil)
+	assert.NoError(t, err)
+	assertBooksDownloaded(t, rpo, storage, dataset)
+}
+
+// TestProcess_FakeSite_SelectorBroken covers a vendor html change. the canary
+// check stops the run before any book is updated, and the parse monitor stops
+// the phase if the canary books still parse
+func TestProcess_FakeSite_SelectorBroken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		faultPrefix string
+		canaryIDs   []int
+		wantPhase   string
+	}{
+		{
+			name:        "canary check",
+			faultPrefix: "/book/",
+			canaryIDs:   []int{1},
+			wantPhase:   "canary",
+		},
+		{
+			name:        "parse monitor of update",
+			faultPrefix: "/book/",
+			wantPhase:   "update",
+		},
+		{
+			name:        "canary check of chapter list",
+			faultPrefix: "/book/2/chapters/",
+			canaryIDs:   []int{2},
+			wantPhase:   "canary",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			dataset := testDataset(20)
+			server := fakesite.NewServer(dataset, client.DecodeMethodUTF8)
+			defer server.Close()
+
+			configure := func(conf *config.SiteConfig) {
+				conf.HealthConfig = config.HealthConfig{
+					CanaryBookIDs: test.canaryIDs, MaxParseFailureRate: 0.5, MinParseSamples: 5,
+				}
+			}
+
+			rpo := newMemoryRepo()
+			err := runProcess(t, server, rpo, t.TempDir(), configure)
+			assert.NoError(t, err)
+			assert.Equal(t, model.HealthHealthy, rpo.health.Status)
+
+			// the vendor changes layout, the next run must keep the books
+			server.InjectFault(test.faultPrefix, 0, fakesite.FaultLayoutChange)
+			bookRequests := server.Requests("/book/20/")
+
+			err = runProcess(t, server, rpo, t.TempDir(), configure)
+			assert.ErrorIs(t, err, serv.ErrSelectorBroken)
+
+			if assert.NotNil(t, rpo.health) {
+				assert.Equal(t, model.HealthBroken, rpo.health.Status)
+				assert.Equal(t, test.wantPhase, rpo.health.Phase)
+			}
+			if test.wantPhase == "canary" && test.faultPrefix == "/book/" {
+				assert.Equal(t, bookRequests, server.Requests("/book/20/"))
+			}
+
+			for _, want := range dataset.Books {
+				bk, ok := rpo.book(want.ID)
+				if assert.True(t, ok) {
+					assert.Equal(t, want.Title, bk.Title)
+					assert.NotEqualValues(t, model.StatusError, bk.Status)
+				}
+			}
+		})
+	}
+}
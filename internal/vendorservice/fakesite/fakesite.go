@@ -0,0 +1,336 @@
+// Package fakesite serves a fake novel site from a small dataset, so the
+// vendor pipeline can be tested end to end without network access
+package fakesite
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// CheckString is shown in the availability page of fake site
+const CheckString = "fake novel site"
+
+type Chapter struct {
+	Title   string
+	Content string
+}
+
+type Book struct {
+	ID         int
+	Title      string
+	Writer     string
+	Type       string
+	UpdateDate string
+	Chapters   []Chapter
+}
+
+// UpdateChapter returns the title of last chapter
+func (bk Book) UpdateChapter() string {
+	if len(bk.Chapters) == 0 {
+		return ""
+	}
+
+	return bk.Chapters[len(bk.Chapters)-1].Title
+}
+
+type Dataset struct {
+	Books []Book
+}
+
+// Fault is the response injected instead of the page. the response is
+// delayed by Delay, which times out client with shorter request timeout.
+// Body replaces the page, e.g. to simulate a vendor html change
+type Fault struct {
+	StatusCode int
+	Delay      time.Duration
+	RetryAfter time.Duration
+	Body       string
+}
+
+var (
+	FaultServerError  = Fault{StatusCode: http.StatusServiceUnavailable}
+	FaultLayoutChange = Fault{Body: "<html><body><div class=\"new-layout\"></div></body></html>"}
+)
+
+func FaultTimeout(delay time.Duration) Fault {
+	return Fault{Delay: delay}
+}
+
+func FaultRateLimit(retryAfter time.Duration) Fault {
+	return Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+type pendingFault struct {
+	pathPrefix string
+	remaining  int
+	fault      Fault
+}
+
+// Server is the fake site. pages are
+//   - /: availability page
+//   - /book/{id}/: book page
+//   - /book/{id}/chapters/: chapter list
+//   - /book/{id}/chapter/{index}.html: chapter
+type Server struct {
+	server   *httptest.Server
+	encoding client.DecodeMethod
+	encoder  *encoding.Encoder
+	books    map[int]Book
+
+	lock     sync.Mutex
+	faults   []*pendingFault
+	requests map[string]int
+}
+
+func NewServer(dataset Dataset, decodeMethod client.DecodeMethod) *Server {
+	s := &Server{
+		encoding: decodeMethod,
+		books:    make(map[int]Book, len(dataset.Books)),
+		requests: make(map[string]int),
+	}
+
+	switch decodeMethod {
+	case client.DecodeMethodGBK:
+		s.encoder = simplifiedchinese.GBK.NewEncoder()
+	case client.DecodeMethodBig5:
+		s.encoder = traditionalchinese.Big5.NewEncoder()
+	}
+
+	for _, bk := range dataset.Books {
+		s.books[bk.ID] = bk
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// InjectFault responds the next count requests of paths starting with
+// pathPrefix by the fault, the fault is kept until ClearFaults if count is
+// not positive. faults are consumed in the order of injection
+func (s *Server) InjectFault(pathPrefix string, count int, fault Fault) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.faults = append(s.faults, &pendingFault{pathPrefix: pathPrefix, remaining: count, fault: fault})
+}
+
+// ClearFaults removes the faults not yet consumed
+func (s *Server) ClearFaults() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns the number of requests received by path
+func (s *Server) Requests(path string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.requests[path]
+}
+
+// TotalRequests returns the number of requests received by server
+func (s *Server) TotalRequests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	total := 0
+	for _, count := range s.requests {
+		total += count
+	}
+
+	return total
+}
+
+// SiteConfig returns the config of a configured vendor pointing to server
+func (s *Server) SiteConfig(storage string) config.SiteConfig {
+	return config.SiteConfig{
+		DecodeMethod:   s.encoding,
+		RequestTimeout: time.Second,
+		Storage:        storage,
+		URL: config.URLConfig{
+			Base:          s.server.URL + "/book/%v/",
+			Download:      s.server.URL + "/book/%v/chapters/",
+			ChapterPrefix: s.server.URL,
+		},
+		MaxExploreError:        3,
+		MaxDownloadConcurrency: 2,
+		GoquerySelectorsConfig: config.GoquerySelectorsConfig{
+			Title:            config.GoquerySelectorConfig{Selector: "h1.title"},
+			Writer:           config.GoquerySelectorConfig{Selector: "span.writer"},
+			BookType:         config.GoquerySelectorConfig{Selector: "span.type"},
+			LastUpdate:       config.GoquerySelectorConfig{Selector: "span.update-date"},
+			LastChapter:      config.GoquerySelectorConfig{Selector: "span.update-chapter"},
+			BookChapterURL:   config.GoquerySelectorConfig{Selector: "ul#chapters>li>a", Attr: "href"},
+			BookChapterTitle: config.GoquerySelectorConfig{Selector: "ul#chapters>li>a"},
+			ChapterTitle:     config.GoquerySelectorConfig{Selector: "h1.chapter-title"},
+			ChapterContent:   config.GoquerySelectorConfig{Selector: "div#content>p"},
+		},
+		AvailabilityConfig: config.AvailabilityConfig{URL: s.server.URL + "/", CheckString: CheckString},
+		ClientConfig: config.ClientConfig{
+			RateLimit: config.RateLimitConfig{QueueSize: 4, Interval: time.Millisecond},
+			CircuitBreaker: config.CircuitBreakerConfig{
+				FailureThreshold: 5, SuccessThreshold: 1, RecoverDuration: 100 * time.Millisecond, OpenQueueRatio: 0.5,
+			},
+			Retry: config.RetryConfig{MaxRetries: 2, BaseInterval: time.Millisecond, IntervalType: "static"},
+		},
+	}
+}
+
+func (s *Server) takeFault(path string) *Fault {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests[path]++
+
+	for i, pending := range s.faults {
+		if !strings.HasPrefix(path, pending.pathPrefix) {
+			continue
+		}
+
+		if pending.remaining > 0 {
+			pending.remaining--
+			if pending.remaining == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+
+		return &pending.fault
+	}
+
+	return nil
+}
+
+func (s *Server) serve(res http.ResponseWriter, req *http.Request) {
+	fault := s.takeFault(req.URL.Path)
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-req.Context().Done():
+				return
+			}
+		}
+
+		if fault.RetryAfter > 0 {
+			res.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+		}
+
+		if fault.StatusCode > 0 {
+			res.WriteHeader(fault.StatusCode)
+			return
+		}
+	}
+
+	page, ok := s.page(req.URL.Path)
+	if fault != nil && fault.Body != "" {
+		page, ok = fault.Body, true
+	}
+	if !ok {
+		http.NotFound(res, req)
+		return
+	}
+
+	body := page
+	if s.encoder != nil {
+		var err error
+		body, err = s.encoder.String(page)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res.Header().Set("Content-Type", "text/html; charset="+string(s.encoding))
+	res.Write([]byte(body))
+}
+
+func (s *Server) page(path string) (string, bool) {
+	if path == "/" {
+		return fmt.Sprintf("<html><body><h1>%s</h1></body></html>", CheckString), true
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "book" {
+		return "", false
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", false
+	}
+
+	bk, ok := s.books[id]
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case len(parts) == 2:
+		return bookPage(bk), true
+	case len(parts) == 3 && parts[2] == "chapters":
+		return chapterListPage(bk), true
+	case len(parts) == 4 && parts[2] == "chapter":
+		index, err := strconv.Atoi(strings.TrimSuffix(parts[3], ".html"))
+		if err != nil || index < 0 || index >= len(bk.Chapters) {
+			return "", false
+		}
+
+		return chapterPage(bk.Chapters[index]), true
+	}
+
+	return "", false
+}
+
+func bookPage(bk Book) string {
+	return fmt.Sprintf(
+		`<html><body><h1 class="title">%s</h1><span class="writer">%s</span><span class="type">%s</span>`+
+			`<span class="update-date">%s</span><span class="update-chapter">%s</span></body></html>`,
+		html.EscapeString(bk.Title), html.EscapeString(bk.Writer), html.EscapeString(bk.Type),
+		html.EscapeString(bk.UpdateDate), html.EscapeString(bk.UpdateChapter()),
+	)
+}
+
+func chapterListPage(bk Book) string {
+	var builder strings.Builder
+	builder.WriteString(`<html><body><ul id="chapters">`)
+	for i, chapter := range bk.Chapters {
+		fmt.Fprintf(&builder, `<li><a href="/book/%d/chapter/%d.html">%s</a></li>`, bk.ID, i, html.EscapeString(chapter.Title))
+	}
+	builder.WriteString(`</ul></body></html>`)
+
+	return builder.String()
+}
+
+func chapterPage(chapter Chapter) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, `<html><body><h1 class="chapter-title">%s</h1><div id="content">`, html.EscapeString(chapter.Title))
+	for _, line := range strings.Split(chapter.Content, "\n") {
+		fmt.Fprintf(&builder, "<p>%s</p>", html.EscapeString(line))
+	}
+	builder.WriteString(`</div></body></html>`)
+
+	return builder.String()
+}
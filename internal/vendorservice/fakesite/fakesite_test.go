@@ -0,0 +1,224 @@
+package fakesite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var testDataset = Dataset{
+	Books: []Book{
+		{
+			ID: 1, Title: "測試書名", Writer: "測試作者", Type: "玄幻", UpdateDate: "2020-01-01",
+			Chapters: []Chapter{
+				{Title: "第一章 開始", Content: "第一行\n第二行"},
+				{Title: "第二章 完結", Content: "最後一行"},
+			},
+		},
+	},
+}
+
+func get(t *testing.T, cli *http.Client, url string, decodeMethod client.DecodeMethod) (*http.Response, string) {
+	t.Helper()
+
+	resp, err := cli.Get(url)
+	if err != nil {
+		t.Fatalf("get %s fail: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s fail: %v", url, err)
+	}
+
+	decoded, err := client.NewDecoder(decodeMethod).Decode(string(body))
+	if err != nil {
+		t.Fatalf("decode %s fail: %v", url, err)
+	}
+
+	return resp, decoded
+}
+
+func TestServer_Pages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		decodeMethod client.DecodeMethod
+		path         string
+		wantStatus   int
+		wantContains []string
+	}{
+		{
+			name: "availability page", decodeMethod: client.DecodeMethodUTF8, path: "/",
+			wantStatus: http.StatusOK, wantContains: []string{CheckString},
+		},
+		{
+			name: "book page in utf8", decodeMethod: client.DecodeMethodUTF8, path: "/book/1/",
+			wantStatus: http.StatusOK, wantContains: []string{"測試書名", "測試作者", "玄幻", "2020-01-01", "第二章 完結"},
+		},
+		{
+			name: "book page in gbk", decodeMethod: client.DecodeMethodGBK, path: "/book/1/",
+			wantStatus: http.StatusOK, wantContains: []string{"測試書名", "測試作者"},
+		},
+		{
+			name: "book page in big5", decodeMethod: client.DecodeMethodBig5, path: "/book/1/",
+			wantStatus: http.StatusOK, wantContains: []string{"測試書名", "測試作者"},
+		},
+		{
+			name: "chapter list", decodeMethod: client.DecodeMethodUTF8, path: "/book/1/chapters/",
+			wantStatus: http.StatusOK, wantContains: []string{`href="/book/1/chapter/0.html"`, "第一章 開始"},
+		},
+		{
+			name: "chapter", decodeMethod: client.DecodeMethodBig5, path: "/book/1/chapter/0.html",
+			wantStatus: http.StatusOK, wantContains: []string{"第一章 開始", "<p>第一行</p><p>第二行</p>"},
+		},
+		{
+			name: "chapter out of range", decodeMethod: client.DecodeMethodUTF8, path: "/book/1/chapter/2.html",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "book not exist", decodeMethod: client.DecodeMethodUTF8, path: "/book/2/",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(testDataset, test.decodeMethod)
+			defer server.Close()
+
+			resp, body := get(t, http.DefaultClient, server.URL()+test.path, test.decodeMethod)
+			assert.Equal(t, test.wantStatus, resp.StatusCode)
+			for _, want := range test.wantContains {
+				assert.Contains(t, body, want)
+			}
+			assert.Equal(t, 1, server.Requests(test.path))
+		})
+	}
+}
+
+func TestServer_InjectFault(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		pathPrefix     string
+		count          int
+		fault          Fault
+		path           string
+		wantStatuses   []int
+		wantRetryAfter string
+		wantBodies     []string // the text every response contains if not empty
+	}{
+		{
+			name:       "server error for next requests",
+			pathPrefix: "/book/1/",
+			count:      2,
+			fault:      FaultServerError,
+			path:       "/book/1/chapters/",
+			wantStatuses: []int{
+				http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK,
+			},
+		},
+		{
+			name:           "rate limit with retry after",
+			pathPrefix:     "/",
+			count:          1,
+			fault:          FaultRateLimit(2 * time.Second),
+			path:           "/",
+			wantStatuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			wantRetryAfter: "2",
+		},
+		{
+			name:         "layout change keeps status",
+			pathPrefix:   "/book/",
+			count:        1,
+			fault:        FaultLayoutChange,
+			path:         "/book/1/",
+			wantStatuses: []int{http.StatusOK, http.StatusOK},
+			wantBodies:   []string{"new-layout", "測試書名"},
+		},
+		{
+			name:         "fault of other path is not applied",
+			pathPrefix:   "/book/2/",
+			count:        1,
+			fault:        FaultServerError,
+			path:         "/book/1/",
+			wantStatuses: []int{http.StatusOK},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := NewServer(testDataset, client.DecodeMethodUTF8)
+			defer server.Close()
+			server.InjectFault(test.pathPrefix, test.count, test.fault)
+
+			var statuses []int
+			for i := range test.wantStatuses {
+				resp, body := get(t, http.DefaultClient, server.URL()+test.path, client.DecodeMethodUTF8)
+				statuses = append(statuses, resp.StatusCode)
+				if len(test.wantBodies) > 0 {
+					assert.Contains(t, body, test.wantBodies[i])
+				}
+				if resp.StatusCode == http.StatusTooManyRequests {
+					assert.Equal(t, test.wantRetryAfter, resp.Header.Get("Retry-After"))
+				}
+			}
+
+			assert.Equal(t, test.wantStatuses, statuses)
+			assert.Equal(t, len(test.wantStatuses), server.TotalRequests())
+		})
+	}
+}
+
+func TestServer_InjectFault_Timeout(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(testDataset, client.DecodeMethodUTF8)
+	defer server.Close()
+	server.InjectFault("/", 1, FaultTimeout(time.Minute))
+
+	cli := &http.Client{Timeout: 50 * time.Millisecond}
+	_, err := cli.Get(server.URL() + "/")
+
+	var netErr interface{ Timeout() bool }
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout())
+
+	resp, body := get(t, cli, server.URL()+"/", client.DecodeMethodUTF8)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.Contains(body, CheckString))
+}
+
+func TestServer_SiteConfig(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer(testDataset, client.DecodeMethodGBK)
+	defer server.Close()
+
+	conf := server.SiteConfig("/books")
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, conf.AvailabilityConfig.URL, nil)
+	assert.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, client.DecodeMethodGBK, conf.DecodeMethod)
+	assert.Equal(t, server.URL()+"/book/%v/", conf.URL.Base)
+	assert.Equal(t, "/books", conf.Storage)
+}
@@ -0,0 +1,214 @@
+package vendor_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	mockrepo "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	serv "github.com/htchan/BookSpider/internal/service"
+	serviceV1 "github.com/htchan/BookSpider/internal/service/v1"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/htchan/BookSpider/internal/vendorservice/ck101"
+	"github.com/htchan/BookSpider/internal/vendorservice/hjwzw"
+	"github.com/htchan/BookSpider/internal/vendorservice/uukanshu"
+	"github.com/htchan/BookSpider/internal/vendorservice/xbiquge"
+	"github.com/htchan/BookSpider/internal/vendorservice/xqishu"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/semaphore"
+)
+
+// record captures the fixtures from vendor sites instead of replaying them,
+// run `make record-fixtures` to recapture
+var record = flag.Bool("record", false, "record vendor fixtures from network")
+
+const fixtureDirectory = "test_resources/fixtures"
+
+func TestMain(m *testing.M) {
+	leak := flag.Bool("leak", false, "check for memory leaks")
+	flag.Parse()
+
+	if *leak {
+		goleak.VerifyTestMain(m)
+	} else {
+		os.Exit(m.Run())
+	}
+}
+
+func fixtureSiteConfig(t *testing.T, decodeMethod client.DecodeMethod) config.SiteConfig {
+	return config.SiteConfig{
+		DecodeMethod:   decodeMethod,
+		RequestTimeout: 30 * time.Second,
+		Storage:        t.TempDir(),
+		ClientConfig: config.ClientConfig{
+			// capture run should be gentle to vendor site
+			RateLimit: config.RateLimitConfig{QueueSize: 1, Interval: time.Second},
+			CircuitBreaker: config.CircuitBreakerConfig{
+				FailureThreshold: 10, SuccessThreshold: 1, RecoverDuration: time.Minute, OpenQueueRatio: 1,
+			},
+			Retry: config.RetryConfig{MaxRetries: 2, BaseInterval: time.Second, IntervalType: "exponential"},
+		},
+	}
+}
+
+// TestVendorFixtures runs update and download of a book of every vendor
+// against the recorded responses, so parser regressions are caught without
+// network access. vendors without fixture are skipped until recorded
+func TestVendorFixtures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		host             string
+		vendorService    vendor.VendorService
+		decodeMethod     client.DecodeMethod
+		bookID           int
+		wantTitle        string
+		wantWriter       string
+		wantType         string
+		wantChapter      string
+		wantChapterCount int
+		wantChapterTitle string
+	}{
+		// expectations of vendors without fixture are filled after first capture
+		{
+			host:          ck101.Host,
+			vendorService: &ck101.VendorService{},
+			decodeMethod:  client.DecodeMethodBig5,
+			bookID:        1,
+		},
+		{
+			host:             hjwzw.Host,
+			vendorService:    &hjwzw.VendorService{},
+			decodeMethod:     client.DecodeMethodUTF8,
+			bookID:           37656,
+			wantTitle:        "恐怖修仙世界",
+			wantWriter:       "龍蛇枝",
+			wantType:         "仙俠",
+			wantChapter:      "完本感言",
+			wantChapterCount: 33,
+			wantChapterTitle: "第1章 黑暗恐懼",
+		},
+		{
+			host:             uukanshu.Host,
+			vendorService:    &uukanshu.VendorService{},
+			decodeMethod:     client.DecodeMethodGBK,
+			bookID:           1248,
+			wantTitle:        "从零开始",
+			wantWriter:       "雷云风暴",
+			wantType:         "网游竞技小说",
+			wantChapter:      "第二十三卷 第6章 放飞希望（完结篇）",
+			wantChapterCount: 80,
+			wantChapterTitle: "第二十三卷 第6章 放飞希望（完结篇）",
+		},
+		{
+			host:             xbiquge.Host,
+			vendorService:    &xbiquge.VendorService{},
+			decodeMethod:     client.DecodeMethodGBK,
+			bookID:           45525,
+			wantTitle:        "神印王座II皓月当空",
+			wantWriter:       "唐家三少",
+			wantType:         "都市小说",
+			wantChapter:      "正文 第二百二十章 陷阱，绝境？",
+			wantChapterCount: 42,
+			wantChapterTitle: "第二百二十章 陷阱，绝境？",
+		},
+		{
+			host:          xqishu.Host,
+			vendorService: &xqishu.VendorService{},
+			decodeMethod:  client.DecodeMethodUTF8,
+			bookID:        1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(fixtureDirectory, test.host+".json")
+
+			archive := client.NewFixtureArchive()
+			if !*record {
+				var err error
+				archive, err = client.LoadFixtureArchive(path)
+				if errors.Is(err, os.ErrNotExist) {
+					t.Skipf("no fixture of %s, record it with -record flag", test.host)
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+
+			ctrl := gomock.NewController(t)
+			rpo := mockrepo.NewMockRepository(ctrl)
+			rpo.EXPECT().SaveWriter(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			rpo.EXPECT().CreateBook(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			rpo.EXPECT().UpdateBook(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			rpo.EXPECT().SaveError(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			conf := fixtureSiteConfig(t, test.decodeMethod)
+			service := serviceV1.NewService(test.host, rpo, test.vendorService, semaphore.NewWeighted(10), conf)
+			service.WrapClient(func(cli client.BookClient) client.BookClient {
+				if *record {
+					return client.NewRecordClient(cli.(client.ResponseFetcher), archive)
+				}
+
+				return client.NewReplayClient(archive)
+			})
+			if *record {
+				t.Cleanup(func() {
+					if err := archive.Save(path); err != nil {
+						t.Errorf("save fixture of %s fail: %v", test.host, err)
+					}
+				})
+			}
+
+			ctx := context.Background()
+			bk := model.NewBook(test.host, test.bookID)
+			bk.Status = model.StatusError
+
+			var updateStats serv.UpdateStats
+			err := service.UpdateBook(ctx, &bk, &updateStats)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.wantTitle, bk.Title)
+			assert.Equal(t, test.wantWriter, bk.Writer.Name)
+			assert.Equal(t, test.wantType, bk.Type)
+			assert.Equal(t, test.wantChapter, bk.UpdateChapter)
+
+			chapters, err := service.ChapterList(ctx, &bk)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, test.wantChapterCount, len(chapters))
+
+			if assert.NotEmpty(t, chapters) {
+				err = service.DownloadChapter(ctx, &chapters[0])
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantChapterTitle, chapters[0].Title)
+				assert.NotEmpty(t, chapters[0].Content)
+			}
+
+			bk.Status = model.StatusEnd
+
+			var downloadStats serv.DownloadStats
+			err = service.DownloadBook(ctx, &bk, &downloadStats)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), downloadStats.Success.Load())
+			assert.True(t, bk.IsDownloaded)
+
+			files, err := filepath.Glob(filepath.Join(conf.Storage, "*.txt"))
+			assert.NoError(t, err)
+			assert.Len(t, files, 1)
+		})
+	}
+}